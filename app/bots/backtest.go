@@ -0,0 +1,160 @@
+package bots
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/livingdolls/go-blockchain-simulate/app/dto"
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+	"github.com/livingdolls/go-blockchain-simulate/app/services"
+)
+
+const defaultBacktestUSD = 10000
+
+// simulation - paper trading beberapa bot terhadap harga replay.
+// satu keputusan strategy per tick, eksekusi di harga tick tanpa fee dan tanpa market impact
+type simulation struct {
+	bots []*paperBot
+}
+
+type paperBot struct {
+	bot      models.TradingBot
+	strategy Strategy
+	rng      *rand.Rand
+	history  []float64
+	usd      float64
+	yte      float64
+	initial  float64
+	price    float64
+	buys     int
+	sells    int
+}
+
+// NewSimulation implements [services.ReplayBotSimulator].
+// rng di-seed dari seed + bot id agar hasil backtest deterministik
+func (m *Manager) NewSimulation(botIDs []int64, seed int64, initialUSD, initialYTE float64) (services.ReplaySimulation, error) {
+	if initialUSD < 0 || initialYTE < 0 {
+		return nil, fmt.Errorf("initial balance must not be negative")
+	}
+
+	if initialUSD == 0 && initialYTE == 0 {
+		initialUSD = defaultBacktestUSD
+	}
+
+	sim := &simulation{}
+	seen := make(map[int64]bool)
+
+	for _, id := range botIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		bot, err := m.bots.GetByID(id)
+		if err != nil {
+			return nil, err
+		}
+
+		strategy, err := NewStrategy(bot)
+		if err != nil {
+			return nil, err
+		}
+
+		sim.bots = append(sim.bots, &paperBot{
+			bot:      bot,
+			strategy: strategy,
+			rng:      rand.New(rand.NewSource(seed + bot.ID)),
+			usd:      initialUSD,
+			yte:      initialYTE,
+		})
+	}
+
+	return sim, nil
+}
+
+// OnTick implements [services.ReplaySimulation].
+func (s *simulation) OnTick(tick models.MarketTick) {
+	for _, p := range s.bots {
+		p.onTick(tick.Price)
+	}
+}
+
+// Results implements [services.ReplaySimulation].
+func (s *simulation) Results() []dto.BotBacktestResult {
+	results := make([]dto.BotBacktestResult, 0, len(s.bots))
+
+	for _, p := range s.bots {
+		equity := p.usd + p.yte*p.price
+
+		result := dto.BotBacktestResult{
+			BotID:         p.bot.ID,
+			Name:          p.bot.Name,
+			Strategy:      p.bot.Strategy,
+			Trades:        p.buys + p.sells,
+			Buys:          p.buys,
+			Sells:         p.sells,
+			USDBalance:    p.usd,
+			YTEBalance:    p.yte,
+			InitialEquity: p.initial,
+			Equity:        equity,
+			PnL:           equity - p.initial,
+		}
+
+		if p.initial > 0 {
+			result.PnLPercent = result.PnL / p.initial * 100
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+func (p *paperBot) onTick(price float64) {
+	// equity awal dihitung di harga tick pertama
+	if p.price == 0 {
+		p.initial = p.usd + p.yte*price
+	}
+	p.price = price
+
+	// history sama seperti snapshot live: lookback harga terakhir termasuk harga saat ini
+	p.history = append(p.history, price)
+	if len(p.history) > p.bot.Lookback {
+		p.history = p.history[len(p.history)-p.bot.Lookback:]
+	}
+
+	decision := p.strategy.Decide(Snapshot{
+		Price:      price,
+		History:    p.history,
+		YTEBalance: p.yte,
+		USDBalance: p.usd,
+	}, p.rng)
+
+	// batas saldo dan pembulatan sama seperti Manager.tick
+	amount := decision.Amount
+	switch decision.Side {
+	case services.SellTransaction:
+		amount = math.Min(amount, p.yte)
+	case services.BuyTransaction:
+		amount = math.Min(amount, p.usd/price)
+	default:
+		return
+	}
+
+	amount = math.Floor(amount*100) / 100
+	if amount <= 0 {
+		return
+	}
+
+	if decision.Side == services.BuyTransaction {
+		p.usd -= amount * price
+		p.yte += amount
+		p.buys++
+		return
+	}
+
+	p.yte -= amount
+	p.usd += amount * price
+	p.sells++
+}
@@ -0,0 +1,556 @@
+package bots
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/livingdolls/go-blockchain-simulate/app/dto"
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+	"github.com/livingdolls/go-blockchain-simulate/app/repository"
+	"github.com/livingdolls/go-blockchain-simulate/app/services"
+	"github.com/livingdolls/go-blockchain-simulate/logger"
+	"github.com/livingdolls/go-blockchain-simulate/security"
+	"github.com/livingdolls/go-blockchain-simulate/utils"
+	"go.uber.org/zap"
+)
+
+// Manager - mengatur lifecycle trading bots
+// setiap bot yang RUNNING punya goroutine sendiri yang trading setiap interval
+// bot adalah user biasa: transaksi ditandatangani private key bot dan lewat TransactionService
+// private key disimpan terenkripsi oleh sealer, tanpa sealer bot baru tidak bisa dibuat
+type Manager struct {
+	bots        repository.BotRepository
+	users       repository.UserRepository
+	wallets     repository.UserWalletRepository
+	balances    repository.UserBalanceRepository
+	market      repository.MarketRepository
+	balanceSvc  services.BalanceService
+	txService   services.TransactionService
+	sealer      security.KeySealer
+	mu          sync.Mutex
+	runners     map[int64]*runner
+	tickTimeout time.Duration
+}
+
+type runner struct {
+	bot      models.TradingBot
+	strategy Strategy
+	key      *ecdsa.PrivateKey
+	rng      *rand.Rand
+	stopChan chan struct{}
+	doneChan chan struct{}
+}
+
+func NewManager(
+	bots repository.BotRepository,
+	users repository.UserRepository,
+	wallets repository.UserWalletRepository,
+	balances repository.UserBalanceRepository,
+	market repository.MarketRepository,
+	balanceSvc services.BalanceService,
+	txService services.TransactionService,
+	sealer security.KeySealer,
+) *Manager {
+	return &Manager{
+		bots:        bots,
+		users:       users,
+		wallets:     wallets,
+		balances:    balances,
+		market:      market,
+		balanceSvc:  balanceSvc,
+		txService:   txService,
+		sealer:      sealer,
+		runners:     make(map[int64]*runner),
+		tickTimeout: 10 * time.Second,
+	}
+}
+
+// Create mendaftarkan bot sebagai user baru dengan key pair sendiri
+// dan top-up saldo USD awal jika diminta. Semua insert dalam satu tx, gagal funding = bot tidak dibuat
+func (m *Manager) Create(req dto.CreateBotRequest) (models.TradingBot, error) {
+	bot := models.TradingBot{
+		Name:             strings.TrimSpace(req.Name),
+		Strategy:         strings.ToUpper(strings.TrimSpace(req.Strategy)),
+		IntervalSeconds:  req.IntervalSeconds,
+		MinAmount:        req.MinAmount,
+		MaxAmount:        req.MaxAmount,
+		Lookback:         req.Lookback,
+		ThresholdPercent: req.ThresholdPercent,
+		SpreadPercent:    req.SpreadPercent,
+		TargetInventory:  req.TargetInventory,
+	}
+
+	if bot.Name == "" {
+		return models.TradingBot{}, fmt.Errorf("%w: name is required", entity.ErrInvalidBotConfig)
+	}
+
+	if err := applyDefaults(&bot); err != nil {
+		return models.TradingBot{}, err
+	}
+
+	if req.InitialUSD < 0 {
+		return models.TradingBot{}, fmt.Errorf("%w: initial_usd must not be negative", entity.ErrInvalidBotConfig)
+	}
+
+	if m.sealer == nil {
+		return models.TradingBot{}, entity.ErrBotKeysUnavailable
+	}
+
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		return models.TradingBot{}, fmt.Errorf("generate bot key: %v", err)
+	}
+
+	bot.Address = strings.ToLower(crypto.PubkeyToAddress(privateKey.PublicKey).Hex())
+	bot.PrivateKey, err = m.sealer.Seal(hex.EncodeToString(crypto.FromECDSA(privateKey)), bot.Address)
+	if err != nil {
+		return models.TradingBot{}, fmt.Errorf("seal bot key: %v", err)
+	}
+
+	// daftarkan bot sebagai user biasa
+	user := models.User{
+		Name:      bot.Name,
+		Address:   bot.Address,
+		PublicKey: hexutil.Encode(crypto.FromECDSAPub(&privateKey.PublicKey)),
+	}
+
+	tx, err := m.bots.BeginTx()
+	if err != nil {
+		return models.TradingBot{}, fmt.Errorf("begin tx: %v", err)
+	}
+	defer tx.Rollback()
+
+	if err := m.users.CreateWithTx(tx, user); err != nil {
+		return models.TradingBot{}, fmt.Errorf("create bot user: %v", err)
+	}
+
+	if err := m.wallets.UpsertEmptyIfNotExistsWithTx(tx, bot.Address); err != nil {
+		return models.TradingBot{}, fmt.Errorf("create bot wallet: %v", err)
+	}
+
+	if err := m.balances.UpsertEmptyIfNotExistsWithTx(tx, bot.Address); err != nil {
+		return models.TradingBot{}, fmt.Errorf("create bot balance: %v", err)
+	}
+
+	id, err := m.bots.CreateWithTx(tx, bot)
+	if err != nil {
+		return models.TradingBot{}, fmt.Errorf("create bot: %v", err)
+	}
+
+	if req.InitialUSD > 0 {
+		if _, err := m.balanceSvc.TopUpUSDBalanceWithTx(tx, bot.Address, req.InitialUSD, fmt.Sprintf("bot-%d-init", id), "initial bot funding"); err != nil {
+			return models.TradingBot{}, fmt.Errorf("fund bot: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.TradingBot{}, fmt.Errorf("commit tx: %v", err)
+	}
+
+	logger.LogInfo("Trading bot created",
+		zap.Int64("bot_id", id),
+		zap.String("address", bot.Address),
+		zap.String("strategy", bot.Strategy),
+	)
+
+	return m.bots.GetByID(id)
+}
+
+// List semua bot beserta status runtime
+func (m *Manager) List() ([]models.TradingBot, error) {
+	return m.bots.GetAll()
+}
+
+// Configure update konfigurasi bot, bot yang sedang jalan di-restart dengan config baru
+func (m *Manager) Configure(id int64, req dto.UpdateBotConfigRequest) (models.TradingBot, error) {
+	bot, err := m.bots.GetByID(id)
+	if err != nil {
+		return models.TradingBot{}, err
+	}
+
+	if req.Strategy != "" {
+		bot.Strategy = strings.ToUpper(strings.TrimSpace(req.Strategy))
+	}
+	if req.IntervalSeconds != nil {
+		bot.IntervalSeconds = *req.IntervalSeconds
+	}
+	if req.MinAmount != nil {
+		bot.MinAmount = *req.MinAmount
+	}
+	if req.MaxAmount != nil {
+		bot.MaxAmount = *req.MaxAmount
+	}
+	if req.Lookback != nil {
+		bot.Lookback = *req.Lookback
+	}
+	if req.ThresholdPercent != nil {
+		bot.ThresholdPercent = *req.ThresholdPercent
+	}
+	if req.SpreadPercent != nil {
+		bot.SpreadPercent = *req.SpreadPercent
+	}
+	if req.TargetInventory != nil {
+		bot.TargetInventory = *req.TargetInventory
+	}
+
+	if err := applyDefaults(&bot); err != nil {
+		return models.TradingBot{}, err
+	}
+
+	if err := m.bots.UpdateConfig(bot); err != nil {
+		return models.TradingBot{}, fmt.Errorf("update bot config: %v", err)
+	}
+
+	if m.isRunning(id) {
+		m.stopRunner(id)
+		if err := m.startRunner(bot); err != nil {
+			return models.TradingBot{}, err
+		}
+	}
+
+	return m.bots.GetByID(id)
+}
+
+// Start menjalankan bot dan menyimpan status RUNNING
+func (m *Manager) Start(id int64) (models.TradingBot, error) {
+	bot, err := m.bots.GetByID(id)
+	if err != nil {
+		return models.TradingBot{}, err
+	}
+
+	if err := m.startRunner(bot); err != nil {
+		return models.TradingBot{}, err
+	}
+
+	if err := m.bots.UpdateStatus(id, models.BotStatusRunning); err != nil {
+		m.stopRunner(id)
+		return models.TradingBot{}, fmt.Errorf("update bot status: %v", err)
+	}
+
+	bot.Status = models.BotStatusRunning
+	return bot, nil
+}
+
+// StopBot menghentikan bot dan menyimpan status STOPPED
+func (m *Manager) StopBot(id int64) (models.TradingBot, error) {
+	bot, err := m.bots.GetByID(id)
+	if err != nil {
+		return models.TradingBot{}, err
+	}
+
+	m.stopRunner(id)
+
+	if err := m.bots.UpdateStatus(id, models.BotStatusStopped); err != nil {
+		return models.TradingBot{}, fmt.Errorf("update bot status: %v", err)
+	}
+
+	bot.Status = models.BotStatusStopped
+	return bot, nil
+}
+
+// Resume menjalankan kembali bot yang statusnya RUNNING (dipanggil saat startup)
+// sekaligus mengenkripsi key plaintext yang tersimpan sebelum enkripsi diaktifkan
+func (m *Manager) Resume() {
+	bots, err := m.bots.GetAll()
+	if err != nil {
+		logger.LogError("Failed to load bots", err)
+		return
+	}
+
+	resumed := 0
+	for _, bot := range bots {
+		m.sealLegacyKey(&bot)
+
+		if bot.Status != models.BotStatusRunning {
+			continue
+		}
+
+		if err := m.startRunner(bot); err != nil {
+			logger.LogWarn("Failed to resume bot", zap.Int64("bot_id", bot.ID), zap.Error(err))
+			continue
+		}
+		resumed++
+	}
+
+	logger.LogInfo("Trading bots resumed", zap.Int("count", resumed))
+}
+
+// Stop menghentikan semua goroutine bot tanpa mengubah status di database
+// sehingga bot otomatis jalan lagi saat aplikasi restart
+func (m *Manager) Stop() {
+	m.mu.Lock()
+	ids := make([]int64, 0, len(m.runners))
+	for id := range m.runners {
+		ids = append(ids, id)
+	}
+	m.mu.Unlock()
+
+	for _, id := range ids {
+		m.stopRunner(id)
+	}
+}
+
+func (m *Manager) isRunning(id int64) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, ok := m.runners[id]
+	return ok
+}
+
+func (m *Manager) startRunner(bot models.TradingBot) error {
+	strategy, err := NewStrategy(bot)
+	if err != nil {
+		return err
+	}
+
+	key, err := m.openKey(bot)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.runners[bot.ID]; ok {
+		return nil
+	}
+
+	r := &runner{
+		bot:      bot,
+		strategy: strategy,
+		key:      key,
+		rng:      rand.New(rand.NewSource(time.Now().UnixNano() + bot.ID)),
+		stopChan: make(chan struct{}),
+		doneChan: make(chan struct{}),
+	}
+
+	m.runners[bot.ID] = r
+	go m.run(r)
+
+	logger.LogInfo("Trading bot started",
+		zap.Int64("bot_id", bot.ID),
+		zap.String("strategy", strategy.Name()),
+		zap.Int("interval_seconds", bot.IntervalSeconds),
+	)
+
+	return nil
+}
+
+// openKey dekripsi private key bot, key plaintext dari versi lama masih diterima
+func (m *Manager) openKey(bot models.TradingBot) (*ecdsa.PrivateKey, error) {
+	plain := bot.PrivateKey
+	if security.IsSealed(plain) {
+		if m.sealer == nil {
+			return nil, entity.ErrBotKeysUnavailable
+		}
+
+		var err error
+		plain, err = m.sealer.Open(bot.PrivateKey, bot.Address)
+		if err != nil {
+			return nil, fmt.Errorf("open bot private key: %v", err)
+		}
+	}
+
+	key, err := crypto.HexToECDSA(plain)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bot private key: %v", err)
+	}
+
+	return key, nil
+}
+
+// sealLegacyKey enkripsi ulang key plaintext yang tersimpan sebelum enkripsi diaktifkan
+func (m *Manager) sealLegacyKey(bot *models.TradingBot) {
+	if m.sealer == nil || security.IsSealed(bot.PrivateKey) {
+		return
+	}
+
+	sealed, err := m.sealer.Seal(bot.PrivateKey, bot.Address)
+	if err != nil {
+		logger.LogWarn("Failed to seal bot key", zap.Int64("bot_id", bot.ID), zap.Error(err))
+		return
+	}
+
+	if err := m.bots.UpdatePrivateKey(bot.ID, sealed); err != nil {
+		logger.LogWarn("Failed to store sealed bot key", zap.Int64("bot_id", bot.ID), zap.Error(err))
+		return
+	}
+
+	bot.PrivateKey = sealed
+	logger.LogInfo("Bot key sealed", zap.Int64("bot_id", bot.ID))
+}
+
+func (m *Manager) stopRunner(id int64) {
+	m.mu.Lock()
+	r, ok := m.runners[id]
+	if ok {
+		delete(m.runners, id)
+	}
+	m.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	close(r.stopChan)
+	<-r.doneChan
+
+	logger.LogInfo("Trading bot stopped", zap.Int64("bot_id", id))
+}
+
+func (m *Manager) run(r *runner) {
+	defer close(r.doneChan)
+
+	ticker := time.NewTicker(time.Duration(r.bot.IntervalSeconds) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), m.tickTimeout)
+			if err := m.tick(ctx, r); err != nil {
+				logger.LogWarn("Trading bot tick failed",
+					zap.Int64("bot_id", r.bot.ID),
+					zap.Error(err),
+				)
+			}
+			cancel()
+		case <-r.stopChan:
+			return
+		}
+	}
+}
+
+// tick - satu siklus bot: ambil snapshot market, jalankan strategy, sign dan submit transaksi
+func (m *Manager) tick(ctx context.Context, r *runner) error {
+	snap, err := m.snapshot(r.bot)
+	if err != nil {
+		return err
+	}
+
+	decision := r.strategy.Decide(snap, r.rng)
+	if decision.Side == "" {
+		return nil
+	}
+
+	// jangan submit transaksi yang pasti gagal karena saldo
+	amount := decision.Amount
+	switch decision.Side {
+	case services.SellTransaction:
+		amount = math.Min(amount, snap.YTEBalance)
+	case services.BuyTransaction:
+		if snap.Price > 0 {
+			amount = math.Min(amount, snap.USDBalance/snap.Price)
+		}
+	}
+
+	amount = math.Floor(amount*100) / 100
+	if amount <= 0 {
+		return nil
+	}
+
+	nonce := m.txService.GenerateTransactionNonce(ctx, r.bot.Address)
+
+	signature, err := utils.SignPrefixedMessage(r.key, services.BuySellMessage(decision.Side, amount, nonce))
+	if err != nil {
+		return fmt.Errorf("sign transaction: %v", err)
+	}
+
+	var tx models.Transaction
+	if decision.Side == services.BuyTransaction {
+		tx, err = m.txService.Buy(ctx, r.bot.Address, signature, nonce, amount)
+	} else {
+		tx, err = m.txService.Sell(ctx, r.bot.Address, signature, nonce, amount)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	logger.LogDebug("Trading bot submitted transaction",
+		zap.Int64("bot_id", r.bot.ID),
+		zap.Int64("tx_id", tx.ID),
+		zap.String("side", string(decision.Side)),
+		zap.Float64("amount", amount),
+		zap.Float64("price", snap.Price),
+	)
+
+	return nil
+}
+
+func (m *Manager) snapshot(bot models.TradingBot) (Snapshot, error) {
+	state, err := m.market.GetState()
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("get market state: %v", err)
+	}
+
+	ticks, err := m.market.GetVolumeHistory(bot.Lookback, 0)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("get price history: %v", err)
+	}
+
+	// ticks urut DESC, balik supaya dari yang paling lama
+	history := make([]float64, len(ticks))
+	for i, tick := range ticks {
+		history[len(ticks)-1-i] = tick.Price
+	}
+
+	user, err := m.users.GetByAddressWithBalance(bot.Address)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("get bot balance: %v", err)
+	}
+
+	return Snapshot{
+		Price:      state.Price,
+		History:    history,
+		YTEBalance: user.YTEBalance,
+		USDBalance: user.USDBalance,
+	}, nil
+}
+
+// applyDefaults isi nilai default dan validasi konfigurasi bot
+func applyDefaults(bot *models.TradingBot) error {
+	if bot.IntervalSeconds <= 0 {
+		bot.IntervalSeconds = 5
+	}
+	if bot.Lookback <= 0 {
+		bot.Lookback = 20
+	}
+	if bot.ThresholdPercent <= 0 {
+		bot.ThresholdPercent = 0.5
+	}
+	if bot.SpreadPercent <= 0 {
+		bot.SpreadPercent = 1
+	}
+	if bot.MinAmount <= 0 {
+		bot.MinAmount = 1
+	}
+	if bot.MaxAmount <= 0 {
+		bot.MaxAmount = bot.MinAmount
+	}
+
+	if bot.MaxAmount < bot.MinAmount {
+		return fmt.Errorf("%w: max_amount must be greater than or equal to min_amount", entity.ErrInvalidBotConfig)
+	}
+
+	if bot.TargetInventory < 0 {
+		return fmt.Errorf("%w: target_inventory must not be negative", entity.ErrInvalidBotConfig)
+	}
+
+	if _, err := NewStrategy(*bot); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,190 @@
+package bots
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+	"github.com/livingdolls/go-blockchain-simulate/app/services"
+)
+
+// Snapshot - kondisi market dan inventory bot saat strategy dievaluasi
+type Snapshot struct {
+	Price      float64
+	History    []float64 // harga per block, urut dari yang paling lama
+	YTEBalance float64
+	USDBalance float64
+}
+
+// Decision - hasil strategy, Side kosong berarti bot tidak trading di tick ini
+type Decision struct {
+	Side   services.TransactionType
+	Amount float64
+}
+
+type Strategy interface {
+	Name() string
+	Decide(snap Snapshot, rng *rand.Rand) Decision
+}
+
+// NewStrategy membuat strategy sesuai konfigurasi bot
+func NewStrategy(bot models.TradingBot) (Strategy, error) {
+	sizer := amountSizer{min: bot.MinAmount, max: bot.MaxAmount}
+
+	switch bot.Strategy {
+	case models.BotStrategyRandomWalk:
+		return &randomWalkStrategy{sizer: sizer}, nil
+	case models.BotStrategyMomentum:
+		return &momentumStrategy{sizer: sizer, threshold: bot.ThresholdPercent}, nil
+	case models.BotStrategyMeanReversion:
+		return &meanReversionStrategy{sizer: sizer, threshold: bot.ThresholdPercent}, nil
+	case models.BotStrategyMarketMaker:
+		return &marketMakerStrategy{sizer: sizer, spread: bot.SpreadPercent, targetInventory: bot.TargetInventory}, nil
+	}
+
+	return nil, entity.ErrUnknownBotStrategy
+}
+
+type amountSizer struct {
+	min float64
+	max float64
+}
+
+// amount random di antara min dan max, dibulatkan 2 desimal (sesuai format signature)
+func (a amountSizer) amount(rng *rand.Rand) float64 {
+	amount := a.min
+	if a.max > a.min {
+		amount = a.min + rng.Float64()*(a.max-a.min)
+	}
+
+	return math.Max(math.Round(amount*100)/100, 0.01)
+}
+
+// randomWalkStrategy - BUY/SELL acak 50:50
+type randomWalkStrategy struct {
+	sizer amountSizer
+}
+
+func (s *randomWalkStrategy) Name() string { return models.BotStrategyRandomWalk }
+
+func (s *randomWalkStrategy) Decide(snap Snapshot, rng *rand.Rand) Decision {
+	side := services.BuyTransaction
+	if rng.Intn(2) == 1 {
+		side = services.SellTransaction
+	}
+
+	return Decision{Side: side, Amount: s.sizer.amount(rng)}
+}
+
+// momentumStrategy - ikut arah tren: BUY saat harga di atas rata-rata, SELL saat di bawah
+type momentumStrategy struct {
+	sizer     amountSizer
+	threshold float64
+}
+
+func (s *momentumStrategy) Name() string { return models.BotStrategyMomentum }
+
+func (s *momentumStrategy) Decide(snap Snapshot, rng *rand.Rand) Decision {
+	deviation, ok := deviationPercent(snap)
+	if !ok {
+		return Decision{}
+	}
+
+	switch {
+	case deviation >= s.threshold:
+		return Decision{Side: services.BuyTransaction, Amount: s.sizer.amount(rng)}
+	case deviation <= -s.threshold:
+		return Decision{Side: services.SellTransaction, Amount: s.sizer.amount(rng)}
+	}
+
+	return Decision{}
+}
+
+// meanReversionStrategy - lawan arah tren: SELL saat harga di atas rata-rata, BUY saat di bawah
+type meanReversionStrategy struct {
+	sizer     amountSizer
+	threshold float64
+}
+
+func (s *meanReversionStrategy) Name() string { return models.BotStrategyMeanReversion }
+
+func (s *meanReversionStrategy) Decide(snap Snapshot, rng *rand.Rand) Decision {
+	deviation, ok := deviationPercent(snap)
+	if !ok {
+		return Decision{}
+	}
+
+	switch {
+	case deviation >= s.threshold:
+		return Decision{Side: services.SellTransaction, Amount: s.sizer.amount(rng)}
+	case deviation <= -s.threshold:
+		return Decision{Side: services.BuyTransaction, Amount: s.sizer.amount(rng)}
+	}
+
+	return Decision{}
+}
+
+// marketMakerStrategy - quote dua sisi di sekitar harga rata-rata dengan spread
+// - harga di bawah bid (mean - spread/2) -> BUY
+// - harga di atas ask (mean + spread/2) -> SELL
+// - di dalam spread -> rebalance inventory ke target
+type marketMakerStrategy struct {
+	sizer           amountSizer
+	spread          float64
+	targetInventory float64
+}
+
+func (s *marketMakerStrategy) Name() string { return models.BotStrategyMarketMaker }
+
+func (s *marketMakerStrategy) Decide(snap Snapshot, rng *rand.Rand) Decision {
+	deviation, ok := deviationPercent(snap)
+	if !ok {
+		return Decision{}
+	}
+
+	halfSpread := s.spread / 2
+
+	switch {
+	case deviation <= -halfSpread:
+		return Decision{Side: services.BuyTransaction, Amount: s.sizer.amount(rng)}
+	case deviation >= halfSpread:
+		return Decision{Side: services.SellTransaction, Amount: s.sizer.amount(rng)}
+	}
+
+	amount := s.sizer.amount(rng)
+
+	if snap.YTEBalance < s.targetInventory {
+		return Decision{Side: services.BuyTransaction, Amount: amount}
+	}
+
+	if snap.YTEBalance > s.targetInventory {
+		return Decision{Side: services.SellTransaction, Amount: math.Min(amount, snap.YTEBalance-s.targetInventory)}
+	}
+
+	// inventory pas di target, tetap sediakan volume dua arah
+	if rng.Intn(2) == 0 {
+		return Decision{Side: services.BuyTransaction, Amount: amount}
+	}
+
+	return Decision{Side: services.SellTransaction, Amount: amount}
+}
+
+// deviationPercent - selisih harga terakhir terhadap rata-rata history dalam persen
+func deviationPercent(snap Snapshot) (float64, bool) {
+	if len(snap.History) == 0 || snap.Price <= 0 {
+		return 0, false
+	}
+
+	sum := 0.0
+	for _, p := range snap.History {
+		sum += p
+	}
+
+	mean := sum / float64(len(snap.History))
+	if mean <= 0 {
+		return 0, false
+	}
+
+	return (snap.Price - mean) / mean * 100, true
+}
@@ -2,13 +2,14 @@ package app
 
 import (
 	"github.com/jmoiron/sqlx"
+	"github.com/livingdolls/go-blockchain-simulate/app/bots"
 	"github.com/livingdolls/go-blockchain-simulate/app/handler"
+	"github.com/livingdolls/go-blockchain-simulate/app/port"
 	"github.com/livingdolls/go-blockchain-simulate/app/publisher"
 	"github.com/livingdolls/go-blockchain-simulate/app/repository"
 	"github.com/livingdolls/go-blockchain-simulate/app/services"
 	"github.com/livingdolls/go-blockchain-simulate/app/websocket"
 	"github.com/livingdolls/go-blockchain-simulate/app/worker"
-	"github.com/livingdolls/go-blockchain-simulate/rabbitmq"
 	"github.com/livingdolls/go-blockchain-simulate/redis"
 	"github.com/livingdolls/go-blockchain-simulate/security"
 )
@@ -22,12 +23,15 @@ type AppConfig struct {
 	RedisServices redis.MemoryAdapter
 
 	// Message Queue
-	RMQClient *rabbitmq.Client
+	Broker port.MessageBroker
 
 	// Auth
 	JWT      security.JWTService
 	JWTAdmin security.AdminJWTService
 
+	// Enkripsi private key bot (nil jika BOT_KEY_ENCRYPTION_KEY tidak di-set)
+	BotKeySealer security.KeySealer
+
 	// WebSocket
 	Hub         *websocket.Hub
 	PublisherWS *publisher.PublisherWS
@@ -43,11 +47,18 @@ type AppConfig struct {
 	CandleRepo      repository.CandlesRepository
 	DiscrepancyRepo repository.DiscrepancyRepository
 	AdminRepo       repository.AdminRepository
+	CondOrderRepo   repository.ConditionalOrderRepository
+	BotRepo         repository.BotRepository
+	AssetRepo       repository.AssetRepository
+	TokenRepo       repository.TokenRepository
+	TradingHaltRepo repository.TradingHaltRepository
+	ExportRepo      repository.ExportRepository
+	RetentionRepo   repository.RetentionRepository
+	OutboxRepo      repository.OutboxRepository
+	RebuildRepo     repository.LedgerRebuildRepository
 
 	// Publishers
-	PricingPublisher services.MarketPricingPublisher
-	LedgerPublisher  services.LedgerPublisher
-	RewardPublisher  services.RewardPublisher
+	RewardPublisher services.RewardPublisher
 
 	// Services
 	UserService        services.RegisterService
@@ -55,11 +66,23 @@ type AppConfig struct {
 	BalanceService     services.BalanceService
 	MarketService      services.MarketEngineService
 	CandleService      services.CandleService
+	CandleStream       services.CandleStreamService
+	MarketStream       services.MarketStreamService
 	BlockService       services.BlockService
 	RewardService      services.RewardService
 	ProfileService     services.ProfileService
 	AdminService       services.AdminService
 	AdminAuthService   services.AdminAuthService
+	CondOrderService   services.ConditionalOrderService
+	AssetService       services.AssetService
+	TokenService       services.TokenService
+	CircuitBreaker     services.CircuitBreakerService
+	ExportService      services.ExportService
+	ReplayService      services.ReplayService
+	RetentionService   services.RetentionService
+	OutboxService      services.OutboxService
+	DeadLetterService  services.DeadLetterService
+	RebuildService     services.LedgerRebuildService
 
 	// Handlers
 	UserHandler         *handler.RegisterHandler
@@ -73,15 +96,32 @@ type AppConfig struct {
 	CandleStreamHandler *handler.CandleStreamHandler
 	AdminHandler        *handler.AdminHandler
 	AdminLoginHandler   *handler.AdminLoginHandler
+	CondOrderHandler    *handler.ConditionalOrderHandler
+	BotHandler          *handler.BotHandler
+	AssetHandler        *handler.AssetHandler
+	TokenHandler        *handler.TokenHandler
+	TradingHandler      *handler.TradingHandler
+	ExportHandler       *handler.ExportHandler
+	ReplayHandler       *handler.ReplayHandler
+	RetentionHandler    *handler.RetentionHandler
+	OutboxHandler       *handler.OutboxHandler
+	DeadLetterHandler   *handler.DeadLetterHandler
+	RebuildHandler      *handler.LedgerRebuildHandler
+	WebSocketHandler    *handler.WebSocketHandler
+	RPCHandler          *handler.RPCHandler
 	// Workers
-	BlockWorker  *worker.GenerateBlockWorker
-	CandleWorker *worker.GenerateCandleWorker
+	BlockWorker     *worker.GenerateBlockWorker
+	RetentionWorker *worker.RetentionWorker
+	OutboxRelay     *worker.OutboxRelayWorker
+	MarketFeed      *worker.MarketFeedWorker
+	BotManager      *bots.Manager
 
 	// Consumers
 	TransactionConsumer        *worker.TransactionConsumer
 	PricingConsumer            *worker.MarketPricingConsumer
+	CandleConsumer             *worker.CandleAggregationConsumer
 	VolumeConsumer             *worker.MarketVolumeConsumer
-	LedgerPersistenceConsumer  *worker.LedgerPersistenceConsumer
+	LedgerProjectionConsumer   *worker.LedgerProjectionConsumer
 	AuditConsumer              *worker.LedgerAuditConsumer
 	ReconcileConsumer          *worker.LedgerReconcileConsumer
 	RewardCalculationConsumer  *worker.RewardCalculationConsumer
@@ -0,0 +1,17 @@
+package dto
+
+type CreateAssetRequest struct {
+	Symbol   string `json:"symbol" binding:"required"`
+	Name     string `json:"name" binding:"required"`
+	Decimals int    `json:"decimals"`
+}
+
+type AssetBalanceItem struct {
+	Asset   string  `json:"asset"`
+	Balance float64 `json:"balance"`
+}
+
+type AssetBalancesResponse struct {
+	Address  string             `json:"address"`
+	Balances []AssetBalanceItem `json:"balances"`
+}
@@ -0,0 +1,26 @@
+package dto
+
+type CreateBotRequest struct {
+	Name             string  `json:"name" binding:"required"`
+	Strategy         string  `json:"strategy" binding:"required"` // "RANDOM_WALK", "MOMENTUM", "MEAN_REVERSION", "MARKET_MAKER"
+	IntervalSeconds  int     `json:"interval_seconds"`
+	MinAmount        float64 `json:"min_amount"`
+	MaxAmount        float64 `json:"max_amount"`
+	Lookback         int     `json:"lookback"`
+	ThresholdPercent float64 `json:"threshold_percent"`
+	SpreadPercent    float64 `json:"spread_percent"`
+	TargetInventory  float64 `json:"target_inventory"`
+	InitialUSD       float64 `json:"initial_usd"`
+}
+
+// UpdateBotConfigRequest - field nil tidak diubah
+type UpdateBotConfigRequest struct {
+	Strategy         string   `json:"strategy"`
+	IntervalSeconds  *int     `json:"interval_seconds"`
+	MinAmount        *float64 `json:"min_amount"`
+	MaxAmount        *float64 `json:"max_amount"`
+	Lookback         *int     `json:"lookback"`
+	ThresholdPercent *float64 `json:"threshold_percent"`
+	SpreadPercent    *float64 `json:"spread_percent"`
+	TargetInventory  *float64 `json:"target_inventory"`
+}
@@ -0,0 +1,12 @@
+package dto
+
+type PlaceConditionalOrderRequest struct {
+	Address      string  `json:"address"`
+	OrderType    string  `json:"order_type"` // "STOP_LOSS", "TAKE_PROFIT", "TRAILING_STOP"
+	Side         string  `json:"side"`       // "BUY", "SELL"
+	Amount       float64 `json:"amount"`
+	TriggerPrice float64 `json:"trigger_price,omitempty"`
+	TrailPercent float64 `json:"trail_percent,omitempty"`
+	Nonce        string  `json:"nonce"`
+	Signature    string  `json:"signature"`
+}
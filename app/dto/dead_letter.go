@@ -0,0 +1,7 @@
+package dto
+
+// DeadLetterActionResponse - jumlah pesan yang di-replay / di-purge
+type DeadLetterActionResponse struct {
+	Queue string `json:"queue"`
+	Count int    `json:"count"`
+}
@@ -6,6 +6,7 @@ type LedgerEntryEvent struct {
 	BlockID      int64   `json:"block_id"`
 	BlockNumber  int     `json:"block_number"`
 	Address      string  `json:"address"`
+	Asset        string  `json:"asset,omitempty"` // kosong = YTE
 	Amount       float64 `json:"amount"`
 	BalanceAfter float64 `json:"balance_after"`
 	EntryType    string  `json:"entry_type"`
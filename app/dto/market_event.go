@@ -1,6 +1,7 @@
 package dto
 
 type MarketPricingEvent struct {
+	Pair         string  `json:"pair"`
 	BlockID      int64   `json:"block_id"`
 	BlockNumber  int     `json:"block_number"`
 	Price        float64 `json:"price"`
@@ -31,6 +32,7 @@ type MarketStateSnapshot struct {
 }
 
 type PriceUpdate struct {
+	Pair               string  `json:"pair"`
 	BlockID            int64   `json:"block_id"`
 	BlockNumber        int     `json:"block_number"`
 	Price              float64 `json:"price"`
@@ -43,3 +45,70 @@ type PriceUpdate struct {
 	Timestamp          int64   `json:"timestamp"`
 	MinerAddress       string  `json:"miner_address"`
 }
+
+// TradeEvent - BUY/SELL yang terkonfirmasi di block, harga = harga block
+type TradeEvent struct {
+	Pair        string  `json:"pair"`
+	TxID        int64   `json:"tx_id"`
+	BlockNumber int     `json:"block_number"`
+	Side        string  `json:"side"`
+	Address     string  `json:"address"`
+	Amount      float64 `json:"amount"`
+	Price       float64 `json:"price"`
+	Timestamp   int64   `json:"timestamp"`
+}
+
+// Kind pesan market feed WebSocket: snapshot dikirim saat subscribe, delta setelahnya
+const (
+	FeedSnapshot = "snapshot"
+	FeedDelta    = "delta"
+)
+
+// Ticker24h - statistik 24 jam terakhir, selalu dikirim lengkap (delta = snapshot terbaru)
+type Ticker24h struct {
+	Seq                uint64  `json:"seq"`
+	Kind               string  `json:"kind"`
+	Pair               string  `json:"pair"`
+	Price              float64 `json:"price"`
+	Open               float64 `json:"open"`
+	High               float64 `json:"high"`
+	Low                float64 `json:"low"`
+	PriceChange        float64 `json:"price_change"`
+	PriceChangePercent float64 `json:"price_change_percent"`
+	BuyVolume          float64 `json:"buy_volume"`
+	SellVolume         float64 `json:"sell_volume"`
+	Volume             float64 `json:"volume"`
+	TxCount            int64   `json:"tx_count"`
+	Timestamp          int64   `json:"timestamp"`
+}
+
+// RecentTradesUpdate - snapshot berisi trade terakhir, delta hanya trade baru
+type RecentTradesUpdate struct {
+	Seq    uint64       `json:"seq"`
+	Kind   string       `json:"kind"`
+	Pair   string       `json:"pair"`
+	Trades []TradeEvent `json:"trades"`
+}
+
+// DepthLevel - amount 0 pada delta berarti level dihapus
+type DepthLevel struct {
+	Tier      int     `json:"tier"`
+	MinAmount float64 `json:"min_amount"`
+	Orders    int64   `json:"orders"`
+	Amount    float64 `json:"amount"`
+}
+
+// DepthUpdate - BUY/SELL pending dari transaction pool, dikelompokkan per tier ukuran order.
+// snapshot berisi semua level, delta hanya level yang berubah; total selalu dikirim
+type DepthUpdate struct {
+	Seq        uint64       `json:"seq"`
+	Kind       string       `json:"kind"`
+	Pair       string       `json:"pair"`
+	Buys       []DepthLevel `json:"buys"`
+	Sells      []DepthLevel `json:"sells"`
+	BuyAmount  float64      `json:"buy_amount"`
+	SellAmount float64      `json:"sell_amount"`
+	BuyOrders  int64        `json:"buy_orders"`
+	SellOrders int64        `json:"sell_orders"`
+	Timestamp  int64        `json:"timestamp"`
+}
@@ -0,0 +1,9 @@
+package dto
+
+import "github.com/livingdolls/go-blockchain-simulate/app/models"
+
+// OutboxResponse - ringkasan event PENDING + daftar event stuck (gagal publish atau lama tertahan)
+type OutboxResponse struct {
+	Stats models.OutboxStats   `json:"stats"`
+	Stuck []models.OutboxEvent `json:"stuck"`
+}
@@ -0,0 +1,36 @@
+package dto
+
+// StartReplayRequest - replay market_ticks yang sudah tercatat.
+// from / to: RFC3339 atau unix seconds, speed 0 = secepatnya (default 1x)
+type StartReplayRequest struct {
+	Pair       string   `json:"pair"`
+	From       string   `json:"from" binding:"required"`
+	To         string   `json:"to"`
+	Speed      *float64 `json:"speed"`
+	BotIDs     []int64  `json:"bot_ids"`
+	Seed       int64    `json:"seed"`
+	InitialUSD float64  `json:"initial_usd"`
+	InitialYTE float64  `json:"initial_yte"`
+}
+
+// ReplayUpdate - payload websocket market.replay
+type ReplayUpdate struct {
+	SessionID int64 `json:"session_id"`
+	PriceUpdate
+}
+
+// BotBacktestResult - hasil paper trading bot terhadap harga replay
+type BotBacktestResult struct {
+	BotID         int64   `json:"bot_id"`
+	Name          string  `json:"name"`
+	Strategy      string  `json:"strategy"`
+	Trades        int     `json:"trades"`
+	Buys          int     `json:"buys"`
+	Sells         int     `json:"sells"`
+	USDBalance    float64 `json:"usd_balance"`
+	YTEBalance    float64 `json:"yte_balance"`
+	InitialEquity float64 `json:"initial_equity"`
+	Equity        float64 `json:"equity"`
+	PnL           float64 `json:"pnl"`
+	PnLPercent    float64 `json:"pnl_percent"`
+}
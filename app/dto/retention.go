@@ -0,0 +1,19 @@
+package dto
+
+import "github.com/livingdolls/go-blockchain-simulate/app/models"
+
+// RetentionConfigDTO - durasi dalam detik, 0 / interval tidak ada = simpan selamanya
+type RetentionConfigDTO struct {
+	Enabled                bool             `json:"enabled"`
+	RunEverySeconds        int64            `json:"run_every_seconds"`
+	CandleRetentionSeconds map[string]int64 `json:"candle_retention_seconds"`
+	TickRetentionSeconds   int64            `json:"tick_retention_seconds"`
+	TickArchiveInterval    string           `json:"tick_archive_interval"`
+}
+
+// StorageStatsResponse - ukuran tabel database dalam bytes
+type StorageStatsResponse struct {
+	Tables     []models.TableStorage   `json:"tables"`
+	TotalBytes int64                   `json:"total_bytes"`
+	LastRun    *models.RetentionReport `json:"last_retention_run,omitempty"`
+}
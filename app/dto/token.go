@@ -0,0 +1,35 @@
+package dto
+
+import "github.com/livingdolls/go-blockchain-simulate/app/models"
+
+type CreateTokenRequest struct {
+	Address   string  `json:"address"`
+	Symbol    string  `json:"symbol"`
+	Name      string  `json:"name"`
+	Decimals  int     `json:"decimals"`
+	Supply    float64 `json:"supply"`
+	Nonce     string  `json:"nonce"`
+	Signature string  `json:"signature"`
+}
+
+type TokenTransferRequest struct {
+	FromAddress string  `json:"from_address"`
+	ToAddress   string  `json:"to_address"`
+	Symbol      string  `json:"symbol"`
+	Amount      float64 `json:"amount"`
+	Nonce       string  `json:"nonce"`
+	Signature   string  `json:"signature"`
+}
+
+type TokenDetailResponse struct {
+	models.Token
+	HolderCount int64 `json:"holder_count"`
+}
+
+type TokenHoldersResponse struct {
+	Symbol  string               `json:"symbol"`
+	Holders []models.TokenHolder `json:"holders"`
+	Total   int64                `json:"total"`
+	Limit   int                  `json:"limit"`
+	Offset  int                  `json:"offset"`
+}
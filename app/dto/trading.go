@@ -0,0 +1,17 @@
+package dto
+
+// HaltTradingRequest - duration_seconds 0 / kosong = halt sampai di-resume manual
+type HaltTradingRequest struct {
+	Reason          string `json:"reason"`
+	DurationSeconds int64  `json:"duration_seconds"`
+}
+
+type CircuitBreakerLevelDTO struct {
+	ThresholdPercent float64 `json:"threshold_percent" binding:"required,gt=0"`
+	CooldownSeconds  int64   `json:"cooldown_seconds" binding:"required,gt=0"`
+}
+
+type CircuitBreakerConfigDTO struct {
+	Enabled bool                     `json:"enabled"`
+	Levels  []CircuitBreakerLevelDTO `json:"levels" binding:"dive"`
+}
@@ -20,11 +20,61 @@ var ErrAddressAlreadyRegistered = errors.New("address already registered")
 var ErrTransactionNotFound = errors.New("transaction not found")
 var ErrInvalidTransactionType = errors.New("invalid transaction type")
 var ErrSignatureVerificationFailed = errors.New("signature verification failed")
+var ErrInvalidNonce = errors.New("invalid nonce")
+var ErrTradingHalted = errors.New("trading is halted")
+var ErrTradingNotHalted = errors.New("trading is not halted")
+
+// CONDITIONAL ORDER ERRORS
+var ErrConditionalOrderNotFound = errors.New("conditional order not found")
+var ErrInvalidConditionalOrder = errors.New("invalid conditional order")
+var ErrConditionalOrderNotActive = errors.New("conditional order is not active")
+
+// ASSET / PAIR ERRORS
+var ErrAssetNotFound = errors.New("asset not found")
+var ErrPairNotFound = errors.New("trading pair not found")
+var ErrInvalidAsset = errors.New("invalid asset")
+var ErrPairNotTradable = errors.New("trading pair is not tradable, only market data is available")
+
+// TOKEN ERRORS
+var ErrTokenNotFound = errors.New("token not found")
+var ErrInvalidTokenDefinition = errors.New("invalid token definition")
+var ErrInsufficientTokenBalance = errors.New("insufficient token balance")
+
+// INDICATOR ERRORS
+var ErrUnknownIndicator = errors.New("unknown indicator")
+var ErrInvalidIndicatorParams = errors.New("invalid indicator params")
+
+// EXPORT ERRORS
+var ErrInvalidExportFormat = errors.New("invalid export format")
+var ErrInvalidExportRange = errors.New("invalid export range")
+
+// REPLAY ERRORS
+var ErrReplayNotFound = errors.New("replay session not found")
+var ErrInvalidReplay = errors.New("invalid replay request")
+var ErrReplayRunning = errors.New("replay session is still running")
+var ErrTooManyReplays = errors.New("too many running replay sessions")
+
+// WEBSOCKET ERRORS
+var ErrInvalidTopic = errors.New("invalid subscription topic")
+var ErrTopicForbidden = errors.New("subscription topic not allowed")
+var ErrTooManySubscriptions = errors.New("too many subscriptions")
+
+// BOT ERRORS
+var ErrBotNotFound = errors.New("bot not found")
+var ErrInvalidBotConfig = errors.New("invalid bot config")
+var ErrUnknownBotStrategy = errors.New("unknown bot strategy")
+var ErrBotKeysUnavailable = errors.New("bot key encryption is not configured")
 
 // BLOCK ERRORS
 var ErrBlockNotFound = errors.New("block not found")
 var ErrInvalidBlockData = errors.New("invalid block data")
 
+// OUTBOX ERRORS
+var ErrOutboxEventNotFound = errors.New("pending outbox event not found")
+
+// DEAD LETTER ERRORS
+var ErrUnknownQueue = errors.New("unknown queue")
+
 // AUTHENTICATION ERRORS
 var ErrUnauthorized = errors.New("unauthorized access")
 var ErrInvalidToken = errors.New("invalid token")
@@ -7,6 +7,15 @@ const (
 	EventTypeBlockMined    MessageType = "block.mined"
 	EventTypeSubscribe     MessageType = "subscribe"
 	EventTypeUnsubscribe   MessageType = "unsubscribe"
+	EventTypeResume        MessageType = "resume"
+	EventTypeAck           MessageType = "ack"
 	EventTransactionUpdate MessageType = "transaction.update"
 	EventBalanceUpdate     MessageType = "balance.update"
+	EventConditionalOrder  MessageType = "order.conditional"
+	EventTradingStatus     MessageType = "trading.status"
+	EventMarketReplay      MessageType = "market.replay"
+	EventTicker24h         MessageType = "market.ticker"
+	EventRecentTrades      MessageType = "market.trades"
+	EventMarketDepth       MessageType = "market.depth"
+	EventCandleUpdate      MessageType = "candles"
 )
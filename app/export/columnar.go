@@ -0,0 +1,236 @@
+package export
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Layout file columnar (.ycol), mirip Parquet versi sederhana:
+//
+//	"YCOL" | version (1 byte)
+//	row group 1: chunk kolom 1 | chunk kolom 2 | ...
+//	row group 2: ...
+//	footer (JSON) | panjang footer (uint32 LE) | "YCOL"
+//
+// Setiap chunk dikompres DEFLATE (raw). Encoding sebelum kompresi:
+//   - int64:   delta terhadap value sebelumnya dalam chunk, zigzag varint
+//   - float64: 8 byte IEEE 754 little-endian
+//   - string:  uvarint panjang + bytes UTF-8
+const (
+	columnarMagic        = "YCOL"
+	columnarVersion      = 1
+	columnarRowGroupSize = 65536
+)
+
+type columnarFooter struct {
+	Version   int                `json:"version"`
+	Columns   []Column           `json:"columns"`
+	Rows      int64              `json:"rows"`
+	RowGroups []columnarRowGroup `json:"row_groups"`
+}
+
+type columnarRowGroup struct {
+	Rows   int             `json:"rows"`
+	Chunks []columnarChunk `json:"chunks"`
+}
+
+// columnarChunk - offset absolut dari awal file
+type columnarChunk struct {
+	Offset int64 `json:"offset"`
+	Size   int64 `json:"size"`
+}
+
+type columnarWriter struct {
+	w       io.Writer
+	columns []Column
+	offset  int64
+	footer  columnarFooter
+
+	ints    [][]int64
+	floats  [][]float64
+	strings [][]string
+	rows    int
+}
+
+func newColumnarWriter(w io.Writer, columns []Column) (*columnarWriter, error) {
+	for _, col := range columns {
+		switch col.Type {
+		case Int64, Float64, String:
+		default:
+			return nil, fmt.Errorf("unsupported column type %q for %s", col.Type, col.Name)
+		}
+	}
+
+	cw := &columnarWriter{
+		w:       w,
+		columns: columns,
+		footer:  columnarFooter{Version: columnarVersion, Columns: columns},
+		ints:    make([][]int64, len(columns)),
+		floats:  make([][]float64, len(columns)),
+		strings: make([][]string, len(columns)),
+	}
+
+	if err := cw.write(append([]byte(columnarMagic), columnarVersion)); err != nil {
+		return nil, err
+	}
+
+	return cw, nil
+}
+
+func (c *columnarWriter) WriteRow(values []any) error {
+	if len(values) != len(c.columns) {
+		return fmt.Errorf("row has %d values, schema has %d columns", len(values), len(c.columns))
+	}
+
+	for i, col := range c.columns {
+		switch col.Type {
+		case Int64:
+			v, ok := values[i].(int64)
+			if !ok {
+				return fmt.Errorf("column %s: expected int64, got %T", col.Name, values[i])
+			}
+			c.ints[i] = append(c.ints[i], v)
+		case Float64:
+			v, ok := values[i].(float64)
+			if !ok {
+				return fmt.Errorf("column %s: expected float64, got %T", col.Name, values[i])
+			}
+			c.floats[i] = append(c.floats[i], v)
+		case String:
+			v, ok := values[i].(string)
+			if !ok {
+				return fmt.Errorf("column %s: expected string, got %T", col.Name, values[i])
+			}
+			c.strings[i] = append(c.strings[i], v)
+		}
+	}
+
+	c.rows++
+	if c.rows >= columnarRowGroupSize {
+		return c.flushRowGroup()
+	}
+
+	return nil
+}
+
+func (c *columnarWriter) Close() error {
+	if err := c.flushRowGroup(); err != nil {
+		return err
+	}
+
+	footer, err := json.Marshal(c.footer)
+	if err != nil {
+		return err
+	}
+
+	trailer := binary.LittleEndian.AppendUint32(nil, uint32(len(footer)))
+	trailer = append(trailer, columnarMagic...)
+
+	if err := c.write(footer); err != nil {
+		return err
+	}
+
+	return c.write(trailer)
+}
+
+func (c *columnarWriter) flushRowGroup() error {
+	if c.rows == 0 {
+		return nil
+	}
+
+	group := columnarRowGroup{Rows: c.rows, Chunks: make([]columnarChunk, len(c.columns))}
+
+	for i, col := range c.columns {
+		var raw []byte
+		switch col.Type {
+		case Int64:
+			raw = encodeInts(c.ints[i])
+			c.ints[i] = c.ints[i][:0]
+		case Float64:
+			raw = encodeFloats(c.floats[i])
+			c.floats[i] = c.floats[i][:0]
+		case String:
+			raw = encodeStrings(c.strings[i])
+			c.strings[i] = c.strings[i][:0]
+		}
+
+		chunk, err := deflate(raw)
+		if err != nil {
+			return err
+		}
+
+		group.Chunks[i] = columnarChunk{Offset: c.offset, Size: int64(len(chunk))}
+		if err := c.write(chunk); err != nil {
+			return err
+		}
+	}
+
+	c.footer.RowGroups = append(c.footer.RowGroups, group)
+	c.footer.Rows += int64(c.rows)
+	c.rows = 0
+
+	return nil
+}
+
+func (c *columnarWriter) write(p []byte) error {
+	n, err := c.w.Write(p)
+	c.offset += int64(n)
+	return err
+}
+
+func encodeInts(values []int64) []byte {
+	out := make([]byte, 0, len(values)*2)
+
+	var prev int64
+	for _, v := range values {
+		out = binary.AppendVarint(out, v-prev)
+		prev = v
+	}
+
+	return out
+}
+
+func encodeFloats(values []float64) []byte {
+	out := make([]byte, 0, len(values)*8)
+
+	for _, v := range values {
+		out = binary.LittleEndian.AppendUint64(out, math.Float64bits(v))
+	}
+
+	return out
+}
+
+func encodeStrings(values []string) []byte {
+	var out []byte
+
+	for _, v := range values {
+		out = binary.AppendUvarint(out, uint64(len(v)))
+		out = append(out, v...)
+	}
+
+	return out
+}
+
+func deflate(raw []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	fw, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := fw.Write(raw); err != nil {
+		return nil, err
+	}
+
+	if err := fw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,221 @@
+// Package export menulis data market (candle, tick, trade) sebagai CSV, NDJSON, atau file columnar.
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+)
+
+const (
+	FormatCSV      = "csv"
+	FormatNDJSON   = "ndjson"
+	FormatColumnar = "columnar"
+)
+
+type ColumnType string
+
+const (
+	Int64   ColumnType = "int64"
+	Float64 ColumnType = "float64"
+	String  ColumnType = "string"
+)
+
+type Column struct {
+	Name string     `json:"name"`
+	Type ColumnType `json:"type"`
+}
+
+// RowWriter - satu row = satu value per kolom, urutan sama dengan schema.
+// Close wajib dipanggil agar buffer / footer ditulis
+type RowWriter interface {
+	WriteRow(values []any) error
+	Close() error
+}
+
+// NewWriter - format csv | ndjson | columnar
+func NewWriter(format string, w io.Writer, columns []Column) (RowWriter, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, columns)
+	case FormatNDJSON:
+		return newNDJSONWriter(w, columns), nil
+	case FormatColumnar:
+		return newColumnarWriter(w, columns)
+	default:
+		return nil, fmt.Errorf("%w: %s", entity.ErrInvalidExportFormat, format)
+	}
+}
+
+// ContentType - content type HTTP untuk format
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// FileExtension - ekstensi file untuk format
+func FileExtension(format string) string {
+	switch format {
+	case FormatNDJSON:
+		return "ndjson"
+	case FormatColumnar:
+		return "ycol"
+	default:
+		return "csv"
+	}
+}
+
+var CandleColumns = []Column{
+	{Name: "start_time", Type: Int64},
+	{Name: "pair", Type: String},
+	{Name: "interval", Type: String},
+	{Name: "open", Type: Float64},
+	{Name: "high", Type: Float64},
+	{Name: "low", Type: Float64},
+	{Name: "close", Type: Float64},
+	{Name: "volume", Type: Float64},
+	{Name: "buy_volume", Type: Float64},
+	{Name: "sell_volume", Type: Float64},
+	{Name: "trade_count", Type: Int64},
+}
+
+func CandleRow(c models.Candle) []any {
+	return []any{
+		c.StartTime, c.Pair, c.IntervalType,
+		c.OpenPrice, c.HighPrice, c.LowPrice, c.ClosePrice,
+		c.Volume, c.BuyVolume, c.SellVolume, int64(c.TradeCount),
+	}
+}
+
+var TickColumns = []Column{
+	{Name: "created_at", Type: Int64},
+	{Name: "pair", Type: String},
+	{Name: "block_id", Type: Int64},
+	{Name: "price", Type: Float64},
+	{Name: "buy_volume", Type: Float64},
+	{Name: "sell_volume", Type: Float64},
+	{Name: "tx_count", Type: Int64},
+}
+
+func TickRow(t models.MarketTick) []any {
+	return []any{
+		t.CreatedAt, t.Pair, t.BlockID,
+		t.Price, t.BuyVolume, t.SellVolume, int64(t.TxCount),
+	}
+}
+
+var TradeColumns = []Column{
+	{Name: "timestamp", Type: Int64},
+	{Name: "tx_id", Type: Int64},
+	{Name: "block_number", Type: Int64},
+	{Name: "side", Type: String},
+	{Name: "address", Type: String},
+	{Name: "amount", Type: Float64},
+	{Name: "price", Type: Float64},
+}
+
+func TradeRow(t models.Trade) []any {
+	return []any{
+		t.Timestamp, t.TxID, int64(t.BlockNumber),
+		t.Side, t.Address, t.Amount, t.Price,
+	}
+}
+
+type csvWriter struct {
+	w      *csv.Writer
+	record []string
+}
+
+func newCSVWriter(w io.Writer, columns []Column) (*csvWriter, error) {
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.Name
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return nil, err
+	}
+
+	return &csvWriter{w: cw, record: make([]string, len(columns))}, nil
+}
+
+func (c *csvWriter) WriteRow(values []any) error {
+	for i, v := range values {
+		c.record[i] = formatValue(v)
+	}
+
+	return c.w.Write(c.record)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// ndjsonWriter - urutan key mengikuti schema (bukan urutan alfabet seperti map)
+type ndjsonWriter struct {
+	w    *bufio.Writer
+	keys [][]byte
+}
+
+func newNDJSONWriter(w io.Writer, columns []Column) *ndjsonWriter {
+	keys := make([][]byte, len(columns))
+	for i, col := range columns {
+		key, _ := json.Marshal(col.Name)
+		keys[i] = key
+	}
+
+	return &ndjsonWriter{w: bufio.NewWriter(w), keys: keys}
+}
+
+func (n *ndjsonWriter) WriteRow(values []any) error {
+	n.w.WriteByte('{')
+
+	for i, v := range values {
+		if i > 0 {
+			n.w.WriteByte(',')
+		}
+
+		value, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+
+		n.w.Write(n.keys[i])
+		n.w.WriteByte(':')
+		n.w.Write(value)
+	}
+
+	_, err := n.w.WriteString("}\n")
+	return err
+}
+
+func (n *ndjsonWriter) Close() error {
+	return n.w.Flush()
+}
+
+func formatValue(v any) string {
+	switch value := v.(type) {
+	case int64:
+		return strconv.FormatInt(value, 10)
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case string:
+		return value
+	default:
+		return fmt.Sprint(value)
+	}
+}
@@ -0,0 +1,106 @@
+package export
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+)
+
+// ReadTicksCSV - baca tick dari CSV dengan header (format sama dengan /export/ticks).
+// kolom wajib: created_at (unix seconds), price. opsional: buy_volume, sell_volume, tx_count, block_id.
+// maxRows > 0 membatasi jumlah row
+func ReadTicksCSV(r io.Reader, maxRows int) ([]models.MarketTick, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for _, required := range []string{"created_at", "price"} {
+		if _, ok := index[required]; !ok {
+			return nil, fmt.Errorf("missing column %q", required)
+		}
+	}
+
+	var ticks []models.MarketTick
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if maxRows > 0 && len(ticks) >= maxRows {
+			return nil, fmt.Errorf("too many rows, max %d", maxRows)
+		}
+
+		tick, err := parseTickRecord(record, index)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		ticks = append(ticks, tick)
+	}
+
+	return ticks, nil
+}
+
+func parseTickRecord(record []string, index map[string]int) (models.MarketTick, error) {
+	field := func(name string) string {
+		i, ok := index[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var tick models.MarketTick
+	var err error
+
+	if tick.CreatedAt, err = strconv.ParseInt(field("created_at"), 10, 64); err != nil {
+		return tick, fmt.Errorf("invalid created_at")
+	}
+
+	if tick.Price, err = strconv.ParseFloat(field("price"), 64); err != nil || tick.Price <= 0 {
+		return tick, fmt.Errorf("invalid price")
+	}
+
+	floats := map[string]*float64{"buy_volume": &tick.BuyVolume, "sell_volume": &tick.SellVolume}
+	for name, dst := range floats {
+		if raw := field(name); raw != "" {
+			if *dst, err = strconv.ParseFloat(raw, 64); err != nil || *dst < 0 {
+				return tick, fmt.Errorf("invalid %s", name)
+			}
+		}
+	}
+
+	if raw := field("tx_count"); raw != "" {
+		if tick.TxCount, err = strconv.Atoi(raw); err != nil {
+			return tick, fmt.Errorf("invalid tx_count")
+		}
+	}
+
+	if raw := field("block_id"); raw != "" {
+		if tick.BlockID, err = strconv.ParseInt(raw, 10, 64); err != nil {
+			return tick, fmt.Errorf("invalid block_id")
+		}
+	}
+
+	tick.Pair = field("pair")
+
+	return tick, nil
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/livingdolls/go-blockchain-simulate/app/dto"
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+	"github.com/livingdolls/go-blockchain-simulate/app/services"
+)
+
+type AssetHandler struct {
+	service services.AssetService
+}
+
+func NewAssetHandler(service services.AssetService) *AssetHandler {
+	return &AssetHandler{service: service}
+}
+
+func (h *AssetHandler) ListAssets(c *gin.Context) {
+	assets, err := h.service.ListAssets()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(assets))
+}
+
+func (h *AssetHandler) CreateAsset(c *gin.Context) {
+	var req dto.CreateAssetRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse[string]("Invalid request body"))
+		return
+	}
+
+	asset, err := h.service.CreateAsset(req)
+	if err != nil {
+		c.JSON(assetErrorStatus(err), dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.NewSuccessResponse(asset))
+}
+
+func (h *AssetHandler) ListPairs(c *gin.Context) {
+	pairs, err := h.service.ListPairs()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(pairs))
+}
+
+func (h *AssetHandler) GetBalances(c *gin.Context) {
+	address := c.Param("address")
+
+	if address == "" {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse[string]("address is required"))
+		return
+	}
+
+	balances, err := h.service.GetBalances(address)
+	if err != nil {
+		c.JSON(assetErrorStatus(err), dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(balances))
+}
+
+func assetErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, entity.ErrAssetNotFound),
+		errors.Is(err, entity.ErrPairNotFound),
+		errors.Is(err, entity.ErrAddressNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, entity.ErrInvalidAsset):
+		return http.StatusBadRequest
+	case errors.Is(err, entity.ErrConflict):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
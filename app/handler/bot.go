@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/livingdolls/go-blockchain-simulate/app/bots"
+	"github.com/livingdolls/go-blockchain-simulate/app/dto"
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+)
+
+type BotHandler struct {
+	manager *bots.Manager
+}
+
+func NewBotHandler(manager *bots.Manager) *BotHandler {
+	return &BotHandler{manager: manager}
+}
+
+func (h *BotHandler) ListBots(c *gin.Context) {
+	list, err := h.manager.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(list))
+}
+
+func (h *BotHandler) CreateBot(c *gin.Context) {
+	var req dto.CreateBotRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse[string]("Invalid request body"))
+		return
+	}
+
+	bot, err := h.manager.Create(req)
+	if err != nil {
+		c.JSON(botErrorStatus(err), dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.NewSuccessResponse(bot))
+}
+
+func (h *BotHandler) ConfigureBot(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse[string]("Invalid bot ID"))
+		return
+	}
+
+	var req dto.UpdateBotConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse[string]("Invalid request body"))
+		return
+	}
+
+	bot, err := h.manager.Configure(id, req)
+	if err != nil {
+		c.JSON(botErrorStatus(err), dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(bot))
+}
+
+func (h *BotHandler) StartBot(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse[string]("Invalid bot ID"))
+		return
+	}
+
+	bot, err := h.manager.Start(id)
+	if err != nil {
+		c.JSON(botErrorStatus(err), dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(bot))
+}
+
+func (h *BotHandler) StopBot(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse[string]("Invalid bot ID"))
+		return
+	}
+
+	bot, err := h.manager.StopBot(id)
+	if err != nil {
+		c.JSON(botErrorStatus(err), dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(bot))
+}
+
+func botErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, entity.ErrBotNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, entity.ErrInvalidBotConfig),
+		errors.Is(err, entity.ErrUnknownBotStrategy):
+		return http.StatusBadRequest
+	case errors.Is(err, entity.ErrBotKeysUnavailable):
+		return http.StatusServiceUnavailable
+	}
+
+	return http.StatusInternalServerError
+}
@@ -1,10 +1,13 @@
 package handler
 
 import (
+	"errors"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/livingdolls/go-blockchain-simulate/app/dto"
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+	"github.com/livingdolls/go-blockchain-simulate/app/indicators"
 	"github.com/livingdolls/go-blockchain-simulate/app/services"
 )
 
@@ -21,6 +24,7 @@ func NewCandleHandler(service services.CandleService) *CandleHandler {
 func (h *CandleHandler) GetCandle(c *gin.Context) {
 	intervalType := c.Query("interval") // '1m', '5m', '15m', '30m', '1h', '4h', '1d'
 	limit := c.DefaultQuery("limit", "100")
+	pair := services.NormalizePair(c.Query("pair"))
 
 	// validation intervalType
 	if !validateIntervalType(intervalType) {
@@ -35,7 +39,8 @@ func (h *CandleHandler) GetCandle(c *gin.Context) {
 		return
 	}
 
-	candles, err := h.service.GetCandles(intervalType, limitInt)
+	// fill=true: interval tanpa transaksi diisi candle flat
+	candles, err := h.service.GetCandles(pair, intervalType, limitInt, c.Query("fill") == "true")
 	if err != nil {
 		c.JSON(500, dto.NewErrorResponse[string](err.Error()))
 		return
@@ -48,6 +53,7 @@ func (h *CandleHandler) GetCandleFrom(c *gin.Context) {
 	intervalType := c.Query("interval") // '1m', '5m', '15m', '30m', '1h', '4h', '1d'
 	startTimeStr := c.Query("start_time")
 	limit := c.DefaultQuery("limit", "100")
+	pair := services.NormalizePair(c.Query("pair"))
 
 	// validation intervalType
 	if !validateIntervalType(intervalType) {
@@ -68,7 +74,7 @@ func (h *CandleHandler) GetCandleFrom(c *gin.Context) {
 		return
 	}
 
-	candles, err := h.service.GetCandlesFrom(intervalType, startTimeInt, limitInt)
+	candles, err := h.service.GetCandlesFrom(pair, intervalType, startTimeInt, limitInt, c.Query("fill") == "true")
 	if err != nil {
 		c.JSON(500, dto.NewErrorResponse[string](err.Error()))
 		return
@@ -77,6 +83,42 @@ func (h *CandleHandler) GetCandleFrom(c *gin.Context) {
 	c.JSON(200, dto.NewSuccessResponse(candles))
 }
 
+// GetIndicator - indicator=sma|ema|rsi|macd|bollinger|vwap|atr, params dipisah koma (mis. 12,26,9)
+func (h *CandleHandler) GetIndicator(c *gin.Context) {
+	intervalType := c.Query("interval")
+	indicator := c.Query("indicator")
+	pair := services.NormalizePair(c.Query("pair"))
+
+	if !validateIntervalType(intervalType) {
+		c.JSON(400, dto.NewErrorResponse[string]("invalid interval type"))
+		return
+	}
+
+	params, err := indicators.ParseParams(indicator, c.Query("params"))
+	if err != nil {
+		c.JSON(400, dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	limitInt, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limitInt <= 0 || limitInt > 1000 {
+		c.JSON(400, dto.NewErrorResponse[string]("invalid limit"))
+		return
+	}
+
+	result, err := h.service.GetIndicator(pair, intervalType, indicator, params, limitInt)
+	if err != nil {
+		if errors.Is(err, entity.ErrUnknownIndicator) || errors.Is(err, entity.ErrInvalidIndicatorParams) {
+			c.JSON(400, dto.NewErrorResponse[string](err.Error()))
+			return
+		}
+		c.JSON(500, dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	c.JSON(200, dto.NewSuccessResponse(result))
+}
+
 func validateIntervalType(intervalType string) bool {
 	validIntervals := map[string]bool{
 		"1m":  true,
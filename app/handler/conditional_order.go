@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/livingdolls/go-blockchain-simulate/app/dto"
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+	"github.com/livingdolls/go-blockchain-simulate/app/services"
+)
+
+type ConditionalOrderHandler struct {
+	service services.ConditionalOrderService
+}
+
+func NewConditionalOrderHandler(service services.ConditionalOrderService) *ConditionalOrderHandler {
+	return &ConditionalOrderHandler{service: service}
+}
+
+func (h *ConditionalOrderHandler) PlaceOrder(c *gin.Context) {
+	var req dto.PlaceConditionalOrderRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse[string]("invalid request body"))
+		return
+	}
+
+	order, err := h.service.PlaceOrder(c.Request.Context(), req)
+	if err != nil {
+		status := http.StatusInternalServerError
+
+		switch {
+		case errors.Is(err, entity.ErrAmountMustBePositive),
+			errors.Is(err, entity.ErrInvalidTransactionType),
+			errors.Is(err, entity.ErrInvalidConditionalOrder),
+			errors.Is(err, entity.ErrAddressNotFound):
+			status = http.StatusBadRequest
+		case errors.Is(err, entity.ErrSignatureVerificationFailed):
+			status = http.StatusUnauthorized
+		}
+
+		c.JSON(status, dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.NewSuccessResponse(order))
+}
+
+func (h *ConditionalOrderHandler) GetOrders(c *gin.Context) {
+	address := c.Param("address")
+
+	if address == "" {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse[string]("address is required"))
+		return
+	}
+
+	orders, err := h.service.GetOrders(address, c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(orders))
+}
+
+func (h *ConditionalOrderHandler) CancelOrder(c *gin.Context) {
+	claims, ok := GetUserClaims(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, dto.NewErrorResponse[string]("unauthorized"))
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse[string]("invalid order ID"))
+		return
+	}
+
+	if err := h.service.CancelOrder(claims.Address, id); err != nil {
+		status := http.StatusInternalServerError
+
+		switch {
+		case errors.Is(err, entity.ErrConditionalOrderNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, entity.ErrUnauthorized):
+			status = http.StatusForbidden
+		case errors.Is(err, entity.ErrConditionalOrderNotActive):
+			status = http.StatusConflict
+		}
+
+		c.JSON(status, dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(map[string]interface{}{
+		"message": "Conditional order cancelled",
+	}))
+}
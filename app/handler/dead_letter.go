@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/livingdolls/go-blockchain-simulate/app/dto"
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+	"github.com/livingdolls/go-blockchain-simulate/app/services"
+)
+
+type DeadLetterHandler struct {
+	service services.DeadLetterService
+}
+
+func NewDeadLetterHandler(service services.DeadLetterService) *DeadLetterHandler {
+	return &DeadLetterHandler{service: service}
+}
+
+// Stats - jumlah pesan di setiap parking queue
+func (h *DeadLetterHandler) Stats(c *gin.Context) {
+	stats, err := h.service.Stats()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(stats))
+}
+
+// Peek - query: limit (default 20)
+func (h *DeadLetterHandler) Peek(c *gin.Context) {
+	limit := 20
+	if l := c.Query("limit"); l != "" {
+		limit, _ = strconv.Atoi(l)
+	}
+
+	letters, err := h.service.Peek(c.Param("queue"), limit)
+	if err != nil {
+		c.JSON(deadLetterErrorStatus(err), dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(letters))
+}
+
+// Replay - query: limit (default 100)
+func (h *DeadLetterHandler) Replay(c *gin.Context) {
+	limit := 100
+	if l := c.Query("limit"); l != "" {
+		limit, _ = strconv.Atoi(l)
+	}
+
+	replayed, err := h.service.Replay(c.Request.Context(), c.Param("queue"), limit)
+	if err != nil {
+		c.JSON(deadLetterErrorStatus(err), dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(dto.DeadLetterActionResponse{Queue: c.Param("queue"), Count: replayed}))
+}
+
+func (h *DeadLetterHandler) Purge(c *gin.Context) {
+	purged, err := h.service.Purge(c.Param("queue"))
+	if err != nil {
+		c.JSON(deadLetterErrorStatus(err), dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(dto.DeadLetterActionResponse{Queue: c.Param("queue"), Count: purged}))
+}
+
+func deadLetterErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, entity.ErrUnknownQueue):
+		return http.StatusNotFound
+	case errors.Is(err, entity.ErrInvalidInput):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
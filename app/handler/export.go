@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/livingdolls/go-blockchain-simulate/app/dto"
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+	"github.com/livingdolls/go-blockchain-simulate/app/export"
+	"github.com/livingdolls/go-blockchain-simulate/app/services"
+	"github.com/livingdolls/go-blockchain-simulate/logger"
+	"github.com/livingdolls/go-blockchain-simulate/utils"
+)
+
+type ExportHandler struct {
+	service services.ExportService
+}
+
+func NewExportHandler(service services.ExportService) *ExportHandler {
+	return &ExportHandler{
+		service: service,
+	}
+}
+
+// ExportCandles - query: pair, interval (default 1m), from, to, format=csv|ndjson
+func (h *ExportHandler) ExportCandles(c *gin.Context) {
+	h.export(c, services.ExportDatasetCandles)
+}
+
+// ExportTicks - query: pair, from, to, format=csv|ndjson
+func (h *ExportHandler) ExportTicks(c *gin.Context) {
+	h.export(c, services.ExportDatasetTicks)
+}
+
+// ExportTrades - query: pair, from, to, format=csv|ndjson
+func (h *ExportHandler) ExportTrades(c *gin.Context) {
+	h.export(c, services.ExportDatasetTrades)
+}
+
+func (h *ExportHandler) export(c *gin.Context, dataset string) {
+	format := c.DefaultQuery("format", export.FormatCSV)
+
+	// columnar hanya lewat CLI (cmd/market-export)
+	if format != export.FormatCSV && format != export.FormatNDJSON {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse[string]("invalid format, use csv or ndjson"))
+		return
+	}
+
+	from, err := utils.ParseTime(c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse[string]("invalid from"))
+		return
+	}
+
+	var to int64
+	if raw := c.Query("to"); raw != "" {
+		if to, err = utils.ParseTime(raw); err != nil {
+			c.JSON(http.StatusBadRequest, dto.NewErrorResponse[string]("invalid to"))
+			return
+		}
+	}
+
+	query, err := h.service.Validate(format, services.ExportQuery{
+		Dataset:  dataset,
+		Pair:     c.Query("pair"),
+		Interval: c.Query("interval"),
+		From:     from,
+		To:       to,
+	})
+	if err != nil {
+		c.JSON(exportErrorStatus(err), dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	filename := fmt.Sprintf("%s_%s_%d_%d.%s", dataset, query.Pair, query.From, query.To, export.FileExtension(format))
+	c.Header("Content-Type", export.ContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("Cache-Control", "no-cache")
+	c.Status(http.StatusOK)
+
+	rows, err := h.service.Export(c.Request.Context(), &flushWriter{w: c.Writer}, format, query)
+	if err != nil {
+		// header sudah terkirim, response hanya bisa diputus
+		logger.LogError(fmt.Sprintf("Export %s failed after %d rows", dataset, rows), err)
+		return
+	}
+
+	logger.LogInfo(fmt.Sprintf("Exported %d %s rows for %s", rows, dataset, query.Pair))
+}
+
+// flushWriter - flush setiap write agar data langsung dikirim ke client (writer format sudah di-buffer)
+type flushWriter struct {
+	w gin.ResponseWriter
+}
+
+func (f *flushWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	f.w.Flush()
+	return n, err
+}
+
+var _ io.Writer = (*flushWriter)(nil)
+
+func exportErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, entity.ErrInvalidExportFormat), errors.Is(err, entity.ErrInvalidExportRange):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/livingdolls/go-blockchain-simulate/app/dto"
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+	"github.com/livingdolls/go-blockchain-simulate/app/services"
+)
+
+type LedgerRebuildHandler struct {
+	service services.LedgerRebuildService
+}
+
+func NewLedgerRebuildHandler(service services.LedgerRebuildService) *LedgerRebuildHandler {
+	return &LedgerRebuildHandler{service: service}
+}
+
+// Rebuild - query: apply (default false = dry run), tolerance (default 1e-6). sinkron
+func (h *LedgerRebuildHandler) Rebuild(c *gin.Context) {
+	var opts services.LedgerRebuildOptions
+
+	if raw := c.Query("apply"); raw != "" {
+		apply, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, dto.NewErrorResponse[string]("invalid apply"))
+			return
+		}
+		opts.Apply = apply
+	}
+
+	if raw := c.Query("tolerance"); raw != "" {
+		tolerance, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, dto.NewErrorResponse[string]("invalid tolerance"))
+			return
+		}
+		opts.Tolerance = tolerance
+	}
+
+	report, err := h.service.Rebuild(c.Request.Context(), opts)
+	if err != nil {
+		c.JSON(ledgerRebuildErrorStatus(err), dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(report))
+}
+
+// LastReport - report rebuild terakhir sejak proses berjalan
+func (h *LedgerRebuildHandler) LastReport(c *gin.Context) {
+	report, ok := h.service.LastReport()
+	if !ok {
+		c.JSON(http.StatusNotFound, dto.NewErrorResponse[string]("no ledger rebuild has run yet"))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(report))
+}
+
+func ledgerRebuildErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, entity.ErrInvalidInput):
+		return http.StatusBadRequest
+	case errors.Is(err, entity.ErrConflict):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -1,8 +1,11 @@
 package handler
 
 import (
+	"errors"
+
 	"github.com/gin-gonic/gin"
 	"github.com/livingdolls/go-blockchain-simulate/app/dto"
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
 	"github.com/livingdolls/go-blockchain-simulate/app/services"
 )
 
@@ -25,3 +28,27 @@ func (h *MarketHandler) GetMarketEngineState(c *gin.Context) {
 
 	c.JSON(200, dto.NewSuccessResponse(state))
 }
+
+func (h *MarketHandler) ListMarkets(c *gin.Context) {
+	states, err := h.service.GetAllStates()
+	if err != nil {
+		c.JSON(500, dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	c.JSON(200, dto.NewSuccessResponse(states))
+}
+
+func (h *MarketHandler) GetMarketByPair(c *gin.Context) {
+	state, err := h.service.GetStateByPair(services.NormalizePair(c.Param("pair")))
+	if err != nil {
+		if errors.Is(err, entity.ErrPairNotFound) {
+			c.JSON(404, dto.NewErrorResponse[string](err.Error()))
+			return
+		}
+		c.JSON(500, dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	c.JSON(200, dto.NewSuccessResponse(state))
+}
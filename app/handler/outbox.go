@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/livingdolls/go-blockchain-simulate/app/dto"
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+	"github.com/livingdolls/go-blockchain-simulate/app/services"
+)
+
+type OutboxHandler struct {
+	service services.OutboxService
+}
+
+func NewOutboxHandler(service services.OutboxService) *OutboxHandler {
+	return &OutboxHandler{service: service}
+}
+
+// GetOutbox - query: limit (default 50), offset
+func (h *OutboxHandler) GetOutbox(c *gin.Context) {
+	limit := 50
+	offset := 0
+
+	if l := c.Query("limit"); l != "" {
+		limit, _ = strconv.Atoi(l)
+	}
+
+	if o := c.Query("offset"); o != "" {
+		offset, _ = strconv.Atoi(o)
+	}
+
+	if limit <= 0 || limit > 500 || offset < 0 {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse[string]("invalid limit or offset"))
+		return
+	}
+
+	stats, err := h.service.Stats()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	stuck, err := h.service.Stuck(limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(dto.OutboxResponse{Stats: stats, Stuck: stuck}))
+}
+
+// RetryEvent - publish ulang event PENDING tanpa menunggu backoff
+func (h *OutboxHandler) RetryEvent(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse[string]("invalid id"))
+		return
+	}
+
+	if err := h.service.Retry(id); err != nil {
+		c.JSON(outboxErrorStatus(err), dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse("outbox event scheduled for retry"))
+}
+
+func outboxErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, entity.ErrOutboxEventNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/livingdolls/go-blockchain-simulate/app/dto"
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+	"github.com/livingdolls/go-blockchain-simulate/app/export"
+	"github.com/livingdolls/go-blockchain-simulate/app/services"
+	"github.com/livingdolls/go-blockchain-simulate/utils"
+)
+
+const maxReplayUploadBytes = 64 << 20
+
+type ReplayHandler struct {
+	service services.ReplayService
+}
+
+func NewReplayHandler(service services.ReplayService) *ReplayHandler {
+	return &ReplayHandler{service: service}
+}
+
+// StartReplay - replay market_ticks yang tercatat
+func (h *ReplayHandler) StartReplay(c *gin.Context) {
+	var req dto.StartReplayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse[string]("Invalid request body"))
+		return
+	}
+
+	from, err := utils.ParseTime(req.From)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse[string]("invalid from"))
+		return
+	}
+
+	var to int64
+	if req.To != "" {
+		if to, err = utils.ParseTime(req.To); err != nil {
+			c.JSON(http.StatusBadRequest, dto.NewErrorResponse[string]("invalid to"))
+			return
+		}
+	}
+
+	speed := 1.0
+	if req.Speed != nil {
+		speed = *req.Speed
+	}
+
+	session, err := h.service.ReplayRecorded(req.Pair, from, to, services.ReplayOptions{
+		Speed:      speed,
+		BotIDs:     req.BotIDs,
+		Seed:       req.Seed,
+		InitialUSD: req.InitialUSD,
+		InitialYTE: req.InitialYTE,
+	})
+	if err != nil {
+		c.JSON(replayErrorStatus(err), dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.NewSuccessResponse(session))
+}
+
+// ImportReplay - multipart: file (CSV format /export/ticks), pair, speed, bot_ids (dipisah koma), seed, initial_usd, initial_yte
+func (h *ReplayHandler) ImportReplay(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxReplayUploadBytes)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse[string]("file is required"))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse[string]("cannot open file"))
+		return
+	}
+	defer file.Close()
+
+	ticks, err := export.ReadTicksCSV(file, services.MaxReplayTicks)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse[string]("invalid csv: "+err.Error()))
+		return
+	}
+
+	opts, err := parseReplayForm(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	session, err := h.service.ReplayTicks(services.ReplaySourceCSV, c.PostForm("pair"), ticks, opts)
+	if err != nil {
+		c.JSON(replayErrorStatus(err), dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.NewSuccessResponse(session))
+}
+
+func (h *ReplayHandler) ListReplays(c *gin.Context) {
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(h.service.List()))
+}
+
+func (h *ReplayHandler) GetReplay(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse[string]("Invalid replay ID"))
+		return
+	}
+
+	session, err := h.service.Get(id)
+	if err != nil {
+		c.JSON(replayErrorStatus(err), dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(session))
+}
+
+func (h *ReplayHandler) StopReplay(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse[string]("Invalid replay ID"))
+		return
+	}
+
+	session, err := h.service.Stop(id)
+	if err != nil {
+		c.JSON(replayErrorStatus(err), dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(session))
+}
+
+func (h *ReplayHandler) DeleteReplay(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse[string]("Invalid replay ID"))
+		return
+	}
+
+	if err := h.service.Delete(id); err != nil {
+		c.JSON(replayErrorStatus(err), dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse("replay session deleted"))
+}
+
+func parseReplayForm(c *gin.Context) (services.ReplayOptions, error) {
+	opts := services.ReplayOptions{Speed: 1}
+
+	floats := map[string]*float64{"speed": &opts.Speed, "initial_usd": &opts.InitialUSD, "initial_yte": &opts.InitialYTE}
+	for name, dst := range floats {
+		if raw := c.PostForm(name); raw != "" {
+			v, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				return opts, errors.New("invalid " + name)
+			}
+			*dst = v
+		}
+	}
+
+	if raw := c.PostForm("seed"); raw != "" {
+		seed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return opts, errors.New("invalid seed")
+		}
+		opts.Seed = seed
+	}
+
+	if raw := c.PostForm("bot_ids"); raw != "" {
+		for _, part := range strings.Split(raw, ",") {
+			id, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+			if err != nil {
+				return opts, errors.New("invalid bot_ids")
+			}
+			opts.BotIDs = append(opts.BotIDs, id)
+		}
+	}
+
+	return opts, nil
+}
+
+func replayErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, entity.ErrReplayNotFound), errors.Is(err, entity.ErrBotNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, entity.ErrInvalidReplay), errors.Is(err, entity.ErrUnknownBotStrategy):
+		return http.StatusBadRequest
+	case errors.Is(err, entity.ErrReplayRunning), errors.Is(err, entity.ErrTooManyReplays):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
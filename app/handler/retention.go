@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/livingdolls/go-blockchain-simulate/app/dto"
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+	"github.com/livingdolls/go-blockchain-simulate/app/services"
+	"github.com/livingdolls/go-blockchain-simulate/utils"
+)
+
+type RetentionHandler struct {
+	service services.RetentionService
+}
+
+func NewRetentionHandler(service services.RetentionService) *RetentionHandler {
+	return &RetentionHandler{service: service}
+}
+
+// StorageStats - ukuran setiap tabel + hasil retention job terakhir
+func (h *RetentionHandler) StorageStats(c *gin.Context) {
+	tables, err := h.service.StorageStats()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	resp := dto.StorageStatsResponse{Tables: tables}
+	for _, t := range tables {
+		resp.TotalBytes += t.TotalBytes
+	}
+
+	if report, ok := h.service.LastReport(); ok {
+		resp.LastRun = &report
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(resp))
+}
+
+func (h *RetentionHandler) GetConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(toRetentionConfigDTO(h.service.Config())))
+}
+
+func (h *RetentionHandler) UpdateConfig(c *gin.Context) {
+	var req dto.RetentionConfigDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse[string]("Invalid request body"))
+		return
+	}
+
+	cfg := services.RetentionConfig{
+		Enabled:             req.Enabled,
+		RunEvery:            time.Duration(req.RunEverySeconds) * time.Second,
+		CandleRetention:     make(map[string]time.Duration, len(req.CandleRetentionSeconds)),
+		TickRetention:       time.Duration(req.TickRetentionSeconds) * time.Second,
+		TickArchiveInterval: req.TickArchiveInterval,
+	}
+
+	for interval, seconds := range req.CandleRetentionSeconds {
+		cfg.CandleRetention[interval] = time.Duration(seconds) * time.Second
+	}
+
+	updated, err := h.service.UpdateConfig(cfg)
+	if err != nil {
+		c.JSON(retentionErrorStatus(err), dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(toRetentionConfigDTO(updated)))
+}
+
+// RunNow - jalankan retention job sekarang (sinkron)
+func (h *RetentionHandler) RunNow(c *gin.Context) {
+	report, err := h.service.Run(c.Request.Context())
+	if err != nil {
+		c.JSON(retentionErrorStatus(err), dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(report))
+}
+
+// GetTickArchives - query: pair, interval (default interval arsip), from, to
+func (h *RetentionHandler) GetTickArchives(c *gin.Context) {
+	from, err := utils.ParseTime(c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse[string]("invalid from"))
+		return
+	}
+
+	var to int64
+	if raw := c.Query("to"); raw != "" {
+		if to, err = utils.ParseTime(raw); err != nil {
+			c.JSON(http.StatusBadRequest, dto.NewErrorResponse[string]("invalid to"))
+			return
+		}
+	}
+
+	archives, err := h.service.GetTickArchives(c.Query("pair"), c.Query("interval"), from, to)
+	if err != nil {
+		c.JSON(retentionErrorStatus(err), dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(archives))
+}
+
+func toRetentionConfigDTO(cfg services.RetentionConfig) dto.RetentionConfigDTO {
+	candles := make(map[string]int64, len(cfg.CandleRetention))
+	for interval, retention := range cfg.CandleRetention {
+		candles[interval] = int64(retention / time.Second)
+	}
+
+	return dto.RetentionConfigDTO{
+		Enabled:                cfg.Enabled,
+		RunEverySeconds:        int64(cfg.RunEvery / time.Second),
+		CandleRetentionSeconds: candles,
+		TickRetentionSeconds:   int64(cfg.TickRetention / time.Second),
+		TickArchiveInterval:    cfg.TickArchiveInterval,
+	}
+}
+
+func retentionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, entity.ErrInvalidInput):
+		return http.StatusBadRequest
+	case errors.Is(err, entity.ErrConflict):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+	"github.com/livingdolls/go-blockchain-simulate/app/port"
+	"github.com/livingdolls/go-blockchain-simulate/app/services"
+	"github.com/livingdolls/go-blockchain-simulate/app/websocket"
+	"github.com/livingdolls/go-blockchain-simulate/app/worker"
+	"github.com/livingdolls/go-blockchain-simulate/rabbitmq"
+)
+
+// kode error aplikasi JSON-RPC (range -32000 s/d -32099)
+const (
+	rpcErrTradingHalted = -32001
+	rpcErrForbidden     = -32002
+	rpcErrNotFound      = -32003
+)
+
+type RPCGetBalanceParams struct {
+	Address string `json:"address"`
+}
+
+type RPCGetBlockParams struct {
+	Number int64 `json:"number"`
+}
+
+type RPCGetCandlesParams struct {
+	Interval string `json:"interval"`
+	Pair     string `json:"pair"`
+	Limit    int    `json:"limit"`
+	Fill     bool   `json:"fill"`
+}
+
+// RPCSendTransactionParams - type SEND/BUY/SELL, pengirim selalu address JWT koneksi
+type RPCSendTransactionParams struct {
+	Type        string  `json:"type"`
+	Pair        string  `json:"pair"`
+	FromAddress string  `json:"from_address"`
+	ToAddress   string  `json:"to_address"`
+	Amount      float64 `json:"amount"`
+	Nonce       string  `json:"nonce"`
+	Signature   string  `json:"signature"`
+}
+
+// RPCHandler - method JSON-RPC di /ws/market yang memakai service yang sama dengan REST
+type RPCHandler struct {
+	transactionService services.TransactionService
+	balanceService     services.BalanceService
+	blockService       services.BlockService
+	candleService      services.CandleService
+	rmqClient          port.MessageBroker
+	breaker            services.CircuitBreakerService
+}
+
+func NewRPCHandler(
+	transactionService services.TransactionService,
+	balanceService services.BalanceService,
+	blockService services.BlockService,
+	candleService services.CandleService,
+	rmqClient port.MessageBroker,
+	breaker services.CircuitBreakerService,
+) *RPCHandler {
+	return &RPCHandler{
+		transactionService: transactionService,
+		balanceService:     balanceService,
+		blockService:       blockService,
+		candleService:      candleService,
+		rmqClient:          rmqClient,
+		breaker:            breaker,
+	}
+}
+
+// HandleRPC implements [websocket.RPCHandler].
+func (h *RPCHandler) HandleRPC(ctx context.Context, address, method string, params json.RawMessage) (any, error) {
+	var (
+		result any
+		err    error
+	)
+
+	switch method {
+	case "getBalance":
+		result, err = h.getBalance(address, params)
+	case "getBlock":
+		result, err = h.getBlock(params)
+	case "getNonce":
+		result = map[string]string{"nonce": h.transactionService.GenerateTransactionNonce(ctx, address)}
+	case "getCandles":
+		result, err = h.getCandles(params)
+	case "sendTransaction":
+		result, err = h.sendTransaction(ctx, address, params)
+	default:
+		return nil, websocket.NewRPCError(websocket.RPCMethodNotFound, "method not found")
+	}
+
+	if err != nil {
+		return nil, rpcError(err)
+	}
+
+	return result, nil
+}
+
+// getBalance - tanpa params memakai address koneksi
+func (h *RPCHandler) getBalance(address string, params json.RawMessage) (any, error) {
+	var req RPCGetBalanceParams
+	if err := websocket.DecodeRPCParams(params, &req); err != nil {
+		return nil, err
+	}
+
+	if req.Address != "" {
+		address = req.Address
+	}
+
+	return h.balanceService.GetUserWithUSDBalance(address)
+}
+
+func (h *RPCHandler) getBlock(params json.RawMessage) (any, error) {
+	var req RPCGetBlockParams
+	if err := websocket.DecodeRPCParams(params, &req); err != nil {
+		return nil, err
+	}
+
+	if req.Number <= 0 {
+		return nil, websocket.NewRPCError(websocket.RPCInvalidParams, "number is required")
+	}
+
+	return h.blockService.GetDetailsByBlockNumber(req.Number)
+}
+
+func (h *RPCHandler) getCandles(params json.RawMessage) (any, error) {
+	req := RPCGetCandlesParams{Limit: 100}
+	if err := websocket.DecodeRPCParams(params, &req); err != nil {
+		return nil, err
+	}
+
+	if !validateIntervalType(req.Interval) {
+		return nil, websocket.NewRPCError(websocket.RPCInvalidParams, "invalid interval type")
+	}
+
+	if req.Limit <= 0 {
+		return nil, websocket.NewRPCError(websocket.RPCInvalidParams, "invalid limit")
+	}
+
+	return h.candleService.GetCandles(services.NormalizePair(req.Pair), req.Interval, req.Limit, req.Fill)
+}
+
+// sendTransaction - sama seperti REST: dipublish ke RabbitMQ dan diproses consumer transaksi
+func (h *RPCHandler) sendTransaction(ctx context.Context, address string, params json.RawMessage) (any, error) {
+	var req RPCSendTransactionParams
+	if err := websocket.DecodeRPCParams(params, &req); err != nil {
+		return nil, err
+	}
+
+	if req.FromAddress != "" && !strings.EqualFold(req.FromAddress, address) {
+		return nil, websocket.NewRPCError(rpcErrForbidden, "from_address must match the authenticated address")
+	}
+
+	msg := worker.TransactionMessage{
+		Type:      strings.ToUpper(req.Type),
+		Address:   address,
+		Amount:    req.Amount,
+		Nonce:     req.Nonce,
+		Signature: req.Signature,
+	}
+
+	switch services.TransactionType(msg.Type) {
+	case services.BuyTransaction, services.SellTransaction:
+		if err := services.EnsureTradablePair(req.Pair); err != nil {
+			return nil, websocket.NewRPCError(websocket.RPCInvalidParams, err.Error())
+		}
+
+		// tolak lebih awal saat trading di-halt, consumer juga akan menolak
+		if err := h.breaker.EnsureTradingOpen(ctx); err != nil {
+			return nil, err
+		}
+	case "SEND":
+		if req.ToAddress == "" {
+			return nil, websocket.NewRPCError(websocket.RPCInvalidParams, "to_address is required")
+		}
+		msg.ToAddress = req.ToAddress
+	default:
+		return nil, websocket.NewRPCError(websocket.RPCInvalidParams, "type must be SEND, BUY or SELL")
+	}
+
+	body, _ := json.Marshal(msg)
+
+	if err := h.rmqClient.Publish(
+		ctx,
+		rabbitmq.TransactionExchange,
+		rabbitmq.TransactionSubmittedKey,
+		body,
+	); err != nil {
+		return nil, errors.New("failed to publish transaction message")
+	}
+
+	return map[string]string{
+		"status":  "submitted",
+		"message": "Transaction submitted successfully and is being processed",
+	}, nil
+}
+
+func rpcError(err error) error {
+	var rpcErr *websocket.RPCError
+	if errors.As(err, &rpcErr) {
+		return err
+	}
+
+	switch {
+	case errors.Is(err, entity.ErrTradingHalted):
+		return websocket.NewRPCError(rpcErrTradingHalted, err.Error())
+	case errors.Is(err, entity.ErrAddressNotFound),
+		errors.Is(err, sql.ErrNoRows):
+		return websocket.NewRPCError(rpcErrNotFound, err.Error())
+	}
+
+	return err
+}
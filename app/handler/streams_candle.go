@@ -5,34 +5,83 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/livingdolls/go-blockchain-simulate/logger"
 
 	"github.com/gin-gonic/gin"
 	"github.com/livingdolls/go-blockchain-simulate/app/dto"
+	"github.com/livingdolls/go-blockchain-simulate/app/indicators"
 	"github.com/livingdolls/go-blockchain-simulate/app/models"
 	"github.com/livingdolls/go-blockchain-simulate/app/services"
 	"github.com/livingdolls/go-blockchain-simulate/utils"
 )
 
+const (
+	sseHeartbeatInterval = 15 * time.Second
+	sseRetryMillis       = 3000
+	// batas candle yang di-replay per interval saat reconnect
+	sseReplayLimit = 500
+)
+
+// named SSE event types
+const (
+	sseEventCandle    = "candle"
+	sseEventIndicator = "indicator"
+	sseEventTicker    = "ticker"
+	sseEventTrade     = "trade"
+	sseEventHeartbeat = "heartbeat"
+)
+
 type CandleStreamHandler struct {
 	streamService services.CandleStreamService
+	marketStream  services.MarketStreamService
 	candleService services.CandleService
 }
 
-func NewCandleStreamHandler(streamService services.CandleStreamService, candleService services.CandleService) *CandleStreamHandler {
+func NewCandleStreamHandler(streamService services.CandleStreamService, marketStream services.MarketStreamService, candleService services.CandleService) *CandleStreamHandler {
 	return &CandleStreamHandler{
 		streamService: streamService,
+		marketStream:  marketStream,
 		candleService: candleService,
 	}
 }
 
+type sseEvent struct {
+	name string
+	data any
+}
+
+// StreamCandles - satu koneksi SSE untuk beberapa interval candle (+ ticker / trade)
+// query: interval=1m atau intervals=1m,5m, streams=ticker,trades, indicator=rsi:14 (boleh berulang)
+// setiap event punya id (unix millis), reconnect dengan Last-Event-ID me-replay candle yang terlewat dari DB
 func (h *CandleStreamHandler) StreamCandles(c *gin.Context) {
-	interval := c.Query("interval")
+	pair := services.NormalizePair(c.Query("pair"))
+
+	intervals, err := parseStreamIntervals(c.Query("interval"), c.Query("intervals"))
+	if err != nil {
+		c.JSON(400, dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	streams, err := parseStreamFeeds(c.Query("streams"))
+	if err != nil {
+		c.JSON(400, dto.NewErrorResponse[string](err.Error()))
+		return
+	}
 
-	if !dto.IsValidInterval(interval) {
-		c.JSON(400, dto.NewErrorResponse[string]("invalid interval"))
+	// indicator opsional, contoh: ?indicator=rsi:14&indicator=macd:12,26,9
+	streamIndicators, err := parseStreamIndicators(c.QueryArray("indicator"))
+	if err != nil {
+		c.JSON(400, dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	lastEventID, err := parseLastEventID(c)
+	if err != nil {
+		c.JSON(400, dto.NewErrorResponse[string](err.Error()))
 		return
 	}
 
@@ -51,82 +100,281 @@ func (h *CandleStreamHandler) StreamCandles(c *gin.Context) {
 	ctx, cancel := context.WithCancel(c.Request.Context())
 	defer cancel()
 
-	// listen untuk client
-	logger.LogInfo("SSE connected for interval: " + interval)
-
-	// write initial candle
-	go func() {
-		latestCandle, err := h.candleService.GetLatestCandleByInterval(interval)
-		if err == nil {
-			data, err := json.Marshal(latestCandle)
-
-			if err == nil {
-				fmt.Fprintf(w, "data: %s\n\n", string(data))
-				flusher.Flush()
-				logger.LogInfo("Initial candle sent for interval: " + interval)
-			}
-		}
-	}()
+	logger.LogInfo(fmt.Sprintf("SSE connected for %s intervals: %s", pair, strings.Join(intervals, ",")))
 
+	// semua subscriber mengirim ke channel ini, hanya loop utama yang menulis ke response
+	events := make(chan sseEvent, 64)
 	errChan := make(chan error, 1)
 	doneChan := make(chan struct{})
 
-	// subscribe to candle stream
-	go func() {
-		defer close(doneChan)
-		err := h.streamService.SubscribeCandle(ctx, interval, func(candle models.Candle) error {
-			// check context
+	emit := func(event sseEvent) error {
+		select {
+		case events <- event:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
 
+	reportErr := func(err error) {
+		if err != nil && err != context.Canceled {
 			select {
-			case <-ctx.Done():
-				return ctx.Err()
+			case errChan <- err:
 			default:
 			}
+		}
+	}
 
-			data, err := json.Marshal(candle)
+	subscribers := 0
+	subscriberDone := make(chan struct{}, len(intervals)+len(streams))
 
-			if err != nil {
-				logger.LogError("Marshal candle error", err)
-				return err
-			}
+	for _, interval := range intervals {
+		subscribers++
+		go func(interval string) {
+			defer func() { subscriberDone <- struct{}{} }()
+			reportErr(h.streamService.SubscribeCandle(ctx, pair, interval, func(candle models.Candle) error {
+				if err := emit(sseEvent{name: sseEventCandle, data: candle}); err != nil {
+					return err
+				}
 
-			// recover panic
-			defer func() {
-				if r := recover(); r != nil {
-					logger.LogInfo(fmt.Sprintf("Recovered in candle stream SSE: %v", r))
+				// nilai indicator terbaru dikirim sebagai event "indicator"
+				for _, result := range h.latestIndicators(pair, interval, streamIndicators) {
+					if err := emit(sseEvent{name: sseEventIndicator, data: result}); err != nil {
+						return err
+					}
 				}
-			}()
-			// safe write
 
-			if _, err := fmt.Fprintf(w, "data: %s\n\n", string(data)); err != nil {
+				return nil
+			}))
+		}(interval)
+	}
+
+	if streams[sseEventTicker] {
+		subscribers++
+		go func() {
+			defer func() { subscriberDone <- struct{}{} }()
+			reportErr(h.marketStream.SubscribeTicker(ctx, pair, func(update dto.PriceUpdate) error {
+				return emit(sseEvent{name: sseEventTicker, data: update})
+			}))
+		}()
+	}
+
+	if streams[sseEventTrade] {
+		subscribers++
+		go func() {
+			defer func() { subscriberDone <- struct{}{} }()
+			reportErr(h.marketStream.SubscribeTrades(ctx, pair, func(trade dto.TradeEvent) error {
+				return emit(sseEvent{name: sseEventTrade, data: trade})
+			}))
+		}()
+	}
+
+	go func() {
+		for i := 0; i < subscribers; i++ {
+			<-subscriberDone
+		}
+		close(doneChan)
+	}()
+
+	// event id = unix millis, dibuat naik terus per koneksi
+	var lastID int64
+	write := func(event sseEvent) error {
+		data, err := json.Marshal(event.data)
+		if err != nil {
+			logger.LogError("Marshal SSE event error", err)
+			return nil
+		}
+
+		id := time.Now().UnixMilli()
+		if id <= lastID {
+			id = lastID + 1
+		}
+		lastID = id
+
+		if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", id, event.name, string(data)); err != nil {
+			return err
+		}
+
+		flusher.Flush()
+		return nil
+	}
+
+	fmt.Fprintf(w, "retry: %d\n\n", sseRetryMillis)
+	flusher.Flush()
+
+	// replay candle yang terlewat, atau candle terakhir untuk koneksi baru
+	for _, candle := range h.initialCandles(pair, intervals, lastEventID) {
+		if err := write(sseEvent{name: sseEventCandle, data: candle}); err != nil {
+			logger.LogError("Write to SSE error", err)
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+loop:
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			logger.LogInfo("SSE disconnected for pair: " + pair)
+			break loop
+		case err := <-errChan:
+			logger.LogError("SSE error for pair "+pair, err)
+			break loop
+		case event := <-events:
+			if err := write(event); err != nil {
 				logger.LogError("Write to SSE error", err)
-				return err
+				break loop
+			}
+		case <-heartbeat.C:
+			if err := write(sseEvent{name: sseEventHeartbeat, data: gin.H{"time": time.Now().Unix()}}); err != nil {
+				break loop
 			}
+		}
+	}
 
-			flusher.Flush()
-			return nil
-		})
+	cancel()
+	<-doneChan
+	logger.LogInfo("SSE routine ended for pair: " + pair)
+}
 
-		if err != nil && err != context.Canceled {
-			select {
-			case errChan <- err:
-			default:
+// initialCandles - tanpa Last-Event-ID: candle terakhir per interval.
+// dengan Last-Event-ID: semua candle yang bisa berubah sejak event itu (start_time >= awal bucket saat itu)
+func (h *CandleStreamHandler) initialCandles(pair string, intervals []string, lastEventID int64) []models.Candle {
+	var result []models.Candle
+
+	for _, interval := range intervals {
+		if lastEventID <= 0 {
+			candle, err := h.candleService.GetLatestCandleByInterval(pair, interval)
+			if err == nil {
+				result = append(result, candle)
 			}
+			continue
+		}
+
+		since := utils.FloorTime(lastEventID/1000, interval)
+		candles, err := h.candleService.GetCandlesFrom(pair, interval, since, sseReplayLimit, false)
+		if err != nil {
+			logger.LogError("SSE replay error for interval "+interval, err)
+			continue
 		}
-	}()
 
-	// handle disconnection
-	select {
-	case <-c.Request.Context().Done():
-		logger.LogInfo("SSE disconnected for interval: " + interval)
-		cancel()
-	case err := <-errChan:
-		logger.LogError("SSE error for interval "+interval, err)
-		cancel()
+		// repository mengembalikan urutan terbaru dulu
+		for i := len(candles) - 1; i >= 0; i-- {
+			result = append(result, candles[i])
+		}
 	}
 
-	<-doneChan
-	logger.LogInfo("SSE routine ended for interval: " + interval)
+	return result
+}
+
+func parseStreamIntervals(single, multi string) ([]string, error) {
+	raw := multi
+	if raw == "" {
+		raw = single
+	}
+
+	var intervals []string
+	seen := make(map[string]bool)
+
+	for _, interval := range strings.Split(raw, ",") {
+		interval = strings.TrimSpace(interval)
+		if !dto.IsValidInterval(interval) {
+			return nil, fmt.Errorf("invalid interval: %q", interval)
+		}
+
+		if !seen[interval] {
+			seen[interval] = true
+			intervals = append(intervals, interval)
+		}
+	}
+
+	return intervals, nil
+}
+
+// parseStreamFeeds - streams=ticker,trades
+func parseStreamFeeds(raw string) (map[string]bool, error) {
+	feeds := make(map[string]bool)
+	if raw == "" {
+		return feeds, nil
+	}
+
+	for _, feed := range strings.Split(raw, ",") {
+		switch strings.TrimSpace(feed) {
+		case "ticker":
+			feeds[sseEventTicker] = true
+		case "trades", "trade":
+			feeds[sseEventTrade] = true
+		default:
+			return nil, fmt.Errorf("invalid stream: %q", feed)
+		}
+	}
+
+	return feeds, nil
+}
+
+// parseLastEventID - header Last-Event-ID (reconnect otomatis browser) atau query last_event_id
+func parseLastEventID(c *gin.Context) (int64, error) {
+	raw := c.GetHeader("Last-Event-ID")
+	if raw == "" {
+		raw = c.Query("last_event_id")
+	}
+
+	if raw == "" {
+		return 0, nil
+	}
+
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id < 0 {
+		return 0, fmt.Errorf("invalid Last-Event-ID")
+	}
+
+	return id, nil
+}
+
+type streamIndicator struct {
+	name   string
+	params []float64
+}
+
+func parseStreamIndicators(values []string) ([]streamIndicator, error) {
+	result := make([]streamIndicator, 0, len(values))
+
+	for _, value := range values {
+		name, raw, _ := strings.Cut(value, ":")
+
+		params, err := indicators.ParseParams(name, raw)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, streamIndicator{name: strings.ToLower(name), params: params})
+	}
+
+	return result, nil
+}
+
+// streamIndicatorEvent - payload event indicator, interval disertakan karena koneksi bisa multi-interval
+type streamIndicatorEvent struct {
+	Interval string `json:"interval"`
+	indicators.Result
+}
+
+func (h *CandleStreamHandler) latestIndicators(pair, interval string, specs []streamIndicator) []streamIndicatorEvent {
+	results := make([]streamIndicatorEvent, 0, len(specs))
+
+	for _, spec := range specs {
+		result, err := h.candleService.GetIndicator(pair, interval, spec.name, spec.params, 1)
+		if err != nil {
+			logger.LogError("Indicator stream error", err)
+			continue
+		}
+
+		results = append(results, streamIndicatorEvent{Interval: interval, Result: result})
+	}
+
+	return results
 }
 
 func (h *CandleStreamHandler) Ping(c *gin.Context) {
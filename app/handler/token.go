@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/livingdolls/go-blockchain-simulate/app/dto"
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+	"github.com/livingdolls/go-blockchain-simulate/app/port"
+	"github.com/livingdolls/go-blockchain-simulate/app/services"
+	"github.com/livingdolls/go-blockchain-simulate/app/worker"
+	"github.com/livingdolls/go-blockchain-simulate/rabbitmq"
+)
+
+type TokenHandler struct {
+	service   services.TokenService
+	rmqClient port.MessageBroker
+}
+
+func NewTokenHandler(service services.TokenService, rmqClient port.MessageBroker) *TokenHandler {
+	return &TokenHandler{
+		service:   service,
+		rmqClient: rmqClient,
+	}
+}
+
+// CreateToken - transaksi CREATE_TOKEN masuk antrian yang sama dengan SEND
+func (h *TokenHandler) CreateToken(c *gin.Context) {
+	var req dto.CreateTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse[string]("Invalid request body"))
+		return
+	}
+
+	msg := worker.TransactionMessage{
+		Type:      "CREATE_TOKEN",
+		Address:   req.Address,
+		ToAddress: req.Address,
+		Amount:    req.Supply,
+		Token:     req.Symbol,
+		TokenName: req.Name,
+		Decimals:  req.Decimals,
+		Nonce:     req.Nonce,
+		Signature: req.Signature,
+	}
+
+	h.publish(c, msg, "Create token transaction submitted successfully and is being processed")
+}
+
+func (h *TokenHandler) TransferToken(c *gin.Context) {
+	var req dto.TokenTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse[string]("Invalid request body"))
+		return
+	}
+
+	msg := worker.TransactionMessage{
+		Type:      "TOKEN_TRANSFER",
+		Address:   req.FromAddress,
+		ToAddress: req.ToAddress,
+		Amount:    req.Amount,
+		Token:     req.Symbol,
+		Nonce:     req.Nonce,
+		Signature: req.Signature,
+	}
+
+	h.publish(c, msg, "Token transfer submitted successfully and is being processed")
+}
+
+func (h *TokenHandler) publish(c *gin.Context, msg worker.TransactionMessage, message string) {
+	body, _ := json.Marshal(msg)
+
+	if err := h.rmqClient.Publish(
+		c.Request.Context(),
+		rabbitmq.TransactionExchange,
+		rabbitmq.TransactionSubmittedKey,
+		body,
+	); err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewErrorResponse[string]("failed to publish token transaction message"))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, dto.NewSuccessResponse(map[string]interface{}{
+		"message": message,
+	}))
+}
+
+func (h *TokenHandler) ListTokens(c *gin.Context) {
+	limit, offset := parseLimitOffset(c)
+
+	tokens, err := h.service.ListTokens(c.Query("status"), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(tokens))
+}
+
+func (h *TokenHandler) GetToken(c *gin.Context) {
+	token, err := h.service.GetToken(c.Param("symbol"))
+	if err != nil {
+		c.JSON(tokenErrorStatus(err), dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(token))
+}
+
+func (h *TokenHandler) GetHolders(c *gin.Context) {
+	limit, offset := parseLimitOffset(c)
+
+	holders, err := h.service.GetHolders(c.Param("symbol"), limit, offset)
+	if err != nil {
+		c.JSON(tokenErrorStatus(err), dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(holders))
+}
+
+func parseLimitOffset(c *gin.Context) (int, int) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	return limit, offset
+}
+
+func tokenErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, entity.ErrTokenNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, entity.ErrInvalidTokenDefinition):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
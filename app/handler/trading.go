@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/livingdolls/go-blockchain-simulate/app/dto"
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+	"github.com/livingdolls/go-blockchain-simulate/app/services"
+	"github.com/livingdolls/go-blockchain-simulate/logger"
+	"github.com/livingdolls/go-blockchain-simulate/utils"
+)
+
+type TradingHandler struct {
+	breaker services.CircuitBreakerService
+}
+
+func NewTradingHandler(breaker services.CircuitBreakerService) *TradingHandler {
+	return &TradingHandler{breaker: breaker}
+}
+
+func (h *TradingHandler) GetStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(h.breaker.Status(c.Request.Context())))
+}
+
+// StreamStatus - SSE untuk perubahan status trading (halt / resume)
+func (h *TradingHandler) StreamStatus(c *gin.Context) {
+	utils.SetupSSEHeaders(c)
+
+	w := c.Writer
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dto.NewErrorResponse[string]("streaming unsupported"))
+		return
+	}
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	writeStatus := func(status models.TradingStatus) error {
+		data, err := json.Marshal(status)
+		if err != nil {
+			return err
+		}
+
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", string(data)); err != nil {
+			return err
+		}
+
+		flusher.Flush()
+		return nil
+	}
+
+	// status saat ini sebagai event pertama
+	if err := writeStatus(h.breaker.Status(ctx)); err != nil {
+		logger.LogError("Write to SSE error", err)
+		return
+	}
+
+	errChan := make(chan error, 1)
+	doneChan := make(chan struct{})
+
+	go func() {
+		defer close(doneChan)
+		err := h.breaker.SubscribeStatus(ctx, func(status models.TradingStatus) error {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+
+			return writeStatus(status)
+		})
+
+		if err != nil && err != context.Canceled {
+			select {
+			case errChan <- err:
+			default:
+			}
+		}
+	}()
+
+	select {
+	case <-c.Request.Context().Done():
+		logger.LogInfo("SSE trading status disconnected")
+		cancel()
+	case err := <-errChan:
+		logger.LogError("SSE trading status error", err)
+		cancel()
+	}
+
+	<-doneChan
+}
+
+func (h *TradingHandler) Halt(c *gin.Context) {
+	admin, err := GetAdminFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.NewErrorResponse[string]("Unauthorized: admin not found"))
+		return
+	}
+
+	var req dto.HaltTradingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse[string]("Invalid request body"))
+		return
+	}
+
+	status, err := h.breaker.Halt(c.Request.Context(), admin.ID, req.Reason, time.Duration(req.DurationSeconds)*time.Second)
+	if err != nil {
+		c.JSON(tradingErrorStatus(err), dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(status))
+}
+
+func (h *TradingHandler) Resume(c *gin.Context) {
+	admin, err := GetAdminFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.NewErrorResponse[string]("Unauthorized: admin not found"))
+		return
+	}
+
+	status, err := h.breaker.Resume(c.Request.Context(), admin.ID)
+	if err != nil {
+		c.JSON(tradingErrorStatus(err), dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(status))
+}
+
+func (h *TradingHandler) History(c *gin.Context) {
+	limit, offset := parseLimitOffset(c)
+
+	halts, err := h.breaker.History(limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(halts))
+}
+
+func (h *TradingHandler) GetConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(toBreakerConfigDTO(h.breaker.Config())))
+}
+
+func (h *TradingHandler) UpdateConfig(c *gin.Context) {
+	var req dto.CircuitBreakerConfigDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse[string]("Invalid request body"))
+		return
+	}
+
+	cfg := services.CircuitBreakerConfig{Enabled: req.Enabled}
+	for _, l := range req.Levels {
+		cfg.Levels = append(cfg.Levels, services.CircuitBreakerLevel{
+			ThresholdPercent: l.ThresholdPercent,
+			Cooldown:         time.Duration(l.CooldownSeconds) * time.Second,
+		})
+	}
+
+	updated, err := h.breaker.UpdateConfig(cfg)
+	if err != nil {
+		c.JSON(tradingErrorStatus(err), dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(toBreakerConfigDTO(updated)))
+}
+
+func toBreakerConfigDTO(cfg services.CircuitBreakerConfig) dto.CircuitBreakerConfigDTO {
+	levels := make([]dto.CircuitBreakerLevelDTO, 0, len(cfg.Levels))
+	for _, l := range cfg.Levels {
+		levels = append(levels, dto.CircuitBreakerLevelDTO{
+			ThresholdPercent: l.ThresholdPercent,
+			CooldownSeconds:  int64(l.Cooldown / time.Second),
+		})
+	}
+
+	return dto.CircuitBreakerConfigDTO{Enabled: cfg.Enabled, Levels: levels}
+}
+
+func tradingErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, entity.ErrInvalidInput):
+		return http.StatusBadRequest
+	case errors.Is(err, entity.ErrConflict), errors.Is(err, entity.ErrTradingNotHalted):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
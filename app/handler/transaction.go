@@ -7,6 +7,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/livingdolls/go-blockchain-simulate/app/dto"
+	"github.com/livingdolls/go-blockchain-simulate/app/port"
 	"github.com/livingdolls/go-blockchain-simulate/app/services"
 	"github.com/livingdolls/go-blockchain-simulate/app/worker"
 	"github.com/livingdolls/go-blockchain-simulate/rabbitmq"
@@ -27,7 +28,9 @@ type SendTransactionWithSignatureRequest struct {
 	Signature   string  `json:"signature"`
 }
 
+// BuySellTransactionRequest - pair opsional, hanya pair native (YTE-USD) yang bisa di-trade
 type BuySellTransactionRequest struct {
+	Pair      string  `json:"pair"`
 	Address   string  `json:"address"`
 	Amount    float64 `json:"amount"`
 	Nonce     string  `json:"nonce"`
@@ -36,13 +39,15 @@ type BuySellTransactionRequest struct {
 
 type TransactionHandler struct {
 	transactionService services.TransactionService
-	rmqClient          *rabbitmq.Client
+	rmqClient          port.MessageBroker
+	breaker            services.CircuitBreakerService
 }
 
-func NewTransactionHandler(transactionService services.TransactionService, rmqClient *rabbitmq.Client) *TransactionHandler {
+func NewTransactionHandler(transactionService services.TransactionService, rmqClient port.MessageBroker, breaker services.CircuitBreakerService) *TransactionHandler {
 	return &TransactionHandler{
 		transactionService: transactionService,
 		rmqClient:          rmqClient,
+		breaker:            breaker,
 	}
 }
 
@@ -86,6 +91,17 @@ func (h *TransactionHandler) Buy(c *gin.Context) {
 		return
 	}
 
+	if err := services.EnsureTradablePair(req.Pair); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	// tolak lebih awal saat trading di-halt, consumer juga akan menolak
+	if err := h.breaker.EnsureTradingOpen(c.Request.Context()); err != nil {
+		c.JSON(http.StatusServiceUnavailable, dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
 	msg := worker.TransactionMessage{
 		Type:      "BUY",
 		Address:   req.Address,
@@ -118,6 +134,17 @@ func (h *TransactionHandler) Sell(c *gin.Context) {
 		return
 	}
 
+	if err := services.EnsureTradablePair(req.Pair); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
+	// tolak lebih awal saat trading di-halt, consumer juga akan menolak
+	if err := h.breaker.EnsureTradingOpen(c.Request.Context()); err != nil {
+		c.JSON(http.StatusServiceUnavailable, dto.NewErrorResponse[string](err.Error()))
+		return
+	}
+
 	msg := worker.TransactionMessage{
 		Type:      "SELL",
 		Address:   req.Address,
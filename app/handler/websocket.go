@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/livingdolls/go-blockchain-simulate/app/dto"
+	"github.com/livingdolls/go-blockchain-simulate/app/websocket"
+)
+
+type WebSocketHandler struct {
+	hub *websocket.Hub
+}
+
+func NewWebSocketHandler(hub *websocket.Hub) *WebSocketHandler {
+	return &WebSocketHandler{hub: hub}
+}
+
+// Metrics - counter pesan terkirim/drop/replay/ack dan client lambat di instance ini
+func (h *WebSocketHandler) Metrics(c *gin.Context) {
+	c.JSON(http.StatusOK, dto.NewSuccessResponse(h.hub.Metrics()))
+}
@@ -0,0 +1,210 @@
+// Package indicators menghitung technical indicator dari deret candle (urut naik berdasarkan start_time).
+package indicators
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+)
+
+const (
+	SMA       = "sma"
+	EMA       = "ema"
+	RSI       = "rsi"
+	MACD      = "macd"
+	Bollinger = "bollinger"
+	VWAP      = "vwap"
+	ATR       = "atr"
+)
+
+// Point - nilai indicator pada satu candle, key = nama garis (value, macd, signal, upper, ...)
+type Point struct {
+	Time   int64              `json:"time"`
+	Values map[string]float64 `json:"values"`
+}
+
+type Result struct {
+	Indicator string    `json:"indicator"`
+	Params    []float64 `json:"params"`
+	Points    []Point   `json:"points"`
+}
+
+type spec struct {
+	defaults []float64
+	// lookback - jumlah candle tambahan sebelum window agar nilai awal sudah stabil
+	lookback func(params []float64) int
+	compute  func(candles []models.Candle, params []float64) map[string][]float64
+}
+
+var registry = map[string]spec{
+	SMA: {
+		defaults: []float64{20},
+		lookback: func(p []float64) int { return int(p[0]) },
+		compute: func(c []models.Candle, p []float64) map[string][]float64 {
+			return map[string][]float64{"value": sma(closes(c), int(p[0]))}
+		},
+	},
+	EMA: {
+		defaults: []float64{20},
+		lookback: func(p []float64) int { return int(p[0]) * 3 },
+		compute: func(c []models.Candle, p []float64) map[string][]float64 {
+			return map[string][]float64{"value": ema(closes(c), int(p[0]))}
+		},
+	},
+	RSI: {
+		defaults: []float64{14},
+		lookback: func(p []float64) int { return int(p[0]) * 3 },
+		compute: func(c []models.Candle, p []float64) map[string][]float64 {
+			return map[string][]float64{"value": rsi(closes(c), int(p[0]))}
+		},
+	},
+	MACD: {
+		defaults: []float64{12, 26, 9},
+		lookback: func(p []float64) int { return int(p[1])*3 + int(p[2]) },
+		compute: func(c []models.Candle, p []float64) map[string][]float64 {
+			line, signal, hist := macd(closes(c), int(p[0]), int(p[1]), int(p[2]))
+			return map[string][]float64{"macd": line, "signal": signal, "histogram": hist}
+		},
+	},
+	Bollinger: {
+		defaults: []float64{20, 2},
+		lookback: func(p []float64) int { return int(p[0]) },
+		compute: func(c []models.Candle, p []float64) map[string][]float64 {
+			upper, middle, lower := bollinger(closes(c), int(p[0]), p[1])
+			return map[string][]float64{"upper": upper, "middle": middle, "lower": lower}
+		},
+	},
+	VWAP: {
+		// period 0 = kumulatif sepanjang window
+		defaults: []float64{0},
+		lookback: func(p []float64) int { return int(p[0]) },
+		compute: func(c []models.Candle, p []float64) map[string][]float64 {
+			return map[string][]float64{"value": vwap(c, int(p[0]))}
+		},
+	},
+	ATR: {
+		defaults: []float64{14},
+		lookback: func(p []float64) int { return int(p[0]) * 3 },
+		compute: func(c []models.Candle, p []float64) map[string][]float64 {
+			return map[string][]float64{"value": atr(c, int(p[0]))}
+		},
+	},
+}
+
+// Names - indicator yang didukung
+func Names() []string {
+	return []string{SMA, EMA, RSI, MACD, Bollinger, VWAP, ATR}
+}
+
+// ParseParams - "12,26,9" -> []float64, param kosong diisi default
+func ParseParams(name, raw string) ([]float64, error) {
+	s, ok := registry[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", entity.ErrUnknownIndicator, name)
+	}
+
+	params := append([]float64(nil), s.defaults...)
+
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return params, nil
+	}
+
+	parts := strings.Split(raw, ",")
+	if len(parts) > len(params) {
+		return nil, fmt.Errorf("%w: %s accepts at most %d params", entity.ErrInvalidIndicatorParams, name, len(params))
+	}
+
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil || v < 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, fmt.Errorf("%w: %q", entity.ErrInvalidIndicatorParams, part)
+		}
+		params[i] = v
+	}
+
+	return params, validate(strings.ToLower(name), params)
+}
+
+// Lookback - jumlah candle warm-up yang dibutuhkan sebelum titik pertama yang diminta
+func Lookback(name string, params []float64) int {
+	s, ok := registry[strings.ToLower(name)]
+	if !ok {
+		return 0
+	}
+	return s.lookback(params)
+}
+
+// Calculate - hitung indicator, titik yang belum punya nilai (warm-up) tidak dikembalikan
+func Calculate(name string, params []float64, candles []models.Candle) (Result, error) {
+	name = strings.ToLower(name)
+
+	s, ok := registry[name]
+	if !ok {
+		return Result{}, fmt.Errorf("%w: %s", entity.ErrUnknownIndicator, name)
+	}
+
+	if err := validate(name, params); err != nil {
+		return Result{}, err
+	}
+
+	lines := s.compute(candles, params)
+
+	points := make([]Point, 0, len(candles))
+	for i, candle := range candles {
+		values := make(map[string]float64, len(lines))
+		for key, line := range lines {
+			if !math.IsNaN(line[i]) {
+				values[key] = line[i]
+			}
+		}
+
+		// semua garis harus sudah terisi (mis. MACD signal)
+		if len(values) != len(lines) {
+			continue
+		}
+
+		points = append(points, Point{Time: candle.StartTime, Values: values})
+	}
+
+	return Result{Indicator: name, Params: params, Points: points}, nil
+}
+
+func validate(name string, params []float64) error {
+	s := registry[name]
+	if len(params) != len(s.defaults) {
+		return fmt.Errorf("%w: %s expects %d params", entity.ErrInvalidIndicatorParams, name, len(s.defaults))
+	}
+
+	for i, p := range params {
+		// bollinger param kedua (multiplier) boleh pecahan, vwap period boleh 0
+		if name == Bollinger && i == 1 {
+			if p <= 0 {
+				return fmt.Errorf("%w: multiplier must be greater than zero", entity.ErrInvalidIndicatorParams)
+			}
+			continue
+		}
+
+		if p != math.Trunc(p) || p > 500 || (p < 1 && name != VWAP) {
+			return fmt.Errorf("%w: period must be an integer between 1 and 500", entity.ErrInvalidIndicatorParams)
+		}
+	}
+
+	if name == MACD && params[0] >= params[1] {
+		return fmt.Errorf("%w: macd fast period must be shorter than slow period", entity.ErrInvalidIndicatorParams)
+	}
+
+	return nil
+}
+
+func closes(candles []models.Candle) []float64 {
+	out := make([]float64, len(candles))
+	for i, c := range candles {
+		out[i] = c.ClosePrice
+	}
+	return out
+}
@@ -0,0 +1,251 @@
+package indicators
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+)
+
+var nan = math.NaN()
+
+// assertLine - NaN di want berarti index tersebut belum punya nilai (warm-up)
+func assertLine(t *testing.T, name string, got, want []float64) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("%s = %v, want %v", name, got, want)
+	}
+
+	for i := range want {
+		if math.IsNaN(want[i]) != math.IsNaN(got[i]) || (!math.IsNaN(want[i]) && math.Abs(got[i]-want[i]) > 1e-9) {
+			t.Fatalf("%s[%d] = %v, want %v (line %v)", name, i, got[i], want[i], got)
+		}
+	}
+}
+
+func candle(high, low, close, volume float64) models.Candle {
+	return models.Candle{HighPrice: high, LowPrice: low, ClosePrice: close, Volume: volume}
+}
+
+func TestSMA(t *testing.T) {
+	assertLine(t, "sma", sma([]float64{1, 2, 3, 4, 5}, 3), []float64{nan, nan, 2, 3, 4})
+	assertLine(t, "sma period > len", sma([]float64{1, 2}, 3), []float64{nan, nan})
+}
+
+func TestEMASeededFromSMA(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		period int
+		want   []float64
+	}{
+		// seed = SMA(2, 4, 6) = 4, k = 2/(3+1) = 0.5
+		{name: "seed then smoothing", values: []float64{2, 4, 6, 8, 4}, period: 3, want: []float64{nan, nan, 4, 6, 5}},
+		// NaN di depan (mis. garis MACD) dilewati, seed dihitung dari nilai valid pertama
+		{name: "leading NaN", values: []float64{nan, 2, 4, 6, 8}, period: 3, want: []float64{nan, nan, nan, 4, 6}},
+		{name: "period 1 follows input", values: []float64{3, 1, 2}, period: 1, want: []float64{3, 1, 2}},
+		{name: "not enough values", values: []float64{1, 2}, period: 3, want: []float64{nan, nan}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertLine(t, "ema", ema(tt.values, tt.period), tt.want)
+		})
+	}
+}
+
+func TestRSIWilder(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   []float64
+	}{
+		// perubahan +1 -1 +2 | +1 -2. awal: avg gain 1, avg loss 1/3 -> 75.
+		// Wilder: gain (1*2+1)/3 = 1, loss (1/3*2)/3 = 2/9 -> 100 - 100/5.5,
+		// lalu gain 2/3, loss 22/27 -> 45 (RSI rata-rata sederhana 3 perubahan terakhir = 60)
+		{name: "smoothing", values: []float64{10, 11, 10, 12, 13, 11}, want: []float64{nan, nan, nan, 75, 100 - 100/5.5, 45}},
+		{name: "only gains", values: []float64{1, 2, 3, 4}, want: []float64{nan, nan, nan, 100}},
+		{name: "flat", values: []float64{5, 5, 5, 5}, want: []float64{nan, nan, nan, 50}},
+		{name: "not enough values", values: []float64{1, 2, 3}, want: []float64{nan, nan, nan}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertLine(t, "rsi", rsi(tt.values, 3), tt.want)
+		})
+	}
+}
+
+func TestMACDSignalAlignment(t *testing.T) {
+	values := []float64{1, 2, 4, 8, 16, 32}
+
+	// fast EMA(2) dan slow EMA(3) terisi mulai index 2, signal EMA(2) dari garis MACD mulai index 3
+	line, signal, hist := macd(values, 2, 3, 2)
+
+	assertLine(t, "macd", line, []float64{nan, nan, 5.0 / 6, 11.0 / 9, 239.0 / 108, 2791.0 / 648})
+	assertLine(t, "signal", signal, []float64{nan, nan, nan, 37.0 / 36, 589.0 / 324, 845.0 / 243})
+	assertLine(t, "histogram", hist, []float64{nan, nan, nan, 11.0/9 - 37.0/36, 239.0/108 - 589.0/324, 2791.0/648 - 845.0/243})
+
+	// Calculate hanya mengembalikan titik yang semua garisnya terisi
+	candles := make([]models.Candle, len(values))
+	for i, v := range values {
+		candles[i] = models.Candle{StartTime: int64(i) * 60, ClosePrice: v}
+	}
+
+	result, err := Calculate(MACD, []float64{2, 3, 2}, candles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Points) != 3 || result.Points[0].Time != 180 || len(result.Points[0].Values) != 3 {
+		t.Fatalf("points = %+v, want 3 points starting at the first signal value", result.Points)
+	}
+}
+
+func TestBollinger(t *testing.T) {
+	upper, middle, lower := bollinger([]float64{2, 4, 6, 8}, 3, 2)
+
+	// deviasi standar populasi dari (2, 4, 6) dan (4, 6, 8) = sqrt(8/3)
+	band := 2 * math.Sqrt(8.0/3)
+	assertLine(t, "middle", middle, []float64{nan, nan, 4, 6})
+	assertLine(t, "upper", upper, []float64{nan, nan, 4 + band, 6 + band})
+	assertLine(t, "lower", lower, []float64{nan, nan, 4 - band, 6 - band})
+}
+
+func TestVWAP(t *testing.T) {
+	// typical price 10, 20, 30 dengan volume 1, 3, 1
+	candles := []models.Candle{candle(12, 8, 10, 1), candle(22, 18, 20, 3), candle(32, 28, 30, 1)}
+
+	tests := []struct {
+		name    string
+		candles []models.Candle
+		period  int
+		want    []float64
+	}{
+		{name: "cumulative", candles: candles, period: 0, want: []float64{10, 17.5, 20}},
+		{name: "rolling", candles: candles, period: 2, want: []float64{nan, 17.5, 22.5}},
+		{name: "period 1 is typical price", candles: candles, period: 1, want: []float64{10, 20, 30}},
+		{name: "no volume yet", candles: []models.Candle{candle(12, 8, 10, 0), candle(22, 18, 20, 2)}, period: 0, want: []float64{nan, 20}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertLine(t, "vwap", vwap(tt.candles, tt.period), tt.want)
+		})
+	}
+}
+
+func TestATRWilder(t *testing.T) {
+	candles := []models.Candle{
+		candle(10, 8, 9, 0),
+		candle(11, 9, 10, 0),  // TR 2
+		candle(14, 12, 13, 0), // gap naik: |14 - 10| = 4
+		candle(13, 9, 10, 0),  // |9 - 13| = 4
+	}
+
+	// ATR awal = rata-rata TR index 1..2 = 3, lalu (3*1 + 4) / 2
+	assertLine(t, "atr", atr(candles, 2), []float64{nan, nan, 3, 3.5})
+	assertLine(t, "atr not enough candles", atr(candles[:2], 2), []float64{nan, nan})
+}
+
+func TestParseParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    []float64
+		wantErr error
+	}{
+		{name: "sma", raw: "", want: []float64{20}},
+		{name: "SMA", raw: " 50 ", want: []float64{50}},
+		{name: "sma", raw: "1", want: []float64{1}},
+		{name: "sma", raw: "500", want: []float64{500}},
+		{name: "sma", raw: "0", wantErr: entity.ErrInvalidIndicatorParams},
+		{name: "sma", raw: "501", wantErr: entity.ErrInvalidIndicatorParams},
+		{name: "sma", raw: "2.5", wantErr: entity.ErrInvalidIndicatorParams},
+		{name: "sma", raw: "-1", wantErr: entity.ErrInvalidIndicatorParams},
+		{name: "sma", raw: "NaN", wantErr: entity.ErrInvalidIndicatorParams},
+		{name: "sma", raw: "abc", wantErr: entity.ErrInvalidIndicatorParams},
+		{name: "sma", raw: "10,20", wantErr: entity.ErrInvalidIndicatorParams},
+		// param yang tidak diisi tetap default
+		{name: "macd", raw: "5,10", want: []float64{5, 10, 9}},
+		{name: "macd", raw: "26,12", wantErr: entity.ErrInvalidIndicatorParams},
+		{name: "macd", raw: "12,12", wantErr: entity.ErrInvalidIndicatorParams},
+		{name: "bollinger", raw: "20,2.5", want: []float64{20, 2.5}},
+		{name: "bollinger", raw: "20,0", wantErr: entity.ErrInvalidIndicatorParams},
+		{name: "vwap", raw: "", want: []float64{0}},
+		{name: "vwap", raw: "30", want: []float64{30}},
+		{name: "ema", raw: "0", wantErr: entity.ErrInvalidIndicatorParams},
+		{name: "stoch", raw: "", wantErr: entity.ErrUnknownIndicator},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+"/"+tt.raw, func(t *testing.T) {
+			got, err := ParseParams(tt.name, tt.raw)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ParseParams(%q, %q) = %v, %v; want %v", tt.name, tt.raw, got, err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertLine(t, "params", got, tt.want)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		params []float64
+		ok     bool
+	}{
+		{name: SMA, params: []float64{20}, ok: true},
+		{name: SMA, params: nil},
+		{name: SMA, params: []float64{20, 2}},
+		{name: MACD, params: []float64{12, 26}},
+		{name: MACD, params: []float64{12, 26, 0}},
+		{name: Bollinger, params: []float64{20, -1}},
+		{name: VWAP, params: []float64{0}, ok: true},
+		{name: ATR, params: []float64{14.5}},
+	}
+
+	for _, tt := range tests {
+		err := validate(tt.name, tt.params)
+		if tt.ok != (err == nil) || (err != nil && !errors.Is(err, entity.ErrInvalidIndicatorParams)) {
+			t.Fatalf("validate(%s, %v) = %v, want ok=%t", tt.name, tt.params, err, tt.ok)
+		}
+	}
+
+	// Calculate memvalidasi ulang params dari caller
+	if _, err := Calculate(SMA, []float64{0}, nil); !errors.Is(err, entity.ErrInvalidIndicatorParams) {
+		t.Fatalf("Calculate with invalid params = %v", err)
+	}
+}
+
+func TestLookback(t *testing.T) {
+	tests := []struct {
+		name   string
+		params []float64
+		want   int
+	}{
+		{name: SMA, params: []float64{20}, want: 20},
+		{name: EMA, params: []float64{20}, want: 60},
+		{name: RSI, params: []float64{14}, want: 42},
+		{name: MACD, params: []float64{12, 26, 9}, want: 87},
+		{name: Bollinger, params: []float64{20, 2}, want: 20},
+		{name: VWAP, params: []float64{0}, want: 0},
+		{name: "ATR", params: []float64{14}, want: 42},
+		{name: "stoch", params: []float64{14}, want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := Lookback(tt.name, tt.params); got != tt.want {
+			t.Fatalf("Lookback(%s, %v) = %d, want %d", tt.name, tt.params, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package indicators
+
+import (
+	"math"
+
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+)
+
+// semua fungsi mengembalikan slice sepanjang input, NaN untuk index yang belum punya nilai
+
+func nanSlice(n int) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = math.NaN()
+	}
+	return out
+}
+
+func sma(values []float64, period int) []float64 {
+	out := nanSlice(len(values))
+	if period <= 0 {
+		return out
+	}
+
+	var sum float64
+	for i, v := range values {
+		sum += v
+		if i >= period {
+			sum -= values[i-period]
+		}
+		if i >= period-1 {
+			out[i] = sum / float64(period)
+		}
+	}
+
+	return out
+}
+
+// ema - seed dengan SMA periode pertama, NaN di input dilewati
+func ema(values []float64, period int) []float64 {
+	out := nanSlice(len(values))
+	if period <= 0 {
+		return out
+	}
+
+	k := 2 / float64(period+1)
+	count := 0
+	var sum, prev float64
+
+	for i, v := range values {
+		if math.IsNaN(v) {
+			continue
+		}
+
+		count++
+		if count < period {
+			sum += v
+			continue
+		}
+
+		if count == period {
+			sum += v
+			prev = sum / float64(period)
+		} else {
+			prev = (v-prev)*k + prev
+		}
+		out[i] = prev
+	}
+
+	return out
+}
+
+// rsi - Wilder smoothing
+func rsi(values []float64, period int) []float64 {
+	out := nanSlice(len(values))
+	if period <= 0 || len(values) <= period {
+		return out
+	}
+
+	var gain, loss float64
+	for i := 1; i <= period; i++ {
+		change := values[i] - values[i-1]
+		if change > 0 {
+			gain += change
+		} else {
+			loss -= change
+		}
+	}
+
+	avgGain := gain / float64(period)
+	avgLoss := loss / float64(period)
+	out[period] = rsiValue(avgGain, avgLoss)
+
+	for i := period + 1; i < len(values); i++ {
+		change := values[i] - values[i-1]
+		g, l := 0.0, 0.0
+		if change > 0 {
+			g = change
+		} else {
+			l = -change
+		}
+
+		avgGain = (avgGain*float64(period-1) + g) / float64(period)
+		avgLoss = (avgLoss*float64(period-1) + l) / float64(period)
+		out[i] = rsiValue(avgGain, avgLoss)
+	}
+
+	return out
+}
+
+func rsiValue(avgGain, avgLoss float64) float64 {
+	if avgLoss == 0 {
+		if avgGain == 0 {
+			return 50
+		}
+		return 100
+	}
+
+	rs := avgGain / avgLoss
+	return 100 - 100/(1+rs)
+}
+
+func macd(values []float64, fast, slow, signalPeriod int) ([]float64, []float64, []float64) {
+	fastLine := ema(values, fast)
+	slowLine := ema(values, slow)
+
+	line := nanSlice(len(values))
+	for i := range values {
+		if !math.IsNaN(fastLine[i]) && !math.IsNaN(slowLine[i]) {
+			line[i] = fastLine[i] - slowLine[i]
+		}
+	}
+
+	signal := ema(line, signalPeriod)
+
+	hist := nanSlice(len(values))
+	for i := range values {
+		if !math.IsNaN(line[i]) && !math.IsNaN(signal[i]) {
+			hist[i] = line[i] - signal[i]
+		}
+	}
+
+	return line, signal, hist
+}
+
+// bollinger - middle = SMA, band = middle ± multiplier * standar deviasi (populasi)
+func bollinger(values []float64, period int, multiplier float64) ([]float64, []float64, []float64) {
+	middle := sma(values, period)
+	upper := nanSlice(len(values))
+	lower := nanSlice(len(values))
+
+	for i := range values {
+		if math.IsNaN(middle[i]) {
+			continue
+		}
+
+		var variance float64
+		for j := i - period + 1; j <= i; j++ {
+			d := values[j] - middle[i]
+			variance += d * d
+		}
+		std := math.Sqrt(variance / float64(period))
+
+		upper[i] = middle[i] + multiplier*std
+		lower[i] = middle[i] - multiplier*std
+	}
+
+	return upper, middle, lower
+}
+
+// vwap - typical price (H+L+C)/3 dibobot volume, period 0 = kumulatif
+func vwap(candles []models.Candle, period int) []float64 {
+	out := nanSlice(len(candles))
+
+	var pv, vol float64
+	for i, c := range candles {
+		typical := (c.HighPrice + c.LowPrice + c.ClosePrice) / 3
+		pv += typical * c.Volume
+		vol += c.Volume
+
+		if period > 0 && i >= period {
+			old := candles[i-period]
+			pv -= (old.HighPrice + old.LowPrice + old.ClosePrice) / 3 * old.Volume
+			vol -= old.Volume
+		}
+
+		if period > 0 && i < period-1 {
+			continue
+		}
+
+		if vol > 0 {
+			out[i] = pv / vol
+		}
+	}
+
+	return out
+}
+
+// atr - true range dengan Wilder smoothing
+func atr(candles []models.Candle, period int) []float64 {
+	out := nanSlice(len(candles))
+	if period <= 0 || len(candles) <= period {
+		return out
+	}
+
+	tr := make([]float64, len(candles))
+	for i, c := range candles {
+		if i == 0 {
+			tr[i] = c.HighPrice - c.LowPrice
+			continue
+		}
+
+		prevClose := candles[i-1].ClosePrice
+		tr[i] = math.Max(c.HighPrice-c.LowPrice, math.Max(math.Abs(c.HighPrice-prevClose), math.Abs(c.LowPrice-prevClose)))
+	}
+
+	var sum float64
+	for i := 1; i <= period; i++ {
+		sum += tr[i]
+	}
+
+	prev := sum / float64(period)
+	out[period] = prev
+
+	for i := period + 1; i < len(candles); i++ {
+		prev = (prev*float64(period-1) + tr[i]) / float64(period)
+		out[i] = prev
+	}
+
+	return out
+}
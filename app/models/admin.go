@@ -166,6 +166,8 @@ type AdminDashboardStats struct {
 	SuspendedAdmins     int     `json:"suspended_admins"`
 	RecentActivityCount int64   `json:"recent_activity_count"`
 	TotalVolume         float64 `json:"total_volume"`
+	TradingHalted       bool    `json:"trading_halted"`
+	HaltsLast24h        int64   `json:"halts_last_24h"`
 }
 
 // PermissionSet untuk cek permission
@@ -0,0 +1,46 @@
+package models
+
+const (
+	// DefaultPair - pair native yang harganya digerakkan oleh block pricing
+	DefaultPair = "YTE-USD"
+	NativeAsset = "YTE"
+	QuoteAsset  = "USD"
+)
+
+// Ledger leg - peran entry dalam satu transaksi, bagian dari unique key (block_id, tx_id, address, leg)
+const (
+	LedgerLegDebit       = "debit"
+	LedgerLegCredit      = "credit"
+	LedgerLegFee         = "fee"
+	LedgerLegTokenDebit  = "token_debit"
+	LedgerLegTokenCredit = "token_credit"
+)
+
+const (
+	AssetStatusActive   = "ACTIVE"
+	AssetStatusDelisted = "DELISTED"
+)
+
+type Asset struct {
+	Symbol    string `db:"symbol" json:"symbol"`
+	Name      string `db:"name" json:"name"`
+	Decimals  int    `db:"decimals" json:"decimals"`
+	IsNative  bool   `db:"is_native" json:"is_native"`
+	Status    string `db:"status" json:"status"`
+	CreatedAt string `db:"created_at" json:"created_at"`
+}
+
+type TradingPair struct {
+	Symbol     string `db:"symbol" json:"symbol"` // BASE-QUOTE, contoh: YTE-USD
+	BaseAsset  string `db:"base_asset" json:"base_asset"`
+	QuoteAsset string `db:"quote_asset" json:"quote_asset"`
+	Status     string `db:"status" json:"status"`
+	CreatedAt  string `db:"created_at" json:"created_at"`
+}
+
+type AssetBalance struct {
+	UserAddress string  `db:"user_address" json:"user_address"`
+	AssetSymbol string  `db:"asset_symbol" json:"asset_symbol"`
+	Balance     float64 `db:"balance" json:"balance"`
+	UpdatedAt   string  `db:"updated_at" json:"updated_at"`
+}
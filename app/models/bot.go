@@ -0,0 +1,31 @@
+package models
+
+const (
+	BotStrategyRandomWalk    = "RANDOM_WALK"
+	BotStrategyMomentum      = "MOMENTUM"
+	BotStrategyMeanReversion = "MEAN_REVERSION"
+	BotStrategyMarketMaker   = "MARKET_MAKER"
+)
+
+const (
+	BotStatusRunning = "RUNNING"
+	BotStatusStopped = "STOPPED"
+)
+
+type TradingBot struct {
+	ID               int64   `db:"id" json:"id"`
+	Name             string  `db:"name" json:"name"`
+	Address          string  `db:"address" json:"address"`
+	PrivateKey       string  `db:"private_key" json:"-"`
+	Strategy         string  `db:"strategy" json:"strategy"`
+	Status           string  `db:"status" json:"status"`
+	IntervalSeconds  int     `db:"interval_seconds" json:"interval_seconds"`
+	MinAmount        float64 `db:"min_amount" json:"min_amount"`
+	MaxAmount        float64 `db:"max_amount" json:"max_amount"`
+	Lookback         int     `db:"lookback" json:"lookback"`                   // jumlah tick untuk momentum / mean reversion / market maker
+	ThresholdPercent float64 `db:"threshold_percent" json:"threshold_percent"` // deviasi minimal dari rata-rata sebelum bot trading
+	SpreadPercent    float64 `db:"spread_percent" json:"spread_percent"`       // lebar spread market maker
+	TargetInventory  float64 `db:"target_inventory" json:"target_inventory"`   // inventory YTE yang dijaga market maker
+	CreatedAt        string  `db:"created_at" json:"created_at"`
+	UpdatedAt        string  `db:"updated_at" json:"updated_at"`
+}
@@ -2,6 +2,7 @@ package models
 
 type Candle struct {
 	ID           int64   `db:"id" json:"id"`
+	Pair         string  `db:"pair" json:"pair"`
 	IntervalType string  `db:"interval_type" json:"interval_type"`
 	StartTime    int64   `db:"start_time" json:"start_time"`
 	OpenPrice    float64 `db:"open_price" json:"open_price"`
@@ -9,4 +10,8 @@ type Candle struct {
 	LowPrice     float64 `db:"low_price" json:"low_price"`
 	ClosePrice   float64 `db:"close_price" json:"close_price"`
 	Volume       float64 `db:"volume" json:"volume"`
+	BuyVolume    float64 `db:"buy_volume" json:"buy_volume"`
+	SellVolume   float64 `db:"sell_volume" json:"sell_volume"`
+	TradeCount   int     `db:"trade_count" json:"trade_count"`
+	LastBlockID  int64   `db:"last_block_id" json:"-"`
 }
@@ -0,0 +1,34 @@
+package models
+
+const (
+	ConditionalStopLoss     = "STOP_LOSS"
+	ConditionalTakeProfit   = "TAKE_PROFIT"
+	ConditionalTrailingStop = "TRAILING_STOP"
+)
+
+const (
+	ConditionalStatusActive    = "ACTIVE"
+	ConditionalStatusTriggered = "TRIGGERED"
+	ConditionalStatusExecuted  = "EXECUTED"
+	ConditionalStatusFailed    = "FAILED"
+	ConditionalStatusCancelled = "CANCELLED"
+)
+
+type ConditionalOrder struct {
+	ID             int64    `db:"id" json:"id"`
+	UserAddress    string   `db:"user_address" json:"user_address"`
+	OrderType      string   `db:"order_type" json:"order_type"` // "STOP_LOSS", "TAKE_PROFIT", "TRAILING_STOP"
+	Side           string   `db:"side" json:"side"`             // "BUY", "SELL"
+	Amount         float64  `db:"amount" json:"amount"`
+	TriggerPrice   float64  `db:"trigger_price" json:"trigger_price"`
+	TrailPercent   float64  `db:"trail_percent" json:"trail_percent"`
+	ReferencePrice float64  `db:"reference_price" json:"reference_price"` // peak (SELL) / trough (BUY) untuk trailing stop
+	Signature      string   `db:"signature" json:"-"`
+	Status         string   `db:"status" json:"status"`
+	TriggeredPrice *float64 `db:"triggered_price" json:"triggered_price,omitempty"`
+	TriggeredBlock *int64   `db:"triggered_block" json:"triggered_block,omitempty"`
+	TxID           *int64   `db:"tx_id" json:"tx_id,omitempty"`
+	FailureReason  *string  `db:"failure_reason" json:"failure_reason,omitempty"`
+	CreatedAt      string   `db:"created_at" json:"created_at"`
+	UpdatedAt      string   `db:"updated_at" json:"updated_at"`
+}
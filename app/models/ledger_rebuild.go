@@ -0,0 +1,104 @@
+package models
+
+// RebuildTransaction - transaksi terkonfirmasi urut block_number lalu id, sumber replay market dan USD
+type RebuildTransaction struct {
+	BlockID     int64   `db:"block_id"`
+	BlockNumber int64   `db:"block_number"`
+	TxID        int64   `db:"tx_id"`
+	Type        string  `db:"type"`
+	FromAddress string  `db:"from_address"`
+	ToAddress   string  `db:"to_address"`
+	Amount      float64 `db:"amount"`
+	Fee         float64 `db:"fee"`
+}
+
+// BalanceChange - satu perubahan saldo per address (ledger YTE / token, reward mining, deposit USD).
+// Asset hanya diisi untuk entry ledger token
+type BalanceChange struct {
+	Address string  `db:"address"`
+	Asset   string  `db:"asset"`
+	Amount  float64 `db:"amount"`
+}
+
+// WalletDiff - yte_balance di user_wallets vs hasil replay ledger + reward mining
+type WalletDiff struct {
+	Address    string  `json:"address"`
+	Expected   float64 `json:"expected"`
+	Actual     float64 `json:"actual"`
+	Difference float64 `json:"difference"`
+	Missing    bool    `json:"missing,omitempty"`
+}
+
+// AssetBalanceDiff - asset_balances vs hasil replay entry ledger token (token_debit / token_credit)
+type AssetBalanceDiff struct {
+	Address    string  `json:"address"`
+	Asset      string  `json:"asset"`
+	Expected   float64 `json:"expected"`
+	Actual     float64 `json:"actual"`
+	Difference float64 `json:"difference"`
+	Missing    bool    `json:"missing,omitempty"`
+}
+
+// USDState - kolom user_balances yang bisa diturunkan dari history (locked_balance tidak)
+type USDState struct {
+	USDBalance     float64 `json:"usd_balance"`
+	TotalDeposited float64 `json:"total_deposited"`
+	TotalWithdrawn float64 `json:"total_withdrawn"`
+	TotalTraded    float64 `json:"total_traded"`
+}
+
+// USDBalanceDiff - user_balances vs hasil replay deposit + BUY/SELL. Difference hanya usd_balance
+type USDBalanceDiff struct {
+	Address    string   `json:"address"`
+	Expected   USDState `json:"expected"`
+	Actual     USDState `json:"actual"`
+	Difference float64  `json:"difference"`
+	Missing    bool     `json:"missing,omitempty"`
+}
+
+// MarketDiff - market_engine pair native vs hasil replay NextState dari volume setiap block
+type MarketDiff struct {
+	Expected MarketEngine `json:"expected"`
+	Actual   MarketEngine `json:"actual"`
+	Match    bool         `json:"match"`
+}
+
+// TickMismatch - market_ticks yang harganya berbeda dengan harga replay di block yang sama
+type TickMismatch struct {
+	BlockNumber int64   `json:"block_number"`
+	Recorded    float64 `json:"recorded"`
+	Replayed    float64 `json:"replayed"`
+}
+
+// LedgerRebuildReport - hasil satu kali replay dari genesis. Applied = tabel materialized sudah ditulis ulang.
+// UncheckedPairs - pair selain pair native, market_engine / market_ticks-nya tidak punya history untuk di-replay.
+// UncheckedWallets - address yang yte_balance-nya tidak dibandingkan (MINER_ACCOUNT), USD-nya tetap dicek
+type LedgerRebuildReport struct {
+	StartedAt              int64              `json:"started_at"`
+	FinishedAt             int64              `json:"finished_at"`
+	Tolerance              float64            `json:"tolerance"`
+	Blocks                 int64              `json:"blocks"`
+	Transactions           int64              `json:"transactions"`
+	LedgerEntries          int64              `json:"ledger_entries"`
+	TokenLedgerEntries     int64              `json:"token_ledger_entries"`
+	MiningRewards          int64              `json:"mining_rewards"`
+	Deposits               int64              `json:"deposits"`
+	WalletsChecked         int                `json:"wallets_checked"`
+	BalancesChecked        int                `json:"balances_checked"`
+	AssetBalancesChecked   int                `json:"asset_balances_checked"`
+	TicksChecked           int64              `json:"ticks_checked"`
+	WalletDiffs            []WalletDiff       `json:"wallet_diffs"`
+	USDBalanceDiffs        []USDBalanceDiff   `json:"usd_balance_diffs"`
+	AssetBalanceDiffs      []AssetBalanceDiff `json:"asset_balance_diffs"`
+	Market                 MarketDiff         `json:"market"`
+	TickMismatches         int64              `json:"tick_mismatches"`
+	FirstTickDiff          *TickMismatch      `json:"first_tick_mismatch,omitempty"`
+	UncheckedPairs         []string           `json:"unchecked_pairs"`
+	UncheckedWallets       []string           `json:"unchecked_wallets"`
+	Consistent             bool               `json:"consistent"`
+	Applied                bool               `json:"applied"`
+	WalletsRewritten       int                `json:"wallets_rewritten"`
+	BalancesRewritten      int                `json:"balances_rewritten"`
+	AssetBalancesRewritten int                `json:"asset_balances_rewritten"`
+	MarketRewritten        bool               `json:"market_rewritten"`
+}
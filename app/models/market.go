@@ -2,6 +2,7 @@ package models
 
 type MarketEngine struct {
 	ID        int     `db:"id" json:"id"`
+	Pair      string  `db:"pair" json:"pair"`
 	Price     float64 `db:"price" json:"price"`
 	Liquidity float64 `db:"liquidity" json:"liquidity"`
 	LastBlock int64   `db:"last_block" json:"last_block"`
@@ -10,6 +11,7 @@ type MarketEngine struct {
 
 type MarketTick struct {
 	ID         int     `db:"id" json:"id"`
+	Pair       string  `db:"pair" json:"pair"`
 	BlockID    int64   `db:"block_id" json:"block_id"`
 	Price      float64 `db:"price" json:"price"`
 	BuyVolume  float64 `db:"buy_volume" json:"buy_volume"`
@@ -17,3 +19,34 @@ type MarketTick struct {
 	TxCount    int     `db:"tx_count" json:"tx_count"`
 	CreatedAt  int64   `db:"created_at" json:"created_at"`
 }
+
+// Trade - BUY/SELL yang terkonfirmasi di block, harga = harga tick block tersebut
+type Trade struct {
+	TxID        int64   `db:"tx_id" json:"tx_id"`
+	BlockNumber int     `db:"block_number" json:"block_number"`
+	Side        string  `db:"side" json:"side"`
+	Address     string  `db:"address" json:"address"`
+	Amount      float64 `db:"amount" json:"amount"`
+	Price       float64 `db:"price" json:"price"`
+	Timestamp   int64   `db:"timestamp" json:"timestamp"`
+}
+
+// TickerStats - agregat market_ticks dalam satu window (mis. 24 jam terakhir)
+type TickerStats struct {
+	OpenPrice  float64 `db:"open_price"`
+	HighPrice  float64 `db:"high_price"`
+	LowPrice   float64 `db:"low_price"`
+	LastPrice  float64 `db:"last_price"`
+	BuyVolume  float64 `db:"buy_volume"`
+	SellVolume float64 `db:"sell_volume"`
+	TxCount    int64   `db:"tx_count"`
+	TickCount  int64   `db:"tick_count"`
+}
+
+// DepthLevel - total BUY/SELL pending per tier ukuran order (tier = floor(log10(amount)))
+type DepthLevel struct {
+	Side   string  `db:"side"`
+	Tier   int     `db:"tier"`
+	Orders int64   `db:"orders"`
+	Amount float64 `db:"amount"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+const (
+	OutboxStatusPending  = "PENDING"
+	OutboxStatusInFlight = "IN_FLIGHT"
+	OutboxStatusSent     = "SENT"
+)
+
+// OutboxEvent - pesan RabbitMQ yang disimpan bersama perubahan state, dipublish oleh outbox relay
+type OutboxEvent struct {
+	ID            int64      `db:"id" json:"id"`
+	AggregateType string     `db:"aggregate_type" json:"aggregate_type"`
+	AggregateID   int64      `db:"aggregate_id" json:"aggregate_id"`
+	Exchange      string     `db:"exchange_name" json:"exchange"`
+	RoutingKey    string     `db:"routing_key" json:"routing_key"`
+	Payload       string     `db:"payload" json:"payload"`
+	Status        string     `db:"status" json:"status"`
+	Attempts      int        `db:"attempts" json:"attempts"`
+	LastError     *string    `db:"last_error" json:"last_error,omitempty"`
+	NextAttemptAt time.Time  `db:"next_attempt_at" json:"next_attempt_at"`
+	CreatedAt     time.Time  `db:"created_at" json:"created_at"`
+	SentAt        *time.Time `db:"sent_at" json:"sent_at,omitempty"`
+}
+
+// OutboxStats - ringkasan event yang belum SENT (pending termasuk in_flight),
+// stuck = sudah gagal dipublish atau lebih tua dari batas stuck
+type OutboxStats struct {
+	Pending         int64      `db:"pending" json:"pending"`
+	InFlight        int64      `db:"in_flight" json:"in_flight"`
+	Stuck           int64      `db:"stuck" json:"stuck"`
+	MaxAttempts     int        `db:"max_attempts" json:"max_attempts"`
+	OldestPendingAt *time.Time `db:"oldest_pending_at" json:"oldest_pending_at,omitempty"`
+}
@@ -1,9 +1,13 @@
 package models
 
+import "time"
+
 type QueueDef struct {
 	Name       string
 	Durable    bool
 	AutoDelete bool
+	// Args - x-dead-letter-exchange, x-message-ttl, dll
+	Args map[string]interface{}
 }
 
 type ExchangeDef struct {
@@ -17,3 +21,33 @@ type BindDef struct {
 	Exchange   string
 	RoutingKey string
 }
+
+// PolicyDef - policy broker (management API), bisa diubah untuk queue yang sudah ada tanpa redeclare.
+// Pattern regex nama queue, Definition mis. dead-letter-exchange / dead-letter-routing-key
+type PolicyDef struct {
+	Name       string
+	Pattern    string
+	ApplyTo    string
+	Priority   int
+	Definition map[string]interface{}
+}
+
+// DeadLetterQueueStats - jumlah pesan di parking queue <queue>.dead
+type DeadLetterQueueStats struct {
+	Queue           string `json:"queue"`
+	DeadLetterQueue string `json:"dead_letter_queue"`
+	Messages        int    `json:"messages"`
+}
+
+// DeadLetter - pesan yang diparkir setelah gagal diproses
+type DeadLetter struct {
+	Queue      string    `json:"queue"`
+	MessageID  string    `json:"message_id,omitempty"`
+	Exchange   string    `json:"exchange"`
+	RoutingKey string    `json:"routing_key"`
+	Attempts   int       `json:"attempts"`
+	LastError  string    `json:"last_error,omitempty"`
+	Reason     string    `json:"reason,omitempty"`
+	ParkedAt   time.Time `json:"parked_at"`
+	Body       string    `json:"body"`
+}
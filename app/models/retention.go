@@ -0,0 +1,38 @@
+package models
+
+// TickArchive - ringkasan market_ticks satu bucket setelah tick aslinya dihapus
+type TickArchive struct {
+	ID           int64   `db:"id" json:"id"`
+	Pair         string  `db:"pair" json:"pair"`
+	IntervalType string  `db:"interval_type" json:"interval_type"`
+	BucketStart  int64   `db:"bucket_start" json:"bucket_start"`
+	OpenPrice    float64 `db:"open_price" json:"open_price"`
+	HighPrice    float64 `db:"high_price" json:"high_price"`
+	LowPrice     float64 `db:"low_price" json:"low_price"`
+	ClosePrice   float64 `db:"close_price" json:"close_price"`
+	BuyVolume    float64 `db:"buy_volume" json:"buy_volume"`
+	SellVolume   float64 `db:"sell_volume" json:"sell_volume"`
+	TxCount      int     `db:"tx_count" json:"tx_count"`
+	TickCount    int     `db:"tick_count" json:"tick_count"`
+	FirstBlockID int64   `db:"first_block_id" json:"first_block_id"`
+	LastBlockID  int64   `db:"last_block_id" json:"last_block_id"`
+}
+
+// TableStorage - ukuran tabel dari information_schema (table_rows adalah estimasi InnoDB)
+type TableStorage struct {
+	TableName  string `db:"table_name" json:"table_name"`
+	TableRows  int64  `db:"table_rows" json:"table_rows"`
+	DataBytes  int64  `db:"data_bytes" json:"data_bytes"`
+	IndexBytes int64  `db:"index_bytes" json:"index_bytes"`
+	TotalBytes int64  `db:"total_bytes" json:"total_bytes"`
+}
+
+// RetentionReport - hasil satu kali retention job
+type RetentionReport struct {
+	StartedAt       int64            `json:"started_at"`
+	FinishedAt      int64            `json:"finished_at"`
+	CandlesDeleted  map[string]int64 `json:"candles_deleted"`
+	TicksArchived   int64            `json:"ticks_archived"`
+	ArchivesWritten int64            `json:"archives_written"`
+	Error           string           `json:"error,omitempty"`
+}
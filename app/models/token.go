@@ -0,0 +1,38 @@
+package models
+
+// tipe transaksi token, fee tetap dibayar dalam YTE
+const (
+	TxTypeCreateToken   = "CREATE_TOKEN"
+	TxTypeTokenTransfer = "TOKEN_TRANSFER"
+)
+
+const (
+	TokenStatusPending = "PENDING"
+	TokenStatusActive  = "ACTIVE"
+	TokenStatusFailed  = "FAILED"
+)
+
+// MaxTokenDecimals mengikuti presisi kolom DECIMAL(30, 8)
+const MaxTokenDecimals = 8
+
+type Token struct {
+	Symbol         string  `db:"symbol" json:"symbol"`
+	Name           string  `db:"name" json:"name"`
+	Decimals       int     `db:"decimals" json:"decimals"`
+	TotalSupply    float64 `db:"total_supply" json:"total_supply"`
+	CreatorAddress string  `db:"creator_address" json:"creator_address"`
+	CreateTxID     int64   `db:"create_tx_id" json:"create_tx_id"`
+	BlockID        *int64  `db:"block_id" json:"block_id,omitempty"`
+	Status         string  `db:"status" json:"status"`
+	CreatedAt      string  `db:"created_at" json:"created_at"`
+}
+
+type TokenHolder struct {
+	Address string  `db:"user_address" json:"address"`
+	Balance float64 `db:"balance" json:"balance"`
+}
+
+// IsTokenTransaction - transaksi yang memindahkan token, bukan YTE
+func IsTokenTransaction(txType string) bool {
+	return txType == TxTypeCreateToken || txType == TxTypeTokenTransfer
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+const (
+	HaltSourceAuto   = "AUTO"
+	HaltSourceManual = "MANUAL"
+)
+
+type TradingHalt struct {
+	ID                 int64      `db:"id" json:"id"`
+	Pair               string     `db:"pair" json:"pair"`
+	Source             string     `db:"source" json:"source"`
+	Reason             string     `db:"reason" json:"reason"`
+	PriceChangePercent *float64   `db:"price_change_percent" json:"price_change_percent,omitempty"`
+	ThresholdPercent   *float64   `db:"threshold_percent" json:"threshold_percent,omitempty"`
+	BlockNumber        *int64     `db:"block_number" json:"block_number,omitempty"`
+	HaltedBy           *int       `db:"halted_by" json:"halted_by,omitempty"`
+	HaltedAt           time.Time  `db:"halted_at" json:"halted_at"`
+	ResumeAt           *time.Time `db:"resume_at" json:"resume_at,omitempty"`
+	ResumedAt          *time.Time `db:"resumed_at" json:"resumed_at,omitempty"`
+	ResumedBy          *int       `db:"resumed_by" json:"resumed_by,omitempty"`
+}
+
+// TradingStatus - state trading saat ini, disimpan di redis dan dibroadcast ke client
+type TradingStatus struct {
+	Halted   bool       `json:"halted"`
+	HaltID   int64      `json:"halt_id,omitempty"`
+	Source   string     `json:"source,omitempty"`
+	Reason   string     `json:"reason,omitempty"`
+	HaltedAt *time.Time `json:"halted_at,omitempty"`
+	ResumeAt *time.Time `json:"resume_at,omitempty"` // nil = sampai di-resume manual
+}
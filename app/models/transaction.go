@@ -8,7 +8,8 @@ type Transaction struct {
 	ToAddress   string  `db:"to_address" json:"to_address"`
 	Amount      float64 `db:"amount" json:"amount"`
 	Fee         float64 `db:"fee" json:"fee"`
-	Type        string  `db:"type" json:"type"` // "TRANSFER", "BUY", "SELL"
+	Type        string  `db:"type" json:"type"` // "TRANSFER", "BUY", "SELL", "CREATE_TOKEN", "TOKEN_TRANSFER"
+	TokenSymbol string  `db:"token_symbol" json:"token_symbol,omitempty"`
 	Signature   string  `db:"signature" json:"signature"`
 	Status      string  `db:"status" json:"status"`
 	CreatedAt   string  `db:"created_at" json:"created_at"`
@@ -1,8 +1,83 @@
 package port
 
-import "context"
+import (
+	"context"
+	"errors"
 
-type MessageBroker interface {
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+)
+
+// PubSub - publish/subscribe per channel tanpa antrian (redis)
+type PubSub interface {
 	Publish(ctx context.Context, channel string, payload []byte) error
 	Subscribe(ctx context.Context, channel string, callback func([]byte) error) error
 }
+
+// Message - satu pesan untuk PublishBatch. MessageID kosong diisi broker, harus unik di dalam satu batch
+type Message struct {
+	Exchange   string
+	RoutingKey string
+	MessageID  string
+	Body       []byte
+}
+
+// Delivery - pesan yang diterima consumer, lepas dari tipe library broker
+type Delivery struct {
+	Exchange   string
+	RoutingKey string
+	MessageID  string
+	Headers    map[string]interface{}
+	Body       []byte
+	// Attempts - percobaan sebelumnya yang gagal, 0 = pengiriman pertama
+	Attempts int
+}
+
+// DeliveryHandler - nil = ack. error = retry dengan delay, Permanent(err) = langsung diparkir di <queue>.dead
+type DeliveryHandler func(Delivery) error
+
+// MessageBroker - broker antrian yang dipakai publisher, consumer dan admin dead letter.
+// diimplementasikan oleh rabbitmq.Client dan membroker.MemoryBroker (satu proses, untuk test dan demo lokal)
+type MessageBroker interface {
+	DeclareExchange(e models.ExchangeDef) error
+	DeclareQueue(q models.QueueDef) error
+	Bind(b models.BindDef) error
+	// ApplyPolicy - argumen queue yang bisa diubah tanpa redeclare (dead-letter exchange queue kerja)
+	ApplyPolicy(p models.PolicyDef) error
+
+	// Publish - fire and forget
+	Publish(ctx context.Context, exchange, routingKey string, body []byte) error
+	// PublishWithConfirm - gagal jika broker nack, pesan tidak ter-route ke queue mana pun atau timeout
+	PublishWithConfirm(ctx context.Context, exchange, routingKey string, body []byte) error
+	// PublishBatch - results[i] nil = pesan ke-i sudah di-route, error kedua = kegagalan pertama
+	PublishBatch(ctx context.Context, msgs []Message) ([]error, error)
+
+	// ConsumeWithRetry - ack, retry dengan delay dan parking di <queue>.dead diurus broker
+	ConsumeWithRetry(ctx context.Context, queue string, workers int, handler DeliveryHandler) error
+
+	DeadLetterStats(queues []string) ([]models.DeadLetterQueueStats, error)
+	PeekDeadLetters(queue string, limit int) ([]models.DeadLetter, error)
+	ReplayDeadLetters(ctx context.Context, queue string, limit int) (int, error)
+	PurgeDeadLetters(queue string) (int, error)
+
+	Close()
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent - pesan tidak akan pernah berhasil (payload rusak, data tidak valid), retry dilewati
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
@@ -19,6 +19,20 @@ func (p *PublisherWS) Publish(eventType entity.MessageType, message interface{})
 	p.hub.BroadCast(eventType, message)
 }
 
+// PublishLocal - hanya ke client yang terhubung ke instance ini
+func (p *PublisherWS) PublishLocal(eventType entity.MessageType, message any) {
+	p.hub.BroadCastLocal(eventType, message)
+}
+
 func (p *PublisherWS) PublishToAddress(address string, eventType entity.MessageType, message any) {
 	p.hub.SendToAddress(address, eventType, message)
 }
+
+// PublishToAddressWithAck - notifikasi penting, dikirim ulang sampai client mengirim ack
+func (p *PublisherWS) PublishToAddressWithAck(address string, eventType entity.MessageType, message any) {
+	p.hub.SendToAddressWithAck(address, eventType, message)
+}
+
+func (p *PublisherWS) RegisterSnapshot(eventType entity.MessageType, fn ws.SnapshotFunc) {
+	p.hub.RegisterSnapshot(eventType, fn)
+}
@@ -13,6 +13,7 @@ func getQueueDefinitions() []models.QueueDef {
 		{Name: rabbitmq.BlockGenerationQueue, Durable: true, AutoDelete: false},
 		{Name: rabbitmq.BlockMinedQueue, Durable: true, AutoDelete: false},
 		{Name: rabbitmq.MarketPricingQueue, Durable: true, AutoDelete: false},
+		{Name: rabbitmq.CandleAggregationQueue, Durable: true, AutoDelete: false},
 		{Name: rabbitmq.MarketVolumeQueue, Durable: true, AutoDelete: false},
 		{Name: rabbitmq.LedgerEntriesQueue, Durable: true, AutoDelete: false},
 		{Name: rabbitmq.RewardCalculationQueue, Durable: true, AutoDelete: false},
@@ -31,6 +32,7 @@ func getExchangeDefinitions() []models.ExchangeDef {
 		{Name: rabbitmq.MarketExchange, Kind: "topic", Durable: true},
 		{Name: rabbitmq.LedgerExchange, Kind: "topic", Durable: true},
 		{Name: rabbitmq.RewardExchange, Kind: "topic", Durable: true},
+		{Name: rabbitmq.DeadLetterExchange, Kind: "direct", Durable: true},
 	}
 }
 
@@ -42,6 +44,7 @@ func getBindingDefinitions() []models.BindDef {
 		{Queue: rabbitmq.BlockGenerationQueue, Exchange: rabbitmq.BlockExchange, RoutingKey: rabbitmq.BlockGenerateKey},
 		{Queue: rabbitmq.BlockMinedQueue, Exchange: rabbitmq.BlockExchange, RoutingKey: rabbitmq.BlockMinedKey},
 		{Queue: rabbitmq.MarketPricingQueue, Exchange: rabbitmq.MarketExchange, RoutingKey: rabbitmq.MarketPricingKey},
+		{Queue: rabbitmq.CandleAggregationQueue, Exchange: rabbitmq.MarketExchange, RoutingKey: rabbitmq.MarketPricingKey},
 		{Queue: rabbitmq.MarketVolumeQueue, Exchange: rabbitmq.MarketExchange, RoutingKey: rabbitmq.MarketVolumeUpdateKey},
 		{Queue: rabbitmq.LedgerEntriesQueue, Exchange: rabbitmq.LedgerExchange, RoutingKey: rabbitmq.LedgerBatchKey},
 		{Queue: rabbitmq.RewardCalculationQueue, Exchange: rabbitmq.RewardExchange, RoutingKey: rabbitmq.RewardCalculationKey},
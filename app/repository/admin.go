@@ -295,6 +295,21 @@ func (r *adminRepository) GetDashboardStats(ctx context.Context) (*models.AdminD
 	// Total volume from transactions
 	_ = r.db.QueryRow("SELECT COALESCE(SUM(amount), 0) FROM transactions").Scan(&stats.TotalVolume)
 
+	// Trading halt (circuit breaker / manual)
+	var openHalts int64
+	_ = r.db.QueryRow(`
+		SELECT COUNT(*)
+		FROM trading_halts
+		WHERE resumed_at IS NULL AND (resume_at IS NULL OR resume_at > NOW())
+	`).Scan(&openHalts)
+	stats.TradingHalted = openHalts > 0
+
+	_ = r.db.QueryRow(`
+		SELECT COUNT(*)
+		FROM trading_halts
+		WHERE halted_at >= DATE_SUB(NOW(), INTERVAL 1 DAY)
+	`).Scan(&stats.HaltsLast24h)
+
 	return stats, nil
 }
 
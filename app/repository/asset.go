@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+)
+
+type AssetRepository interface {
+	GetAssets() ([]models.Asset, error)
+	GetAsset(symbol string) (models.Asset, error)
+	CreateAsset(asset models.Asset) error
+	CreateAssetWithTx(tx *sqlx.Tx, asset models.Asset) error
+	GetPairs(status string) ([]models.TradingPair, error)
+	GetPair(symbol string) (models.TradingPair, error)
+	GetBalances(address string) ([]models.AssetBalance, error)
+	GetBalance(address, symbol string) (models.AssetBalance, error)
+	GetBalanceForUpdateWithTx(tx *sqlx.Tx, address, symbol string) (models.AssetBalance, error)
+	AdjustBalanceWithTx(tx *sqlx.Tx, address, symbol string, delta float64) error
+}
+
+type assetRepository struct {
+	db *sqlx.DB
+}
+
+func NewAssetRepository(db *sqlx.DB) AssetRepository {
+	return &assetRepository{db: db}
+}
+
+// GetAssets implements [AssetRepository].
+func (r *assetRepository) GetAssets() ([]models.Asset, error) {
+	assets := []models.Asset{}
+	err := r.db.Select(&assets, `SELECT symbol, name, decimals, is_native, status, created_at FROM assets ORDER BY is_native DESC, symbol ASC`)
+	return assets, err
+}
+
+// GetAsset implements [AssetRepository].
+func (r *assetRepository) GetAsset(symbol string) (models.Asset, error) {
+	var asset models.Asset
+	err := r.db.Get(&asset, `SELECT symbol, name, decimals, is_native, status, created_at FROM assets WHERE symbol = ?`, symbol)
+
+	if err == sql.ErrNoRows {
+		return models.Asset{}, entity.ErrAssetNotFound
+	}
+
+	return asset, err
+}
+
+// CreateAsset implements [AssetRepository].
+func (r *assetRepository) CreateAsset(asset models.Asset) error {
+	_, err := r.db.Exec(`INSERT INTO assets (symbol, name, decimals, is_native) VALUES (?, ?, ?, ?)`,
+		asset.Symbol, asset.Name, asset.Decimals, asset.IsNative)
+	return err
+}
+
+// CreateAssetWithTx implements [AssetRepository].
+func (r *assetRepository) CreateAssetWithTx(tx *sqlx.Tx, asset models.Asset) error {
+	_, err := tx.Exec(`INSERT INTO assets (symbol, name, decimals, is_native) VALUES (?, ?, ?, ?)`,
+		asset.Symbol, asset.Name, asset.Decimals, asset.IsNative)
+	return err
+}
+
+// GetPairs implements [AssetRepository].
+func (r *assetRepository) GetPairs(status string) ([]models.TradingPair, error) {
+	pairs := []models.TradingPair{}
+
+	if status == "" {
+		err := r.db.Select(&pairs, `SELECT symbol, base_asset, quote_asset, status, created_at FROM trading_pairs ORDER BY symbol ASC`)
+		return pairs, err
+	}
+
+	err := r.db.Select(&pairs, `SELECT symbol, base_asset, quote_asset, status, created_at FROM trading_pairs WHERE status = ? ORDER BY symbol ASC`, status)
+	return pairs, err
+}
+
+// GetPair implements [AssetRepository].
+func (r *assetRepository) GetPair(symbol string) (models.TradingPair, error) {
+	var pair models.TradingPair
+	err := r.db.Get(&pair, `SELECT symbol, base_asset, quote_asset, status, created_at FROM trading_pairs WHERE symbol = ?`, symbol)
+
+	if err == sql.ErrNoRows {
+		return models.TradingPair{}, entity.ErrPairNotFound
+	}
+
+	return pair, err
+}
+
+// GetBalances implements [AssetRepository].
+func (r *assetRepository) GetBalances(address string) ([]models.AssetBalance, error) {
+	balances := []models.AssetBalance{}
+	err := r.db.Select(&balances, `SELECT user_address, asset_symbol, balance, updated_at FROM asset_balances WHERE user_address = ? ORDER BY asset_symbol ASC`, address)
+	return balances, err
+}
+
+// GetBalance implements [AssetRepository].
+func (r *assetRepository) GetBalance(address, symbol string) (models.AssetBalance, error) {
+	var balance models.AssetBalance
+	err := r.db.Get(&balance, `SELECT user_address, asset_symbol, balance, updated_at FROM asset_balances WHERE user_address = ? AND asset_symbol = ?`, address, symbol)
+
+	if err == sql.ErrNoRows {
+		return models.AssetBalance{UserAddress: address, AssetSymbol: symbol}, nil
+	}
+
+	return balance, err
+}
+
+// GetBalanceForUpdateWithTx implements [AssetRepository].
+func (r *assetRepository) GetBalanceForUpdateWithTx(tx *sqlx.Tx, address, symbol string) (models.AssetBalance, error) {
+	var balance models.AssetBalance
+	err := tx.Get(&balance, `SELECT user_address, asset_symbol, balance, updated_at FROM asset_balances WHERE user_address = ? AND asset_symbol = ? FOR UPDATE`, address, symbol)
+
+	if err == sql.ErrNoRows {
+		return models.AssetBalance{UserAddress: address, AssetSymbol: symbol}, nil
+	}
+
+	return balance, err
+}
+
+// AdjustBalanceWithTx implements [AssetRepository].
+// tambah / kurangi saldo asset, row dibuat jika belum ada
+func (r *assetRepository) AdjustBalanceWithTx(tx *sqlx.Tx, address, symbol string, delta float64) error {
+	_, err := tx.Exec(`
+		INSERT INTO asset_balances (user_address, asset_symbol, balance) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE balance = balance + VALUES(balance)`,
+		address, symbol, delta)
+	return err
+}
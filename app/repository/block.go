@@ -143,7 +143,7 @@ func (b *blockRepository) GetAllBlocks() ([]models.Block, error) {
 	for i := range blocks {
 		var txs []models.Transaction
 		query := `
-			SELECT t.id, t.from_address, t.to_address, t.amount, t.signature, t.status
+			SELECT t.id, t.from_address, t.to_address, t.amount, t.type, t.token_symbol, t.signature, t.status
 			FROM transactions t
 			INNER JOIN block_transactions bt ON t.id = bt.transaction_id
 			WHERE bt.block_id = ?
@@ -187,7 +187,7 @@ func (b *blockRepository) GetTransactionsByBlockNumber(ctx context.Context, bloc
 	var transcations []models.Transaction
 
 	query := `
-		SELECT t.id, t.from_address, t.to_address, t.amount, t.signature, t.status
+		SELECT t.id, t.from_address, t.to_address, t.amount, t.type, t.token_symbol, t.signature, t.status
 		FROM transactions t
 		INNER JOIN block_transactions bt ON t.id = bt.transaction_id
 		INNER JOIN blocks b ON bt.block_id = b.id
@@ -278,7 +278,7 @@ func (b *blockRepository) GetLatestBlockInfo(ctx context.Context) (models.Block,
 	err := b.db.GetContext(ctx, &block, query)
 
 	queryTransaction := `
-		SELECT t.id, t.from_address, t.to_address, t.amount, t.signature, t.status
+		SELECT t.id, t.from_address, t.to_address, t.amount, t.type, t.token_symbol, t.signature, t.status
 		FROM transactions t
 		INNER JOIN block_transactions bt ON t.id = bt.transaction_id
 		WHERE bt.block_id = ?
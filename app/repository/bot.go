@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+)
+
+type BotRepository interface {
+	BeginTx() (*sqlx.Tx, error)
+	Create(bot models.TradingBot) (int64, error)
+	CreateWithTx(tx *sqlx.Tx, bot models.TradingBot) (int64, error)
+	GetByID(id int64) (models.TradingBot, error)
+	GetAll() ([]models.TradingBot, error)
+	GetByStatus(status string) ([]models.TradingBot, error)
+	UpdateConfig(bot models.TradingBot) error
+	UpdateStatus(id int64, status string) error
+	UpdatePrivateKey(id int64, privateKey string) error
+}
+
+type botRepository struct {
+	db *sqlx.DB
+}
+
+func NewBotRepository(db *sqlx.DB) BotRepository {
+	return &botRepository{db: db}
+}
+
+const botColumns = `id, name, address, private_key, strategy, status, interval_seconds, min_amount, max_amount, lookback, threshold_percent, spread_percent, target_inventory, created_at, updated_at`
+
+// BeginTx implements [BotRepository].
+func (r *botRepository) BeginTx() (*sqlx.Tx, error) {
+	return r.db.Beginx()
+}
+
+const insertBotQuery = `
+	INSERT INTO trading_bots (name, address, private_key, strategy, status, interval_seconds, min_amount, max_amount, lookback, threshold_percent, spread_percent, target_inventory)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+// Create implements [BotRepository].
+func (r *botRepository) Create(bot models.TradingBot) (int64, error) {
+	res, err := r.db.Exec(insertBotQuery, insertBotArgs(bot)...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+// CreateWithTx implements [BotRepository].
+func (r *botRepository) CreateWithTx(tx *sqlx.Tx, bot models.TradingBot) (int64, error) {
+	res, err := tx.Exec(insertBotQuery, insertBotArgs(bot)...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+func insertBotArgs(bot models.TradingBot) []any {
+	return []any{
+		bot.Name,
+		bot.Address,
+		bot.PrivateKey,
+		bot.Strategy,
+		models.BotStatusStopped,
+		bot.IntervalSeconds,
+		bot.MinAmount,
+		bot.MaxAmount,
+		bot.Lookback,
+		bot.ThresholdPercent,
+		bot.SpreadPercent,
+		bot.TargetInventory,
+	}
+}
+
+// GetByID implements [BotRepository].
+func (r *botRepository) GetByID(id int64) (models.TradingBot, error) {
+	var bot models.TradingBot
+	err := r.db.Get(&bot, `SELECT `+botColumns+` FROM trading_bots WHERE id = ?`, id)
+
+	if err == sql.ErrNoRows {
+		return models.TradingBot{}, entity.ErrBotNotFound
+	}
+
+	return bot, err
+}
+
+// GetAll implements [BotRepository].
+func (r *botRepository) GetAll() ([]models.TradingBot, error) {
+	bots := []models.TradingBot{}
+	err := r.db.Select(&bots, `SELECT `+botColumns+` FROM trading_bots ORDER BY id ASC`)
+	return bots, err
+}
+
+// GetByStatus implements [BotRepository].
+func (r *botRepository) GetByStatus(status string) ([]models.TradingBot, error) {
+	var bots []models.TradingBot
+	err := r.db.Select(&bots, `SELECT `+botColumns+` FROM trading_bots WHERE status = ? ORDER BY id ASC`, status)
+	return bots, err
+}
+
+// UpdateConfig implements [BotRepository].
+func (r *botRepository) UpdateConfig(bot models.TradingBot) error {
+	_, err := r.db.Exec(`
+		UPDATE trading_bots
+		SET strategy = ?, interval_seconds = ?, min_amount = ?, max_amount = ?, lookback = ?, threshold_percent = ?, spread_percent = ?, target_inventory = ?
+		WHERE id = ?`,
+		bot.Strategy,
+		bot.IntervalSeconds,
+		bot.MinAmount,
+		bot.MaxAmount,
+		bot.Lookback,
+		bot.ThresholdPercent,
+		bot.SpreadPercent,
+		bot.TargetInventory,
+		bot.ID,
+	)
+
+	return err
+}
+
+// UpdateStatus implements [BotRepository].
+func (r *botRepository) UpdateStatus(id int64, status string) error {
+	_, err := r.db.Exec(`UPDATE trading_bots SET status = ? WHERE id = ?`, status, id)
+	return err
+}
+
+// UpdatePrivateKey implements [BotRepository].
+func (r *botRepository) UpdatePrivateKey(id int64, privateKey string) error {
+	_, err := r.db.Exec(`UPDATE trading_bots SET private_key = ? WHERE id = ?`, privateKey, id)
+	return err
+}
@@ -10,15 +10,20 @@ import (
 
 type CandlesRepository interface {
 	InsertCandleWithTx(tx *sqlx.Tx, candle models.Candle) (int64, error)
-	GetCandleByInterval(intervalType string, limit int) ([]models.Candle, error)
-	GetCandleByIntervalAndTime(intervalType string, startTime int64, limit int) ([]models.Candle, error)
-	GetCandleByIntervalAndStartTime(intervalType string, startTime int64) (models.Candle, error)
-	GetLatestCandleByInterval(intervalType string) (models.Candle, error)
+	GetCandleByInterval(pair, intervalType string, limit int) ([]models.Candle, error)
+	GetCandleByIntervalAndTime(pair, intervalType string, startTime int64, limit int) ([]models.Candle, error)
+	GetCandleByIntervalAndStartTime(pair, intervalType string, startTime int64) (models.Candle, error)
+	GetLatestCandleByInterval(pair, intervalType string) (models.Candle, error)
 	UpdateCandleWithTx(tx *sqlx.Tx, candle models.Candle) error
 	UpsertCandleWithTx(tx *sqlx.Tx, candle models.Candle) error
-	DeleteOldCandlesWithTx(tx *sqlx.Tx, intervalType string, beforeTime int64) error
-	GetTicksRange(startTime, endTime int64) ([]models.MarketTick, error)
+	DeleteOldCandlesWithTx(tx *sqlx.Tx, pair, intervalType string, beforeTime int64) (int64, error)
+	DeleteCandlesByPair(pair string) (int64, error)
+	GetTicksRange(pair string, startTime, endTime int64) ([]models.MarketTick, error)
 	UpsertCandleOnDuplicateWithTx(tx *sqlx.Tx, candle models.Candle) (int64, error)
+	ApplyTickWithTx(tx *sqlx.Tx, candle models.Candle) (int64, error)
+	GetCandlesRangeWithTx(tx *sqlx.Tx, pair, intervalType string, startTime, endTime int64) ([]models.Candle, error)
+	GetCandleBeforeWithTx(tx *sqlx.Tx, pair, intervalType string, startTime int64) (models.Candle, error)
+	GetCandleBefore(pair, intervalType string, startTime int64) (models.Candle, error)
 	CandleBeginTx() (*sqlx.Tx, error)
 }
 
@@ -30,26 +35,42 @@ func NewCandleRepository(db *sqlx.DB) CandlesRepository {
 	return &candleRepository{db: db}
 }
 
+// DeleteCandlesByPair implements [CandlesRepository].
+func (c *candleRepository) DeleteCandlesByPair(pair string) (int64, error) {
+	res, err := c.db.Exec(`DELETE FROM candles WHERE pair = ?`, pair)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
 // DeleteOldCandlesWithTx implements [CandlesRepository].
-func (c *candleRepository) DeleteOldCandlesWithTx(tx *sqlx.Tx, intervalType string, beforeTime int64) error {
-	_, err := tx.Exec(
-		`DELETE FROM candles WHERE interval_type = ? AND start_time < ?`,
+func (c *candleRepository) DeleteOldCandlesWithTx(tx *sqlx.Tx, pair, intervalType string, beforeTime int64) (int64, error) {
+	res, err := tx.Exec(
+		`DELETE FROM candles WHERE pair = ? AND interval_type = ? AND start_time < ?`,
+		pair,
 		intervalType,
 		beforeTime,
 	)
 
-	return err
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
 }
 
 // GetCandleByInterval implements [CandlesRepository].
-func (c *candleRepository) GetCandleByInterval(intervalType string, limit int) ([]models.Candle, error) {
+func (c *candleRepository) GetCandleByInterval(pair, intervalType string, limit int) ([]models.Candle, error) {
 	var candles []models.Candle
 
 	err := c.db.Select(&candles,
-		`SELECT id, interval_type, start_time, open_price, high_price, low_price, close_price, volume FROM candles 
-		WHERE interval_type = ? 
+		`SELECT id, pair, interval_type, start_time, open_price, high_price, low_price, close_price, volume, buy_volume, sell_volume, trade_count FROM candles 
+		WHERE pair = ? AND interval_type = ? 
 		ORDER BY start_time DESC 
 		LIMIT ?`,
+		pair,
 		intervalType,
 		limit,
 	)
@@ -66,12 +87,13 @@ func (c *candleRepository) GetCandleByInterval(intervalType string, limit int) (
 }
 
 // GetCandleByIntervalAndStartTime implements [CandlesRepository].
-func (c *candleRepository) GetCandleByIntervalAndStartTime(intervalType string, startTime int64) (models.Candle, error) {
+func (c *candleRepository) GetCandleByIntervalAndStartTime(pair, intervalType string, startTime int64) (models.Candle, error) {
 	var candle models.Candle
 
 	err := c.db.Get(&candle,
-		`SELECT id, interval_type, start_time, open_price, high_price, low_price, close_price, volume FROM candles 
-		WHERE interval_type = ? AND start_time = ?`,
+		`SELECT id, pair, interval_type, start_time, open_price, high_price, low_price, close_price, volume, buy_volume, sell_volume, trade_count FROM candles 
+		WHERE pair = ? AND interval_type = ? AND start_time = ?`,
+		pair,
 		intervalType,
 		startTime,
 	)
@@ -80,14 +102,15 @@ func (c *candleRepository) GetCandleByIntervalAndStartTime(intervalType string,
 }
 
 // GetCandleByIntervalAndTime implements [CandlesRepository].
-func (c *candleRepository) GetCandleByIntervalAndTime(intervalType string, startTime int64, limit int) ([]models.Candle, error) {
+func (c *candleRepository) GetCandleByIntervalAndTime(pair, intervalType string, startTime int64, limit int) ([]models.Candle, error) {
 	var candles []models.Candle
 
 	err := c.db.Select(&candles,
-		`SELECT id, interval_type, start_time, open_price, high_price, low_price, close_price, volume FROM candles
-		WHERE interval_type = ? AND start_time >= ?
+		`SELECT id, pair, interval_type, start_time, open_price, high_price, low_price, close_price, volume, buy_volume, sell_volume, trade_count FROM candles
+		WHERE pair = ? AND interval_type = ? AND start_time >= ?
 		ORDER BY start_time DESC
 		LIMIT ?`,
+		pair,
 		intervalType,
 		startTime,
 		limit,
@@ -107,7 +130,8 @@ func (c *candleRepository) GetCandleByIntervalAndTime(intervalType string, start
 // InsertCandleWithTx implements [CandlesRepository].
 func (c *candleRepository) InsertCandleWithTx(tx *sqlx.Tx, candle models.Candle) (int64, error) {
 	res, err := tx.Exec(
-		`INSERT INTO candles (interval_type, start_time, open_price, high_price, low_price, close_price, volume) VALUES (?,?,?,?,?,?,?)`,
+		`INSERT INTO candles (pair, interval_type, start_time, open_price, high_price, low_price, close_price, volume, buy_volume, sell_volume, trade_count) VALUES (?,?,?,?,?,?,?,?,?,?,?)`,
+		candlePair(candle),
 		candle.IntervalType,
 		candle.StartTime,
 		candle.OpenPrice,
@@ -115,6 +139,9 @@ func (c *candleRepository) InsertCandleWithTx(tx *sqlx.Tx, candle models.Candle)
 		candle.LowPrice,
 		candle.ClosePrice,
 		candle.Volume,
+		candle.BuyVolume,
+		candle.SellVolume,
+		candle.TradeCount,
 	)
 
 	if err != nil {
@@ -132,13 +159,17 @@ func (c *candleRepository) InsertCandleWithTx(tx *sqlx.Tx, candle models.Candle)
 func (c *candleRepository) UpdateCandleWithTx(tx *sqlx.Tx, candle models.Candle) error {
 	_, err := tx.Exec(
 		`UPDATE candles
-		SET open_price = ?, high_price = ?, low_price = ?, close_price = ?, volume = ?
-		WHERE interval_type = ? AND start_time = ?`,
+		SET open_price = ?, high_price = ?, low_price = ?, close_price = ?, volume = ?, buy_volume = ?, sell_volume = ?, trade_count = ?
+		WHERE pair = ? AND interval_type = ? AND start_time = ?`,
 		candle.OpenPrice,
 		candle.HighPrice,
 		candle.LowPrice,
 		candle.ClosePrice,
 		candle.Volume,
+		candle.BuyVolume,
+		candle.SellVolume,
+		candle.TradeCount,
+		candlePair(candle),
 		candle.IntervalType,
 		candle.StartTime,
 	)
@@ -151,7 +182,8 @@ func (c *candleRepository) UpsertCandleWithTx(tx *sqlx.Tx, candle models.Candle)
 	var exists bool
 
 	err := tx.Get(&exists,
-		`SELECT COUNT(*) > 0 FROM candles WHERE interval_type = ? AND start_time = ?`,
+		`SELECT COUNT(*) > 0 FROM candles WHERE pair = ? AND interval_type = ? AND start_time = ?`,
+		candlePair(candle),
 		candle.IntervalType,
 		candle.StartTime,
 	)
@@ -170,13 +202,18 @@ func (c *candleRepository) UpsertCandleWithTx(tx *sqlx.Tx, candle models.Candle)
 
 func (c *candleRepository) UpsertCandleOnDuplicateWithTx(tx *sqlx.Tx, candle models.Candle) (int64, error) {
 	res, err := tx.Exec(
-		`INSERT INTO candles (interval_type, start_time, open_price, high_price, low_price, close_price, volume) VALUES (?,?,?,?,?,?,?)
+		`INSERT INTO candles (pair, interval_type, start_time, open_price, high_price, low_price, close_price, volume, buy_volume, sell_volume, trade_count, last_block_id) VALUES (?,?,?,?,?,?,?,?,?,?,?,?)
 		ON DUPLICATE KEY UPDATE 
 			open_price = VALUES(open_price),
 			high_price = VALUES(high_price),
 			low_price = VALUES(low_price),
 			close_price = VALUES(close_price),
-			volume = VALUES(volume)`,
+			volume = VALUES(volume),
+			buy_volume = VALUES(buy_volume),
+			sell_volume = VALUES(sell_volume),
+			trade_count = VALUES(trade_count),
+			last_block_id = GREATEST(last_block_id, VALUES(last_block_id))`,
+		candlePair(candle),
 		candle.IntervalType,
 		candle.StartTime,
 		candle.OpenPrice,
@@ -184,6 +221,10 @@ func (c *candleRepository) UpsertCandleOnDuplicateWithTx(tx *sqlx.Tx, candle mod
 		candle.LowPrice,
 		candle.ClosePrice,
 		candle.Volume,
+		candle.BuyVolume,
+		candle.SellVolume,
+		candle.TradeCount,
+		candle.LastBlockID,
 	)
 
 	var rowsAffected int64 = 0
@@ -194,13 +235,104 @@ func (c *candleRepository) UpsertCandleOnDuplicateWithTx(tx *sqlx.Tx, candle mod
 	return rowsAffected, err
 }
 
-func (c *candleRepository) GetTicksRange(startTime, endTime int64) ([]models.MarketTick, error) {
+// ApplyTickWithTx implements [CandlesRepository].
+// update candle in place dari satu tick: open tetap, high/low diperluas, close diganti, volume ditambah.
+// tick dengan block yang sudah diterapkan (last_block_id) diabaikan, rows affected = 0
+func (c *candleRepository) ApplyTickWithTx(tx *sqlx.Tx, candle models.Candle) (int64, error) {
+	res, err := tx.Exec(
+		`INSERT INTO candles (pair, interval_type, start_time, open_price, high_price, low_price, close_price, volume, buy_volume, sell_volume, trade_count, last_block_id) VALUES (?,?,?,?,?,?,?,?,?,?,?,?)
+		ON DUPLICATE KEY UPDATE
+			high_price = IF(VALUES(last_block_id) > last_block_id, GREATEST(high_price, VALUES(high_price)), high_price),
+			low_price = IF(VALUES(last_block_id) > last_block_id, LEAST(low_price, VALUES(low_price)), low_price),
+			close_price = IF(VALUES(last_block_id) > last_block_id, VALUES(close_price), close_price),
+			volume = IF(VALUES(last_block_id) > last_block_id, volume + VALUES(volume), volume),
+			buy_volume = IF(VALUES(last_block_id) > last_block_id, buy_volume + VALUES(buy_volume), buy_volume),
+			sell_volume = IF(VALUES(last_block_id) > last_block_id, sell_volume + VALUES(sell_volume), sell_volume),
+			trade_count = IF(VALUES(last_block_id) > last_block_id, trade_count + VALUES(trade_count), trade_count),
+			last_block_id = GREATEST(last_block_id, VALUES(last_block_id))`,
+		candlePair(candle),
+		candle.IntervalType,
+		candle.StartTime,
+		candle.OpenPrice,
+		candle.HighPrice,
+		candle.LowPrice,
+		candle.ClosePrice,
+		candle.Volume,
+		candle.BuyVolume,
+		candle.SellVolume,
+		candle.TradeCount,
+		candle.LastBlockID,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// GetCandlesRangeWithTx implements [CandlesRepository].
+func (c *candleRepository) GetCandlesRangeWithTx(tx *sqlx.Tx, pair, intervalType string, startTime, endTime int64) ([]models.Candle, error) {
+	var candles []models.Candle
+
+	err := tx.Select(&candles,
+		`SELECT id, pair, interval_type, start_time, open_price, high_price, low_price, close_price, volume, buy_volume, sell_volume, trade_count, last_block_id FROM candles
+		WHERE pair = ? AND interval_type = ? AND start_time >= ? AND start_time < ?
+		ORDER BY start_time ASC`,
+		pair,
+		intervalType,
+		startTime,
+		endTime,
+	)
+
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	return candles, nil
+}
+
+// GetCandleBeforeWithTx implements [CandlesRepository].
+func (c *candleRepository) GetCandleBeforeWithTx(tx *sqlx.Tx, pair, intervalType string, startTime int64) (models.Candle, error) {
+	var candle models.Candle
+
+	err := tx.Get(&candle,
+		`SELECT id, pair, interval_type, start_time, open_price, high_price, low_price, close_price, volume, buy_volume, sell_volume, trade_count, last_block_id FROM candles
+		WHERE pair = ? AND interval_type = ? AND start_time < ?
+		ORDER BY start_time DESC
+		LIMIT 1`,
+		pair,
+		intervalType,
+		startTime,
+	)
+
+	return candle, err
+}
+
+// GetCandleBefore implements [CandlesRepository].
+func (c *candleRepository) GetCandleBefore(pair, intervalType string, startTime int64) (models.Candle, error) {
+	var candle models.Candle
+
+	err := c.db.Get(&candle,
+		`SELECT id, pair, interval_type, start_time, open_price, high_price, low_price, close_price, volume, buy_volume, sell_volume, trade_count FROM candles
+		WHERE pair = ? AND interval_type = ? AND start_time < ?
+		ORDER BY start_time DESC
+		LIMIT 1`,
+		pair,
+		intervalType,
+		startTime,
+	)
+
+	return candle, err
+}
+
+func (c *candleRepository) GetTicksRange(pair string, startTime, endTime int64) ([]models.MarketTick, error) {
 	var ticks []models.MarketTick
 
 	err := c.db.Select(&ticks,
-		`SELECT id, block_id, price, buy_volume, sell_volume, tx_count, UNIX_TIMESTAMP(created_at) AS created_at FROM market_ticks
-		WHERE created_at >= FROM_UNIXTIME(?) AND created_at < FROM_UNIXTIME(?)
+		`SELECT id, pair, block_id, price, buy_volume, sell_volume, tx_count, UNIX_TIMESTAMP(created_at) AS created_at FROM market_ticks
+		WHERE pair = ? AND created_at >= FROM_UNIXTIME(?) AND created_at < FROM_UNIXTIME(?)
 		ORDER BY created_at ASC`,
+		pair,
 		startTime,
 		endTime,
 	)
@@ -220,16 +352,25 @@ func (c *candleRepository) CandleBeginTx() (*sqlx.Tx, error) {
 	return c.db.Beginx()
 }
 
-func (c *candleRepository) GetLatestCandleByInterval(intervalType string) (models.Candle, error) {
+func (c *candleRepository) GetLatestCandleByInterval(pair, intervalType string) (models.Candle, error) {
 	var candle models.Candle
 
 	err := c.db.Get(&candle,
-		`SELECT id, interval_type, start_time, open_price, high_price, low_price, close_price, volume FROM candles 
-		WHERE interval_type = ? 
+		`SELECT id, pair, interval_type, start_time, open_price, high_price, low_price, close_price, volume, buy_volume, sell_volume, trade_count FROM candles 
+		WHERE pair = ? AND interval_type = ? 
 		ORDER BY start_time DESC 
 		LIMIT 1`,
+		pair,
 		intervalType,
 	)
 
 	return candle, err
 }
+
+// candlePair - candle tanpa pair dianggap milik pair native
+func candlePair(candle models.Candle) string {
+	if candle.Pair == "" {
+		return models.DefaultPair
+	}
+	return candle.Pair
+}
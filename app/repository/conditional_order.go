@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+)
+
+type ConditionalOrderRepository interface {
+	Create(order models.ConditionalOrder) (int64, error)
+	GetByID(id int64) (models.ConditionalOrder, error)
+	GetByAddress(address, status string) ([]models.ConditionalOrder, error)
+	GetActive() ([]models.ConditionalOrder, error)
+	UpdateReferencePrice(id int64, referencePrice float64) error
+	MarkTriggered(id int64, price float64, blockNumber int64) (bool, error)
+	MarkExecuted(id, txID int64) error
+	MarkFailed(id int64, reason string) error
+	Cancel(id int64) (bool, error)
+}
+
+type conditionalOrderRepository struct {
+	db *sqlx.DB
+}
+
+func NewConditionalOrderRepository(db *sqlx.DB) ConditionalOrderRepository {
+	return &conditionalOrderRepository{db: db}
+}
+
+const conditionalOrderColumns = `id, user_address, order_type, side, amount, trigger_price, trail_percent, reference_price, signature, status, triggered_price, triggered_block, tx_id, failure_reason, created_at, updated_at`
+
+// Create implements [ConditionalOrderRepository].
+func (r *conditionalOrderRepository) Create(order models.ConditionalOrder) (int64, error) {
+	res, err := r.db.Exec(`
+		INSERT INTO conditional_orders (user_address, order_type, side, amount, trigger_price, trail_percent, reference_price, signature, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		order.UserAddress,
+		order.OrderType,
+		order.Side,
+		order.Amount,
+		order.TriggerPrice,
+		order.TrailPercent,
+		order.ReferencePrice,
+		order.Signature,
+		models.ConditionalStatusActive,
+	)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+// GetByID implements [ConditionalOrderRepository].
+func (r *conditionalOrderRepository) GetByID(id int64) (models.ConditionalOrder, error) {
+	var order models.ConditionalOrder
+	err := r.db.Get(&order, `SELECT `+conditionalOrderColumns+` FROM conditional_orders WHERE id = ?`, id)
+
+	if err == sql.ErrNoRows {
+		return models.ConditionalOrder{}, entity.ErrConditionalOrderNotFound
+	}
+
+	return order, err
+}
+
+// GetByAddress implements [ConditionalOrderRepository].
+func (r *conditionalOrderRepository) GetByAddress(address, status string) ([]models.ConditionalOrder, error) {
+	orders := []models.ConditionalOrder{}
+
+	if status == "" {
+		err := r.db.Select(&orders, `SELECT `+conditionalOrderColumns+` FROM conditional_orders WHERE user_address = ? ORDER BY id DESC`, address)
+		return orders, err
+	}
+
+	err := r.db.Select(&orders, `SELECT `+conditionalOrderColumns+` FROM conditional_orders WHERE user_address = ? AND status = ? ORDER BY id DESC`, address, status)
+	return orders, err
+}
+
+// GetActive implements [ConditionalOrderRepository].
+func (r *conditionalOrderRepository) GetActive() ([]models.ConditionalOrder, error) {
+	var orders []models.ConditionalOrder
+	err := r.db.Select(&orders, `SELECT `+conditionalOrderColumns+` FROM conditional_orders WHERE status = ? ORDER BY id ASC`, models.ConditionalStatusActive)
+	return orders, err
+}
+
+// UpdateReferencePrice implements [ConditionalOrderRepository].
+func (r *conditionalOrderRepository) UpdateReferencePrice(id int64, referencePrice float64) error {
+	_, err := r.db.Exec(`UPDATE conditional_orders SET reference_price = ? WHERE id = ? AND status = ?`, referencePrice, id, models.ConditionalStatusActive)
+	return err
+}
+
+// MarkTriggered implements [ConditionalOrderRepository].
+// hanya satu worker yang berhasil claim order (status ACTIVE -> TRIGGERED)
+func (r *conditionalOrderRepository) MarkTriggered(id int64, price float64, blockNumber int64) (bool, error) {
+	res, err := r.db.Exec(`
+		UPDATE conditional_orders
+		SET status = ?, triggered_price = ?, triggered_block = ?
+		WHERE id = ? AND status = ?`,
+		models.ConditionalStatusTriggered,
+		price,
+		blockNumber,
+		id,
+		models.ConditionalStatusActive,
+	)
+
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows == 1, nil
+}
+
+// MarkExecuted implements [ConditionalOrderRepository].
+func (r *conditionalOrderRepository) MarkExecuted(id, txID int64) error {
+	_, err := r.db.Exec(`UPDATE conditional_orders SET status = ?, tx_id = ? WHERE id = ?`, models.ConditionalStatusExecuted, txID, id)
+	return err
+}
+
+// MarkFailed implements [ConditionalOrderRepository].
+func (r *conditionalOrderRepository) MarkFailed(id int64, reason string) error {
+	_, err := r.db.Exec(`UPDATE conditional_orders SET status = ?, failure_reason = ? WHERE id = ?`, models.ConditionalStatusFailed, reason, id)
+	return err
+}
+
+// Cancel implements [ConditionalOrderRepository].
+func (r *conditionalOrderRepository) Cancel(id int64) (bool, error) {
+	res, err := r.db.Exec(`UPDATE conditional_orders SET status = ? WHERE id = ? AND status = ?`, models.ConditionalStatusCancelled, id, models.ConditionalStatusActive)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows == 1, nil
+}
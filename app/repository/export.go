@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+)
+
+// ExportRepository - baca data historis row per row (tanpa LIMIT) untuk export,
+// hasil tidak ditampung di memory
+type ExportRepository interface {
+	StreamCandles(ctx context.Context, pair, intervalType string, from, to int64, fn func(models.Candle) error) error
+	StreamTicks(ctx context.Context, pair string, from, to int64, fn func(models.MarketTick) error) error
+	StreamTrades(ctx context.Context, from, to int64, fn func(models.Trade) error) error
+}
+
+type exportRepository struct {
+	db *sqlx.DB
+}
+
+func NewExportRepository(db *sqlx.DB) ExportRepository {
+	return &exportRepository{db: db}
+}
+
+// StreamCandles implements [ExportRepository].
+func (e *exportRepository) StreamCandles(ctx context.Context, pair, intervalType string, from, to int64, fn func(models.Candle) error) error {
+	rows, err := e.db.QueryxContext(ctx,
+		`SELECT id, pair, interval_type, start_time, open_price, high_price, low_price, close_price, volume, buy_volume, sell_volume, trade_count, last_block_id
+		FROM candles
+		WHERE pair = ? AND interval_type = ? AND start_time >= ? AND start_time < ?
+		ORDER BY start_time ASC`,
+		pair,
+		intervalType,
+		from,
+		to,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return streamRows(rows, fn)
+}
+
+// StreamTicks implements [ExportRepository].
+func (e *exportRepository) StreamTicks(ctx context.Context, pair string, from, to int64, fn func(models.MarketTick) error) error {
+	rows, err := e.db.QueryxContext(ctx,
+		`SELECT id, pair, block_id, price, buy_volume, sell_volume, tx_count, UNIX_TIMESTAMP(created_at) AS created_at FROM market_ticks
+		WHERE pair = ? AND created_at >= FROM_UNIXTIME(?) AND created_at < FROM_UNIXTIME(?)
+		ORDER BY created_at ASC, block_id ASC`,
+		pair,
+		from,
+		to,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return streamRows(rows, fn)
+}
+
+// StreamTrades implements [ExportRepository].
+// BUY/SELL selalu untuk default pair, harga diambil dari tick block tersebut
+func (e *exportRepository) StreamTrades(ctx context.Context, from, to int64, fn func(models.Trade) error) error {
+	rows, err := e.db.QueryxContext(ctx,
+		`SELECT t.id AS tx_id, b.block_number, t.type AS side,
+			CASE WHEN t.type = 'BUY' THEN t.to_address ELSE t.from_address END AS address,
+			t.amount, COALESCE(mt.price, 0) AS price, b.timestamp
+		FROM transactions t
+		JOIN block_transactions bt ON bt.transaction_id = t.id
+		JOIN blocks b ON b.id = bt.block_id
+		LEFT JOIN market_ticks mt ON mt.block_id = b.id AND mt.pair = ?
+		WHERE t.type IN ('BUY', 'SELL') AND b.timestamp >= ? AND b.timestamp < ?
+		ORDER BY b.block_number ASC, t.id ASC`,
+		models.DefaultPair,
+		from,
+		to,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return streamRows(rows, fn)
+}
+
+func streamRows[T any](rows *sqlx.Rows, fn func(T) error) error {
+	defer rows.Close()
+
+	for rows.Next() {
+		var item T
+		if err := rows.StructScan(&item); err != nil {
+			return err
+		}
+
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -4,12 +4,15 @@ import (
 	"fmt"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
 )
 
 type LedgerEntry struct {
 	BlockID      int64   `db:"block_id"`
 	TxID         *int64  `db:"tx_id"`
 	Address      string  `db:"address"`
+	Asset        string  `db:"asset"` // kosong = YTE
+	Leg          string  `db:"leg"`
 	Amount       float64 `db:"change_amount"`
 	BalanceAfter float64 `db:"balance_after"`
 }
@@ -19,13 +22,14 @@ type LedgerEntryWithID struct {
 	BlockID      int64   `db:"block_id"`
 	TxID         *int64  `db:"tx_id"`
 	Address      string  `db:"address"`
+	Asset        string  `db:"asset"` // kosong = YTE
+	Leg          string  `db:"leg"`
 	Amount       float64 `db:"change_amount"`
 	BalanceAfter float64 `db:"balance_after"`
 }
 
 type LedgerRepository interface {
 	BulkCreateWithTx(dbTx *sqlx.Tx, entries []LedgerEntry) error
-	BulkCreate(entries []LedgerEntry) error
 	GetEntriesByBlockID(blockID int64) ([]LedgerEntryWithID, error)
 	GetEntriesByAddress(address string, limit int) ([]LedgerEntryWithID, error)
 }
@@ -40,21 +44,23 @@ func NewLedgerRepository(db *sqlx.DB) LedgerRepository {
 	}
 }
 
-// BulkCreateWithTx inserts multiple ledger entries in a single query
+// BulkCreateWithTx inserts multiple ledger entries in a single query.
+// dipanggil di transaction block yang sama dengan update wallet; entry duplikat melanggar
+// unique key (block_id, tx_key, address, leg) dan membatalkan block. tx_key = COALESCE(tx_id, 0)
 func (l *ledgerRepository) BulkCreateWithTx(dbTx *sqlx.Tx, entries []LedgerEntry) error {
 	if len(entries) == 0 {
 		return nil
 	}
 
-	query := `INSERT INTO ledger (block_id, tx_id, address, change_amount, balance_after) VALUES `
+	query := `INSERT INTO ledger (block_id, tx_id, address, asset, leg, change_amount, balance_after) VALUES `
 	var values []interface{}
 
 	for i, entry := range entries {
 		if i > 0 {
 			query += ","
 		}
-		query += "(?, ?, ?, ?, ?)"
-		values = append(values, entry.BlockID, entry.TxID, entry.Address, entry.Amount, entry.BalanceAfter)
+		query += "(?, ?, ?, ?, ?, ?, ?)"
+		values = append(values, entry.BlockID, entry.TxID, entry.Address, ledgerAsset(entry.Asset), entry.Leg, entry.Amount, entry.BalanceAfter)
 	}
 	_, err := dbTx.Exec(query, values...)
 	if err != nil {
@@ -63,31 +69,9 @@ func (l *ledgerRepository) BulkCreateWithTx(dbTx *sqlx.Tx, entries []LedgerEntry
 	return nil
 }
 
-func (l *ledgerRepository) BulkCreate(entries []LedgerEntry) error {
-	if len(entries) == 0 {
-		return nil
-	}
-
-	query := `INSERT INTO ledger (block_id, tx_id, address, change_amount, balance_after) VALUES `
-	var values []interface{}
-
-	for i, entry := range entries {
-		if i > 0 {
-			query += ","
-		}
-		query += "(?, ?, ?, ?, ?)"
-		values = append(values, entry.BlockID, entry.TxID, entry.Address, entry.Amount, entry.BalanceAfter)
-	}
-	_, err := l.db.Exec(query, values...)
-	if err != nil {
-		return fmt.Errorf("bulk create: %w", err)
-	}
-	return nil
-}
-
 func (l *ledgerRepository) GetEntriesByBlockID(blockID int64) ([]LedgerEntryWithID, error) {
 	var entries []LedgerEntryWithID
-	query := `SELECT id, block_id, tx_id, address, change_amount, balance_after FROM ledger WHERE block_id = ? ORDER BY id ASC`
+	query := `SELECT id, block_id, tx_id, address, asset, leg, change_amount, balance_after FROM ledger WHERE block_id = ? ORDER BY id ASC`
 	err := l.db.Select(&entries, query, blockID)
 	if err != nil {
 		return nil, fmt.Errorf("get entries by block id: %w", err)
@@ -97,10 +81,17 @@ func (l *ledgerRepository) GetEntriesByBlockID(blockID int64) ([]LedgerEntryWith
 
 func (l *ledgerRepository) GetEntriesByAddress(address string, limit int) ([]LedgerEntryWithID, error) {
 	var entries []LedgerEntryWithID
-	query := `SELECT id, block_id, tx_id, address, change_amount, balance_after FROM ledger WHERE address = ? ORDER BY id DESC LIMIT ?`
+	query := `SELECT id, block_id, tx_id, address, asset, leg, change_amount, balance_after FROM ledger WHERE address = ? ORDER BY id DESC LIMIT ?`
 	err := l.db.Select(&entries, query, address, limit)
 	if err != nil {
 		return nil, fmt.Errorf("get entries by address: %w", err)
 	}
 	return entries, nil
 }
+
+func ledgerAsset(asset string) string {
+	if asset == "" {
+		return models.NativeAsset
+	}
+	return asset
+}
@@ -0,0 +1,182 @@
+//go:build integration
+
+package repository
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+)
+
+// LEDGER_TEST_DSN harus menunjuk ke database MySQL kosong, tabel ledger dibuat ulang oleh test:
+//
+//	LEDGER_TEST_DSN='root:root@tcp(127.0.0.1:3306)/ledger_test?parseTime=true' go test -tags integration ./app/repository
+func openLedgerTestDB(t *testing.T) *sqlx.DB {
+	t.Helper()
+
+	dsn := os.Getenv("LEDGER_TEST_DSN")
+	if dsn == "" {
+		t.Skip("LEDGER_TEST_DSN not set")
+	}
+
+	db, err := sqlx.Open("mysql", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Exec(`DROP TABLE IF EXISTS ledger`)
+		db.Close()
+	})
+
+	// bentuk tabel sebelum ledger_leg.sql, tanpa foreign key ke blocks/transactions
+	for _, stmt := range []string{
+		`DROP TABLE IF EXISTS ledger`,
+		`CREATE TABLE ledger (
+			id BIGINT AUTO_INCREMENT PRIMARY KEY,
+			block_id BIGINT NOT NULL,
+			tx_id BIGINT NULL,
+			address VARCHAR(255) NOT NULL,
+			asset VARCHAR(16) NOT NULL DEFAULT 'YTE',
+			change_amount DECIMAL(20, 8) NOT NULL,
+			balance_after DECIMAL(20, 8) NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return db
+}
+
+// applyLedgerLegMigration - jalankan migration apa adanya, satu statement per Exec
+func applyLedgerLegMigration(t *testing.T, db *sqlx.DB) {
+	t.Helper()
+
+	raw, err := os.ReadFile("../../database/migrations/ledger_leg.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, stmt := range strings.Split(string(raw), ";") {
+		if strings.TrimSpace(stripSQLComments(stmt)) == "" {
+			continue
+		}
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("migration statement failed: %v\n%s", err, stmt)
+		}
+	}
+}
+
+func stripSQLComments(stmt string) string {
+	var lines []string
+	for _, line := range strings.Split(stmt, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "--") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isDuplicateKey(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062
+}
+
+func countLedger(t *testing.T, db *sqlx.DB) int {
+	t.Helper()
+
+	var n int
+	if err := db.Get(&n, `SELECT COUNT(*) FROM ledger`); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+// insertLedger - satu BulkCreateWithTx di transaction sendiri, rollback jika gagal seperti block
+func insertLedger(t *testing.T, repo LedgerRepository, db *sqlx.DB, entries []LedgerEntry) error {
+	t.Helper()
+
+	tx, err := db.Beginx()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.BulkCreateWithTx(tx, entries); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func TestLedgerLegMigrationDedupesNullTxID(t *testing.T) {
+	db := openLedgerTestDB(t)
+
+	// redelivery lama: duplikat persis, termasuk entry tanpa tx_id
+	if _, err := db.Exec(`INSERT INTO ledger (block_id, tx_id, address, asset, change_amount, balance_after) VALUES
+		(1, NULL, 'miner', 'YTE', 50, 50),
+		(1, NULL, 'miner', 'YTE', 50, 50),
+		(1, 7, 'alice', 'YTE', -10, 90),
+		(1, 7, 'alice', 'YTE', -10, 90),
+		(1, 7, 'bob', 'YTE', 10, 10)`); err != nil {
+		t.Fatal(err)
+	}
+
+	applyLedgerLegMigration(t, db)
+
+	if got := countLedger(t, db); got != 3 {
+		t.Fatalf("ledger rows after migration = %d, want 3", got)
+	}
+
+	var legs []string
+	if err := db.Select(&legs, `SELECT leg FROM ledger ORDER BY id`); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(legs, ",") != "credit,debit,credit" {
+		t.Fatalf("legs = %v", legs)
+	}
+}
+
+func TestLedgerBulkCreateIsIdempotentPerLeg(t *testing.T) {
+	db := openLedgerTestDB(t)
+	applyLedgerLegMigration(t, db)
+
+	repo := NewLedgerRepository(db)
+	txID := int64(7)
+
+	block := []LedgerEntry{
+		{BlockID: 1, TxID: &txID, Address: "alice", Leg: "debit", Amount: -10, BalanceAfter: 90},
+		{BlockID: 1, TxID: &txID, Address: "bob", Leg: "credit", Amount: 10, BalanceAfter: 10},
+		{BlockID: 1, TxID: nil, Address: "miner", Leg: "credit", Amount: 50, BalanceAfter: 50},
+	}
+
+	if err := insertLedger(t, repo, db, block); err != nil {
+		t.Fatalf("first insert: %v", err)
+	}
+
+	// block yang sama ditulis ulang: seluruh batch ditolak, tidak ada entry ganda
+	if err := insertLedger(t, repo, db, block); !isDuplicateKey(err) {
+		t.Fatalf("second insert error = %v, want duplicate key", err)
+	}
+
+	// entry tanpa tx_id saja juga tertolak (NULL tidak lolos unique key)
+	if err := insertLedger(t, repo, db, block[2:]); !isDuplicateKey(err) {
+		t.Fatalf("NULL tx_id insert error = %v, want duplicate key", err)
+	}
+
+	if got := countLedger(t, db); got != len(block) {
+		t.Fatalf("ledger rows = %d, want %d", got, len(block))
+	}
+
+	// leg berbeda pada transaksi dan address yang sama tetap boleh (mis. credit + fee ke MINER_ACCOUNT)
+	fee := []LedgerEntry{{BlockID: 1, TxID: &txID, Address: "bob", Leg: "fee", Amount: 0.1, BalanceAfter: 10.1}}
+	if err := insertLedger(t, repo, db, fee); err != nil {
+		t.Fatalf("fee leg insert: %v", err)
+	}
+}
@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+)
+
+// LedgerRebuildRepository - sumber replay dari genesis (block, ledger, history) dan tabel materialized
+// yang dibandingkan. semua dibaca di satu transaction agar hasilnya satu snapshot
+type LedgerRebuildRepository interface {
+	BeginTx() (*sqlx.Tx, error)
+	// LockMaterializedWithTx - tahan block baru, reward dan top up selama rebuild yang menulis ulang tabel
+	LockMaterializedWithTx(tx *sqlx.Tx) error
+	StreamTransactionsWithTx(ctx context.Context, tx *sqlx.Tx, fn func(models.RebuildTransaction) error) error
+	StreamLedgerChangesWithTx(ctx context.Context, tx *sqlx.Tx, asset string, fn func(models.BalanceChange) error) error
+	// StreamTokenLedgerChangesWithTx - semua entry ledger selain YTE, Asset terisi
+	StreamTokenLedgerChangesWithTx(ctx context.Context, tx *sqlx.Tx, fn func(models.BalanceChange) error) error
+	StreamMiningRewardsWithTx(ctx context.Context, tx *sqlx.Tx, fn func(models.BalanceChange) error) error
+	StreamDepositsWithTx(ctx context.Context, tx *sqlx.Tx, fn func(models.BalanceChange) error) error
+	StreamTicksWithTx(ctx context.Context, tx *sqlx.Tx, pair string, fn func(models.MarketTick) error) error
+	GetWalletsWithTx(ctx context.Context, tx *sqlx.Tx) ([]models.UserWallet, error)
+	GetUSDBalancesWithTx(ctx context.Context, tx *sqlx.Tx) ([]models.UserBalance, error)
+	GetAssetBalancesWithTx(ctx context.Context, tx *sqlx.Tx) ([]models.AssetBalance, error)
+	GetMarketStateWithTx(ctx context.Context, tx *sqlx.Tx, pair string) (models.MarketEngine, error)
+	// GetOtherPairsWithTx - pair terdaftar atau punya market_engine selain pair yang diberikan
+	GetOtherPairsWithTx(ctx context.Context, tx *sqlx.Tx, pair string) ([]string, error)
+	SetWalletBalanceWithTx(tx *sqlx.Tx, address string, balance float64) error
+	SetUSDBalanceWithTx(tx *sqlx.Tx, address string, state models.USDState) error
+	SetAssetBalanceWithTx(tx *sqlx.Tx, address, asset string, balance float64) error
+}
+
+type ledgerRebuildRepository struct {
+	db *sqlx.DB
+}
+
+func NewLedgerRebuildRepository(db *sqlx.DB) LedgerRebuildRepository {
+	return &ledgerRebuildRepository{db: db}
+}
+
+// BeginTx implements [LedgerRebuildRepository].
+func (r *ledgerRebuildRepository) BeginTx() (*sqlx.Tx, error) {
+	return r.db.Beginx()
+}
+
+// LockMaterializedWithTx implements [LedgerRebuildRepository].
+// urutan lock sama dengan GenerateBlock (block terakhir lalu wallet) agar tidak deadlock
+func (r *ledgerRebuildRepository) LockMaterializedWithTx(tx *sqlx.Tx) error {
+	queries := []string{
+		`SELECT id FROM blocks ORDER BY block_number DESC LIMIT 1 FOR UPDATE`,
+		`SELECT id FROM user_wallets FOR UPDATE`,
+		`SELECT id FROM user_balances FOR UPDATE`,
+		`SELECT user_address FROM asset_balances FOR UPDATE`,
+		`SELECT id FROM market_engine FOR UPDATE`,
+	}
+
+	for _, query := range queries {
+		rows, err := tx.Query(query)
+		if err != nil {
+			return err
+		}
+		rows.Close()
+	}
+
+	return nil
+}
+
+// StreamTransactionsWithTx implements [LedgerRebuildRepository].
+func (r *ledgerRebuildRepository) StreamTransactionsWithTx(ctx context.Context, tx *sqlx.Tx, fn func(models.RebuildTransaction) error) error {
+	rows, err := tx.QueryxContext(ctx,
+		`SELECT b.id AS block_id, b.block_number, t.id AS tx_id, t.type, t.from_address, t.to_address, t.amount, t.fee
+		FROM block_transactions bt
+		JOIN blocks b ON b.id = bt.block_id
+		JOIN transactions t ON t.id = bt.transaction_id
+		ORDER BY b.block_number ASC, t.id ASC`,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return streamRows(rows, fn)
+}
+
+// StreamLedgerChangesWithTx implements [LedgerRebuildRepository].
+func (r *ledgerRebuildRepository) StreamLedgerChangesWithTx(ctx context.Context, tx *sqlx.Tx, asset string, fn func(models.BalanceChange) error) error {
+	rows, err := tx.QueryxContext(ctx,
+		`SELECT address, change_amount AS amount FROM ledger WHERE asset = ? ORDER BY id ASC`,
+		ledgerAsset(asset),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return streamRows(rows, fn)
+}
+
+// StreamTokenLedgerChangesWithTx implements [LedgerRebuildRepository].
+func (r *ledgerRebuildRepository) StreamTokenLedgerChangesWithTx(ctx context.Context, tx *sqlx.Tx, fn func(models.BalanceChange) error) error {
+	rows, err := tx.QueryxContext(ctx,
+		`SELECT address, asset, change_amount AS amount FROM ledger WHERE asset <> ? ORDER BY id ASC`,
+		models.NativeAsset,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return streamRows(rows, fn)
+}
+
+// StreamMiningRewardsWithTx implements [LedgerRebuildRepository].
+// reward block dikreditkan reward distribution consumer ke user_wallets tanpa entry ledger
+func (r *ledgerRebuildRepository) StreamMiningRewardsWithTx(ctx context.Context, tx *sqlx.Tx, fn func(models.BalanceChange) error) error {
+	rows, err := tx.QueryxContext(ctx,
+		`SELECT user_address AS address, amount FROM wallet_history WHERE change_type = 'MINING' ORDER BY id ASC`,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return streamRows(rows, fn)
+}
+
+// StreamDepositsWithTx implements [LedgerRebuildRepository].
+func (r *ledgerRebuildRepository) StreamDepositsWithTx(ctx context.Context, tx *sqlx.Tx, fn func(models.BalanceChange) error) error {
+	rows, err := tx.QueryxContext(ctx,
+		`SELECT user_address AS address, amount FROM balance_history WHERE change_type = 'DEPOSIT' ORDER BY id ASC`,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return streamRows(rows, fn)
+}
+
+// StreamTicksWithTx implements [LedgerRebuildRepository].
+func (r *ledgerRebuildRepository) StreamTicksWithTx(ctx context.Context, tx *sqlx.Tx, pair string, fn func(models.MarketTick) error) error {
+	rows, err := tx.QueryxContext(ctx,
+		`SELECT id, pair, block_id, price, buy_volume, sell_volume, tx_count, UNIX_TIMESTAMP(created_at) AS created_at
+		FROM market_ticks
+		WHERE pair = ?
+		ORDER BY block_id ASC`,
+		pair,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return streamRows(rows, fn)
+}
+
+// GetWalletsWithTx implements [LedgerRebuildRepository].
+func (r *ledgerRebuildRepository) GetWalletsWithTx(ctx context.Context, tx *sqlx.Tx) ([]models.UserWallet, error) {
+	var wallets []models.UserWallet
+	err := tx.SelectContext(ctx, &wallets, `SELECT user_address, yte_balance, locked_balance FROM user_wallets ORDER BY user_address ASC`)
+	return wallets, err
+}
+
+// GetUSDBalancesWithTx implements [LedgerRebuildRepository].
+func (r *ledgerRebuildRepository) GetUSDBalancesWithTx(ctx context.Context, tx *sqlx.Tx) ([]models.UserBalance, error) {
+	var balances []models.UserBalance
+	err := tx.SelectContext(ctx, &balances,
+		`SELECT user_address, usd_balance, locked_balance, total_deposited, total_withdrawn, total_traded
+		FROM user_balances
+		ORDER BY user_address ASC`,
+	)
+	return balances, err
+}
+
+// GetAssetBalancesWithTx implements [LedgerRebuildRepository].
+func (r *ledgerRebuildRepository) GetAssetBalancesWithTx(ctx context.Context, tx *sqlx.Tx) ([]models.AssetBalance, error) {
+	var balances []models.AssetBalance
+	err := tx.SelectContext(ctx, &balances,
+		`SELECT user_address, asset_symbol, balance, updated_at FROM asset_balances ORDER BY user_address ASC, asset_symbol ASC`,
+	)
+	return balances, err
+}
+
+// GetMarketStateWithTx implements [LedgerRebuildRepository].
+func (r *ledgerRebuildRepository) GetMarketStateWithTx(ctx context.Context, tx *sqlx.Tx, pair string) (models.MarketEngine, error) {
+	var state models.MarketEngine
+	err := tx.GetContext(ctx, &state, `SELECT id, pair, price, liquidity, last_block, updated_at FROM market_engine WHERE pair = ?`, pair)
+	return state, err
+}
+
+// GetOtherPairsWithTx implements [LedgerRebuildRepository].
+func (r *ledgerRebuildRepository) GetOtherPairsWithTx(ctx context.Context, tx *sqlx.Tx, pair string) ([]string, error) {
+	var pairs []string
+	err := tx.SelectContext(ctx, &pairs,
+		`SELECT symbol FROM trading_pairs WHERE symbol <> ?
+		UNION
+		SELECT pair FROM market_engine WHERE pair <> ?
+		ORDER BY 1`,
+		pair,
+		pair,
+	)
+	return pairs, err
+}
+
+// SetWalletBalanceWithTx implements [LedgerRebuildRepository].
+// last_transaction_at tidak disentuh, rebuild bukan transaksi
+func (r *ledgerRebuildRepository) SetWalletBalanceWithTx(tx *sqlx.Tx, address string, balance float64) error {
+	_, err := tx.Exec(`
+		INSERT INTO user_wallets (user_address, yte_balance, locked_balance, total_received, total_sent)
+		VALUES (?, ?, 0, 0, 0)
+		ON DUPLICATE KEY UPDATE yte_balance = VALUES(yte_balance)`,
+		address,
+		balance,
+	)
+	return err
+}
+
+// SetUSDBalanceWithTx implements [LedgerRebuildRepository].
+func (r *ledgerRebuildRepository) SetUSDBalanceWithTx(tx *sqlx.Tx, address string, state models.USDState) error {
+	_, err := tx.Exec(`
+		INSERT INTO user_balances (user_address, usd_balance, locked_balance, total_deposited, total_withdrawn, total_traded)
+		VALUES (?, ?, 0, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE usd_balance = VALUES(usd_balance), total_deposited = VALUES(total_deposited),
+			total_withdrawn = VALUES(total_withdrawn), total_traded = VALUES(total_traded)`,
+		address,
+		state.USDBalance,
+		state.TotalDeposited,
+		state.TotalWithdrawn,
+		state.TotalTraded,
+	)
+	return err
+}
+
+// SetAssetBalanceWithTx implements [LedgerRebuildRepository].
+func (r *ledgerRebuildRepository) SetAssetBalanceWithTx(tx *sqlx.Tx, address, asset string, balance float64) error {
+	_, err := tx.Exec(`
+		INSERT INTO asset_balances (user_address, asset_symbol, balance)
+		VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE balance = VALUES(balance)`,
+		address,
+		asset,
+		balance,
+	)
+	return err
+}
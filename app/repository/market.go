@@ -7,13 +7,20 @@ import (
 
 type MarketRepository interface {
 	GetState() (models.MarketEngine, error)
-	GetStateForUpdateWithTx(tx *sqlx.Tx) (models.MarketEngine, error)
+	GetStateByPair(pair string) (models.MarketEngine, error)
+	GetAllStates() ([]models.MarketEngine, error)
+	GetStateForUpdateWithTx(tx *sqlx.Tx, pair string) (models.MarketEngine, error)
 	UpdateStateWithTx(tx *sqlx.Tx, market models.MarketEngine) error
 	InsertTickWithTx(tx *sqlx.Tx, tick models.MarketTick) (int64, error)
 	GetTickByBlockID(blockID int64) (models.MarketTick, error)
 	GetVolumeHistory(limit, offset int) ([]models.MarketTick, error)
+	GetTickHistoryByPair(pair string, limit, offset int) ([]models.MarketTick, error)
 	GetVolumeBlockRange(startBlock, endBlock int64) ([]models.MarketTick, error)
 	GetAverateVolume(blockRange int64) (float64, float64, error)
+	// GetTickerStats - open/high/low/last dan volume tick sejak since (unix seconds)
+	GetTickerStats(pair string, since int64) (models.TickerStats, error)
+	// GetRecentTrades - BUY/SELL terkonfirmasi terakhir, urut dari yang terlama
+	GetRecentTrades(limit int) ([]models.Trade, error)
 }
 
 type marketRepository struct {
@@ -29,19 +36,24 @@ func NewMarketRepository(db *sqlx.DB) MarketRepository {
 func (m *marketRepository) GetTickByBlockID(blockID int64) (models.MarketTick, error) {
 	var tick models.MarketTick
 
-	err := m.db.Get(&tick, `SELECT id, block_id, price, buy_volume, sell_volume, tx_count, UNIX_TIMESTAMP(created_at) as created_at FROM market_ticks WHERE block_id = ?`, blockID)
+	err := m.db.Get(&tick, `SELECT id, pair, block_id, price, buy_volume, sell_volume, tx_count, UNIX_TIMESTAMP(created_at) as created_at FROM market_ticks WHERE pair = ? AND block_id = ?`, models.DefaultPair, blockID)
 	return tick, err
 }
 
 func (m *marketRepository) GetVolumeHistory(limit, offset int) ([]models.MarketTick, error) {
+	return m.GetTickHistoryByPair(models.DefaultPair, limit, offset)
+}
+
+// GetTickHistoryByPair implements [MarketRepository].
+func (m *marketRepository) GetTickHistoryByPair(pair string, limit, offset int) ([]models.MarketTick, error) {
 	var ticks []models.MarketTick
-	err := m.db.Select(&ticks, `SELECT id, block_id, price, buy_volume, sell_volume, tx_count, UNIX_TIMESTAMP(created_at) as created_at FROM market_ticks ORDER BY block_id DESC LIMIT ? OFFSET ?`, limit, offset)
+	err := m.db.Select(&ticks, `SELECT id, pair, block_id, price, buy_volume, sell_volume, tx_count, UNIX_TIMESTAMP(created_at) as created_at FROM market_ticks WHERE pair = ? ORDER BY block_id DESC LIMIT ? OFFSET ?`, pair, limit, offset)
 	return ticks, err
 }
 
 func (m *marketRepository) GetVolumeBlockRange(startBlock, endBlock int64) ([]models.MarketTick, error) {
 	var ticks []models.MarketTick
-	err := m.db.Select(&ticks, `SELECT id, block_id, price, buy_volume, sell_volume, tx_count, UNIX_TIMESTAMP(created_at) as created_at FROM market_ticks WHERE block_id BETWEEN ? AND ? ORDER BY block_id ASC`, startBlock, endBlock)
+	err := m.db.Select(&ticks, `SELECT id, pair, block_id, price, buy_volume, sell_volume, tx_count, UNIX_TIMESTAMP(created_at) as created_at FROM market_ticks WHERE pair = ? AND block_id BETWEEN ? AND ? ORDER BY block_id ASC`, models.DefaultPair, startBlock, endBlock)
 	return ticks, err
 }
 
@@ -57,8 +69,8 @@ func (m *marketRepository) GetAverateVolume(blockRange int64) (float64, float64,
 			AVG(buy_volume) as buy_avg,
 			AVG(sell_volume) as sell_avg
 		 FROM market_ticks 
-		 WHERE block_id > (SELECT MAX(block_id) - ? FROM market_ticks)`,
-		blockRange)
+		 WHERE pair = ? AND block_id > (SELECT MAX(block_id) - ? FROM market_ticks WHERE pair = ?)`,
+		models.DefaultPair, blockRange, models.DefaultPair)
 
 	if err != nil {
 		return 0, 0, err
@@ -70,23 +82,94 @@ func (m *marketRepository) GetAverateVolume(blockRange int64) (float64, float64,
 	return buyAvg, sellAvg, nil
 }
 
+// GetTickerStats implements [MarketRepository].
+func (m *marketRepository) GetTickerStats(pair string, since int64) (models.TickerStats, error) {
+	var stats models.TickerStats
+
+	err := m.db.Get(&stats,
+		`SELECT
+			COALESCE((SELECT price FROM market_ticks WHERE pair = ? AND created_at >= FROM_UNIXTIME(?) ORDER BY created_at ASC, block_id ASC LIMIT 1), 0) AS open_price,
+			COALESCE(MAX(price), 0) AS high_price,
+			COALESCE(MIN(price), 0) AS low_price,
+			COALESCE((SELECT price FROM market_ticks WHERE pair = ? ORDER BY created_at DESC, block_id DESC LIMIT 1), 0) AS last_price,
+			COALESCE(SUM(buy_volume), 0) AS buy_volume,
+			COALESCE(SUM(sell_volume), 0) AS sell_volume,
+			COALESCE(SUM(tx_count), 0) AS tx_count,
+			COUNT(*) AS tick_count
+		FROM market_ticks
+		WHERE pair = ? AND created_at >= FROM_UNIXTIME(?)`,
+		pair, since, pair, pair, since,
+	)
+
+	return stats, err
+}
+
+// GetRecentTrades implements [MarketRepository].
+// harga diambil dari tick block tersebut, sama seperti export trades
+func (m *marketRepository) GetRecentTrades(limit int) ([]models.Trade, error) {
+	trades := []models.Trade{}
+
+	err := m.db.Select(&trades,
+		`SELECT t.id AS tx_id, b.block_number, t.type AS side,
+			CASE WHEN t.type = 'BUY' THEN t.to_address ELSE t.from_address END AS address,
+			t.amount, COALESCE(mt.price, 0) AS price, b.timestamp
+		FROM transactions t
+		JOIN block_transactions bt ON bt.transaction_id = t.id
+		JOIN blocks b ON b.id = bt.block_id
+		LEFT JOIN market_ticks mt ON mt.block_id = b.id AND mt.pair = ?
+		WHERE t.type IN ('BUY', 'SELL')
+		ORDER BY b.block_number DESC, t.id DESC
+		LIMIT ?`,
+		models.DefaultPair,
+		limit,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(trades)-1; i < j; i, j = i+1, j-1 {
+		trades[i], trades[j] = trades[j], trades[i]
+	}
+
+	return trades, nil
+}
+
 // GetState implements MarketRepository.
+// state pair native (YTE-USD)
 func (m *marketRepository) GetState() (models.MarketEngine, error) {
+	return m.GetStateByPair(models.DefaultPair)
+}
+
+// GetStateByPair implements MarketRepository.
+func (m *marketRepository) GetStateByPair(pair string) (models.MarketEngine, error) {
 	var state models.MarketEngine
-	err := m.db.Get(&state, `SELECT id, price, liquidity, last_block, updated_at FROM market_engine WHERE id = 1`)
+	err := m.db.Get(&state, `SELECT id, pair, price, liquidity, last_block, updated_at FROM market_engine WHERE pair = ?`, pair)
 	return state, err
 }
 
+// GetAllStates implements MarketRepository.
+func (m *marketRepository) GetAllStates() ([]models.MarketEngine, error) {
+	states := []models.MarketEngine{}
+	err := m.db.Select(&states, `SELECT id, pair, price, liquidity, last_block, updated_at FROM market_engine ORDER BY pair ASC`)
+	return states, err
+}
+
 // GetStateForUpdateWithTx implements MarketRepository.
-func (m *marketRepository) GetStateForUpdateWithTx(tx *sqlx.Tx) (models.MarketEngine, error) {
+func (m *marketRepository) GetStateForUpdateWithTx(tx *sqlx.Tx, pair string) (models.MarketEngine, error) {
 	var state models.MarketEngine
-	err := tx.Get(&state, `SELECT id, price, liquidity, last_block, updated_at FROM market_engine WHERE id = 1 FOR UPDATE`)
+	err := tx.Get(&state, `SELECT id, pair, price, liquidity, last_block, updated_at FROM market_engine WHERE pair = ? FOR UPDATE`, pair)
 	return state, err
 }
 
 // InsertTickWithTx implements MarketRepository.
 func (m *marketRepository) InsertTickWithTx(tx *sqlx.Tx, tick models.MarketTick) (int64, error) {
-	res, err := tx.Exec(`INSERT INTO market_ticks (block_id, price, buy_volume, sell_volume, tx_count) VALUES (?, ?, ?, ?, ?)`,
+	if tick.Pair == "" {
+		tick.Pair = models.DefaultPair
+	}
+
+	res, err := tx.Exec(`INSERT INTO market_ticks (pair, block_id, price, buy_volume, sell_volume, tx_count) VALUES (?, ?, ?, ?, ?, ?)`,
+		tick.Pair,
 		tick.BlockID,
 		tick.Price,
 		tick.BuyVolume,
@@ -107,28 +190,20 @@ func (m *marketRepository) InsertTickWithTx(tx *sqlx.Tx, tick models.MarketTick)
 }
 
 // UpdateStateWithTx implements MarketRepository.
+// upsert berdasarkan pair
 func (m *marketRepository) UpdateStateWithTx(tx *sqlx.Tx, market models.MarketEngine) error {
-	res, err := tx.Exec(`UPDATE market_engine SET price = ?, liquidity = ?, last_block = ? WHERE id = ?`,
+	if market.Pair == "" {
+		market.Pair = models.DefaultPair
+	}
+
+	_, err := tx.Exec(`
+		INSERT INTO market_engine (pair, price, liquidity, last_block) VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE price = VALUES(price), liquidity = VALUES(liquidity), last_block = VALUES(last_block)`,
+		market.Pair,
 		market.Price,
 		market.Liquidity,
 		market.LastBlock,
-		market.ID,
 	)
 
-	if err != nil {
-		return err
-	}
-
-	rows, _ := res.RowsAffected()
-	if rows == 0 {
-		_, err = tx.Exec(`INSERT INTO market_engine (id, price, liquidity, last_block) VALUES (1, ?, ?, ?)`,
-			market.Price,
-			market.Liquidity,
-			market.LastBlock,
-		)
-
-		return err
-	}
-
-	return nil
+	return err
 }
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+)
+
+type OutboxRepository interface {
+	BeginTx() (*sqlx.Tx, error)
+	CreateWithTx(dbTx *sqlx.Tx, events []models.OutboxEvent) error
+	GetDueForUpdateWithTx(dbTx *sqlx.Tx, limit int) ([]models.OutboxEvent, error)
+	// durasi dihitung dari NOW() database, bukan jam proses: DSN memakai loc=Local sehingga
+	// time.Time dari Go bisa bergeser terhadap NOW() jika zona waktu server dan database berbeda
+	ClaimWithTx(dbTx *sqlx.Tx, ids []int64, claimTimeout time.Duration) error
+	MarkSent(ids []int64) error
+	MarkFailed(id int64, lastError string, backoff time.Duration) error
+	GetStuck(stuckAfter time.Duration, limit, offset int) ([]models.OutboxEvent, error)
+	GetStats(stuckAfter time.Duration) (models.OutboxStats, error)
+	Retry(id int64) (bool, error)
+	DeleteSentBefore(retention time.Duration, limit int) (int64, error)
+}
+
+type outboxRepository struct {
+	db *sqlx.DB
+}
+
+func NewOutboxRepository(db *sqlx.DB) OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+const outboxColumns = `id, aggregate_type, aggregate_id, exchange_name, routing_key, payload, status,
+	attempts, last_error, next_attempt_at, created_at, sent_at`
+
+// BeginTx implements [OutboxRepository].
+func (r *outboxRepository) BeginTx() (*sqlx.Tx, error) {
+	return r.db.Beginx()
+}
+
+// CreateWithTx implements [OutboxRepository].
+// dipanggil di dalam transaction yang mengubah state, event ikut di-rollback jika transaction gagal
+func (r *outboxRepository) CreateWithTx(dbTx *sqlx.Tx, events []models.OutboxEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	query := `INSERT INTO outbox_events (aggregate_type, aggregate_id, exchange_name, routing_key, payload) VALUES `
+	placeholders := make([]string, 0, len(events))
+	values := make([]interface{}, 0, len(events)*5)
+
+	for _, event := range events {
+		placeholders = append(placeholders, "(?, ?, ?, ?, ?)")
+		values = append(values, event.AggregateType, event.AggregateID, event.Exchange, event.RoutingKey, event.Payload)
+	}
+
+	if _, err := dbTx.Exec(query+strings.Join(placeholders, ","), values...); err != nil {
+		return fmt.Errorf("create outbox events: %w", err)
+	}
+
+	return nil
+}
+
+// GetDueForUpdateWithTx implements [OutboxRepository].
+// PENDING yang jatuh tempo atau IN_FLIGHT yang klaimnya kedaluwarsa (relay mati sebelum menandai hasil).
+// SKIP LOCKED: beberapa instance relay bisa berjalan tanpa mengambil event yang sama
+func (r *outboxRepository) GetDueForUpdateWithTx(dbTx *sqlx.Tx, limit int) ([]models.OutboxEvent, error) {
+	events := []models.OutboxEvent{}
+	err := dbTx.Select(&events, `
+		SELECT `+outboxColumns+`
+		FROM outbox_events
+		WHERE status IN (?, ?) AND next_attempt_at <= NOW()
+		ORDER BY id
+		LIMIT ?
+		FOR UPDATE SKIP LOCKED`,
+		models.OutboxStatusPending, models.OutboxStatusInFlight, limit)
+	return events, err
+}
+
+// ClaimWithTx implements [OutboxRepository].
+// next_attempt_at dipakai sebagai batas klaim, setelah lewat event bisa diambil relay lain
+func (r *outboxRepository) ClaimWithTx(dbTx *sqlx.Tx, ids []int64, claimTimeout time.Duration) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	query, args, err := sqlx.In(`UPDATE outbox_events SET status = ?, next_attempt_at = DATE_ADD(NOW(), INTERVAL ? SECOND) WHERE id IN (?)`,
+		models.OutboxStatusInFlight, intervalSeconds(claimTimeout), ids)
+	if err != nil {
+		return err
+	}
+
+	_, err = dbTx.Exec(query, args...)
+	return err
+}
+
+// MarkSent implements [OutboxRepository].
+func (r *outboxRepository) MarkSent(ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	query, args, err := sqlx.In(`UPDATE outbox_events SET status = ?, sent_at = NOW(), last_error = NULL WHERE id IN (?)`, models.OutboxStatusSent, ids)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(query, args...)
+	return err
+}
+
+// MarkFailed implements [OutboxRepository].
+// event kembali PENDING dan dicoba lagi setelah backoff
+func (r *outboxRepository) MarkFailed(id int64, lastError string, backoff time.Duration) error {
+	if len(lastError) > 512 {
+		lastError = lastError[:512]
+	}
+
+	_, err := r.db.Exec(`
+		UPDATE outbox_events
+		SET status = ?, attempts = attempts + 1, last_error = ?, next_attempt_at = DATE_ADD(NOW(), INTERVAL ? SECOND)
+		WHERE id = ? AND status = ?`,
+		models.OutboxStatusPending, lastError, intervalSeconds(backoff), id, models.OutboxStatusInFlight)
+	return err
+}
+
+// GetStuck implements [OutboxRepository].
+// stuck = belum SENT dan sudah pernah gagal atau dibuat lebih dari stuckAfter yang lalu
+func (r *outboxRepository) GetStuck(stuckAfter time.Duration, limit, offset int) ([]models.OutboxEvent, error) {
+	events := []models.OutboxEvent{}
+	err := r.db.Select(&events, `
+		SELECT `+outboxColumns+`
+		FROM outbox_events
+		WHERE status <> ? AND (attempts > 0 OR created_at < DATE_SUB(NOW(), INTERVAL ? SECOND))
+		ORDER BY id
+		LIMIT ? OFFSET ?`,
+		models.OutboxStatusSent, intervalSeconds(stuckAfter), limit, offset)
+	return events, err
+}
+
+// GetStats implements [OutboxRepository].
+func (r *outboxRepository) GetStats(stuckAfter time.Duration) (models.OutboxStats, error) {
+	var stats models.OutboxStats
+	err := r.db.Get(&stats, `
+		SELECT
+			COUNT(*) AS pending,
+			COALESCE(SUM(status = ?), 0) AS in_flight,
+			COALESCE(SUM(attempts > 0 OR created_at < DATE_SUB(NOW(), INTERVAL ? SECOND)), 0) AS stuck,
+			COALESCE(MAX(attempts), 0) AS max_attempts,
+			MIN(created_at) AS oldest_pending_at
+		FROM outbox_events
+		WHERE status <> ?`,
+		models.OutboxStatusInFlight, intervalSeconds(stuckAfter), models.OutboxStatusSent)
+	return stats, err
+}
+
+// Retry implements [OutboxRepository].
+// false jika event tidak ada atau sudah terkirim
+func (r *outboxRepository) Retry(id int64) (bool, error) {
+	var status string
+	err := r.db.Get(&status, `SELECT status FROM outbox_events WHERE id = ?`, id)
+	if err == sql.ErrNoRows || (err == nil && status != models.OutboxStatusPending) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	_, err = r.db.Exec(`UPDATE outbox_events SET next_attempt_at = NOW() WHERE id = ? AND status = ?`, id, models.OutboxStatusPending)
+	return err == nil, err
+}
+
+// DeleteSentBefore implements [OutboxRepository].
+// hapus event SENT yang terkirim lebih dari retention yang lalu
+func (r *outboxRepository) DeleteSentBefore(retention time.Duration, limit int) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM outbox_events WHERE status = ? AND sent_at < DATE_SUB(NOW(), INTERVAL ? SECOND) LIMIT ?`,
+		models.OutboxStatusSent, intervalSeconds(retention), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// intervalSeconds - durasi untuk INTERVAL ? SECOND, sisa di bawah satu detik dibulatkan ke atas
+func intervalSeconds(d time.Duration) int64 {
+	return int64((d + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+)
+
+type RetentionRepository interface {
+	GetCandlePairs() ([]string, error)
+	GetTickPairs() ([]string, error)
+	// GetOldestTickTime - unix seconds, ok false jika pair belum punya tick
+	GetOldestTickTime(pair string) (int64, bool, error)
+	UpsertTickArchiveWithTx(tx *sqlx.Tx, archive models.TickArchive) error
+	DeleteTicksRangeWithTx(tx *sqlx.Tx, pair string, startTime, endTime int64) (int64, error)
+	GetTickArchives(pair, intervalType string, startTime, endTime int64) ([]models.TickArchive, error)
+	GetTableStorage() ([]models.TableStorage, error)
+	BeginTx() (*sqlx.Tx, error)
+}
+
+type retentionRepository struct {
+	db *sqlx.DB
+}
+
+func NewRetentionRepository(db *sqlx.DB) RetentionRepository {
+	return &retentionRepository{db: db}
+}
+
+// GetCandlePairs implements [RetentionRepository].
+func (r *retentionRepository) GetCandlePairs() ([]string, error) {
+	var pairs []string
+	err := r.db.Select(&pairs, `SELECT DISTINCT pair FROM candles`)
+	return pairs, err
+}
+
+// GetTickPairs implements [RetentionRepository].
+func (r *retentionRepository) GetTickPairs() ([]string, error) {
+	var pairs []string
+	err := r.db.Select(&pairs, `SELECT DISTINCT pair FROM market_ticks`)
+	return pairs, err
+}
+
+// GetOldestTickTime implements [RetentionRepository].
+func (r *retentionRepository) GetOldestTickTime(pair string) (int64, bool, error) {
+	var oldest sql.NullInt64
+
+	err := r.db.Get(&oldest, `SELECT UNIX_TIMESTAMP(MIN(created_at)) FROM market_ticks WHERE pair = ?`, pair)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+
+	return oldest.Int64, oldest.Valid, nil
+}
+
+// UpsertTickArchiveWithTx implements [RetentionRepository].
+func (r *retentionRepository) UpsertTickArchiveWithTx(tx *sqlx.Tx, archive models.TickArchive) error {
+	_, err := tx.NamedExec(
+		`INSERT INTO market_tick_archives (pair, interval_type, bucket_start, open_price, high_price, low_price, close_price, buy_volume, sell_volume, tx_count, tick_count, first_block_id, last_block_id)
+		VALUES (:pair, :interval_type, :bucket_start, :open_price, :high_price, :low_price, :close_price, :buy_volume, :sell_volume, :tx_count, :tick_count, :first_block_id, :last_block_id)
+		ON DUPLICATE KEY UPDATE
+			open_price = open_price,
+			high_price = GREATEST(high_price, VALUES(high_price)),
+			low_price = LEAST(low_price, VALUES(low_price)),
+			close_price = VALUES(close_price),
+			buy_volume = buy_volume + VALUES(buy_volume),
+			sell_volume = sell_volume + VALUES(sell_volume),
+			tx_count = tx_count + VALUES(tx_count),
+			tick_count = tick_count + VALUES(tick_count),
+			last_block_id = GREATEST(last_block_id, VALUES(last_block_id))`,
+		archive,
+	)
+
+	return err
+}
+
+// DeleteTicksRangeWithTx implements [RetentionRepository].
+func (r *retentionRepository) DeleteTicksRangeWithTx(tx *sqlx.Tx, pair string, startTime, endTime int64) (int64, error) {
+	res, err := tx.Exec(
+		`DELETE FROM market_ticks WHERE pair = ? AND created_at >= FROM_UNIXTIME(?) AND created_at < FROM_UNIXTIME(?)`,
+		pair,
+		startTime,
+		endTime,
+	)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// GetTickArchives implements [RetentionRepository].
+func (r *retentionRepository) GetTickArchives(pair, intervalType string, startTime, endTime int64) ([]models.TickArchive, error) {
+	var archives []models.TickArchive
+
+	err := r.db.Select(&archives,
+		`SELECT id, pair, interval_type, bucket_start, open_price, high_price, low_price, close_price, buy_volume, sell_volume, tx_count, tick_count, first_block_id, last_block_id
+		FROM market_tick_archives
+		WHERE pair = ? AND interval_type = ? AND bucket_start >= ? AND bucket_start < ?
+		ORDER BY bucket_start ASC`,
+		pair,
+		intervalType,
+		startTime,
+		endTime,
+	)
+
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	return archives, nil
+}
+
+// GetTableStorage implements [RetentionRepository].
+func (r *retentionRepository) GetTableStorage() ([]models.TableStorage, error) {
+	var tables []models.TableStorage
+
+	err := r.db.Select(&tables,
+		`SELECT TABLE_NAME AS table_name,
+			COALESCE(TABLE_ROWS, 0) AS table_rows,
+			COALESCE(DATA_LENGTH, 0) AS data_bytes,
+			COALESCE(INDEX_LENGTH, 0) AS index_bytes,
+			COALESCE(DATA_LENGTH, 0) + COALESCE(INDEX_LENGTH, 0) AS total_bytes
+		FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = DATABASE()
+		ORDER BY total_bytes DESC`,
+	)
+
+	return tables, err
+}
+
+// BeginTx implements [RetentionRepository].
+func (r *retentionRepository) BeginTx() (*sqlx.Tx, error) {
+	return r.db.Beginx()
+}
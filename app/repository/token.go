@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+)
+
+type TokenRepository interface {
+	BeginTx() (*sqlx.Tx, error)
+	CreateWithTx(tx *sqlx.Tx, token models.Token) error
+	GetBySymbol(symbol string) (models.Token, error)
+	GetBySymbolForUpdateWithTx(tx *sqlx.Tx, symbol string) (models.Token, error)
+	GetAll(status string, limit, offset int) ([]models.Token, error)
+	ActivateWithTx(tx *sqlx.Tx, symbol string, blockID int64) error
+	GetHolders(symbol string, limit, offset int) ([]models.TokenHolder, error)
+	CountHolders(symbol string) (int64, error)
+}
+
+type tokenRepository struct {
+	db *sqlx.DB
+}
+
+func NewTokenRepository(db *sqlx.DB) TokenRepository {
+	return &tokenRepository{db: db}
+}
+
+const tokenColumns = `symbol, name, decimals, total_supply, creator_address, create_tx_id, block_id, status, created_at`
+
+// BeginTx implements [TokenRepository].
+func (r *tokenRepository) BeginTx() (*sqlx.Tx, error) {
+	return r.db.Beginx()
+}
+
+// CreateWithTx implements [TokenRepository].
+func (r *tokenRepository) CreateWithTx(tx *sqlx.Tx, token models.Token) error {
+	_, err := tx.Exec(`
+		INSERT INTO tokens (symbol, name, decimals, total_supply, creator_address, create_tx_id, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		token.Symbol, token.Name, token.Decimals, token.TotalSupply, token.CreatorAddress, token.CreateTxID, models.TokenStatusPending)
+	return err
+}
+
+// GetBySymbol implements [TokenRepository].
+func (r *tokenRepository) GetBySymbol(symbol string) (models.Token, error) {
+	var token models.Token
+	err := r.db.Get(&token, `SELECT `+tokenColumns+` FROM tokens WHERE symbol = ?`, symbol)
+
+	if err == sql.ErrNoRows {
+		return models.Token{}, entity.ErrTokenNotFound
+	}
+
+	return token, err
+}
+
+// GetBySymbolForUpdateWithTx implements [TokenRepository].
+func (r *tokenRepository) GetBySymbolForUpdateWithTx(tx *sqlx.Tx, symbol string) (models.Token, error) {
+	var token models.Token
+	err := tx.Get(&token, `SELECT `+tokenColumns+` FROM tokens WHERE symbol = ? FOR UPDATE`, symbol)
+
+	if err == sql.ErrNoRows {
+		return models.Token{}, entity.ErrTokenNotFound
+	}
+
+	return token, err
+}
+
+// GetAll implements [TokenRepository].
+func (r *tokenRepository) GetAll(status string, limit, offset int) ([]models.Token, error) {
+	tokens := []models.Token{}
+
+	if status != "" {
+		err := r.db.Select(&tokens, `SELECT `+tokenColumns+` FROM tokens WHERE status = ? ORDER BY created_at DESC LIMIT ? OFFSET ?`, status, limit, offset)
+		return tokens, err
+	}
+
+	err := r.db.Select(&tokens, `SELECT `+tokenColumns+` FROM tokens ORDER BY created_at DESC LIMIT ? OFFSET ?`, limit, offset)
+	return tokens, err
+}
+
+// ActivateWithTx implements [TokenRepository].
+func (r *tokenRepository) ActivateWithTx(tx *sqlx.Tx, symbol string, blockID int64) error {
+	_, err := tx.Exec(`UPDATE tokens SET status = ?, block_id = ? WHERE symbol = ?`, models.TokenStatusActive, blockID, symbol)
+	return err
+}
+
+// GetHolders implements [TokenRepository].
+func (r *tokenRepository) GetHolders(symbol string, limit, offset int) ([]models.TokenHolder, error) {
+	holders := []models.TokenHolder{}
+	err := r.db.Select(&holders, `
+		SELECT user_address, balance
+		FROM asset_balances
+		WHERE asset_symbol = ? AND balance > 0
+		ORDER BY balance DESC, user_address ASC
+		LIMIT ? OFFSET ?`, symbol, limit, offset)
+	return holders, err
+}
+
+// CountHolders implements [TokenRepository].
+func (r *tokenRepository) CountHolders(symbol string) (int64, error) {
+	var total int64
+	err := r.db.Get(&total, `SELECT COUNT(*) FROM asset_balances WHERE asset_symbol = ? AND balance > 0`, symbol)
+	return total, err
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+)
+
+type TradingHaltRepository interface {
+	Create(halt models.TradingHalt) (int64, error)
+	GetByID(id int64) (models.TradingHalt, error)
+	GetOpen() (models.TradingHalt, bool, error)
+	UpdateResumeAt(id int64, resumeAt *time.Time) error
+	MarkResumed(id int64, resumedBy *int) error
+	GetHistory(limit, offset int) ([]models.TradingHalt, error)
+}
+
+type tradingHaltRepository struct {
+	db *sqlx.DB
+}
+
+func NewTradingHaltRepository(db *sqlx.DB) TradingHaltRepository {
+	return &tradingHaltRepository{db: db}
+}
+
+const tradingHaltColumns = `id, pair, source, reason, price_change_percent, threshold_percent, block_number,
+	halted_by, halted_at, resume_at, resumed_at, resumed_by`
+
+// Create implements [TradingHaltRepository].
+func (r *tradingHaltRepository) Create(halt models.TradingHalt) (int64, error) {
+	result, err := r.db.Exec(`
+		INSERT INTO trading_halts (pair, source, reason, price_change_percent, threshold_percent, block_number, halted_by, halted_at, resume_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		halt.Pair, halt.Source, halt.Reason, halt.PriceChangePercent, halt.ThresholdPercent, halt.BlockNumber,
+		halt.HaltedBy, halt.HaltedAt, halt.ResumeAt)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+// GetByID implements [TradingHaltRepository].
+func (r *tradingHaltRepository) GetByID(id int64) (models.TradingHalt, error) {
+	var halt models.TradingHalt
+	err := r.db.Get(&halt, `SELECT `+tradingHaltColumns+` FROM trading_halts WHERE id = ?`, id)
+	return halt, err
+}
+
+// GetOpen implements [TradingHaltRepository].
+// halt terakhir yang belum di-resume
+func (r *tradingHaltRepository) GetOpen() (models.TradingHalt, bool, error) {
+	var halt models.TradingHalt
+	err := r.db.Get(&halt, `SELECT `+tradingHaltColumns+` FROM trading_halts WHERE resumed_at IS NULL ORDER BY id DESC LIMIT 1`)
+
+	if err == sql.ErrNoRows {
+		return models.TradingHalt{}, false, nil
+	}
+
+	if err != nil {
+		return models.TradingHalt{}, false, err
+	}
+
+	return halt, true, nil
+}
+
+// UpdateResumeAt implements [TradingHaltRepository].
+func (r *tradingHaltRepository) UpdateResumeAt(id int64, resumeAt *time.Time) error {
+	_, err := r.db.Exec(`UPDATE trading_halts SET resume_at = ? WHERE id = ?`, resumeAt, id)
+	return err
+}
+
+// MarkResumed implements [TradingHaltRepository].
+func (r *tradingHaltRepository) MarkResumed(id int64, resumedBy *int) error {
+	_, err := r.db.Exec(`UPDATE trading_halts SET resumed_at = NOW(), resumed_by = ? WHERE id = ? AND resumed_at IS NULL`, resumedBy, id)
+	return err
+}
+
+// GetHistory implements [TradingHaltRepository].
+func (r *tradingHaltRepository) GetHistory(limit, offset int) ([]models.TradingHalt, error) {
+	halts := []models.TradingHalt{}
+	err := r.db.Select(&halts, `SELECT `+tradingHaltColumns+` FROM trading_halts ORDER BY id DESC LIMIT ? OFFSET ?`, limit, offset)
+	return halts, err
+}
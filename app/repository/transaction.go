@@ -18,9 +18,11 @@ type TransactionRepository interface {
 	BulkMarkConfirmedWithTx(dbTx *sqlx.Tx, txIDs []int64) error
 	GetPendingTransactionsByAddress(address string) (float64, error)
 	GetPendingBuyCostByBuyer(address string) (float64, error)
+	GetPendingTokenAmountByAddress(address, symbol string) (float64, error)
 	GetTransactionsByBlockID(blockID int64) ([]models.Transaction, error)
 	GetTransactionByID(id int64) (models.Transaction, error)
 	GetTransactionByAddress(filter models.TransactionFilter) (models.TransactionWithTypeResponse, error)
+	GetPendingDepth() ([]models.DepthLevel, error)
 }
 
 type transactionRepository struct {
@@ -35,11 +37,11 @@ func NewTransactionRepository(db *sqlx.DB) TransactionRepository {
 
 func (r *transactionRepository) CreateWithTx(dbTx *sqlx.Tx, transaction models.Transaction) (int64, error) {
 	query := `
-		INSERT INTO transactions (from_address, to_address, amount, fee, type, signature, status)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO transactions (from_address, to_address, amount, fee, type, token_symbol, signature, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	result, err := dbTx.Exec(query, transaction.FromAddress, transaction.ToAddress, transaction.Amount, transaction.Fee, transaction.Type, transaction.Signature, transaction.Status)
+	result, err := dbTx.Exec(query, transaction.FromAddress, transaction.ToAddress, transaction.Amount, transaction.Fee, transaction.Type, transaction.TokenSymbol, transaction.Signature, transaction.Status)
 	if err != nil {
 		return 0, err
 	}
@@ -54,11 +56,11 @@ func (r *transactionRepository) CreateWithTx(dbTx *sqlx.Tx, transaction models.T
 
 func (r *transactionRepository) Create(transaction models.Transaction) (int64, error) {
 	query := `
-		INSERT INTO transactions (from_address, to_address, amount, fee, type, signature, status)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO transactions (from_address, to_address, amount, fee, type, token_symbol, signature, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	result, err := r.db.Exec(query, transaction.FromAddress, transaction.ToAddress, transaction.Amount, transaction.Fee, transaction.Type, transaction.Signature, transaction.Status)
+	result, err := r.db.Exec(query, transaction.FromAddress, transaction.ToAddress, transaction.Amount, transaction.Fee, transaction.Type, transaction.TokenSymbol, transaction.Signature, transaction.Status)
 	if err != nil {
 		return 0, err
 	}
@@ -86,7 +88,7 @@ func (r *transactionRepository) GetPendingTransactionsWithTx(dbTx *sqlx.Tx) ([]m
 	var list []models.Transaction
 
 	query := `
-        SELECT id, from_address, to_address, amount, fee, type, signature, status 
+        SELECT id, from_address, to_address, amount, fee, type, token_symbol, signature, status 
         FROM transactions 
         WHERE TRIM(status) = 'PENDING'
         ORDER BY id ASC
@@ -123,7 +125,7 @@ func (r *transactionRepository) GetPendingTransactions(limit int) ([]models.Tran
 	var list []models.Transaction
 
 	query := `
-        SELECT id, from_address, to_address, amount, fee, type, signature, status 
+        SELECT id, from_address, to_address, amount, fee, type, token_symbol, signature, status 
         FROM transactions 
         WHERE TRIM(status) = 'PENDING'
         ORDER BY id ASC
@@ -159,9 +161,12 @@ func (r *transactionRepository) BulkMarkConfirmedWithTx(dbTx *sqlx.Tx, txIDs []i
 	return err
 }
 
+// GetPendingTransactionsByAddress - total YTE yang masih pending, transaksi token hanya menghitung fee
 func (r *transactionRepository) GetPendingTransactionsByAddress(address string) (float64, error) {
 	query := `
-		SELECT COALESCE(SUM(amount + fee), 0) as pending_amount
+		SELECT COALESCE(SUM(
+			CASE WHEN type IN ('CREATE_TOKEN', 'TOKEN_TRANSFER') THEN fee ELSE amount + fee END
+		), 0) as pending_amount
 		FROM transactions
 		WHERE from_address = ? AND status = 'PENDING'
 	`
@@ -185,11 +190,24 @@ func (r *transactionRepository) GetPendingBuyCostByBuyer(address string) (float6
 	return pendingAmount, err
 }
 
+func (r *transactionRepository) GetPendingTokenAmountByAddress(address, symbol string) (float64, error) {
+	query := `
+		SELECT COALESCE(SUM(amount), 0) as pending_amount
+		FROM transactions
+		WHERE from_address = ? AND token_symbol = ? AND type = 'TOKEN_TRANSFER' AND status = 'PENDING'
+	`
+
+	var pendingAmount float64
+
+	err := r.db.Get(&pendingAmount, query, address, symbol)
+	return pendingAmount, err
+}
+
 func (r *transactionRepository) GetTransactionsByBlockID(blockID int64) ([]models.Transaction, error) {
 	var transaction []models.Transaction
 
 	query := `
-		SELECT tx.id, tx.from_address, tx.to_address, tx.amount, tx.fee, tx.type, tx.signature, tx.status
+		SELECT tx.id, tx.from_address, tx.to_address, tx.amount, tx.fee, tx.type, tx.token_symbol, tx.signature, tx.status
 		FROM transactions as tx
 		JOIN block_transactions as bt ON tx.id = bt.transaction_id
 		WHERE bt.block_id = ?
@@ -203,7 +221,7 @@ func (r *transactionRepository) GetTransactionByID(id int64) (models.Transaction
 	var transaction models.Transaction
 
 	query := `
-		SELECT id, from_address, to_address, amount, fee, type, signature, status
+		SELECT id, from_address, to_address, amount, fee, type, token_symbol, signature, status
 		FROM transactions
 		WHERE id = ?
 	`
@@ -278,7 +296,7 @@ func (r *transactionRepository) GetTransactionByAddress(filter models.Transactio
 			WHEN to_address = 'MINER_ACCOUNT' THEN 'SELLER SYSTEM'
 			ELSE to_address
 		END AS to_address,
-		amount, fee, signature, status, 
+		amount, fee, token_symbol, signature, status, 
 		CASE 
 			WHEN LOWER(type) = 'transfer' THEN
 				CASE
@@ -304,3 +322,22 @@ func (r *transactionRepository) GetTransactionByAddress(filter models.Transactio
 
 	return result, nil
 }
+
+// GetPendingDepth - BUY/SELL pending dikelompokkan per tier ukuran order
+func (r *transactionRepository) GetPendingDepth() ([]models.DepthLevel, error) {
+	levels := []models.DepthLevel{}
+
+	query := `
+		SELECT type AS side, CAST(FLOOR(LOG10(amount)) AS SIGNED) AS tier, COUNT(*) AS orders, SUM(amount) AS amount
+		FROM transactions
+		WHERE status = 'PENDING' AND type IN ('BUY', 'SELL') AND amount > 0
+		GROUP BY side, tier
+		ORDER BY side ASC, tier ASC
+	`
+
+	if err := r.db.Select(&levels, query); err != nil {
+		return nil, err
+	}
+
+	return levels, nil
+}
@@ -10,6 +10,7 @@ import (
 
 type UserRepository interface {
 	Create(user models.User) error
+	CreateWithTx(tx *sqlx.Tx, user models.User) error
 	GetByAddress(address string) (models.User, error)
 	GetByAddressWithBalance(address string) (models.UserWithBalance, error)
 	BeginTx() (*sqlx.Tx, error)
@@ -42,6 +43,16 @@ func (r *userRepository) Create(user models.User) error {
 	return err
 }
 
+func (r *userRepository) CreateWithTx(tx *sqlx.Tx, user models.User) error {
+	query := `
+		INSERT INTO users (name, address, public_key)
+		VALUES (?, ?, ?)
+	`
+
+	_, err := tx.Exec(query, user.Name, user.Address, user.PublicKey)
+	return err
+}
+
 func (r *userRepository) GetByAddress(address string) (models.User, error) {
 	var user models.User
 
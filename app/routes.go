@@ -25,6 +25,24 @@ func (a *AppConfig) SetupRoutes(r *gin.Engine) {
 		txGroup.POST("/sell", a.TransactionHandler.Sell)
 	}
 
+	// Token routes (token fungible buatan user)
+	tokenGroup := r.Group("/tokens")
+	{
+		tokenGroup.POST("", a.TokenHandler.CreateToken)
+		tokenGroup.POST("/transfer", a.TokenHandler.TransferToken)
+		tokenGroup.GET("", a.TokenHandler.ListTokens)
+		tokenGroup.GET("/:symbol", a.TokenHandler.GetToken)
+		tokenGroup.GET("/:symbol/holders", a.TokenHandler.GetHolders)
+	}
+
+	// Conditional order routes
+	condOrderGroup := r.Group("/orders/conditional")
+	{
+		condOrderGroup.POST("", a.CondOrderHandler.PlaceOrder)
+		condOrderGroup.GET("/:address", a.CondOrderHandler.GetOrders)
+		condOrderGroup.DELETE("/:id", handler.JWTMiddleware(a.JWT), a.CondOrderHandler.CancelOrder)
+	}
+
 	// Admin Auth routes (public - no middleware required)
 	adminAuthGroup := r.Group("/admin/auth")
 	{
@@ -44,6 +62,56 @@ func (a *AppConfig) SetupRoutes(r *gin.Engine) {
 		adminGroup.DELETE("/admins/:id", a.AdminHandler.DeleteAdmin)
 		adminGroup.GET("/activity-logs", a.AdminHandler.GetActivityLogs)
 		adminGroup.GET("/activity-logs/recent", a.AdminHandler.RecentActivityLogs)
+
+		// Trading bots
+		adminGroup.GET("/bots", a.BotHandler.ListBots)
+		adminGroup.POST("/bots", a.BotHandler.CreateBot)
+		adminGroup.PUT("/bots/:id/config", a.BotHandler.ConfigureBot)
+		adminGroup.POST("/bots/:id/start", a.BotHandler.StartBot)
+		adminGroup.POST("/bots/:id/stop", a.BotHandler.StopBot)
+
+		// Assets & trading pairs
+		adminGroup.POST("/assets", a.AssetHandler.CreateAsset)
+
+		// Trading halt & circuit breaker
+		adminGroup.GET("/trading/status", a.TradingHandler.GetStatus)
+		adminGroup.POST("/trading/halt", a.TradingHandler.Halt)
+		adminGroup.POST("/trading/resume", a.TradingHandler.Resume)
+		adminGroup.GET("/trading/halts", a.TradingHandler.History)
+		adminGroup.GET("/trading/circuit-breaker", a.TradingHandler.GetConfig)
+		adminGroup.PUT("/trading/circuit-breaker", a.TradingHandler.UpdateConfig)
+
+		// Storage & retention
+		adminGroup.GET("/storage", a.RetentionHandler.StorageStats)
+		adminGroup.GET("/retention", a.RetentionHandler.GetConfig)
+		adminGroup.PUT("/retention", a.RetentionHandler.UpdateConfig)
+		adminGroup.POST("/retention/run", a.RetentionHandler.RunNow)
+		adminGroup.GET("/retention/tick-archives", a.RetentionHandler.GetTickArchives)
+
+		// Transactional outbox
+		adminGroup.GET("/outbox", a.OutboxHandler.GetOutbox)
+		adminGroup.POST("/outbox/:id/retry", a.OutboxHandler.RetryEvent)
+
+		// Dead letter queues
+		adminGroup.GET("/dead-letters", a.DeadLetterHandler.Stats)
+		adminGroup.GET("/dead-letters/:queue", a.DeadLetterHandler.Peek)
+		adminGroup.POST("/dead-letters/:queue/replay", a.DeadLetterHandler.Replay)
+		adminGroup.DELETE("/dead-letters/:queue", a.DeadLetterHandler.Purge)
+
+		// Ledger replay & rebuild materialized state
+		adminGroup.GET("/ledger/rebuild", a.RebuildHandler.LastReport)
+		adminGroup.POST("/ledger/rebuild", a.RebuildHandler.Rebuild)
+
+		// WebSocket delivery metrics
+		adminGroup.GET("/websocket/metrics", a.WebSocketHandler.Metrics)
+
+		// Market replay / backtest
+		adminGroup.GET("/replays", a.ReplayHandler.ListReplays)
+		adminGroup.POST("/replays", a.ReplayHandler.StartReplay)
+		adminGroup.POST("/replays/import", a.ReplayHandler.ImportReplay)
+		adminGroup.GET("/replays/:id", a.ReplayHandler.GetReplay)
+		adminGroup.POST("/replays/:id/stop", a.ReplayHandler.StopReplay)
+		adminGroup.DELETE("/replays/:id", a.ReplayHandler.DeleteReplay)
 	}
 
 	// Nonce generation
@@ -53,6 +121,7 @@ func (a *AppConfig) SetupRoutes(r *gin.Engine) {
 	balanceGroup := r.Group("/balance")
 	{
 		balanceGroup.GET("/:address", a.BalanceHandler.GetUserWithUSDBalance)
+		balanceGroup.GET("/:address/assets", a.AssetHandler.GetBalances)
 		balanceGroup.POST("/topup", a.BalanceHandler.TopUpUSDBalance)
 	}
 
@@ -84,17 +153,34 @@ func (a *AppConfig) SetupRoutes(r *gin.Engine) {
 
 	// Market routes
 	r.GET("/market", a.MarketHandler.GetMarketEngineState)
+	r.GET("/markets", a.MarketHandler.ListMarkets)
+	r.GET("/market/:pair", a.MarketHandler.GetMarketByPair)
+	r.GET("/trading/status", a.TradingHandler.GetStatus)
+
+	// Asset routes
+	r.GET("/assets", a.AssetHandler.ListAssets)
+	r.GET("/pairs", a.AssetHandler.ListPairs)
 
 	// Candle routes
 	candleGroup := r.Group("/candles")
 	{
 		candleGroup.GET("", a.CandleHandler.GetCandle)
 		candleGroup.GET("/range", a.CandleHandler.GetCandleFrom)
+		candleGroup.GET("/indicators", a.CandleHandler.GetIndicator)
+	}
+
+	// Export routes (streaming CSV / NDJSON)
+	exportGroup := r.Group("/export")
+	{
+		exportGroup.GET("/candles", a.ExportHandler.ExportCandles)
+		exportGroup.GET("/ticks", a.ExportHandler.ExportTicks)
+		exportGroup.GET("/trades", a.ExportHandler.ExportTrades)
 	}
 
 	// Streaming routes
 	r.GET("/sse/candles", a.CandleStreamHandler.StreamCandles)
 	r.GET("/sse/ping", a.CandleStreamHandler.Ping)
+	r.GET("/sse/trading-status", a.TradingHandler.StreamStatus)
 
 	// WebSocket routes
 	r.GET("/ws/market", a.setupWebSocketHandler())
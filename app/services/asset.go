@@ -0,0 +1,158 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/livingdolls/go-blockchain-simulate/app/dto"
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+	"github.com/livingdolls/go-blockchain-simulate/app/repository"
+)
+
+var assetSymbolPattern = regexp.MustCompile(`^[A-Z0-9]{2,16}$`)
+
+type AssetService interface {
+	ListAssets() ([]models.Asset, error)
+	GetAsset(symbol string) (models.Asset, error)
+	CreateAsset(req dto.CreateAssetRequest) (models.Asset, error)
+	ListPairs() ([]models.TradingPair, error)
+	ListActivePairSymbols() ([]string, error)
+	GetPair(symbol string) (models.TradingPair, error)
+	GetBalances(address string) (dto.AssetBalancesResponse, error)
+}
+
+type assetService struct {
+	assets   repository.AssetRepository
+	users    repository.UserRepository
+	wallets  repository.UserWalletRepository
+	balances repository.UserBalanceRepository
+}
+
+func NewAssetService(assets repository.AssetRepository, users repository.UserRepository, wallets repository.UserWalletRepository, balances repository.UserBalanceRepository) AssetService {
+	return &assetService{
+		assets:   assets,
+		users:    users,
+		wallets:  wallets,
+		balances: balances,
+	}
+}
+
+// NormalizePair - "yte_usd", "yte/usd", "YTE-USD" -> "YTE-USD", kosong -> pair native
+func NormalizePair(pair string) string {
+	pair = strings.ToUpper(strings.TrimSpace(pair))
+	if pair == "" {
+		return models.DefaultPair
+	}
+
+	return strings.NewReplacer("/", "-", "_", "-").Replace(pair)
+}
+
+// EnsureTradablePair - BUY/SELL hanya dieksekusi di pair native (YTE-USD). order execution dan
+// pricing per block hanya ada untuk pair native, jadi pair baru juga tidak bisa didaftarkan
+func EnsureTradablePair(pair string) error {
+	if normalized := NormalizePair(pair); normalized != models.DefaultPair {
+		return fmt.Errorf("%w: %s", entity.ErrPairNotTradable, normalized)
+	}
+
+	return nil
+}
+
+// ListAssets implements [AssetService].
+func (s *assetService) ListAssets() ([]models.Asset, error) {
+	return s.assets.GetAssets()
+}
+
+// GetAsset implements [AssetService].
+func (s *assetService) GetAsset(symbol string) (models.Asset, error) {
+	return s.assets.GetAsset(strings.ToUpper(strings.TrimSpace(symbol)))
+}
+
+// CreateAsset implements [AssetService].
+func (s *assetService) CreateAsset(req dto.CreateAssetRequest) (models.Asset, error) {
+	symbol := strings.ToUpper(strings.TrimSpace(req.Symbol))
+
+	if !assetSymbolPattern.MatchString(symbol) {
+		return models.Asset{}, fmt.Errorf("%w: symbol must be 2-16 alphanumeric characters", entity.ErrInvalidAsset)
+	}
+
+	if req.Decimals < 0 || req.Decimals > 18 {
+		return models.Asset{}, fmt.Errorf("%w: decimals must be between 0 and 18", entity.ErrInvalidAsset)
+	}
+
+	if _, err := s.assets.GetAsset(symbol); err == nil {
+		return models.Asset{}, fmt.Errorf("%w: asset %s already exists", entity.ErrConflict, symbol)
+	}
+
+	asset := models.Asset{
+		Symbol:   symbol,
+		Name:     strings.TrimSpace(req.Name),
+		Decimals: req.Decimals,
+	}
+
+	if err := s.assets.CreateAsset(asset); err != nil {
+		return models.Asset{}, fmt.Errorf("create asset: %v", err)
+	}
+
+	return s.assets.GetAsset(symbol)
+}
+
+// ListPairs implements [AssetService].
+func (s *assetService) ListPairs() ([]models.TradingPair, error) {
+	return s.assets.GetPairs("")
+}
+
+// ListActivePairSymbols implements [AssetService].
+func (s *assetService) ListActivePairSymbols() ([]string, error) {
+	pairs, err := s.assets.GetPairs(models.AssetStatusActive)
+	if err != nil {
+		return nil, err
+	}
+
+	symbols := make([]string, 0, len(pairs))
+	for _, p := range pairs {
+		symbols = append(symbols, p.Symbol)
+	}
+
+	return symbols, nil
+}
+
+// GetPair implements [AssetService].
+func (s *assetService) GetPair(symbol string) (models.TradingPair, error) {
+	return s.assets.GetPair(NormalizePair(symbol))
+}
+
+// GetBalances implements [AssetService].
+// YTE dari user_wallets, USD dari user_balances, asset lain dari asset_balances
+func (s *assetService) GetBalances(address string) (dto.AssetBalancesResponse, error) {
+	if _, err := s.users.GetByAddress(address); err != nil {
+		return dto.AssetBalancesResponse{}, entity.ErrAddressNotFound
+	}
+
+	result := dto.AssetBalancesResponse{
+		Address:  address,
+		Balances: []dto.AssetBalanceItem{},
+	}
+
+	wallet, err := s.wallets.GetByAddress(address)
+	if err == nil {
+		result.Balances = append(result.Balances, dto.AssetBalanceItem{Asset: models.NativeAsset, Balance: wallet.YTEBalance})
+	}
+
+	usd, err := s.balances.GetByAddress(address)
+	if err == nil {
+		result.Balances = append(result.Balances, dto.AssetBalanceItem{Asset: models.QuoteAsset, Balance: usd.USDBalance})
+	}
+
+	others, err := s.assets.GetBalances(address)
+	if err != nil {
+		return dto.AssetBalancesResponse{}, fmt.Errorf("get asset balances: %v", err)
+	}
+
+	for _, b := range others {
+		result.Balances = append(result.Balances, dto.AssetBalanceItem{Asset: b.AssetSymbol, Balance: b.Balance})
+	}
+
+	return result, nil
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/livingdolls/go-blockchain-simulate/app/dto"
 	"github.com/livingdolls/go-blockchain-simulate/app/entity"
 	"github.com/livingdolls/go-blockchain-simulate/app/models"
@@ -18,6 +19,7 @@ type BalanceService interface {
 	GetUserWithUSDBalance(address string) (dto.DTOUserWithBalance, error)
 	GetWalletBalance(filter models.TransactionFilter) (models.WalletResponse, error)
 	TopUpUSDBalance(address string, amount float64, referenceID, description string) (dto.TopUpResultDTO, error)
+	TopUpUSDBalanceWithTx(tx *sqlx.Tx, address string, amount float64, referenceID, description string) (dto.TopUpResultDTO, error)
 }
 
 type balanceService struct {
@@ -84,6 +86,47 @@ func (s *balanceService) TopUpUSDBalance(address string, amount float64, referen
 	}
 	defer tx.Rollback()
 
+	result, err := s.TopUpUSDBalanceWithTx(tx, address, amount, referenceID, description)
+	if err != nil {
+		return dto.TopUpResultDTO{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return dto.TopUpResultDTO{}, fmt.Errorf("commit tx: %v", err)
+	}
+
+	// Notify via WebSocket
+	// prepare data
+	data, err := s.users.GetByAddressWithBalance(address)
+	if err != nil {
+		// don't fail the top-up just because notification fails
+		logger.LogError("failed to get user data for notification", err)
+	} else {
+		logger.LogInfo("Publishing balance update for address", zap.String("address", address), zap.Any("data", data))
+		dtoResult := dto.DTOUserWithBalance{
+			Name:       data.Name,
+			Address:    data.Address,
+			YTEBalance: data.YTEBalance,
+			USDBalance: data.USDBalance,
+		}
+
+		s.publisherWs.PublishToAddress(address, entity.EventBalanceUpdate, dtoResult)
+	}
+
+	return result, nil
+}
+
+// TopUpUSDBalanceWithTx menambah saldo USD di dalam tx milik caller tanpa commit dan tanpa notifikasi WebSocket.
+// Caller bertanggung jawab memastikan user ada (bisa saja baru dibuat di tx yang sama)
+func (s *balanceService) TopUpUSDBalanceWithTx(tx *sqlx.Tx, address string, amount float64, referenceID, description string) (dto.TopUpResultDTO, error) {
+	if address == "" {
+		return dto.TopUpResultDTO{}, entity.ErrAddressNotFound
+	}
+
+	if amount <= 0 {
+		return dto.TopUpResultDTO{}, entity.ErrAmountMustBePositive
+	}
+
 	if err := s.userBalances.UpsertEmptyIfNotExistsWithTx(tx, address); err != nil {
 		return dto.TopUpResultDTO{}, fmt.Errorf("upsert empty user balance: %v", err)
 	}
@@ -129,38 +172,14 @@ func (s *balanceService) TopUpUSDBalance(address string, amount float64, referen
 		return dto.TopUpResultDTO{}, fmt.Errorf("insert balance history: %v", err)
 	}
 
-	if err := tx.Commit(); err != nil {
-		return dto.TopUpResultDTO{}, fmt.Errorf("commit tx: %v", err)
-	}
-
-	result := dto.TopUpResultDTO{
+	return dto.TopUpResultDTO{
 		Address:       address,
 		Amount:        amount,
 		BalanceBefore: balanceBefore,
 		BalanceAfter:  afterBalance,
 		ReferenceID:   refPtr,
 		Description:   descPtr,
-	}
-
-	// Notify via WebSocket
-	// prepare data
-	data, err := s.users.GetByAddressWithBalance(address)
-	if err != nil {
-		// don't fail the top-up just because notification fails
-		logger.LogError("failed to get user data for notification", err)
-	} else {
-		logger.LogInfo("Publishing balance update for address", zap.String("address", address), zap.Any("data", data))
-		dtoResult := dto.DTOUserWithBalance{
-			Name:       data.Name,
-			Address:    data.Address,
-			YTEBalance: data.YTEBalance,
-			USDBalance: data.USDBalance,
-		}
-
-		s.publisherWs.PublishToAddress(address, entity.EventBalanceUpdate, dtoResult)
-	}
-
-	return result, nil
+	}, nil
 }
 
 func (s *balanceService) GetUserWithUSDBalance(address string) (dto.DTOUserWithBalance, error) {
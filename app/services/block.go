@@ -12,6 +12,7 @@ import (
 	"github.com/livingdolls/go-blockchain-simulate/app/publisher"
 	"github.com/livingdolls/go-blockchain-simulate/app/repository"
 	"github.com/livingdolls/go-blockchain-simulate/logger"
+	"github.com/livingdolls/go-blockchain-simulate/rabbitmq"
 	"github.com/livingdolls/go-blockchain-simulate/utils"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
@@ -32,32 +33,34 @@ type BlockService interface {
 }
 
 type blockService struct {
-	blockRepo        repository.BlockRepository
-	walletRepo       repository.UserWalletRepository
-	balanceRepo      repository.UserBalanceRepository
-	txRepo           repository.TransactionRepository
-	userRepo         repository.UserRepository
-	candle           CandleService
-	market           MarketEngineService
-	publisherWS      *publisher.PublisherWS
-	pricingPublisher MarketPricingPublisher
-	ledgerPublisher  LedgerPublisher
-	rewardPublisher  RewardPublisher
+	blockRepo    repository.BlockRepository
+	walletRepo   repository.UserWalletRepository
+	balanceRepo  repository.UserBalanceRepository
+	txRepo       repository.TransactionRepository
+	userRepo     repository.UserRepository
+	ledgerRepo   repository.LedgerRepository
+	candle       CandleService
+	market       MarketEngineService
+	publisherWS  *publisher.PublisherWS
+	outbox       OutboxService
+	tokens       TokenService
+	marketStream MarketStreamService
 }
 
-func NewBlockService(blockRepo repository.BlockRepository, walletRepo repository.UserWalletRepository, balanceRepo repository.UserBalanceRepository, txRepo repository.TransactionRepository, userRepo repository.UserRepository, candle CandleService, market MarketEngineService, publisherWS *publisher.PublisherWS, pricingPublisher MarketPricingPublisher, ledgerPublisher LedgerPublisher, rewardPublisher RewardPublisher) BlockService {
+func NewBlockService(blockRepo repository.BlockRepository, walletRepo repository.UserWalletRepository, balanceRepo repository.UserBalanceRepository, txRepo repository.TransactionRepository, userRepo repository.UserRepository, ledgerRepo repository.LedgerRepository, candle CandleService, market MarketEngineService, publisherWS *publisher.PublisherWS, outbox OutboxService, tokens TokenService, marketStream MarketStreamService) BlockService {
 	return &blockService{
-		blockRepo:        blockRepo,
-		walletRepo:       walletRepo,
-		balanceRepo:      balanceRepo,
-		txRepo:           txRepo,
-		userRepo:         userRepo,
-		candle:           candle,
-		market:           market,
-		publisherWS:      publisherWS,
-		pricingPublisher: pricingPublisher,
-		ledgerPublisher:  ledgerPublisher,
-		rewardPublisher:  rewardPublisher,
+		blockRepo:    blockRepo,
+		walletRepo:   walletRepo,
+		balanceRepo:  balanceRepo,
+		txRepo:       txRepo,
+		userRepo:     userRepo,
+		ledgerRepo:   ledgerRepo,
+		candle:       candle,
+		market:       market,
+		publisherWS:  publisherWS,
+		outbox:       outbox,
+		tokens:       tokens,
+		marketStream: marketStream,
 	}
 }
 
@@ -169,15 +172,23 @@ func (s *blockService) GenerateBlock() (models.Block, error) {
 		}
 
 		// calculato total deduction amount + fee
-		totalDeduction := t.Amount + t.Fee
+		amount := nativeAmount(t)
+		totalDeduction := amount + t.Fee
 
 		if balances[sender.Address] < totalDeduction {
 			return models.Block{}, fmt.Errorf("insufficient balance for address %s: need %.8f (amount: %.8f + fee: %.8f), have %.8f",
-				sender.Address, totalDeduction, t.Amount, t.Fee, balances[sender.Address])
+				sender.Address, totalDeduction, amount, t.Fee, balances[sender.Address])
 		}
 
 		balances[t.FromAddress] -= totalDeduction // - amount + fee
-		balances[t.ToAddress] += t.Amount
+		balances[t.ToAddress] += amount
+	}
+
+	// validasi saldo token (CREATE_TOKEN / TOKEN_TRANSFER)
+	if s.tokens != nil {
+		if err := s.tokens.ValidateBlockTokens(pendingTxs); err != nil {
+			return models.Block{}, fmt.Errorf("validate token transactions: %w", err)
+		}
 	}
 
 	// MINING PHASE : Prof of Work
@@ -267,23 +278,25 @@ func (s *blockService) GenerateBlock() (models.Block, error) {
 	var txIDs []int64
 	totalFees := 0.00000000
 
-	// Calculate balances per transaction
-	txBalanceChanges := make(map[string]float64)
+	// Calculate balances per transaction (running balance, satu address bisa muncul di beberapa tx)
+	txBalanceChanges := make([]txBalanceSnapshot, 0, len(pendingTxs))
 	for _, t := range pendingTxs {
-		totalDeduction := t.Amount + t.Fee
-		txBalanceChanges[t.FromAddress] = currentBalances[t.FromAddress] - totalDeduction
-		txBalanceChanges[t.ToAddress] = currentBalances[t.ToAddress] + t.Amount
-		txBalanceChanges["MINER_ACCOUNT"] = currentBalances["MINER_ACCOUNT"] + t.Fee
+		amount := nativeAmount(t)
+		currentBalances[t.FromAddress] -= amount + t.Fee
+		fromAfter := currentBalances[t.FromAddress]
+		currentBalances[t.ToAddress] += amount
+		currentBalances["MINER_ACCOUNT"] += t.Fee
+
+		txBalanceChanges = append(txBalanceChanges, txBalanceSnapshot{
+			from:  fromAfter,
+			to:    currentBalances[t.ToAddress],
+			miner: currentBalances["MINER_ACCOUNT"],
+		})
 
 		totalFees += t.Fee
 		txIDs = append(txIDs, t.ID)
 	}
 
-	// Update currentBalances
-	for addr, bal := range txBalanceChanges {
-		currentBalances[addr] = bal
-	}
-
 	// Create block FIRST to get blockID
 	newBlock := models.Block{
 		BlockNumber:  nextBlockNumber,
@@ -305,32 +318,45 @@ func (s *blockService) GenerateBlock() (models.Block, error) {
 	newBlock.ID = blockID
 
 	// NOW create ledger entries dengan blockID yang sudah ada
-	for _, t := range pendingTxs {
+	for i, t := range pendingTxs {
 		txID := t.ID
 		txIDPtr := &txID
-		totalDeduction := t.Amount + t.Fee
+		amount := nativeAmount(t)
+		snapshot := txBalanceChanges[i]
 
 		ledgerEntries = append(ledgerEntries,
 			repository.LedgerEntry{
 				BlockID:      blockID,
 				TxID:         txIDPtr,
 				Address:      t.FromAddress,
-				Amount:       -totalDeduction,
-				BalanceAfter: txBalanceChanges[t.FromAddress],
-			},
-			repository.LedgerEntry{
-				BlockID:      blockID,
-				TxID:         txIDPtr,
-				Address:      t.ToAddress,
-				Amount:       t.Amount,
-				BalanceAfter: txBalanceChanges[t.ToAddress],
+				Leg:          models.LedgerLegDebit,
+				Amount:       -(amount + t.Fee),
+				BalanceAfter: snapshot.from,
 			},
+		)
+
+		// transaksi token tidak memindahkan YTE ke penerima, cukup fee
+		if amount != 0 {
+			ledgerEntries = append(ledgerEntries,
+				repository.LedgerEntry{
+					BlockID:      blockID,
+					TxID:         txIDPtr,
+					Address:      t.ToAddress,
+					Leg:          models.LedgerLegCredit,
+					Amount:       amount,
+					BalanceAfter: snapshot.to,
+				},
+			)
+		}
+
+		ledgerEntries = append(ledgerEntries,
 			repository.LedgerEntry{
 				BlockID:      blockID,
 				TxID:         txIDPtr,
 				Address:      "MINER_ACCOUNT",
+				Leg:          models.LedgerLegFee,
 				Amount:       t.Fee,
-				BalanceAfter: txBalanceChanges["MINER_ACCOUNT"],
+				BalanceAfter: snapshot.miner,
 			},
 		)
 	}
@@ -360,6 +386,20 @@ func (s *blockService) GenerateBlock() (models.Block, error) {
 		return models.Block{}, fmt.Errorf("bulk update balances: %w", err)
 	}
 
+	// apply token transactions (saldo token + aktivasi token baru)
+	if s.tokens != nil {
+		tokenEntries, err := s.tokens.ApplyBlockTokensWithTx(tx, blockID, pendingTxs)
+		if err != nil {
+			return models.Block{}, fmt.Errorf("apply token transactions: %w", err)
+		}
+		ledgerEntries = append(ledgerEntries, tokenEntries...)
+	}
+
+	// ledger ditulis di transaction yang sama dengan update wallet: tidak ada saldo tanpa jejak ledger
+	if err := s.ledgerRepo.BulkCreateWithTx(tx, ledgerEntries); err != nil {
+		return models.Block{}, fmt.Errorf("create ledger entries: %w", err)
+	}
+
 	// Process USD Balance Updates For BUY and SELL tx
 
 	var buyerAddresses, sellerAddresses []string
@@ -484,6 +524,19 @@ func (s *blockService) GenerateBlock() (models.Block, error) {
 		}
 	}
 
+	// event RabbitMQ disimpan di outbox dalam transaction yang sama: block yang commit pasti
+	// eventnya terkirim (at-least-once), block yang rollback tidak pernah terpublish
+	if s.outbox != nil {
+		events, err := s.blockOutboxEvents(blockID, newBlock, ledgerEntries, marketState, marketTick, len(pendingTxs))
+		if err != nil {
+			return models.Block{}, err
+		}
+
+		if err := s.outbox.EnqueueWithTx(tx, events...); err != nil {
+			return models.Block{}, fmt.Errorf("enqueue outbox events: %w", err)
+		}
+	}
+
 	// Commit (total transaction time: < 2 seconds)
 	if err := tx.Commit(); err != nil {
 		return models.Block{}, fmt.Errorf("commit transaction: %w", err)
@@ -493,46 +546,9 @@ func (s *blockService) GenerateBlock() (models.Block, error) {
 
 	// PHASE 3: Async Event Publishing (POST-COMMIT)
 
-	// publish ledger batch event
-	if s.ledgerPublisher != nil {
-		ledgerEvents := make([]dto.LedgerEntryEvent, 0, len(ledgerEntries))
-
-		for _, entry := range ledgerEntries {
-			event := dto.LedgerEntryEvent{
-				Address:      entry.Address,
-				Amount:       entry.Amount,
-				BalanceAfter: entry.BalanceAfter,
-			}
-			if entry.TxID != nil {
-				event.TxID = entry.TxID
-			}
-
-			ledgerEvents = append(ledgerEvents, event)
-		}
-
-		if err := s.ledgerPublisher.PublishLedgerBatch(
-			ctx,
-			blockID,
-			newBlock.BlockNumber,
-			ledgerEvents,
-			newBlock.MinerAddress,
-		); err != nil {
-			logger.LogWarn("Failed to publish ledger batch", zap.Error(err))
-		}
-	}
-
-	// publish market pricing event
-	if s.pricingPublisher != nil && marketState.ID != 0 {
-		if err := s.pricingPublisher.PublishPricingEvent(
-			ctx,
-			blockID,
-			newBlock.BlockNumber,
-			marketState,
-			marketTick,
-			newBlock.MinerAddress,
-		); err != nil {
-			logger.LogWarn("Failed to publish market pricing event", zap.Error(err))
-		}
+	// relay outbox langsung publish tanpa menunggu poll berikutnya
+	if s.outbox != nil {
+		s.outbox.Notify()
 	}
 
 	//broadcast new block mined
@@ -540,24 +556,6 @@ func (s *blockService) GenerateBlock() (models.Block, error) {
 		s.publisherWS.Publish(entity.EventTypeBlockMined, newBlock)
 	}
 
-	// publish reward calculation event
-	if s.rewardPublisher != nil {
-		rewardCalcEvent := dto.RewardCalculationEvent{
-			BlockID:             blockID,
-			BlockNumber:         newBlock.BlockNumber,
-			MinerAddress:        newBlock.MinerAddress,
-			BlockReward:         newBlock.BlockReward,
-			TransactionCount:    len(pendingTxs),
-			TotalTransactionFee: newBlock.TotalFees,
-			MarketPrice:         marketState.Price,
-			Timestamp:           time.Now().Unix(),
-		}
-
-		if err := s.rewardPublisher.PublishRewardCalculation(ctx, rewardCalcEvent); err != nil {
-			logger.LogWarn("Failed to publish reward calculation event", zap.Error(err))
-		}
-	}
-
 	// load transactions
 	transactions, err := s.txRepo.GetTransactionsByBlockID(blockID)
 	if err != nil {
@@ -567,19 +565,25 @@ func (s *blockService) GenerateBlock() (models.Block, error) {
 	}
 
 	// send notifycation to websocket
+	// transaksi terkonfirmasi termasuk notifikasi penting, client harus mengirim ack
 	if s.publisherWS != nil && len(newBlock.Transactions) > 0 {
 		for _, tx := range newBlock.Transactions {
 			payload := tx
 			if tx.FromAddress != "MINER_ACCOUNT" {
-				s.publisherWS.PublishToAddress(strings.ToLower(tx.FromAddress), entity.EventTransactionUpdate, payload)
+				s.publisherWS.PublishToAddressWithAck(strings.ToLower(tx.FromAddress), entity.EventTransactionUpdate, payload)
 			}
 
 			if tx.ToAddress != "MINER_ACCOUNT" {
-				s.publisherWS.PublishToAddress(strings.ToLower(tx.ToAddress), entity.EventTransactionUpdate, payload)
+				s.publisherWS.PublishToAddressWithAck(strings.ToLower(tx.ToAddress), entity.EventTransactionUpdate, payload)
 			}
 		}
 	}
 
+	// trade feed untuk SSE, BUY/SELL dieksekusi di harga block
+	if s.marketStream != nil {
+		s.publishTrades(ctx, newBlock, marketState.Price)
+	}
+
 	minerWallet, _ := s.walletRepo.GetByAddress("MINER_ACCOUNT")
 	logger.LogBlockEvent(
 		int64(newBlock.BlockNumber),
@@ -728,3 +732,99 @@ func (s *blockService) SearchBlocksByMinerAddress(ctx context.Context, address s
 
 	return s.blockRepo.SearchByMinerAddress(ctx, address, limit, offset)
 }
+
+type txBalanceSnapshot struct {
+	from  float64
+	to    float64
+	miner float64
+}
+
+// nativeAmount - jumlah YTE yang berpindah, transaksi token hanya membayar fee dalam YTE
+func nativeAmount(t models.Transaction) float64 {
+	if models.IsTokenTransaction(t.Type) {
+		return 0
+	}
+	return t.Amount
+}
+
+// blockOutboxEvents - urutan event sama dengan urutan publish sebelumnya: ledger, pricing, reward
+func (s *blockService) blockOutboxEvents(blockID int64, block models.Block, ledgerEntries []repository.LedgerEntry, marketState models.MarketEngine, marketTick models.MarketTick, txCount int) ([]models.OutboxEvent, error) {
+	ledgerEvents := make([]dto.LedgerEntryEvent, 0, len(ledgerEntries))
+	for _, entry := range ledgerEntries {
+		ledgerEvents = append(ledgerEvents, dto.LedgerEntryEvent{
+			TxID:         entry.TxID,
+			Address:      entry.Address,
+			Asset:        entry.Asset,
+			Amount:       entry.Amount,
+			BalanceAfter: entry.BalanceAfter,
+			EntryType:    entry.Leg,
+		})
+	}
+
+	events := make([]models.OutboxEvent, 0, 3)
+	add := func(exchange, key string, payload any) error {
+		event, err := NewOutboxEvent(OutboxAggregateBlock, blockID, exchange, key, payload)
+		if err != nil {
+			return err
+		}
+
+		events = append(events, event)
+		return nil
+	}
+
+	ledgerBatch := NewLedgerBatchEvent(blockID, block.BlockNumber, ledgerEvents, block.MinerAddress)
+	if err := add(rabbitmq.LedgerExchange, rabbitmq.LedgerBatchKey, ledgerBatch); err != nil {
+		return nil, err
+	}
+
+	if marketState.ID != 0 {
+		pricing := NewMarketPricingEvent(blockID, block.BlockNumber, marketState, marketTick, block.MinerAddress)
+		if err := add(rabbitmq.MarketExchange, rabbitmq.MarketPricingKey, pricing); err != nil {
+			return nil, err
+		}
+	}
+
+	rewardCalc := dto.RewardCalculationEvent{
+		BlockID:             blockID,
+		BlockNumber:         block.BlockNumber,
+		MinerAddress:        block.MinerAddress,
+		BlockReward:         block.BlockReward,
+		TransactionCount:    txCount,
+		TotalTransactionFee: block.TotalFees,
+		MarketPrice:         marketState.Price,
+		Timestamp:           time.Now().Unix(),
+	}
+	if err := add(rabbitmq.RewardExchange, rabbitmq.RewardCalculationKey, rewardCalc); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (s *blockService) publishTrades(ctx context.Context, block models.Block, price float64) {
+	for _, tx := range block.Transactions {
+		trade := dto.TradeEvent{
+			Pair:        models.DefaultPair,
+			TxID:        tx.ID,
+			BlockNumber: block.BlockNumber,
+			Amount:      tx.Amount,
+			Price:       price,
+			Timestamp:   block.Timestamp,
+		}
+
+		switch tx.Type {
+		case "BUY":
+			trade.Side = "BUY"
+			trade.Address = tx.ToAddress
+		case "SELL":
+			trade.Side = "SELL"
+			trade.Address = tx.FromAddress
+		default:
+			continue
+		}
+
+		if err := s.marketStream.PublishTrade(ctx, trade); err != nil {
+			logger.LogWarn("Failed to publish trade", zap.Error(err))
+		}
+	}
+}
@@ -2,64 +2,203 @@ package services
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+	"github.com/livingdolls/go-blockchain-simulate/app/indicators"
 	"github.com/livingdolls/go-blockchain-simulate/app/models"
+	"github.com/livingdolls/go-blockchain-simulate/app/publisher"
 	"github.com/livingdolls/go-blockchain-simulate/app/repository"
 	"github.com/livingdolls/go-blockchain-simulate/logger"
 	"github.com/livingdolls/go-blockchain-simulate/utils"
 )
 
 type CandleService interface {
-	GetCandles(intervalType string, limit int) ([]models.Candle, error)
-	GetCandlesFrom(intervalType string, startTime int64, limit int) ([]models.Candle, error)
-	GetCandle(intervalType string, startTime int64) (models.Candle, error)
-	GetLatestCandleByInterval(intervalType string) (models.Candle, error)
+	GetCandles(pair, intervalType string, limit int, fill bool) ([]models.Candle, error)
+	GetCandlesFrom(pair, intervalType string, startTime int64, limit int, fill bool) ([]models.Candle, error)
+	GetCandle(pair, intervalType string, startTime int64) (models.Candle, error)
+	GetLatestCandleByInterval(pair, intervalType string) (models.Candle, error)
 	UpsertCandleWithTx(tx *sqlx.Tx, candle models.Candle) error
-	DeleteOldCandlesWithTx(tx *sqlx.Tx, intervalType string, beforeTime int64) error
-	AggregateCandle(ctx context.Context, interval string, timestamp int64) error
+	DeleteOldCandlesWithTx(tx *sqlx.Tx, pair, intervalType string, beforeTime int64) (int64, error)
+	DeleteCandlesByPair(pair string) (int64, error)
+	ApplyTick(ctx context.Context, tick models.MarketTick) error
+	RebuildCandles(ctx context.Context, pair, interval string, from, to int64) (int, error)
+	GetIndicator(pair, interval, indicator string, params []float64, limit int) (indicators.Result, error)
+}
+
+// candleRollupSource - timeframe besar diturunkan dari timeframe di bawahnya, bukan dari tick
+var candleRollupSource = map[string]string{
+	"5m":  "1m",
+	"15m": "5m",
+	"30m": "15m",
+	"1h":  "30m",
+	"4h":  "1h",
+	"1d":  "4h",
+}
+
+// rentang tick yang dibaca per batch saat rebuild
+const candleRebuildChunk = 24 * 60 * 60
+
+type CandleConfig struct {
+	// FillGaps - menit tanpa tick diisi candle flat (close sebelumnya, volume 0) saat agregasi
+	FillGaps bool
+	// MaxGapFill - batas candle yang diisi per gap, gap lebih panjang hanya diisi bagian terakhirnya
+	MaxGapFill int
 }
 
 type candleService struct {
-	repo   repository.CandlesRepository
-	stream CandleStreamService
+	repo        repository.CandlesRepository
+	stream      CandleStreamService
+	publisherWS *publisher.PublisherWS
+	cfg         CandleConfig
 }
 
-func NewCandleService(repo repository.CandlesRepository, stream CandleStreamService) CandleService {
+// NewCandleService - stream (SSE) dan publisherWS (topic WebSocket candles:<interval>) boleh nil
+func NewCandleService(repo repository.CandlesRepository, stream CandleStreamService, publisherWS *publisher.PublisherWS, cfg CandleConfig) CandleService {
+	if cfg.MaxGapFill <= 0 {
+		cfg.MaxGapFill = 1440
+	}
+
 	return &candleService{
-		repo:   repo,
-		stream: stream,
+		repo:        repo,
+		stream:      stream,
+		publisherWS: publisherWS,
+		cfg:         cfg,
 	}
 }
 
+// DeleteCandlesByPair implements [CandleService].
+func (c *candleService) DeleteCandlesByPair(pair string) (int64, error) {
+	return c.repo.DeleteCandlesByPair(pair)
+}
+
 // DeleteOldCandlesWithTx implements [CandleService].
-func (c *candleService) DeleteOldCandlesWithTx(tx *sqlx.Tx, intervalType string, beforeTime int64) error {
-	return c.repo.DeleteOldCandlesWithTx(tx, intervalType, beforeTime)
+func (c *candleService) DeleteOldCandlesWithTx(tx *sqlx.Tx, pair, intervalType string, beforeTime int64) (int64, error) {
+	return c.repo.DeleteOldCandlesWithTx(tx, pair, intervalType, beforeTime)
 }
 
 // GetCandle implements [CandleService].
-func (c *candleService) GetCandle(intervalType string, startTime int64) (models.Candle, error) {
-	return c.repo.GetCandleByIntervalAndStartTime(intervalType, startTime)
+func (c *candleService) GetCandle(pair, intervalType string, startTime int64) (models.Candle, error) {
+	return c.repo.GetCandleByIntervalAndStartTime(pair, intervalType, startTime)
 }
 
 // GetCandles implements [CandleService].
-func (c *candleService) GetCandles(intervalType string, limit int) ([]models.Candle, error) {
+func (c *candleService) GetCandles(pair, intervalType string, limit int, fill bool) ([]models.Candle, error) {
 	if limit <= 0 {
 		limit = 100
 	}
 
-	return c.repo.GetCandleByInterval(intervalType, limit)
+	candles, err := c.repo.GetCandleByInterval(pair, intervalType, limit)
+	if err != nil || !fill {
+		return candles, err
+	}
+
+	return c.fillSeries(pair, intervalType, candles, 0, limit), nil
 }
 
 // GetCandlesFrom implements [CandleService].
-func (c *candleService) GetCandlesFrom(intervalType string, startTime int64, limit int) ([]models.Candle, error) {
+func (c *candleService) GetCandlesFrom(pair, intervalType string, startTime int64, limit int, fill bool) ([]models.Candle, error) {
 	if limit <= 0 {
 		limit = 100
 	}
 
-	return c.repo.GetCandleByIntervalAndTime(intervalType, startTime, limit)
+	candles, err := c.repo.GetCandleByIntervalAndTime(pair, intervalType, startTime, limit)
+	if err != nil || !fill {
+		return candles, err
+	}
+
+	return c.fillSeries(pair, intervalType, candles, startTime, limit), nil
+}
+
+// fillSeries - isi bucket kosong sampai bucket saat ini dengan candle flat.
+// input dan output urut terbaru dulu (sama seperti repository), maksimal limit candle
+func (c *candleService) fillSeries(pair, interval string, candles []models.Candle, from int64, limit int) []models.Candle {
+	until := utils.FloorTime(time.Now().Unix(), interval)
+	if n := len(candles); n > 0 && candles[0].StartTime > until {
+		until = candles[0].StartTime
+	}
+
+	// hanya window limit bucket terakhir yang dikembalikan
+	if lower := until - int64(limit-1)*utils.IntervalDuration(interval); from < lower {
+		from = lower
+	}
+	from = utils.FloorTime(from, interval)
+
+	byStart := make(map[int64]models.Candle, len(candles))
+	var prev *models.Candle
+
+	for i := len(candles) - 1; i >= 0; i-- {
+		if candles[i].StartTime < from {
+			p := candles[i]
+			prev = &p
+			continue
+		}
+		byStart[candles[i].StartTime] = candles[i]
+	}
+
+	if prev == nil {
+		if before, err := c.repo.GetCandleBefore(pair, interval, from); err == nil {
+			prev = &before
+		}
+	}
+
+	filled := make([]models.Candle, 0, limit)
+	for t := from; t <= until; t = utils.NextIntervalStart(t, interval) {
+		if candle, ok := byStart[t]; ok {
+			filled = append(filled, candle)
+			prev = &candle
+			continue
+		}
+
+		// belum ada harga sebelumnya, tidak bisa diisi
+		if prev == nil {
+			continue
+		}
+
+		filled = append(filled, flatCandle(*prev, interval, t))
+	}
+
+	if len(filled) > limit {
+		filled = filled[len(filled)-limit:]
+	}
+
+	for i, j := 0, len(filled)-1; i < j; i, j = i+1, j-1 {
+		filled[i], filled[j] = filled[j], filled[i]
+	}
+
+	return filled
+}
+
+// flatCandle - candle tanpa transaksi: OHLC = close sebelumnya, volume 0
+func flatCandle(prev models.Candle, interval string, start int64) models.Candle {
+	return models.Candle{
+		Pair:         prev.Pair,
+		IntervalType: interval,
+		StartTime:    start,
+		OpenPrice:    prev.ClosePrice,
+		HighPrice:    prev.ClosePrice,
+		LowPrice:     prev.ClosePrice,
+		ClosePrice:   prev.ClosePrice,
+	}
+}
+
+// gapCandles - candle flat untuk bucket di antara prev dan nextStart (eksklusif), maksimal max candle terakhir
+func gapCandles(prev models.Candle, nextStart int64, interval string, max int) []models.Candle {
+	var gaps []models.Candle
+
+	for t := utils.NextIntervalStart(prev.StartTime, interval); t < nextStart; t = utils.NextIntervalStart(t, interval) {
+		gaps = append(gaps, flatCandle(prev, interval, t))
+	}
+
+	if len(gaps) > max {
+		gaps = gaps[len(gaps)-max:]
+	}
+
+	return gaps
 }
 
 // UpsertCandleWithTx implements [CandleService].
@@ -67,58 +206,266 @@ func (c *candleService) UpsertCandleWithTx(tx *sqlx.Tx, candle models.Candle) er
 	return c.repo.UpsertCandleWithTx(tx, candle)
 }
 
-func (c *candleService) AggregateCandle(ctx context.Context, interval string, timestamp int64) error {
+// ApplyTick implements [CandleService].
+// candle 1m diupdate in place dari tick block terbaru, lalu 5m..1d di-rollup berantai dari timeframe di bawahnya
+func (c *candleService) ApplyTick(ctx context.Context, tick models.MarketTick) error {
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
 	default:
 	}
 
-	start := utils.FloorTime(timestamp, interval)
-	duration := utils.IntervalDuration(interval)
-	end := start + duration
+	pair := tick.Pair
+	if pair == "" {
+		pair = models.DefaultPair
+	}
+
+	base := models.Candle{
+		Pair:         pair,
+		IntervalType: "1m",
+		StartTime:    utils.FloorTime(tick.CreatedAt, "1m"),
+		OpenPrice:    tick.Price,
+		HighPrice:    tick.Price,
+		LowPrice:     tick.Price,
+		ClosePrice:   tick.Price,
+		Volume:       tick.BuyVolume + tick.SellVolume,
+		BuyVolume:    tick.BuyVolume,
+		SellVolume:   tick.SellVolume,
+		TradeCount:   tick.TxCount,
+		LastBlockID:  tick.BlockID,
+	}
+
+	tx, err := c.repo.CandleBeginTx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	rowsAffected, err := c.repo.ApplyTickWithTx(tx, base)
+	if err != nil {
+		return err
+	}
+
+	// block ini sudah pernah diterapkan (redelivery)
+	if rowsAffected == 0 {
+		err = tx.Commit()
+		return err
+	}
 
-	// fetch ticks in the interval
-	ticks, err := c.repo.GetTicksRange(start, end)
+	updated := make([]models.Candle, 0, len(utils.CandleIntervals))
+	rollupFrom := base.StartTime
+
+	// rows affected 1 = candle 1m baru, isi menit kosong sejak candle sebelumnya
+	if rowsAffected == 1 && c.cfg.FillGaps {
+		var gaps []models.Candle
+		if gaps, err = c.fillGapsWithTx(tx, pair, base.StartTime); err != nil {
+			return err
+		}
+
+		if len(gaps) > 0 {
+			rollupFrom = gaps[0].StartTime
+			updated = append(updated, gaps...)
+		}
+	}
+
+	current, err := c.repo.GetCandlesRangeWithTx(tx, pair, "1m", base.StartTime, base.StartTime+utils.IntervalDuration("1m"))
 	if err != nil {
 		return err
 	}
+	updated = append(updated, current...)
+
+	for _, interval := range utils.CandleIntervals[1:] {
+		last := utils.FloorTime(tick.CreatedAt, interval)
+
+		for start := utils.FloorTime(rollupFrom, interval); start <= last; start = utils.NextIntervalStart(start, interval) {
+			var candle models.Candle
+			if candle, err = c.rollup(tx, pair, interval, start); err != nil {
+				return err
+			}
+
+			if _, err = c.repo.UpsertCandleOnDuplicateWithTx(tx, candle); err != nil {
+				return err
+			}
+
+			updated = append(updated, candle)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
 
-	if len(ticks) == 0 {
-		// no ticks in this interval, skip
+	// filter interval & pair dilakukan hub per subscription
+	if c.publisherWS != nil {
+		for _, candle := range updated {
+			c.publisherWS.Publish(entity.EventCandleUpdate, candle)
+		}
+	}
+
+	if c.stream == nil {
 		return nil
 	}
 
-	// hitung ohlcv
-	open := ticks[0].Price
-	closep := ticks[len(ticks)-1].Price
-	high, low := open, open
+	pubCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
 
-	var volume float64
+	for _, candle := range updated {
+		if err := c.stream.PublishCandle(pubCtx, candle); err != nil {
+			logger.LogError("PublishCandle error", err)
+		}
+	}
 
-	for _, tick := range ticks {
-		if tick.Price > high {
-			high = tick.Price
+	return nil
+}
+
+// fillGapsWithTx - sisipkan candle 1m flat di antara candle sebelumnya dan start
+func (c *candleService) fillGapsWithTx(tx *sqlx.Tx, pair string, start int64) ([]models.Candle, error) {
+	prev, err := c.repo.GetCandleBeforeWithTx(tx, pair, "1m", start)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
 		}
+		return nil, err
+	}
 
-		if tick.Price < low {
-			low = tick.Price
+	gaps := gapCandles(prev, start, "1m", c.cfg.MaxGapFill)
+	for _, gap := range gaps {
+		if _, err := c.repo.UpsertCandleOnDuplicateWithTx(tx, gap); err != nil {
+			return nil, err
 		}
+	}
+
+	return gaps, nil
+}
+
+// rollup - bangun candle interval dari candle timeframe sumbernya dalam window bucket start
+func (c *candleService) rollup(tx *sqlx.Tx, pair, interval string, start int64) (models.Candle, error) {
+	end := utils.NextIntervalStart(start, interval)
 
-		volume += tick.BuyVolume + tick.SellVolume
+	sources, err := c.repo.GetCandlesRangeWithTx(tx, pair, candleRollupSource[interval], start, end)
+	if err != nil {
+		return models.Candle{}, err
+	}
+
+	if len(sources) == 0 {
+		return models.Candle{}, fmt.Errorf("no %s candles to roll up into %s at %d", candleRollupSource[interval], interval, start)
 	}
 
 	candle := models.Candle{
+		Pair:         pair,
 		IntervalType: interval,
 		StartTime:    start,
-		OpenPrice:    open,
-		HighPrice:    high,
-		LowPrice:     low,
-		ClosePrice:   closep,
-		Volume:       volume,
+		OpenPrice:    sources[0].OpenPrice,
+		HighPrice:    sources[0].HighPrice,
+		LowPrice:     sources[0].LowPrice,
+		ClosePrice:   sources[len(sources)-1].ClosePrice,
+	}
+
+	for _, src := range sources {
+		if src.HighPrice > candle.HighPrice {
+			candle.HighPrice = src.HighPrice
+		}
+
+		if src.LowPrice < candle.LowPrice {
+			candle.LowPrice = src.LowPrice
+		}
+
+		if src.LastBlockID > candle.LastBlockID {
+			candle.LastBlockID = src.LastBlockID
+		}
+
+		candle.Volume += src.Volume
+		candle.BuyVolume += src.BuyVolume
+		candle.SellVolume += src.SellVolume
+		candle.TradeCount += src.TradeCount
+	}
+
+	return candle, nil
+}
+
+// RebuildCandles implements [CandleService].
+// hitung ulang candle satu interval langsung dari market_ticks dalam rentang [from, to), hasil menimpa candle lama
+func (c *candleService) RebuildCandles(ctx context.Context, pair, interval string, from, to int64) (int, error) {
+	if pair == "" {
+		pair = models.DefaultPair
+	}
+
+	if to <= from {
+		return 0, fmt.Errorf("invalid range: from %d must be before to %d", from, to)
+	}
+
+	total := 0
+	cursor := utils.FloorTime(from, interval)
+
+	// candle terakhir sebelum batch, dipakai untuk gap fill lintas batch
+	var prev *models.Candle
+	if c.cfg.FillGaps {
+		if before, err := c.repo.GetCandleBefore(pair, interval, cursor); err == nil {
+			prev = &before
+		}
+	}
+
+	for cursor < to {
+		select {
+		case <-ctx.Done():
+			return total, ctx.Err()
+		default:
+		}
+
+		// batas batch selalu di awal bucket agar satu candle tidak terpecah dua batch
+		end := utils.FloorTime(cursor+candleRebuildChunk, interval)
+		if end <= cursor {
+			end = cursor + utils.IntervalDuration(interval)
+		}
+
+		ticks, err := c.repo.GetTicksRange(pair, cursor, end)
+		if err != nil {
+			return total, err
+		}
+
+		candles := buildCandlesFromTicks(pair, interval, ticks)
+		if c.cfg.FillGaps {
+			candles, prev = c.fillBuiltCandles(prev, candles, interval)
+		}
+
+		if len(candles) > 0 {
+			if err := c.upsertCandles(candles); err != nil {
+				return total, err
+			}
+		}
+
+		total += len(candles)
+		cursor = end
 	}
 
-	// upsert candle with transaction
+	logger.LogInfo(fmt.Sprintf("Rebuilt %d candles for pair=%s, interval=%s", total, pair, interval))
+
+	return total, nil
+}
+
+// fillBuiltCandles - sisipkan candle flat di antara candle hasil build, mengembalikan candle terakhir untuk batch berikutnya
+func (c *candleService) fillBuiltCandles(prev *models.Candle, candles []models.Candle, interval string) ([]models.Candle, *models.Candle) {
+	filled := make([]models.Candle, 0, len(candles))
+
+	for _, candle := range candles {
+		if prev != nil {
+			filled = append(filled, gapCandles(*prev, candle.StartTime, interval, c.cfg.MaxGapFill)...)
+		}
+
+		filled = append(filled, candle)
+		last := candle
+		prev = &last
+	}
+
+	return filled, prev
+}
+
+func (c *candleService) upsertCandles(candles []models.Candle) (err error) {
 	tx, err := c.repo.CandleBeginTx()
 	if err != nil {
 		return err
@@ -130,33 +477,90 @@ func (c *candleService) AggregateCandle(ctx context.Context, interval string, ti
 		}
 	}()
 
-	rowsAffected, err := c.repo.UpsertCandleOnDuplicateWithTx(tx, candle)
+	for _, candle := range candles {
+		if _, err = c.repo.UpsertCandleOnDuplicateWithTx(tx, candle); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// buildCandlesFromTicks - ticks sudah urut berdasarkan waktu
+func buildCandlesFromTicks(pair, interval string, ticks []models.MarketTick) []models.Candle {
+	var candles []models.Candle
+
+	for _, tick := range ticks {
+		start := utils.FloorTime(tick.CreatedAt, interval)
+		volume := tick.BuyVolume + tick.SellVolume
+
+		if n := len(candles); n > 0 && candles[n-1].StartTime == start {
+			last := &candles[n-1]
+			if tick.Price > last.HighPrice {
+				last.HighPrice = tick.Price
+			}
+			if tick.Price < last.LowPrice {
+				last.LowPrice = tick.Price
+			}
+			if tick.BlockID > last.LastBlockID {
+				last.LastBlockID = tick.BlockID
+			}
+			last.ClosePrice = tick.Price
+			last.Volume += volume
+			last.BuyVolume += tick.BuyVolume
+			last.SellVolume += tick.SellVolume
+			last.TradeCount += tick.TxCount
+			continue
+		}
 
+		candles = append(candles, models.Candle{
+			Pair:         pair,
+			IntervalType: interval,
+			StartTime:    start,
+			OpenPrice:    tick.Price,
+			HighPrice:    tick.Price,
+			LowPrice:     tick.Price,
+			ClosePrice:   tick.Price,
+			Volume:       volume,
+			BuyVolume:    tick.BuyVolume,
+			SellVolume:   tick.SellVolume,
+			TradeCount:   tick.TxCount,
+			LastBlockID:  tick.BlockID,
+		})
+	}
+
+	return candles
+}
+
+// GetIndicator implements [CandleService].
+// candle tambahan (lookback) diambil agar nilai pertama yang dikembalikan sudah stabil
+func (c *candleService) GetIndicator(pair, interval, indicator string, params []float64, limit int) (indicators.Result, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	candles, err := c.repo.GetCandleByInterval(pair, interval, limit+indicators.Lookback(indicator, params))
 	if err != nil {
-		return err
+		return indicators.Result{}, err
 	}
 
-	if err := tx.Commit(); err != nil {
-		return err
+	// repository mengembalikan urutan terbaru dulu
+	for i, j := 0, len(candles)-1; i < j; i, j = i+1, j-1 {
+		candles[i], candles[j] = candles[j], candles[i]
 	}
 
-	if rowsAffected > 0 {
-		logger.LogInfo(fmt.Sprintf("Aggregated candle for interval=%s, start=%d", interval, start))
-		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
-		defer cancel()
+	result, err := indicators.Calculate(indicator, params, candles)
+	if err != nil {
+		return indicators.Result{}, err
+	}
 
-		if err := c.stream.PublishCandle(ctx, candle); err != nil {
-			logger.LogError("PublishCandle error", err)
-		} else {
-			logger.LogInfo(fmt.Sprintf("Published candle for interval=%s, start=%d", interval, start))
-		}
-	} else {
-		// log.Printf("Candle for interval=%s, start=%d already up-to-date, skipped publish\n", interval, start)
+	if len(result.Points) > limit {
+		result.Points = result.Points[len(result.Points)-limit:]
 	}
 
-	return nil
+	return result, nil
 }
 
-func (c *candleService) GetLatestCandleByInterval(intervalType string) (models.Candle, error) {
-	return c.repo.GetLatestCandleByInterval(intervalType)
+func (c *candleService) GetLatestCandleByInterval(pair, intervalType string) (models.Candle, error) {
+	return c.repo.GetLatestCandleByInterval(pair, intervalType)
 }
@@ -14,7 +14,7 @@ import (
 
 type CandleStreamService interface {
 	PublishCandle(ctx context.Context, candle models.Candle) error
-	SubscribeCandle(ctx context.Context, interval string, callback func(models.Candle) error) error
+	SubscribeCandle(ctx context.Context, pair, interval string, callback func(models.Candle) error) error
 	PublishAllIntervals(ctx context.Context, candle models.Candle) error
 }
 
@@ -48,7 +48,12 @@ func (c *candleStreamService) PublishAllIntervals(ctx context.Context, candle mo
 
 // PublishCandle implements [CandleStreamService].
 func (c *candleStreamService) PublishCandle(ctx context.Context, candle models.Candle) error {
-	channel := fmt.Sprintf("candles:%s", candle.IntervalType)
+	pair := candle.Pair
+	if pair == "" {
+		pair = models.DefaultPair
+	}
+
+	channel := candleChannel(pair, candle.IntervalType)
 
 	payload, err := json.Marshal(candle)
 	if err != nil {
@@ -66,16 +71,16 @@ func (c *candleStreamService) PublishCandle(ctx context.Context, candle models.C
 }
 
 // SubscribeCandle implements [CandleStreamService].
-func (c *candleStreamService) SubscribeCandle(ctx context.Context, interval string, callback func(models.Candle) error) error {
-	channel := fmt.Sprintf("candles:%s", interval)
+func (c *candleStreamService) SubscribeCandle(ctx context.Context, pair, interval string, callback func(models.Candle) error) error {
+	channel := candleChannel(pair, interval)
 
 	// increment counter
 	count := c.subscriberCount.Add(1)
-	logger.LogInfo("CandleStreamService SubscribeCandle: New subscriber", zap.String("interval", interval), zap.Int32("total_subscribers", count))
+	logger.LogInfo("CandleStreamService SubscribeCandle: New subscriber", zap.String("pair", pair), zap.String("interval", interval), zap.Int32("total_subscribers", count))
 
 	defer func() {
 		count := c.subscriberCount.Add(-1)
-		logger.LogInfo("CandleStreamService SubscribeCandle: Subscriber left", zap.String("interval", interval), zap.Int32("total_subscribers", count))
+		logger.LogInfo("CandleStreamService SubscribeCandle: Subscriber left", zap.String("pair", pair), zap.String("interval", interval), zap.Int32("total_subscribers", count))
 	}()
 
 	return c.redis.Subscribe(ctx, channel, func(message []byte) error {
@@ -89,3 +94,8 @@ func (c *candleStreamService) SubscribeCandle(ctx context.Context, interval stri
 		return callback(candle)
 	})
 }
+
+// candleChannel - redis channel per pair dan interval, contoh: candles:YTE-USD:1m
+func candleChannel(pair, interval string) string {
+	return fmt.Sprintf("candles:%s:%s", pair, interval)
+}
@@ -0,0 +1,324 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+	"github.com/livingdolls/go-blockchain-simulate/app/repository"
+	"github.com/livingdolls/go-blockchain-simulate/utils"
+)
+
+// candleStore - tabel candles dan market_ticks di memory, transaction dari sqlmock
+type candleStore struct {
+	repository.CandlesRepository
+	db *sqlx.DB
+
+	mu      sync.Mutex
+	candles map[string]map[int64]models.Candle
+	ticks   []models.MarketTick
+}
+
+func newCandleStore(t *testing.T) (*candleStore, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return &candleStore{
+		db:      sqlx.NewDb(db, "mysql"),
+		candles: make(map[string]map[int64]models.Candle),
+	}, mock
+}
+
+func (s *candleStore) CandleBeginTx() (*sqlx.Tx, error) { return s.db.Beginx() }
+
+func (s *candleStore) put(c models.Candle) {
+	if s.candles[c.IntervalType] == nil {
+		s.candles[c.IntervalType] = make(map[int64]models.Candle)
+	}
+	s.candles[c.IntervalType][c.StartTime] = c
+}
+
+// sorted - candle satu interval, urut waktu naik
+func (s *candleStore) sorted(interval string) []models.Candle {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]models.Candle, 0, len(s.candles[interval]))
+	for _, c := range s.candles[interval] {
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].StartTime < out[j].StartTime })
+	return out
+}
+
+// ApplyTickWithTx - 1 = candle baru, 2 = candle diupdate, 0 = block sudah diterapkan
+func (s *candleStore) ApplyTickWithTx(_ *sqlx.Tx, c models.Candle) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.candles[c.IntervalType][c.StartTime]
+	if !ok {
+		s.put(c)
+		return 1, nil
+	}
+
+	if c.LastBlockID <= existing.LastBlockID {
+		return 0, nil
+	}
+
+	if c.HighPrice > existing.HighPrice {
+		existing.HighPrice = c.HighPrice
+	}
+	if c.LowPrice < existing.LowPrice {
+		existing.LowPrice = c.LowPrice
+	}
+	existing.ClosePrice = c.ClosePrice
+	existing.Volume += c.Volume
+	existing.BuyVolume += c.BuyVolume
+	existing.SellVolume += c.SellVolume
+	existing.TradeCount += c.TradeCount
+	existing.LastBlockID = c.LastBlockID
+	s.put(existing)
+
+	return 2, nil
+}
+
+func (s *candleStore) UpsertCandleOnDuplicateWithTx(_ *sqlx.Tx, c models.Candle) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.put(c)
+	return 1, nil
+}
+
+func (s *candleStore) GetCandlesRangeWithTx(_ *sqlx.Tx, _, interval string, start, end int64) ([]models.Candle, error) {
+	var out []models.Candle
+	for _, c := range s.sorted(interval) {
+		if c.StartTime >= start && c.StartTime < end {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func (s *candleStore) GetCandleBeforeWithTx(_ *sqlx.Tx, pair, interval string, start int64) (models.Candle, error) {
+	return s.GetCandleBefore(pair, interval, start)
+}
+
+func (s *candleStore) GetCandleBefore(_, interval string, start int64) (models.Candle, error) {
+	candles := s.sorted(interval)
+	for i := len(candles) - 1; i >= 0; i-- {
+		if candles[i].StartTime < start {
+			return candles[i], nil
+		}
+	}
+	return models.Candle{}, sql.ErrNoRows
+}
+
+// GetCandleByIntervalAndTime - seperti repository: terbaru dulu
+func (s *candleStore) GetCandleByIntervalAndTime(_, interval string, start int64, limit int) ([]models.Candle, error) {
+	var out []models.Candle
+	candles := s.sorted(interval)
+	for i := len(candles) - 1; i >= 0 && len(out) < limit; i-- {
+		if candles[i].StartTime >= start {
+			out = append(out, candles[i])
+		}
+	}
+	return out, nil
+}
+
+func (s *candleStore) GetTicksRange(_ string, start, end int64) ([]models.MarketTick, error) {
+	var out []models.MarketTick
+	for _, tick := range s.ticks {
+		if tick.CreatedAt >= start && tick.CreatedAt < end {
+			out = append(out, tick)
+		}
+	}
+	return out, nil
+}
+
+// candleBase - awal bucket 5m beberapa jam lalu, semua bucket 1m sesudahnya masuk bucket 5m yang sama
+func candleBase() int64 {
+	return utils.FloorTime(time.Now().Add(-3*time.Hour).Unix(), "5m")
+}
+
+func assertFlat(t *testing.T, c models.Candle, start int64, price float64) {
+	t.Helper()
+
+	if c.StartTime != start || c.OpenPrice != price || c.HighPrice != price || c.LowPrice != price ||
+		c.ClosePrice != price || c.Volume != 0 || c.TradeCount != 0 {
+		t.Fatalf("candle at %d = %+v, want flat candle at %d with price %v", c.StartTime, c, start, price)
+	}
+}
+
+func TestApplyTickFillsMissingMinutes(t *testing.T) {
+	store, mock := newCandleStore(t)
+	service := NewCandleService(store, nil, nil, CandleConfig{FillGaps: true})
+
+	base := candleBase()
+	ticks := []models.MarketTick{
+		{Pair: models.DefaultPair, BlockID: 1, Price: 100, BuyVolume: 2, TxCount: 1, CreatedAt: base + 10},
+		{Pair: models.DefaultPair, BlockID: 2, Price: 110, SellVolume: 3, TxCount: 2, CreatedAt: base + 4*60 + 10},
+		// redelivery block 2: tidak mengubah apa pun
+		{Pair: models.DefaultPair, BlockID: 2, Price: 110, SellVolume: 3, TxCount: 2, CreatedAt: base + 4*60 + 10},
+	}
+
+	for range ticks {
+		mock.ExpectBegin()
+		mock.ExpectCommit()
+	}
+
+	for _, tick := range ticks {
+		if err := service.ApplyTick(context.Background(), tick); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	minutes := store.sorted("1m")
+	if len(minutes) != 5 {
+		t.Fatalf("1m candles = %d, want 5: %+v", len(minutes), minutes)
+	}
+	for i := 1; i <= 3; i++ {
+		assertFlat(t, minutes[i], base+int64(i)*60, 100)
+	}
+	if last := minutes[4]; last.OpenPrice != 110 || last.SellVolume != 3 || last.TradeCount != 2 {
+		t.Fatalf("last 1m candle = %+v", last)
+	}
+
+	// rollup 5m memakai candle flat, volume hanya dari menit yang punya tick
+	fives := store.sorted("5m")
+	if len(fives) != 1 {
+		t.Fatalf("5m candles = %+v", fives)
+	}
+	five := fives[0]
+	if five.StartTime != base || five.OpenPrice != 100 || five.ClosePrice != 110 || five.LowPrice != 100 ||
+		five.Volume != 5 || five.BuyVolume != 2 || five.SellVolume != 3 || five.TradeCount != 3 {
+		t.Fatalf("5m candle = %+v", five)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestApplyTickWithoutGapFill(t *testing.T) {
+	store, mock := newCandleStore(t)
+	service := NewCandleService(store, nil, nil, CandleConfig{})
+
+	base := candleBase()
+	for i, tick := range []models.MarketTick{
+		{BlockID: 1, Price: 100, CreatedAt: base},
+		{BlockID: 2, Price: 110, CreatedAt: base + 4*60},
+	} {
+		mock.ExpectBegin()
+		mock.ExpectCommit()
+
+		if err := service.ApplyTick(context.Background(), tick); err != nil {
+			t.Fatalf("tick %d: %v", i, err)
+		}
+	}
+
+	if minutes := store.sorted("1m"); len(minutes) != 2 {
+		t.Fatalf("1m candles = %+v, want only candles with ticks", minutes)
+	}
+}
+
+func TestRebuildCandlesFillsGapsAcrossBatches(t *testing.T) {
+	store, mock := newCandleStore(t)
+	service := NewCandleService(store, nil, nil, CandleConfig{FillGaps: true, MaxGapFill: 2})
+
+	// batas batch rebuild jatuh di antara dua tick, gap 3 menit hanya diisi 2 menit terakhirnya
+	from := utils.FloorTime(time.Now().Add(-48*time.Hour).Unix(), "1m")
+	boundary := from + candleRebuildChunk
+	store.ticks = []models.MarketTick{
+		{BlockID: 1, Price: 100, CreatedAt: boundary - 2*60},
+		{BlockID: 2, Price: 120, CreatedAt: boundary + 2*60},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	total, err := service.RebuildCandles(context.Background(), models.DefaultPair, "1m", from, boundary+10*60)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	minutes := store.sorted("1m")
+	if total != 4 || len(minutes) != 4 {
+		t.Fatalf("rebuilt = %d, candles = %+v", total, minutes)
+	}
+
+	assertFlat(t, minutes[1], boundary, 100)
+	assertFlat(t, minutes[2], boundary+60, 100)
+	if minutes[0].StartTime != boundary-2*60 || minutes[3].StartTime != boundary+2*60 || minutes[3].ClosePrice != 120 {
+		t.Fatalf("candles = %+v", minutes)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetCandlesFromFillsSeries(t *testing.T) {
+	store, _ := newCandleStore(t)
+	service := NewCandleService(store, nil, nil, CandleConfig{})
+
+	// hindari pergantian menit di tengah test, fillSeries mengisi sampai menit berjalan
+	if sec := time.Now().Second(); sec >= 58 {
+		time.Sleep(time.Duration(61-sec) * time.Second)
+	}
+
+	now := utils.FloorTime(time.Now().Unix(), "1m")
+	from := now - 5*60
+
+	// candle sebelum window menjadi harga awal untuk menit kosong di depan
+	store.put(models.Candle{IntervalType: "1m", StartTime: from - 60, OpenPrice: 90, HighPrice: 90, LowPrice: 90, ClosePrice: 95})
+	store.put(models.Candle{IntervalType: "1m", StartTime: from + 2*60, OpenPrice: 95, HighPrice: 105, LowPrice: 95, ClosePrice: 105, Volume: 1})
+
+	raw, err := service.GetCandlesFrom(models.DefaultPair, "1m", from, 100, false)
+	if err != nil || len(raw) != 1 {
+		t.Fatalf("unfilled = %+v, %v", raw, err)
+	}
+
+	filled, err := service.GetCandlesFrom(models.DefaultPair, "1m", from, 100, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// from .. now inklusif, terbaru dulu
+	if len(filled) != 6 {
+		t.Fatalf("filled candles = %d, want 6: %+v", len(filled), filled)
+	}
+	for i, c := range filled {
+		start := now - int64(i)*60
+		switch {
+		case start > from+2*60:
+			assertFlat(t, c, start, 105)
+		case start == from+2*60:
+			if c.Volume != 1 || c.ClosePrice != 105 {
+				t.Fatalf("stored candle = %+v", c)
+			}
+		default:
+			assertFlat(t, c, start, 95)
+		}
+	}
+
+	// limit memotong window ke bucket terbaru
+	limited, err := service.GetCandlesFrom(models.DefaultPair, "1m", from, 2, true)
+	if err != nil || len(limited) != 2 || limited[0].StartTime != now {
+		t.Fatalf("limited = %+v, %v", limited, err)
+	}
+}
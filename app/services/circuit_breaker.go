@@ -0,0 +1,470 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+	"github.com/livingdolls/go-blockchain-simulate/app/publisher"
+	"github.com/livingdolls/go-blockchain-simulate/app/repository"
+	"github.com/livingdolls/go-blockchain-simulate/logger"
+	"github.com/livingdolls/go-blockchain-simulate/redis"
+	"go.uber.org/zap"
+)
+
+const (
+	tradingStatusKey     = "trading:status"
+	TradingStatusChannel = "trading:status"
+
+	// halt manual tidak punya resume otomatis, TTL hanya pengaman di redis
+	manualHaltTTL = 30 * 24 * time.Hour
+)
+
+// CircuitBreakerLevel - perubahan harga >= ThresholdPercent (absolut) menghentikan BUY/SELL selama Cooldown
+type CircuitBreakerLevel struct {
+	ThresholdPercent float64       `json:"threshold_percent"`
+	Cooldown         time.Duration `json:"cooldown"`
+}
+
+type CircuitBreakerConfig struct {
+	Enabled bool                  `json:"enabled"`
+	Levels  []CircuitBreakerLevel `json:"levels"`
+}
+
+type CircuitBreakerService interface {
+	Evaluate(ctx context.Context, pair string, blockNumber int64, priceChangePercent float64) (models.TradingStatus, error)
+	Status(ctx context.Context) models.TradingStatus
+	EnsureTradingOpen(ctx context.Context) error
+	Halt(ctx context.Context, adminID int, reason string, duration time.Duration) (models.TradingStatus, error)
+	Resume(ctx context.Context, adminID int) (models.TradingStatus, error)
+	History(limit, offset int) ([]models.TradingHalt, error)
+	Config() CircuitBreakerConfig
+	UpdateConfig(cfg CircuitBreakerConfig) (CircuitBreakerConfig, error)
+	Restore(ctx context.Context)
+	SubscribeStatus(ctx context.Context, handler func(status models.TradingStatus) error) error
+}
+
+type circuitBreakerService struct {
+	repo        repository.TradingHaltRepository
+	memory      redis.MemoryAdapter
+	publisherWS *publisher.PublisherWS
+
+	mu          sync.Mutex
+	cfg         CircuitBreakerConfig
+	resumeTimer *time.Timer
+}
+
+func NewCircuitBreakerService(repo repository.TradingHaltRepository, memory redis.MemoryAdapter, publisherWS *publisher.PublisherWS, cfg CircuitBreakerConfig) CircuitBreakerService {
+	normalized, err := normalizeBreakerConfig(cfg)
+	if err != nil {
+		logger.LogWarn("Invalid circuit breaker config, breaker disabled", zap.Error(err))
+		normalized = CircuitBreakerConfig{}
+	}
+
+	return &circuitBreakerService{
+		repo:        repo,
+		memory:      memory,
+		publisherWS: publisherWS,
+		cfg:         normalized,
+	}
+}
+
+// Evaluate implements [CircuitBreakerService].
+// dipanggil setiap ada harga block baru, level tertinggi yang tersentuh menentukan lama halt
+func (s *circuitBreakerService) Evaluate(ctx context.Context, pair string, blockNumber int64, priceChangePercent float64) (models.TradingStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := s.status(ctx)
+
+	if !s.cfg.Enabled {
+		return current, nil
+	}
+
+	level, ok := matchBreakerLevel(s.cfg.Levels, priceChangePercent)
+	if !ok {
+		return current, nil
+	}
+
+	now := time.Now()
+	resumeAt := now.Add(level.Cooldown)
+
+	if current.Halted {
+		// halt manual tidak diubah, halt otomatis hanya diperpanjang
+		if current.ResumeAt == nil || !resumeAt.After(*current.ResumeAt) {
+			return current, nil
+		}
+
+		current.ResumeAt = &resumeAt
+		if err := s.repo.UpdateResumeAt(current.HaltID, &resumeAt); err != nil {
+			return current, fmt.Errorf("extend halt: %w", err)
+		}
+
+		s.storeAndBroadcast(ctx, current)
+		s.scheduleResume(current.HaltID, level.Cooldown)
+
+		logger.LogWarn("Trading halt extended",
+			zap.Int64("halt_id", current.HaltID),
+			zap.Float64("price_change_percent", priceChangePercent),
+			zap.Time("resume_at", resumeAt),
+		)
+
+		return current, nil
+	}
+
+	change := priceChangePercent
+	threshold := level.ThresholdPercent
+
+	halt := models.TradingHalt{
+		Pair:               pair,
+		Source:             models.HaltSourceAuto,
+		Reason:             fmt.Sprintf("price moved %.2f%% in block %d (threshold %.2f%%)", priceChangePercent, blockNumber, level.ThresholdPercent),
+		PriceChangePercent: &change,
+		ThresholdPercent:   &threshold,
+		BlockNumber:        &blockNumber,
+		HaltedAt:           now,
+		ResumeAt:           &resumeAt,
+	}
+
+	status, err := s.openHalt(ctx, halt)
+	if err != nil {
+		return current, err
+	}
+
+	s.scheduleResume(status.HaltID, level.Cooldown)
+
+	logger.LogWarn("CIRCUIT BREAKER: trading halted",
+		zap.String("pair", pair),
+		zap.Int64("block_number", blockNumber),
+		zap.Float64("price_change_percent", priceChangePercent),
+		zap.Duration("cooldown", level.Cooldown),
+	)
+
+	return status, nil
+}
+
+// Status implements [CircuitBreakerService].
+func (s *circuitBreakerService) Status(ctx context.Context) models.TradingStatus {
+	return s.status(ctx)
+}
+
+// EnsureTradingOpen implements [CircuitBreakerService].
+func (s *circuitBreakerService) EnsureTradingOpen(ctx context.Context) error {
+	status := s.status(ctx)
+	if !status.Halted {
+		return nil
+	}
+
+	if status.ResumeAt != nil {
+		return fmt.Errorf("%w until %s: %s", entity.ErrTradingHalted, status.ResumeAt.Format(time.RFC3339), status.Reason)
+	}
+
+	return fmt.Errorf("%w: %s", entity.ErrTradingHalted, status.Reason)
+}
+
+// Halt implements [CircuitBreakerService].
+// duration 0 = halt sampai di-resume manual
+func (s *circuitBreakerService) Halt(ctx context.Context, adminID int, reason string, duration time.Duration) (models.TradingStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if duration < 0 {
+		return models.TradingStatus{}, fmt.Errorf("%w: duration must not be negative", entity.ErrInvalidInput)
+	}
+
+	if current := s.status(ctx); current.Halted {
+		return current, fmt.Errorf("%w: trading already halted", entity.ErrConflict)
+	}
+
+	if reason == "" {
+		reason = "manual halt"
+	}
+
+	now := time.Now()
+	halt := models.TradingHalt{
+		Pair:     models.DefaultPair,
+		Source:   models.HaltSourceManual,
+		Reason:   reason,
+		HaltedBy: &adminID,
+		HaltedAt: now,
+	}
+
+	if duration > 0 {
+		resumeAt := now.Add(duration)
+		halt.ResumeAt = &resumeAt
+	}
+
+	status, err := s.openHalt(ctx, halt)
+	if err != nil {
+		return models.TradingStatus{}, err
+	}
+
+	if duration > 0 {
+		s.scheduleResume(status.HaltID, duration)
+	} else {
+		s.stopTimer()
+	}
+
+	logger.LogWarn("Trading halted manually", zap.Int("admin_id", adminID), zap.String("reason", reason))
+
+	return status, nil
+}
+
+// Resume implements [CircuitBreakerService].
+func (s *circuitBreakerService) Resume(ctx context.Context, adminID int) (models.TradingStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := s.status(ctx)
+	if !current.Halted {
+		return current, entity.ErrTradingNotHalted
+	}
+
+	return s.resume(ctx, current.HaltID, &adminID)
+}
+
+// History implements [CircuitBreakerService].
+func (s *circuitBreakerService) History(limit, offset int) ([]models.TradingHalt, error) {
+	return s.repo.GetHistory(limit, offset)
+}
+
+// Config implements [CircuitBreakerService].
+func (s *circuitBreakerService) Config() CircuitBreakerConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cfg := s.cfg
+	cfg.Levels = append([]CircuitBreakerLevel(nil), s.cfg.Levels...)
+	return cfg
+}
+
+// UpdateConfig implements [CircuitBreakerService].
+func (s *circuitBreakerService) UpdateConfig(cfg CircuitBreakerConfig) (CircuitBreakerConfig, error) {
+	normalized, err := normalizeBreakerConfig(cfg)
+	if err != nil {
+		return CircuitBreakerConfig{}, err
+	}
+
+	s.mu.Lock()
+	s.cfg = normalized
+	s.mu.Unlock()
+
+	logger.LogInfo("Circuit breaker config updated", zap.Bool("enabled", normalized.Enabled), zap.Int("levels", len(normalized.Levels)))
+
+	return s.Config(), nil
+}
+
+// Restore implements [CircuitBreakerService].
+// saat startup: jadwalkan ulang resume otomatis, atau tutup halt yang cooldown-nya sudah lewat
+func (s *circuitBreakerService) Restore(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	halt, ok, err := s.repo.GetOpen()
+	if err != nil {
+		logger.LogWarn("Failed to restore trading halt", zap.Error(err))
+		return
+	}
+
+	if !ok {
+		return
+	}
+
+	if halt.ResumeAt == nil {
+		// halt manual tetap berlaku sampai admin resume
+		s.store(ctx, haltStatus(halt))
+		return
+	}
+
+	remaining := time.Until(*halt.ResumeAt)
+	if remaining <= 0 {
+		if _, err := s.resume(ctx, halt.ID, nil); err != nil {
+			logger.LogWarn("Failed to close expired trading halt", zap.Error(err))
+		}
+		return
+	}
+
+	s.store(ctx, haltStatus(halt))
+	s.scheduleResume(halt.ID, remaining)
+}
+
+// SubscribeStatus implements [CircuitBreakerService].
+func (s *circuitBreakerService) SubscribeStatus(ctx context.Context, handler func(status models.TradingStatus) error) error {
+	return s.memory.Subscribe(ctx, TradingStatusChannel, func(payload []byte) error {
+		var status models.TradingStatus
+		if err := json.Unmarshal(payload, &status); err != nil {
+			return nil
+		}
+
+		return handler(status)
+	})
+}
+
+func (s *circuitBreakerService) openHalt(ctx context.Context, halt models.TradingHalt) (models.TradingStatus, error) {
+	id, err := s.repo.Create(halt)
+	if err != nil {
+		return models.TradingStatus{}, fmt.Errorf("record trading halt: %w", err)
+	}
+
+	halt.ID = id
+	status := haltStatus(halt)
+	s.storeAndBroadcast(ctx, status)
+
+	return status, nil
+}
+
+func (s *circuitBreakerService) resume(ctx context.Context, haltID int64, adminID *int) (models.TradingStatus, error) {
+	if err := s.repo.MarkResumed(haltID, adminID); err != nil {
+		return models.TradingStatus{}, fmt.Errorf("record trading resume: %w", err)
+	}
+
+	s.stopTimer()
+	s.memory.Del(ctx, tradingStatusKey)
+
+	status := models.TradingStatus{Halted: false}
+	s.broadcast(ctx, status)
+
+	logger.LogInfo("Trading resumed", zap.Int64("halt_id", haltID))
+
+	return status, nil
+}
+
+// scheduleResume - resume otomatis setelah cooldown, timer lama diganti
+func (s *circuitBreakerService) scheduleResume(haltID int64, after time.Duration) {
+	s.stopTimer()
+
+	s.resumeTimer = time.AfterFunc(after, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		// cek ke database, status di redis sudah kedaluwarsa tepat saat cooldown habis
+		open, ok, err := s.repo.GetOpen()
+		if err != nil {
+			logger.LogWarn("Failed to load trading halt for auto resume", zap.Error(err))
+			return
+		}
+
+		if !ok || open.ID != haltID {
+			return
+		}
+
+		if _, err := s.resume(context.Background(), haltID, nil); err != nil {
+			logger.LogWarn("Failed to auto resume trading", zap.Error(err))
+		}
+	})
+}
+
+func (s *circuitBreakerService) stopTimer() {
+	if s.resumeTimer != nil {
+		s.resumeTimer.Stop()
+		s.resumeTimer = nil
+	}
+}
+
+// status - dibaca langsung dari redis: halt / resume dari instance lain harus langsung berlaku,
+// lru cache proses ini bisa menyimpan status lama sampai 5 menit
+func (s *circuitBreakerService) status(ctx context.Context) models.TradingStatus {
+	raw, ok := s.memory.GetFresh(ctx, tradingStatusKey)
+	if !ok {
+		return models.TradingStatus{}
+	}
+
+	var status models.TradingStatus
+	if err := json.Unmarshal(raw, &status); err != nil {
+		return models.TradingStatus{}
+	}
+
+	// cooldown sudah lewat walau timer belum jalan (mis. instance lain)
+	if status.ResumeAt != nil && time.Now().After(*status.ResumeAt) {
+		return models.TradingStatus{}
+	}
+
+	return status
+}
+
+func (s *circuitBreakerService) store(ctx context.Context, status models.TradingStatus) {
+	payload, err := json.Marshal(status)
+	if err != nil {
+		return
+	}
+
+	ttl := manualHaltTTL
+	if status.ResumeAt != nil {
+		ttl = time.Until(*status.ResumeAt)
+	}
+
+	s.memory.Set(ctx, tradingStatusKey, payload, ttl)
+}
+
+func (s *circuitBreakerService) storeAndBroadcast(ctx context.Context, status models.TradingStatus) {
+	s.store(ctx, status)
+	s.broadcast(ctx, status)
+}
+
+// broadcast ke websocket (event trading.status) dan redis channel untuk SSE
+func (s *circuitBreakerService) broadcast(ctx context.Context, status models.TradingStatus) {
+	if s.publisherWS != nil {
+		s.publisherWS.Publish(entity.EventTradingStatus, status)
+	}
+
+	payload, err := json.Marshal(status)
+	if err != nil {
+		return
+	}
+
+	if err := s.memory.Publish(ctx, TradingStatusChannel, payload); err != nil {
+		logger.LogWarn("Failed to publish trading status", zap.Error(err))
+	}
+}
+
+func haltStatus(halt models.TradingHalt) models.TradingStatus {
+	haltedAt := halt.HaltedAt
+
+	return models.TradingStatus{
+		Halted:   true,
+		HaltID:   halt.ID,
+		Source:   halt.Source,
+		Reason:   halt.Reason,
+		HaltedAt: &haltedAt,
+		ResumeAt: halt.ResumeAt,
+	}
+}
+
+// matchBreakerLevel - levels sudah terurut naik, ambil level tertinggi yang terlewati
+func matchBreakerLevel(levels []CircuitBreakerLevel, priceChangePercent float64) (CircuitBreakerLevel, bool) {
+	change := math.Abs(priceChangePercent)
+
+	for i := len(levels) - 1; i >= 0; i-- {
+		if change >= levels[i].ThresholdPercent {
+			return levels[i], true
+		}
+	}
+
+	return CircuitBreakerLevel{}, false
+}
+
+func normalizeBreakerConfig(cfg CircuitBreakerConfig) (CircuitBreakerConfig, error) {
+	levels := append([]CircuitBreakerLevel(nil), cfg.Levels...)
+
+	for _, l := range levels {
+		if l.ThresholdPercent <= 0 {
+			return CircuitBreakerConfig{}, fmt.Errorf("%w: threshold_percent must be greater than zero", entity.ErrInvalidInput)
+		}
+
+		if l.Cooldown <= 0 {
+			return CircuitBreakerConfig{}, fmt.Errorf("%w: cooldown must be greater than zero", entity.ErrInvalidInput)
+		}
+	}
+
+	sort.Slice(levels, func(i, j int) bool {
+		return levels[i].ThresholdPercent < levels[j].ThresholdPercent
+	})
+
+	return CircuitBreakerConfig{Enabled: cfg.Enabled, Levels: levels}, nil
+}
@@ -0,0 +1,335 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+	"github.com/livingdolls/go-blockchain-simulate/app/repository"
+	"github.com/livingdolls/go-blockchain-simulate/redis"
+)
+
+// breakerMemory - pengganti redis: key/value dengan TTL, publish dicatat.
+// cached meniru lru cache proses yang tidak tahu perubahan dari instance lain
+type breakerMemory struct {
+	redis.MemoryAdapter
+
+	mu        sync.Mutex
+	values    map[string][]byte
+	expires   map[string]time.Time
+	cached    map[string][]byte
+	published [][]byte
+}
+
+func newBreakerMemory() *breakerMemory {
+	return &breakerMemory{values: make(map[string][]byte), expires: make(map[string]time.Time), cached: make(map[string][]byte)}
+}
+
+func (m *breakerMemory) Get(ctx context.Context, key string) ([]byte, bool) {
+	m.mu.Lock()
+	v, ok := m.cached[key]
+	m.mu.Unlock()
+
+	if ok {
+		return v, true
+	}
+	return m.GetFresh(ctx, key)
+}
+
+func (m *breakerMemory) GetFresh(_ context.Context, key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if exp, ok := m.expires[key]; ok && time.Now().After(exp) {
+		return nil, false
+	}
+	v, ok := m.values[key]
+	return v, ok
+}
+
+func (m *breakerMemory) Set(_ context.Context, key string, value []byte, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.values[key] = value
+	m.expires[key] = time.Now().Add(ttl)
+}
+
+func (m *breakerMemory) Del(_ context.Context, key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.values, key)
+	delete(m.expires, key)
+}
+
+func (m *breakerMemory) Publish(_ context.Context, _ string, message []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.published = append(m.published, message)
+	return nil
+}
+
+// haltRepo - tabel trading_halts di memory
+type haltRepo struct {
+	repository.TradingHaltRepository
+
+	mu    sync.Mutex
+	halts []models.TradingHalt
+}
+
+func (r *haltRepo) Create(halt models.TradingHalt) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	halt.ID = int64(len(r.halts) + 1)
+	r.halts = append(r.halts, halt)
+	return halt.ID, nil
+}
+
+func (r *haltRepo) GetOpen() (models.TradingHalt, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := len(r.halts) - 1; i >= 0; i-- {
+		if r.halts[i].ResumedAt == nil {
+			return r.halts[i], true, nil
+		}
+	}
+	return models.TradingHalt{}, false, nil
+}
+
+func (r *haltRepo) UpdateResumeAt(id int64, resumeAt *time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.halts[id-1].ResumeAt = resumeAt
+	return nil
+}
+
+func (r *haltRepo) MarkResumed(id int64, resumedBy *int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.halts[id-1].ResumedAt = &now
+	r.halts[id-1].ResumedBy = resumedBy
+	return nil
+}
+
+func (r *haltRepo) get(id int64) models.TradingHalt {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.halts[id-1]
+}
+
+func (r *haltRepo) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.halts)
+}
+
+func newTestBreaker(levels ...CircuitBreakerLevel) (*circuitBreakerService, *haltRepo, *breakerMemory) {
+	repo := &haltRepo{}
+	memory := newBreakerMemory()
+
+	service := NewCircuitBreakerService(repo, memory, nil, CircuitBreakerConfig{Enabled: true, Levels: levels})
+	return service.(*circuitBreakerService), repo, memory
+}
+
+func TestCircuitBreakerHaltsAboveThreshold(t *testing.T) {
+	breaker, repo, memory := newTestBreaker(
+		CircuitBreakerLevel{ThresholdPercent: 20, Cooldown: time.Hour},
+		CircuitBreakerLevel{ThresholdPercent: 10, Cooldown: time.Minute},
+	)
+	defer breaker.stopTimer()
+
+	ctx := context.Background()
+
+	// di bawah threshold terendah: trading tetap buka
+	status, err := breaker.Evaluate(ctx, models.DefaultPair, 1, 9.99)
+	if err != nil || status.Halted {
+		t.Fatalf("Evaluate(9.99) = %+v, %v", status, err)
+	}
+	if err := breaker.EnsureTradingOpen(ctx); err != nil {
+		t.Fatalf("EnsureTradingOpen = %v, want nil", err)
+	}
+
+	// turun 25% melewati level tertinggi, cooldown level itu yang dipakai
+	before := time.Now()
+	status, err = breaker.Evaluate(ctx, models.DefaultPair, 2, -25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Halted || status.Source != models.HaltSourceAuto || status.ResumeAt == nil {
+		t.Fatalf("status = %+v, want auto halt", status)
+	}
+	if status.ResumeAt.Sub(before) < time.Hour-time.Second {
+		t.Fatalf("resume_at = %s, want cooldown of the 20%% level", status.ResumeAt)
+	}
+
+	if err := breaker.EnsureTradingOpen(ctx); !errors.Is(err, entity.ErrTradingHalted) {
+		t.Fatalf("EnsureTradingOpen = %v, want ErrTradingHalted", err)
+	}
+
+	halt := repo.get(status.HaltID)
+	if halt.BlockNumber == nil || *halt.BlockNumber != 2 || halt.ThresholdPercent == nil || *halt.ThresholdPercent != 20 {
+		t.Fatalf("recorded halt = %+v", halt)
+	}
+
+	if len(memory.published) != 1 {
+		t.Fatalf("status broadcasts = %d, want 1", len(memory.published))
+	}
+}
+
+func TestCircuitBreakerExtendsAutoHaltOnly(t *testing.T) {
+	breaker, repo, _ := newTestBreaker(
+		CircuitBreakerLevel{ThresholdPercent: 10, Cooldown: time.Minute},
+		CircuitBreakerLevel{ThresholdPercent: 20, Cooldown: time.Hour},
+	)
+	defer breaker.stopTimer()
+
+	ctx := context.Background()
+
+	first, err := breaker.Evaluate(ctx, models.DefaultPair, 1, 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	extended, err := breaker.Evaluate(ctx, models.DefaultPair, 2, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if extended.HaltID != first.HaltID || !extended.ResumeAt.After(first.ResumeAt.Add(30*time.Minute)) {
+		t.Fatalf("extended = %+v, want same halt with later resume_at", extended)
+	}
+
+	// cooldown lebih pendek tidak memperpendek halt yang sedang berjalan
+	same, err := breaker.Evaluate(ctx, models.DefaultPair, 3, 11)
+	if err != nil || !same.ResumeAt.Equal(*extended.ResumeAt) {
+		t.Fatalf("Evaluate(11) = %+v, %v", same, err)
+	}
+
+	if repo.count() != 1 || !repo.get(first.HaltID).ResumeAt.Equal(*extended.ResumeAt) {
+		t.Fatalf("halts = %+v", repo.halts)
+	}
+
+	// halt manual tidak diubah oleh breaker otomatis
+	if _, err := breaker.Resume(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	manual, err := breaker.Halt(ctx, 1, "maintenance", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := breaker.Evaluate(ctx, models.DefaultPair, 4, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.HaltID != manual.HaltID || status.ResumeAt != nil || status.Source != models.HaltSourceManual {
+		t.Fatalf("status = %+v, want untouched manual halt", status)
+	}
+}
+
+func TestCircuitBreakerAutoResumesAfterCooldown(t *testing.T) {
+	breaker, repo, _ := newTestBreaker(CircuitBreakerLevel{ThresholdPercent: 5, Cooldown: 50 * time.Millisecond})
+	defer breaker.stopTimer()
+
+	ctx := context.Background()
+
+	status, err := breaker.Evaluate(ctx, models.DefaultPair, 1, 6)
+	if err != nil || !status.Halted {
+		t.Fatalf("Evaluate = %+v, %v", status, err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for repo.get(status.HaltID).ResumedAt == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("halt was not resumed after cooldown")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := breaker.EnsureTradingOpen(ctx); err != nil {
+		t.Fatalf("EnsureTradingOpen after cooldown = %v", err)
+	}
+	if resumedBy := repo.get(status.HaltID).ResumedBy; resumedBy != nil {
+		t.Fatalf("auto resume recorded admin %d", *resumedBy)
+	}
+}
+
+func TestCircuitBreakerRestore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("expired halt is closed", func(t *testing.T) {
+		breaker, repo, _ := newTestBreaker()
+		defer breaker.stopTimer()
+
+		past := time.Now().Add(-time.Minute)
+		id, _ := repo.Create(models.TradingHalt{Source: models.HaltSourceAuto, HaltedAt: past.Add(-time.Minute), ResumeAt: &past})
+
+		breaker.Restore(ctx)
+
+		if repo.get(id).ResumedAt == nil {
+			t.Fatal("expired halt was not closed")
+		}
+		if err := breaker.EnsureTradingOpen(ctx); err != nil {
+			t.Fatalf("EnsureTradingOpen = %v", err)
+		}
+	})
+
+	t.Run("manual halt stays in effect", func(t *testing.T) {
+		breaker, repo, _ := newTestBreaker()
+		defer breaker.stopTimer()
+
+		id, _ := repo.Create(models.TradingHalt{Source: models.HaltSourceManual, Reason: "maintenance", HaltedAt: time.Now()})
+
+		breaker.Restore(ctx)
+
+		if err := breaker.EnsureTradingOpen(ctx); !errors.Is(err, entity.ErrTradingHalted) {
+			t.Fatalf("EnsureTradingOpen = %v, want ErrTradingHalted", err)
+		}
+		if repo.get(id).ResumedAt != nil {
+			t.Fatal("manual halt must not be resumed on restore")
+		}
+	})
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	repo := &haltRepo{}
+	service := NewCircuitBreakerService(repo, newBreakerMemory(), nil, CircuitBreakerConfig{
+		Levels: []CircuitBreakerLevel{{ThresholdPercent: 1, Cooldown: time.Minute}},
+	})
+
+	status, err := service.Evaluate(context.Background(), models.DefaultPair, 1, 90)
+	if err != nil || status.Halted || repo.count() != 0 {
+		t.Fatalf("disabled breaker halted trading: %+v, %v", status, err)
+	}
+}
+
+func TestCircuitBreakerReadsSharedStatus(t *testing.T) {
+	breaker, _, memory := newTestBreaker()
+	defer breaker.stopTimer()
+
+	ctx := context.Background()
+
+	if _, err := breaker.Halt(ctx, 1, "maintenance", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// instance lain melanjutkan trading, cache lokal proses ini masih menyimpan status halt
+	memory.mu.Lock()
+	memory.cached[tradingStatusKey] = memory.values[tradingStatusKey]
+	memory.mu.Unlock()
+	memory.Del(ctx, tradingStatusKey)
+
+	if err := breaker.EnsureTradingOpen(ctx); err != nil {
+		t.Fatalf("EnsureTradingOpen = %v, want status from redis instead of the stale local cache", err)
+	}
+}
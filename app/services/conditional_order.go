@@ -0,0 +1,284 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/livingdolls/go-blockchain-simulate/app/dto"
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+	"github.com/livingdolls/go-blockchain-simulate/app/publisher"
+	"github.com/livingdolls/go-blockchain-simulate/app/repository"
+	"github.com/livingdolls/go-blockchain-simulate/logger"
+	"go.uber.org/zap"
+)
+
+type ConditionalOrderService interface {
+	PlaceOrder(ctx context.Context, req dto.PlaceConditionalOrderRequest) (models.ConditionalOrder, error)
+	CancelOrder(address string, id int64) error
+	GetOrders(address, status string) ([]models.ConditionalOrder, error)
+	EvaluatePrice(ctx context.Context, price float64, blockNumber int64)
+}
+
+type conditionalOrderService struct {
+	orders      repository.ConditionalOrderRepository
+	users       repository.UserRepository
+	market      repository.MarketRepository
+	txService   TransactionService
+	txVerify    VerifyTxService
+	publisherWS *publisher.PublisherWS
+}
+
+func NewConditionalOrderService(
+	orders repository.ConditionalOrderRepository,
+	users repository.UserRepository,
+	market repository.MarketRepository,
+	txService TransactionService,
+	txVerify VerifyTxService,
+	publisherWS *publisher.PublisherWS,
+) ConditionalOrderService {
+	return &conditionalOrderService{
+		orders:      orders,
+		users:       users,
+		market:      market,
+		txService:   txService,
+		txVerify:    txVerify,
+		publisherWS: publisherWS,
+	}
+}
+
+// PlaceOrder implements [ConditionalOrderService].
+// signature diverifikasi saat order dibuat, lalu dipakai ulang sebagai signature transaksi saat trigger
+func (s *conditionalOrderService) PlaceOrder(ctx context.Context, req dto.PlaceConditionalOrderRequest) (models.ConditionalOrder, error) {
+	orderType := strings.ToUpper(strings.TrimSpace(req.OrderType))
+	side := TransactionType(strings.ToUpper(strings.TrimSpace(req.Side)))
+
+	if req.Amount <= 0 {
+		return models.ConditionalOrder{}, entity.ErrAmountMustBePositive
+	}
+
+	if side != BuyTransaction && side != SellTransaction {
+		return models.ConditionalOrder{}, entity.ErrInvalidTransactionType
+	}
+
+	switch orderType {
+	case models.ConditionalStopLoss, models.ConditionalTakeProfit:
+		if req.TriggerPrice <= 0 {
+			return models.ConditionalOrder{}, fmt.Errorf("%w: trigger_price must be greater than zero", entity.ErrInvalidConditionalOrder)
+		}
+	case models.ConditionalTrailingStop:
+		if req.TrailPercent <= 0 || req.TrailPercent >= 100 {
+			return models.ConditionalOrder{}, fmt.Errorf("%w: trail_percent must be between 0 and 100", entity.ErrInvalidConditionalOrder)
+		}
+	default:
+		return models.ConditionalOrder{}, fmt.Errorf("%w: unknown order type %s", entity.ErrInvalidConditionalOrder, req.OrderType)
+	}
+
+	if _, err := s.users.GetByAddress(req.Address); err != nil {
+		return models.ConditionalOrder{}, entity.ErrAddressNotFound
+	}
+
+	payload := ConditionalOrderSigningPayload{
+		OrderType:    orderType,
+		Side:         side,
+		Amount:       req.Amount,
+		TriggerPrice: req.TriggerPrice,
+		TrailPercent: req.TrailPercent,
+	}
+
+	if err := s.txVerify.VerifyConditionalOrderSignature(ctx, req.Address, payload, req.Nonce, req.Signature); err != nil {
+		return models.ConditionalOrder{}, fmt.Errorf("%w: %w", entity.ErrSignatureVerificationFailed, err)
+	}
+
+	order := models.ConditionalOrder{
+		UserAddress:  req.Address,
+		OrderType:    orderType,
+		Side:         string(side),
+		Amount:       req.Amount,
+		TriggerPrice: req.TriggerPrice,
+		TrailPercent: req.TrailPercent,
+		Signature:    req.Signature,
+	}
+
+	// trailing stop dimulai dari harga market saat ini
+	if orderType == models.ConditionalTrailingStop {
+		state, err := s.market.GetState()
+		if err != nil {
+			return models.ConditionalOrder{}, fmt.Errorf("get market state: %v", err)
+		}
+
+		order.ReferencePrice = state.Price
+	}
+
+	id, err := s.orders.Create(order)
+	if err != nil {
+		return models.ConditionalOrder{}, fmt.Errorf("create conditional order: %v", err)
+	}
+
+	return s.orders.GetByID(id)
+}
+
+// CancelOrder implements [ConditionalOrderService].
+func (s *conditionalOrderService) CancelOrder(address string, id int64) error {
+	order, err := s.orders.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	if !strings.EqualFold(order.UserAddress, address) {
+		return entity.ErrUnauthorized
+	}
+
+	cancelled, err := s.orders.Cancel(id)
+	if err != nil {
+		return fmt.Errorf("cancel conditional order: %v", err)
+	}
+
+	if !cancelled {
+		return entity.ErrConditionalOrderNotActive
+	}
+
+	s.notify(id)
+
+	return nil
+}
+
+// GetOrders implements [ConditionalOrderService].
+func (s *conditionalOrderService) GetOrders(address, status string) ([]models.ConditionalOrder, error) {
+	if address == "" {
+		return nil, entity.ErrAddressNotFound
+	}
+
+	return s.orders.GetByAddress(address, strings.ToUpper(strings.TrimSpace(status)))
+}
+
+// EvaluatePrice implements [ConditionalOrderService].
+// dipanggil setiap ada harga block baru dari MarketPricingConsumer
+func (s *conditionalOrderService) EvaluatePrice(ctx context.Context, price float64, blockNumber int64) {
+	if price <= 0 {
+		return
+	}
+
+	orders, err := s.orders.GetActive()
+	if err != nil {
+		logger.LogError("Failed to load active conditional orders", err)
+		return
+	}
+
+	for _, order := range orders {
+		if ctx.Err() != nil {
+			return
+		}
+
+		triggered, reference := evaluateCondition(order, price)
+
+		if !triggered {
+			if reference != order.ReferencePrice {
+				if err := s.orders.UpdateReferencePrice(order.ID, reference); err != nil {
+					logger.LogError("Failed to update trailing reference price", err)
+				}
+			}
+			continue
+		}
+
+		s.execute(ctx, order, price, blockNumber)
+	}
+}
+
+// execute mengubah order yang ter-trigger menjadi transaksi BUY/SELL biasa
+func (s *conditionalOrderService) execute(ctx context.Context, order models.ConditionalOrder, price float64, blockNumber int64) {
+	// claim order agar tidak dieksekusi dua kali oleh worker lain
+	claimed, err := s.orders.MarkTriggered(order.ID, price, blockNumber)
+	if err != nil {
+		logger.LogError("Failed to mark conditional order as triggered", err)
+		return
+	}
+
+	if !claimed {
+		return
+	}
+
+	logger.LogInfo("Conditional order triggered",
+		zap.Int64("order_id", order.ID),
+		zap.String("order_type", order.OrderType),
+		zap.String("side", order.Side),
+		zap.Float64("price", price),
+		zap.Int64("block_number", blockNumber),
+	)
+
+	tx, err := s.txService.SubmitPreAuthorized(ctx, order.UserAddress, order.Signature, order.Amount, TransactionType(order.Side))
+	if err != nil {
+		if markErr := s.orders.MarkFailed(order.ID, truncateReason(err.Error())); markErr != nil {
+			logger.LogError("Failed to mark conditional order as failed", markErr)
+		}
+
+		logger.LogWarn("Conditional order execution failed",
+			zap.Int64("order_id", order.ID),
+			zap.Error(err),
+		)
+	} else if err := s.orders.MarkExecuted(order.ID, tx.ID); err != nil {
+		logger.LogError("Failed to mark conditional order as executed", err)
+	}
+
+	s.notify(order.ID)
+}
+
+// notify kirim status order terbaru ke pemilik order
+func (s *conditionalOrderService) notify(id int64) {
+	if s.publisherWS == nil {
+		return
+	}
+
+	order, err := s.orders.GetByID(id)
+	if err != nil {
+		logger.LogError("Failed to load conditional order for notification", err)
+		return
+	}
+
+	s.publisherWS.PublishToAddress(strings.ToLower(order.UserAddress), entity.EventConditionalOrder, order)
+}
+
+// evaluateCondition mengembalikan apakah order ter-trigger pada harga ini
+// beserta reference price terbaru (peak/trough) untuk trailing stop
+func evaluateCondition(order models.ConditionalOrder, price float64) (bool, float64) {
+	switch order.OrderType {
+	case models.ConditionalStopLoss:
+		if order.Side == string(SellTransaction) {
+			return price <= order.TriggerPrice, order.ReferencePrice
+		}
+		return price >= order.TriggerPrice, order.ReferencePrice
+
+	case models.ConditionalTakeProfit:
+		if order.Side == string(SellTransaction) {
+			return price >= order.TriggerPrice, order.ReferencePrice
+		}
+		return price <= order.TriggerPrice, order.ReferencePrice
+
+	case models.ConditionalTrailingStop:
+		reference := order.ReferencePrice
+
+		// SELL mengikuti harga tertinggi, BUY mengikuti harga terendah
+		if order.Side == string(SellTransaction) {
+			if reference <= 0 || price > reference {
+				reference = price
+			}
+			return price <= reference*(1-order.TrailPercent/100), reference
+		}
+
+		if reference <= 0 || price < reference {
+			reference = price
+		}
+		return price >= reference*(1+order.TrailPercent/100), reference
+	}
+
+	return false, order.ReferencePrice
+}
+
+func truncateReason(reason string) string {
+	const maxLen = 255
+	if len(reason) > maxLen {
+		return reason[:maxLen]
+	}
+	return reason
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+)
+
+func TestEvaluateCondition(t *testing.T) {
+	sell, buy := string(SellTransaction), string(BuyTransaction)
+
+	tests := []struct {
+		name          string
+		order         models.ConditionalOrder
+		price         float64
+		wantTriggered bool
+		wantReference float64
+	}{
+		// stop loss: SELL saat harga turun ke trigger, BUY saat harga naik ke trigger
+		{name: "stop loss sell above trigger", order: models.ConditionalOrder{OrderType: models.ConditionalStopLoss, Side: sell, TriggerPrice: 90, ReferencePrice: 42}, price: 90.01, wantReference: 42},
+		{name: "stop loss sell at trigger", order: models.ConditionalOrder{OrderType: models.ConditionalStopLoss, Side: sell, TriggerPrice: 90, ReferencePrice: 42}, price: 90, wantTriggered: true, wantReference: 42},
+		{name: "stop loss sell below trigger", order: models.ConditionalOrder{OrderType: models.ConditionalStopLoss, Side: sell, TriggerPrice: 90}, price: 80, wantTriggered: true},
+		{name: "stop loss buy below trigger", order: models.ConditionalOrder{OrderType: models.ConditionalStopLoss, Side: buy, TriggerPrice: 110}, price: 109.99},
+		{name: "stop loss buy at trigger", order: models.ConditionalOrder{OrderType: models.ConditionalStopLoss, Side: buy, TriggerPrice: 110}, price: 110, wantTriggered: true},
+		{name: "stop loss buy above trigger", order: models.ConditionalOrder{OrderType: models.ConditionalStopLoss, Side: buy, TriggerPrice: 110}, price: 120, wantTriggered: true},
+
+		// take profit: kebalikan stop loss
+		{name: "take profit sell below trigger", order: models.ConditionalOrder{OrderType: models.ConditionalTakeProfit, Side: sell, TriggerPrice: 110}, price: 109.99},
+		{name: "take profit sell at trigger", order: models.ConditionalOrder{OrderType: models.ConditionalTakeProfit, Side: sell, TriggerPrice: 110, ReferencePrice: 42}, price: 110, wantTriggered: true, wantReference: 42},
+		{name: "take profit sell above trigger", order: models.ConditionalOrder{OrderType: models.ConditionalTakeProfit, Side: sell, TriggerPrice: 110}, price: 130, wantTriggered: true},
+		{name: "take profit buy above trigger", order: models.ConditionalOrder{OrderType: models.ConditionalTakeProfit, Side: buy, TriggerPrice: 90}, price: 90.01},
+		{name: "take profit buy at trigger", order: models.ConditionalOrder{OrderType: models.ConditionalTakeProfit, Side: buy, TriggerPrice: 90}, price: 90, wantTriggered: true},
+		{name: "take profit buy below trigger", order: models.ConditionalOrder{OrderType: models.ConditionalTakeProfit, Side: buy, TriggerPrice: 90}, price: 70, wantTriggered: true},
+
+		// trailing stop SELL: reference = harga tertinggi, trigger 25% di bawahnya
+		{name: "trailing sell sets first reference", order: models.ConditionalOrder{OrderType: models.ConditionalTrailingStop, Side: sell, TrailPercent: 25}, price: 100, wantReference: 100},
+		{name: "trailing sell peak moves up", order: models.ConditionalOrder{OrderType: models.ConditionalTrailingStop, Side: sell, TrailPercent: 25, ReferencePrice: 100}, price: 120, wantReference: 120},
+		{name: "trailing sell peak does not move down", order: models.ConditionalOrder{OrderType: models.ConditionalTrailingStop, Side: sell, TrailPercent: 25, ReferencePrice: 100}, price: 80, wantReference: 100},
+		{name: "trailing sell at trail", order: models.ConditionalOrder{OrderType: models.ConditionalTrailingStop, Side: sell, TrailPercent: 25, ReferencePrice: 100}, price: 75, wantTriggered: true, wantReference: 100},
+		{name: "trailing sell below raised peak", order: models.ConditionalOrder{OrderType: models.ConditionalTrailingStop, Side: sell, TrailPercent: 25, ReferencePrice: 120}, price: 89, wantTriggered: true, wantReference: 120},
+
+		// trailing stop BUY: reference = harga terendah, trigger 25% di atasnya
+		{name: "trailing buy sets first reference", order: models.ConditionalOrder{OrderType: models.ConditionalTrailingStop, Side: buy, TrailPercent: 25}, price: 80, wantReference: 80},
+		{name: "trailing buy trough moves down", order: models.ConditionalOrder{OrderType: models.ConditionalTrailingStop, Side: buy, TrailPercent: 25, ReferencePrice: 80}, price: 60, wantReference: 60},
+		{name: "trailing buy trough does not move up", order: models.ConditionalOrder{OrderType: models.ConditionalTrailingStop, Side: buy, TrailPercent: 25, ReferencePrice: 80}, price: 96, wantReference: 80},
+		{name: "trailing buy at trail", order: models.ConditionalOrder{OrderType: models.ConditionalTrailingStop, Side: buy, TrailPercent: 25, ReferencePrice: 80}, price: 100, wantTriggered: true, wantReference: 80},
+		{name: "trailing buy above lowered trough", order: models.ConditionalOrder{OrderType: models.ConditionalTrailingStop, Side: buy, TrailPercent: 25, ReferencePrice: 60}, price: 76, wantTriggered: true, wantReference: 60},
+
+		{name: "unknown type", order: models.ConditionalOrder{OrderType: "LIMIT", Side: sell, TriggerPrice: 100, ReferencePrice: 42}, price: 100, wantReference: 42},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			triggered, reference := evaluateCondition(tt.order, tt.price)
+			if triggered != tt.wantTriggered || reference != tt.wantReference {
+				t.Fatalf("evaluateCondition(%s %s, %v) = %t, %v; want %t, %v",
+					tt.order.OrderType, tt.order.Side, tt.price, triggered, reference, tt.wantTriggered, tt.wantReference)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+	"github.com/livingdolls/go-blockchain-simulate/app/port"
+	"github.com/livingdolls/go-blockchain-simulate/logger"
+	"go.uber.org/zap"
+)
+
+const maxDeadLetterBatch = 1000
+
+// DeadLetterService - inspect, replay dan purge pesan yang diparkir di <queue>.dead
+type DeadLetterService interface {
+	Stats() ([]models.DeadLetterQueueStats, error)
+	Peek(queue string, limit int) ([]models.DeadLetter, error)
+	Replay(ctx context.Context, queue string, limit int) (int, error)
+	Purge(queue string) (int, error)
+}
+
+type deadLetterService struct {
+	rmqClient port.MessageBroker
+	queues    []string
+	known     map[string]bool
+}
+
+// NewDeadLetterService - queues: nama queue kerja yang dideklarasikan dengan dead letter topology
+func NewDeadLetterService(rmqClient port.MessageBroker, queues []string) DeadLetterService {
+	known := make(map[string]bool, len(queues))
+	for _, q := range queues {
+		known[q] = true
+	}
+
+	return &deadLetterService{
+		rmqClient: rmqClient,
+		queues:    queues,
+		known:     known,
+	}
+}
+
+// Stats implements [DeadLetterService].
+func (s *deadLetterService) Stats() ([]models.DeadLetterQueueStats, error) {
+	return s.rmqClient.DeadLetterStats(s.queues)
+}
+
+// Peek implements [DeadLetterService].
+// pesan dikembalikan ke parking queue, tidak ada yang hilang
+func (s *deadLetterService) Peek(queue string, limit int) ([]models.DeadLetter, error) {
+	if err := s.validate(queue, limit); err != nil {
+		return nil, err
+	}
+
+	return s.rmqClient.PeekDeadLetters(queue, limit)
+}
+
+// Replay implements [DeadLetterService].
+func (s *deadLetterService) Replay(ctx context.Context, queue string, limit int) (int, error) {
+	if err := s.validate(queue, limit); err != nil {
+		return 0, err
+	}
+
+	replayed, err := s.rmqClient.ReplayDeadLetters(ctx, queue, limit)
+	if replayed > 0 {
+		logger.LogInfo("Dead letters replayed", zap.String("queue", queue), zap.Int("count", replayed))
+	}
+	return replayed, err
+}
+
+// Purge implements [DeadLetterService].
+func (s *deadLetterService) Purge(queue string) (int, error) {
+	if !s.known[queue] {
+		return 0, fmt.Errorf("%w: %s", entity.ErrUnknownQueue, queue)
+	}
+
+	purged, err := s.rmqClient.PurgeDeadLetters(queue)
+	if err == nil {
+		logger.LogWarn("Dead letters purged", zap.String("queue", queue), zap.Int("count", purged))
+	}
+	return purged, err
+}
+
+func (s *deadLetterService) validate(queue string, limit int) error {
+	if !s.known[queue] {
+		return fmt.Errorf("%w: %s", entity.ErrUnknownQueue, queue)
+	}
+
+	if limit <= 0 || limit > maxDeadLetterBatch {
+		return fmt.Errorf("%w: limit must be between 1 and %d", entity.ErrInvalidInput, maxDeadLetterBatch)
+	}
+
+	return nil
+}
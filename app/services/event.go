@@ -8,22 +8,22 @@ import (
 )
 
 type EventService struct {
-	broker port.MessageBroker
+	broker port.PubSub
 }
 
-func NewEventService(broker port.MessageBroker) port.MessageBroker {
+func NewEventService(broker port.PubSub) port.PubSub {
 	return &EventService{
 		broker: broker,
 	}
 }
 
-// Publish implements [port.MessageBroker].
+// Publish implements [port.PubSub].
 func (e *EventService) Publish(ctx context.Context, channel string, payload []byte) error {
 	logger.LogInfo("event published : channel=" + channel + " payload=" + string(payload))
 	return e.broker.Publish(ctx, channel, payload)
 }
 
-// Subscribe implements [port.MessageBroker].
+// Subscribe implements [port.PubSub].
 func (e *EventService) Subscribe(ctx context.Context, channel string, callback func([]byte) error) error {
 	return e.broker.Subscribe(
 		ctx, channel, func(msg []byte) error {
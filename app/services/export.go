@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/livingdolls/go-blockchain-simulate/app/dto"
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+	"github.com/livingdolls/go-blockchain-simulate/app/export"
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+	"github.com/livingdolls/go-blockchain-simulate/app/repository"
+)
+
+const (
+	ExportDatasetCandles = "candles"
+	ExportDatasetTicks   = "ticks"
+	ExportDatasetTrades  = "trades"
+)
+
+// ExportQuery - rentang [From, To) dalam unix seconds, Interval hanya untuk candles
+type ExportQuery struct {
+	Dataset  string
+	Pair     string
+	Interval string
+	From     int64
+	To       int64
+}
+
+type ExportService interface {
+	// Validate - cek query & format sebelum response mulai ditulis
+	Validate(format string, query ExportQuery) (ExportQuery, error)
+	// Export - tulis seluruh row ke w, mengembalikan jumlah row
+	Export(ctx context.Context, w io.Writer, format string, query ExportQuery) (int64, error)
+}
+
+type exportService struct {
+	repo repository.ExportRepository
+}
+
+func NewExportService(repo repository.ExportRepository) ExportService {
+	return &exportService{repo: repo}
+}
+
+// Validate implements [ExportService].
+func (e *exportService) Validate(format string, query ExportQuery) (ExportQuery, error) {
+	switch format {
+	case export.FormatCSV, export.FormatNDJSON, export.FormatColumnar:
+	default:
+		return query, fmt.Errorf("%w: %s", entity.ErrInvalidExportFormat, format)
+	}
+
+	query.Pair = NormalizePair(query.Pair)
+
+	if query.To == 0 {
+		query.To = time.Now().Unix()
+	}
+
+	if query.From < 0 || query.To <= query.From {
+		return query, fmt.Errorf("%w: from must be before to", entity.ErrInvalidExportRange)
+	}
+
+	switch query.Dataset {
+	case ExportDatasetCandles:
+		if query.Interval == "" {
+			query.Interval = "1m"
+		}
+		if !dto.IsValidInterval(query.Interval) {
+			return query, fmt.Errorf("%w: invalid interval %q", entity.ErrInvalidExportRange, query.Interval)
+		}
+	case ExportDatasetTicks, ExportDatasetTrades:
+	default:
+		return query, fmt.Errorf("unknown export dataset: %s", query.Dataset)
+	}
+
+	return query, nil
+}
+
+// Export implements [ExportService].
+func (e *exportService) Export(ctx context.Context, w io.Writer, format string, query ExportQuery) (int64, error) {
+	query, err := e.Validate(format, query)
+	if err != nil {
+		return 0, err
+	}
+
+	var columns []export.Column
+	switch query.Dataset {
+	case ExportDatasetCandles:
+		columns = export.CandleColumns
+	case ExportDatasetTicks:
+		columns = export.TickColumns
+	case ExportDatasetTrades:
+		columns = export.TradeColumns
+	}
+
+	writer, err := export.NewWriter(format, w, columns)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	write := func(row []any) error {
+		count++
+		return writer.WriteRow(row)
+	}
+
+	switch query.Dataset {
+	case ExportDatasetCandles:
+		err = e.repo.StreamCandles(ctx, query.Pair, query.Interval, query.From, query.To, func(c models.Candle) error {
+			return write(export.CandleRow(c))
+		})
+	case ExportDatasetTicks:
+		err = e.repo.StreamTicks(ctx, query.Pair, query.From, query.To, func(t models.MarketTick) error {
+			return write(export.TickRow(t))
+		})
+	case ExportDatasetTrades:
+		// BUY/SELL hanya ada untuk default pair
+		if query.Pair == models.DefaultPair {
+			err = e.repo.StreamTrades(ctx, query.From, query.To, func(t models.Trade) error {
+				return write(export.TradeRow(t))
+			})
+		}
+	}
+
+	if err != nil {
+		return count, err
+	}
+
+	return count, writer.Close()
+}
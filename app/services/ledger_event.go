@@ -0,0 +1,19 @@
+package services
+
+import (
+	"time"
+
+	"github.com/livingdolls/go-blockchain-simulate/app/dto"
+)
+
+// NewLedgerBatchEvent - payload event outbox ledger.batch, dipublish relay outbox
+func NewLedgerBatchEvent(blockID int64, blockNumber int, entries []dto.LedgerEntryEvent, minerAddress string) dto.LedgerBatchEvent {
+	return dto.LedgerBatchEvent{
+		BlockID:      blockID,
+		BlockNumber:  blockNumber,
+		TotalEntries: len(entries),
+		Entries:      entries,
+		Timestamp:    time.Now().Unix(),
+		MinerAddress: minerAddress,
+	}
+}
@@ -0,0 +1,437 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+	"github.com/livingdolls/go-blockchain-simulate/app/repository"
+)
+
+// rebuildRepo - history dan tabel materialized di memory, transaction dari sqlmock
+type rebuildRepo struct {
+	repository.LedgerRebuildRepository
+	db *sqlx.DB
+
+	transactions  []models.RebuildTransaction
+	ledger        []models.BalanceChange
+	tokenLedger   []models.BalanceChange
+	rewards       []models.BalanceChange
+	deposits      []models.BalanceChange
+	ticks         []models.MarketTick
+	wallets       []models.UserWallet
+	balances      []models.UserBalance
+	assetBalances []models.AssetBalance
+	market        *models.MarketEngine
+	otherPairs    []string
+
+	walletWrites map[string]float64
+	usdWrites    map[string]models.USDState
+	assetWrites  map[string]float64
+}
+
+// rebuildMarketRepo - hanya UpdateStateWithTx yang dipakai apply
+type rebuildMarketRepo struct {
+	repository.MarketRepository
+	written *models.MarketEngine
+}
+
+func (r *rebuildMarketRepo) UpdateStateWithTx(_ *sqlx.Tx, market models.MarketEngine) error {
+	r.written = &market
+	return nil
+}
+
+func streamChanges(changes []models.BalanceChange, fn func(models.BalanceChange) error) error {
+	for _, c := range changes {
+		if err := fn(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *rebuildRepo) BeginTx() (*sqlx.Tx, error) { return r.db.Beginx() }
+
+func (r *rebuildRepo) LockMaterializedWithTx(*sqlx.Tx) error { return nil }
+
+func (r *rebuildRepo) StreamTransactionsWithTx(_ context.Context, _ *sqlx.Tx, fn func(models.RebuildTransaction) error) error {
+	for _, t := range r.transactions {
+		if err := fn(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *rebuildRepo) StreamLedgerChangesWithTx(_ context.Context, _ *sqlx.Tx, _ string, fn func(models.BalanceChange) error) error {
+	return streamChanges(r.ledger, fn)
+}
+
+func (r *rebuildRepo) StreamTokenLedgerChangesWithTx(_ context.Context, _ *sqlx.Tx, fn func(models.BalanceChange) error) error {
+	return streamChanges(r.tokenLedger, fn)
+}
+
+func (r *rebuildRepo) StreamMiningRewardsWithTx(_ context.Context, _ *sqlx.Tx, fn func(models.BalanceChange) error) error {
+	return streamChanges(r.rewards, fn)
+}
+
+func (r *rebuildRepo) StreamDepositsWithTx(_ context.Context, _ *sqlx.Tx, fn func(models.BalanceChange) error) error {
+	return streamChanges(r.deposits, fn)
+}
+
+func (r *rebuildRepo) StreamTicksWithTx(_ context.Context, _ *sqlx.Tx, _ string, fn func(models.MarketTick) error) error {
+	for _, tick := range r.ticks {
+		if err := fn(tick); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *rebuildRepo) GetWalletsWithTx(context.Context, *sqlx.Tx) ([]models.UserWallet, error) {
+	return r.wallets, nil
+}
+
+func (r *rebuildRepo) GetUSDBalancesWithTx(context.Context, *sqlx.Tx) ([]models.UserBalance, error) {
+	return r.balances, nil
+}
+
+func (r *rebuildRepo) GetAssetBalancesWithTx(context.Context, *sqlx.Tx) ([]models.AssetBalance, error) {
+	return r.assetBalances, nil
+}
+
+func (r *rebuildRepo) GetMarketStateWithTx(context.Context, *sqlx.Tx, string) (models.MarketEngine, error) {
+	if r.market == nil {
+		return models.MarketEngine{}, sql.ErrNoRows
+	}
+	return *r.market, nil
+}
+
+func (r *rebuildRepo) GetOtherPairsWithTx(context.Context, *sqlx.Tx, string) ([]string, error) {
+	return r.otherPairs, nil
+}
+
+func (r *rebuildRepo) SetWalletBalanceWithTx(_ *sqlx.Tx, address string, balance float64) error {
+	r.walletWrites[address] = balance
+	return nil
+}
+
+func (r *rebuildRepo) SetUSDBalanceWithTx(_ *sqlx.Tx, address string, state models.USDState) error {
+	r.usdWrites[address] = state
+	return nil
+}
+
+func (r *rebuildRepo) SetAssetBalanceWithTx(_ *sqlx.Tx, address, asset string, balance float64) error {
+	r.assetWrites[address+"/"+asset] = balance
+	return nil
+}
+
+func newRebuildTestService(t *testing.T, repo *rebuildRepo) (LedgerRebuildService, sqlmock.Sqlmock) {
+	service, _, mock := newRebuildTestServiceWithMarket(t, repo)
+	return service, mock
+}
+
+func newRebuildTestServiceWithMarket(t *testing.T, repo *rebuildRepo) (LedgerRebuildService, *rebuildMarketRepo, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	repo.db = sqlx.NewDb(db, "mysql")
+	repo.walletWrites = make(map[string]float64)
+	repo.usdWrites = make(map[string]models.USDState)
+	repo.assetWrites = make(map[string]float64)
+
+	marketRepo := &rebuildMarketRepo{}
+	return NewLedgerRebuildService(repo, marketRepo, NewMarketEngineService(nil)), marketRepo, mock
+}
+
+func tokenRebuildRepo() *rebuildRepo {
+	return &rebuildRepo{
+		tokenLedger: []models.BalanceChange{
+			{Address: "alice", Asset: "TKN", Amount: 100},
+			{Address: "alice", Asset: "TKN", Amount: -30},
+			{Address: "bob", Asset: "TKN", Amount: 30},
+		},
+		assetBalances: []models.AssetBalance{
+			{UserAddress: "alice", AssetSymbol: "TKN", Balance: 70},
+			{UserAddress: "bob", AssetSymbol: "TKN", Balance: 25},
+			{UserAddress: "carol", AssetSymbol: "TKN", Balance: 5},
+		},
+		otherPairs: []string{"TKN-USD"},
+	}
+}
+
+func TestLedgerRebuildComparesTokenBalances(t *testing.T) {
+	repo := tokenRebuildRepo()
+	service, mock := newRebuildTestService(t, repo)
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	report, err := service.Rebuild(context.Background(), LedgerRebuildOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.TokenLedgerEntries != 3 || report.AssetBalancesChecked != 3 {
+		t.Fatalf("token_ledger_entries = %d, asset_balances_checked = %d", report.TokenLedgerEntries, report.AssetBalancesChecked)
+	}
+
+	want := []models.AssetBalanceDiff{
+		{Address: "bob", Asset: "TKN", Expected: 30, Actual: 25, Difference: 5},
+		{Address: "carol", Asset: "TKN", Expected: 0, Actual: 5, Difference: -5},
+	}
+	if len(report.AssetBalanceDiffs) != len(want) {
+		t.Fatalf("asset diffs = %+v, want %+v", report.AssetBalanceDiffs, want)
+	}
+	for i := range want {
+		if report.AssetBalanceDiffs[i] != want[i] {
+			t.Fatalf("asset diff %d = %+v, want %+v", i, report.AssetBalanceDiffs[i], want[i])
+		}
+	}
+
+	if len(report.UncheckedPairs) != 1 || report.UncheckedPairs[0] != "TKN-USD" {
+		t.Fatalf("unchecked pairs = %v", report.UncheckedPairs)
+	}
+
+	if report.Consistent || report.Applied || len(repo.assetWrites) != 0 {
+		t.Fatalf("dry run must report inconsistency without writing: consistent=%t applied=%t writes=%v",
+			report.Consistent, report.Applied, repo.assetWrites)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLedgerRebuildApplyRewritesTokenBalances(t *testing.T) {
+	repo := tokenRebuildRepo()
+	service, mock := newRebuildTestService(t, repo)
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	report, err := service.Rebuild(context.Background(), LedgerRebuildOptions{Apply: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !report.Applied || report.AssetBalancesRewritten != 2 {
+		t.Fatalf("applied = %t, asset_balances_rewritten = %d", report.Applied, report.AssetBalancesRewritten)
+	}
+
+	if repo.assetWrites["bob/TKN"] != 30 || repo.assetWrites["carol/TKN"] != 0 || len(repo.assetWrites) != 2 {
+		t.Fatalf("asset writes = %v", repo.assetWrites)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLedgerRebuildConsistentWithoutOtherPairs(t *testing.T) {
+	repo := tokenRebuildRepo()
+	repo.assetBalances[1].Balance = 30
+	repo.assetBalances = repo.assetBalances[:2]
+	repo.otherPairs = nil
+
+	service, mock := newRebuildTestService(t, repo)
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	report, err := service.Rebuild(context.Background(), LedgerRebuildOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !report.Consistent || len(report.AssetBalanceDiffs) != 0 {
+		t.Fatalf("consistent = %t, asset diffs = %+v", report.Consistent, report.AssetBalanceDiffs)
+	}
+
+	// selalu array di JSON, bukan null
+	if report.UncheckedPairs == nil || len(report.UncheckedPairs) != 0 {
+		t.Fatalf("unchecked pairs = %#v, want empty slice", report.UncheckedPairs)
+	}
+}
+
+// blockRebuildRepo - dua block seperti ditulis GenerateBlock, tabel materialized sudah sesuai.
+// block 1 di harga 100: BUY 1000 (fee 1 USD), SELL 200 (fee 2 YTE), SEND 2 (fee 0.1), volume bersih +800
+// sehingga harga naik ke 100.08. block 2: SELL 10 tanpa fee di harga 100.08, harga turun ke 100.079
+func blockRebuildRepo() *rebuildRepo {
+	return &rebuildRepo{
+		transactions: []models.RebuildTransaction{
+			{BlockID: 11, BlockNumber: 1, TxID: 1, Type: "BUY", FromAddress: minerAddress, ToAddress: "alice", Amount: 1000, Fee: 1},
+			{BlockID: 11, BlockNumber: 1, TxID: 2, Type: "SELL", FromAddress: "bob", ToAddress: minerAddress, Amount: 200, Fee: 2},
+			{BlockID: 11, BlockNumber: 1, TxID: 3, Type: "SEND", FromAddress: "alice", ToAddress: "bob", Amount: 2, Fee: 0.1},
+			{BlockID: 12, BlockNumber: 2, TxID: 4, Type: "SELL", FromAddress: "alice", ToAddress: minerAddress, Amount: 10},
+		},
+		ledger: []models.BalanceChange{
+			{Address: "bob", Amount: 500},
+			{Address: "alice", Amount: 1000},
+			{Address: minerAddress, Amount: -1000},
+			{Address: "bob", Amount: -202},
+			{Address: minerAddress, Amount: 200},
+			{Address: minerAddress, Amount: 2},
+			{Address: "alice", Amount: -2.1},
+			{Address: "bob", Amount: 2},
+			{Address: minerAddress, Amount: 0.1},
+			{Address: "alice", Amount: -10},
+			{Address: minerAddress, Amount: 10},
+		},
+		rewards:  []models.BalanceChange{{Address: minerAddress, Amount: 50}},
+		deposits: []models.BalanceChange{{Address: "alice", Amount: 2000}},
+		ticks: []models.MarketTick{
+			{Pair: models.DefaultPair, BlockID: 11, Price: 100.08},
+			{Pair: models.DefaultPair, BlockID: 12, Price: 100.079},
+		},
+		// saldo MINER_ACCOUNT = seed + reward, tidak pernah diubah block
+		wallets: []models.UserWallet{
+			{UserAddress: "alice", YTEBalance: 987.9},
+			{UserAddress: "bob", YTEBalance: 300},
+			{UserAddress: minerAddress, YTEBalance: 1000050},
+		},
+		balances: []models.UserBalance{
+			{UserAddress: "alice", USDBalance: 1999.8, TotalDeposited: 2000, TotalWithdrawn: 1001, TotalTraded: 2000.8},
+			{UserAddress: "bob", USDBalance: 20000, TotalTraded: 20000},
+			{UserAddress: minerAddress, USDBalance: 201},
+		},
+		market: &models.MarketEngine{Pair: models.DefaultPair, Price: 100.079, Liquidity: 790, LastBlock: 12},
+	}
+}
+
+func TestLedgerRebuildReplaysBlocks(t *testing.T) {
+	repo := blockRebuildRepo()
+	service, mock := newRebuildTestService(t, repo)
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	report, err := service.Rebuild(context.Background(), LedgerRebuildOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Blocks != 2 || report.Transactions != 4 || report.LedgerEntries != 11 ||
+		report.MiningRewards != 1 || report.Deposits != 1 || report.TicksChecked != 2 {
+		t.Fatalf("replayed blocks=%d transactions=%d ledger=%d rewards=%d deposits=%d ticks=%d",
+			report.Blocks, report.Transactions, report.LedgerEntries, report.MiningRewards, report.Deposits, report.TicksChecked)
+	}
+
+	expected := report.Market.Expected
+	if math.Abs(expected.Price-100.079) > 1e-9 || math.Abs(expected.Liquidity-790) > 1e-9 || expected.LastBlock != 12 {
+		t.Fatalf("replayed market = %+v, want price 100.079, liquidity 790, last block 12", expected)
+	}
+
+	if !report.Consistent || len(report.WalletDiffs) != 0 || len(report.USDBalanceDiffs) != 0 || report.TickMismatches != 0 {
+		t.Fatalf("consistent = %t, wallet diffs = %+v, usd diffs = %+v, tick mismatches = %d",
+			report.Consistent, report.WalletDiffs, report.USDBalanceDiffs, report.TickMismatches)
+	}
+
+	// saldo YTE MINER_ACCOUNT tidak ikut dibandingkan
+	if report.WalletsChecked != 2 || len(report.UncheckedWallets) != 1 || report.UncheckedWallets[0] != minerAddress {
+		t.Fatalf("wallets checked = %d, unchecked = %v", report.WalletsChecked, report.UncheckedWallets)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLedgerRebuildReportsBlockDiffs(t *testing.T) {
+	repo := blockRebuildRepo()
+	repo.wallets[1].YTEBalance = 290
+	// SELL block 2 dihitung dengan harga sesudah block (100.079) bukan sebelum block
+	repo.balances[0].USDBalance = 1999.79
+	repo.balances[0].TotalTraded = 2000.79
+	repo.market = &models.MarketEngine{Pair: models.DefaultPair, Price: 100.08, Liquidity: 800, LastBlock: 11}
+	repo.ticks[1].Price = 100.5
+
+	service, marketRepo, mock := newRebuildTestServiceWithMarket(t, repo)
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	report, err := service.Rebuild(context.Background(), LedgerRebuildOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.WalletDiffs) != 1 || report.WalletDiffs[0] != (models.WalletDiff{Address: "bob", Expected: 300, Actual: 290, Difference: 10}) {
+		t.Fatalf("wallet diffs = %+v", report.WalletDiffs)
+	}
+
+	if len(report.USDBalanceDiffs) != 1 {
+		t.Fatalf("usd diffs = %+v", report.USDBalanceDiffs)
+	}
+	diff := report.USDBalanceDiffs[0]
+	want := models.USDState{USDBalance: 1999.8, TotalDeposited: 2000, TotalWithdrawn: 1001, TotalTraded: 2000.8}
+	if diff.Address != "alice" || diff.Expected != want || diff.Difference != 0.01 {
+		t.Fatalf("usd diff = %+v, want alice expected %+v", diff, want)
+	}
+
+	if report.Market.Match {
+		t.Fatalf("market = %+v, want mismatch on stale market_engine", report.Market)
+	}
+
+	first := report.FirstTickDiff
+	if report.TickMismatches != 1 || first == nil || first.BlockNumber != 2 || first.Recorded != 100.5 ||
+		math.Abs(first.Replayed-100.079) > 1e-9 {
+		t.Fatalf("tick mismatches = %d, first = %+v", report.TickMismatches, first)
+	}
+
+	if report.Consistent || len(repo.walletWrites) != 0 || len(repo.usdWrites) != 0 || marketRepo.written != nil {
+		t.Fatal("dry run must report inconsistency without writing")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLedgerRebuildApplyRewritesBlockState(t *testing.T) {
+	repo := blockRebuildRepo()
+	repo.wallets[1].YTEBalance = 290
+	repo.balances[0].TotalTraded = 2000.79
+	repo.market = &models.MarketEngine{Pair: models.DefaultPair, Price: 100.08, Liquidity: 800, LastBlock: 11}
+
+	service, marketRepo, mock := newRebuildTestServiceWithMarket(t, repo)
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	report, err := service.Rebuild(context.Background(), LedgerRebuildOptions{Apply: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !report.Applied || report.WalletsRewritten != 1 || report.BalancesRewritten != 1 || !report.MarketRewritten {
+		t.Fatalf("applied = %t, wallets = %d, balances = %d, market = %t",
+			report.Applied, report.WalletsRewritten, report.BalancesRewritten, report.MarketRewritten)
+	}
+
+	// MINER_ACCOUNT tidak pernah ditulis ulang walaupun ledger-nya negatif terhadap saldo seed
+	if len(repo.walletWrites) != 1 || repo.walletWrites["bob"] != 300 {
+		t.Fatalf("wallet writes = %v", repo.walletWrites)
+	}
+
+	if len(repo.usdWrites) != 1 || repo.usdWrites["alice"].TotalTraded != 2000.8 {
+		t.Fatalf("usd writes = %+v", repo.usdWrites)
+	}
+
+	if marketRepo.written == nil || marketRepo.written.LastBlock != 12 || math.Abs(marketRepo.written.Price-100.079) > 1e-9 {
+		t.Fatalf("market write = %+v", marketRepo.written)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
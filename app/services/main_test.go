@@ -0,0 +1,14 @@
+package services
+
+import (
+	"os"
+	"testing"
+
+	"github.com/livingdolls/go-blockchain-simulate/logger"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	logger.L = zap.NewNop()
+	os.Exit(m.Run())
+}
@@ -5,13 +5,18 @@ import (
 	"errors"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
 	"github.com/livingdolls/go-blockchain-simulate/app/models"
 	"github.com/livingdolls/go-blockchain-simulate/app/repository"
 )
 
 type MarketEngineService interface {
 	GetState() (models.MarketEngine, error)
+	GetStateByPair(pair string) (models.MarketEngine, error)
+	GetAllStates() ([]models.MarketEngine, error)
 	ApplyBlockPricingWithTx(tx *sqlx.Tx, blockID int64, buyVolume, sellVolume float64, txCount int) (models.MarketEngine, error)
+	// NextState - hitung state berikutnya tanpa menyentuh database (dipakai juga oleh replay)
+	NextState(state models.MarketEngine, buyVolume, sellVolume float64) models.MarketEngine
 }
 
 type marketEngineService struct {
@@ -32,7 +37,8 @@ func NewMarketEngineService(repo repository.MarketRepository) MarketEngineServic
 
 // ApplyBlockPricingWithTx implements MarketEngineService.
 func (m *marketEngineService) ApplyBlockPricingWithTx(tx *sqlx.Tx, blockID int64, buyVolume float64, sellVolume float64, txCount int) (models.MarketEngine, error) {
-	state, err := m.repo.GetStateForUpdateWithTx(tx)
+	// block pricing hanya menggerakkan pair native
+	state, err := m.repo.GetStateForUpdateWithTx(tx, models.DefaultPair)
 
 	if err != nil {
 		if !errors.Is(err, sql.ErrNoRows) {
@@ -41,27 +47,14 @@ func (m *marketEngineService) ApplyBlockPricingWithTx(tx *sqlx.Tx, blockID int64
 
 		state = models.MarketEngine{
 			ID:        1,
+			Pair:      models.DefaultPair,
 			Price:     100.0,
 			Liquidity: 0,
 			LastBlock: 0,
 		}
 	}
 
-	deltaVolume := buyVolume - sellVolume
-	newLiquidity := state.Liquidity + deltaVolume
-
-	if newLiquidity < 0 {
-		newLiquidity = 0
-	}
-
-	newPrice := state.Price + m.slope*deltaVolume
-
-	if newPrice < m.minPrice {
-		newPrice = m.minPrice
-	}
-
-	state.Price = newPrice
-	state.Liquidity = newLiquidity
+	state = m.NextState(state, buyVolume, sellVolume)
 	state.LastBlock = blockID
 
 	if err := m.repo.UpdateStateWithTx(tx, state); err != nil {
@@ -69,6 +62,7 @@ func (m *marketEngineService) ApplyBlockPricingWithTx(tx *sqlx.Tx, blockID int64
 	}
 
 	if _, err := m.repo.InsertTickWithTx(tx, models.MarketTick{
+		Pair:       state.Pair,
 		BlockID:    blockID,
 		Price:      state.Price,
 		BuyVolume:  buyVolume,
@@ -81,7 +75,43 @@ func (m *marketEngineService) ApplyBlockPricingWithTx(tx *sqlx.Tx, blockID int64
 	return state, nil
 }
 
+// NextState implements MarketEngineService.
+func (m *marketEngineService) NextState(state models.MarketEngine, buyVolume, sellVolume float64) models.MarketEngine {
+	deltaVolume := buyVolume - sellVolume
+	newLiquidity := state.Liquidity + deltaVolume
+
+	if newLiquidity < 0 {
+		newLiquidity = 0
+	}
+
+	newPrice := state.Price + m.slope*deltaVolume
+
+	if newPrice < m.minPrice {
+		newPrice = m.minPrice
+	}
+
+	state.Price = newPrice
+	state.Liquidity = newLiquidity
+
+	return state
+}
+
 // GetState implements MarketEngineService.
 func (m *marketEngineService) GetState() (models.MarketEngine, error) {
 	return m.repo.GetState()
 }
+
+// GetStateByPair implements MarketEngineService.
+func (m *marketEngineService) GetStateByPair(pair string) (models.MarketEngine, error) {
+	state, err := m.repo.GetStateByPair(pair)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.MarketEngine{}, entity.ErrPairNotFound
+	}
+
+	return state, err
+}
+
+// GetAllStates implements MarketEngineService.
+func (m *marketEngineService) GetAllStates() ([]models.MarketEngine, error) {
+	return m.repo.GetAllStates()
+}
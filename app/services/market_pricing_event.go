@@ -0,0 +1,27 @@
+package services
+
+import (
+	"github.com/livingdolls/go-blockchain-simulate/app/dto"
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+)
+
+// NewMarketPricingEvent - payload event outbox market.price.update, dipublish relay outbox
+func NewMarketPricingEvent(blockID int64, blockNumber int, priceData models.MarketEngine, volumeData models.MarketTick, minerAddress string) dto.MarketPricingEvent {
+	pair := priceData.Pair
+	if pair == "" {
+		pair = models.DefaultPair
+	}
+
+	return dto.MarketPricingEvent{
+		Pair:         pair,
+		BlockID:      blockID,
+		BlockNumber:  blockNumber,
+		Price:        priceData.Price,
+		Liquidity:    priceData.Liquidity,
+		BuyVolume:    volumeData.BuyVolume,
+		SellVolume:   volumeData.SellVolume,
+		TxCount:      volumeData.TxCount,
+		Timestamp:    volumeData.CreatedAt,
+		MinerAddress: minerAddress,
+	}
+}
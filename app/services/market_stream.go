@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/livingdolls/go-blockchain-simulate/app/dto"
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+	"github.com/livingdolls/go-blockchain-simulate/logger"
+	"github.com/livingdolls/go-blockchain-simulate/redis"
+)
+
+// MarketStreamService - ticker dan trade per pair lewat redis pub/sub, dipakai SSE
+type MarketStreamService interface {
+	PublishTicker(ctx context.Context, update dto.PriceUpdate) error
+	PublishTrade(ctx context.Context, trade dto.TradeEvent) error
+	SubscribeTicker(ctx context.Context, pair string, callback func(dto.PriceUpdate) error) error
+	SubscribeTrades(ctx context.Context, pair string, callback func(dto.TradeEvent) error) error
+}
+
+type marketStreamService struct {
+	redis redis.MemoryAdapter
+}
+
+func NewMarketStreamService(redisAdapter redis.MemoryAdapter) MarketStreamService {
+	return &marketStreamService{redis: redisAdapter}
+}
+
+// PublishTicker implements [MarketStreamService].
+func (m *marketStreamService) PublishTicker(ctx context.Context, update dto.PriceUpdate) error {
+	return m.publish(ctx, tickerChannel(streamPair(update.Pair)), update)
+}
+
+// PublishTrade implements [MarketStreamService].
+func (m *marketStreamService) PublishTrade(ctx context.Context, trade dto.TradeEvent) error {
+	return m.publish(ctx, tradesChannel(streamPair(trade.Pair)), trade)
+}
+
+// SubscribeTicker implements [MarketStreamService].
+func (m *marketStreamService) SubscribeTicker(ctx context.Context, pair string, callback func(dto.PriceUpdate) error) error {
+	return m.redis.Subscribe(ctx, tickerChannel(pair), func(message []byte) error {
+		var update dto.PriceUpdate
+		if err := json.Unmarshal(message, &update); err != nil {
+			logger.LogError("Unmarshal ticker error", err)
+			return err
+		}
+
+		return callback(update)
+	})
+}
+
+// SubscribeTrades implements [MarketStreamService].
+func (m *marketStreamService) SubscribeTrades(ctx context.Context, pair string, callback func(dto.TradeEvent) error) error {
+	return m.redis.Subscribe(ctx, tradesChannel(pair), func(message []byte) error {
+		var trade dto.TradeEvent
+		if err := json.Unmarshal(message, &trade); err != nil {
+			logger.LogError("Unmarshal trade error", err)
+			return err
+		}
+
+		return callback(trade)
+	})
+}
+
+func (m *marketStreamService) publish(ctx context.Context, channel string, v any) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return m.redis.Publish(ctx, channel, payload)
+}
+
+func streamPair(pair string) string {
+	if pair == "" {
+		return models.DefaultPair
+	}
+	return pair
+}
+
+// tickerChannel - contoh: ticker:YTE-USD
+func tickerChannel(pair string) string {
+	return fmt.Sprintf("ticker:%s", pair)
+}
+
+// tradesChannel - contoh: trades:YTE-USD
+func tradesChannel(pair string) string {
+	return fmt.Sprintf("trades:%s", pair)
+}
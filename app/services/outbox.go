@@ -0,0 +1,267 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+	"github.com/livingdolls/go-blockchain-simulate/app/port"
+	"github.com/livingdolls/go-blockchain-simulate/app/repository"
+	"github.com/livingdolls/go-blockchain-simulate/logger"
+	"go.uber.org/zap"
+)
+
+const OutboxAggregateBlock = "block"
+
+// OutboxConfig - nilai 0 diganti default
+type OutboxConfig struct {
+	// BatchSize - event yang dipublish per putaran relay
+	BatchSize int
+	// MaxBackoff - batas jeda retry, jeda = 2^attempts detik
+	MaxBackoff time.Duration
+	// StuckAfter - event PENDING lebih tua dari ini tampil di admin sebagai stuck
+	StuckAfter time.Duration
+	// SentRetention - event SENT dihapus setelah durasi ini
+	SentRetention time.Duration
+	// ClaimTimeout - lama event IN_FLIGHT dikunci satu relay, harus lebih lama dari timeout publish confirm
+	ClaimTimeout time.Duration
+}
+
+type OutboxService interface {
+	EnqueueWithTx(dbTx *sqlx.Tx, events ...models.OutboxEvent) error
+	Notify()
+	Wake() <-chan struct{}
+	Relay(ctx context.Context) (int, error)
+	Prune(ctx context.Context) (int64, error)
+	Stats() (models.OutboxStats, error)
+	Stuck(limit, offset int) ([]models.OutboxEvent, error)
+	Retry(id int64) error
+	Config() OutboxConfig
+}
+
+type outboxService struct {
+	repo      repository.OutboxRepository
+	rmqClient port.MessageBroker
+	cfg       OutboxConfig
+	wake      chan struct{}
+}
+
+func NewOutboxService(repo repository.OutboxRepository, rmqClient port.MessageBroker, cfg OutboxConfig) OutboxService {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 5 * time.Minute
+	}
+	if cfg.StuckAfter <= 0 {
+		cfg.StuckAfter = time.Minute
+	}
+	if cfg.SentRetention <= 0 {
+		cfg.SentRetention = 24 * time.Hour
+	}
+	if cfg.ClaimTimeout <= 0 {
+		cfg.ClaimTimeout = time.Minute
+	}
+
+	return &outboxService{
+		repo:      repo,
+		rmqClient: rmqClient,
+		cfg:       cfg,
+		wake:      make(chan struct{}, 1),
+	}
+}
+
+// NewOutboxEvent - payload di-marshal sekarang agar event yang disimpan sama persis dengan yang dipublish
+func NewOutboxEvent(aggregateType string, aggregateID int64, exchange, routingKey string, payload any) (models.OutboxEvent, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return models.OutboxEvent{}, fmt.Errorf("marshal outbox event %s: %w", routingKey, err)
+	}
+
+	return models.OutboxEvent{
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Exchange:      exchange,
+		RoutingKey:    routingKey,
+		Payload:       string(body),
+		Status:        models.OutboxStatusPending,
+	}, nil
+}
+
+// EnqueueWithTx implements [OutboxService].
+// panggil Notify setelah commit agar relay langsung berjalan
+func (s *outboxService) EnqueueWithTx(dbTx *sqlx.Tx, events ...models.OutboxEvent) error {
+	return s.repo.CreateWithTx(dbTx, events)
+}
+
+// Notify implements [OutboxService].
+func (s *outboxService) Notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Wake implements [OutboxService].
+func (s *outboxService) Wake() <-chan struct{} {
+	return s.wake
+}
+
+// Relay implements [OutboxService].
+// event jatuh tempo diklaim (IN_FLIGHT) dan di-commit dulu, publish dan tunggu confirm broker berjalan
+// tanpa lock / transaction database. tidak ada jaminan urutan: batch dari beberapa relay bisa tumpang
+// tindih dan event yang gagal dicoba lagi dengan backoff setelah event yang lebih baru.
+// at-least-once: relay yang mati setelah publish membuat event dikirim ulang setelah klaim kedaluwarsa,
+// consumer harus idempotent, message id = outbox-<id>
+func (s *outboxService) Relay(ctx context.Context) (int, error) {
+	events, err := s.claim()
+	if err != nil || len(events) == 0 {
+		return 0, err
+	}
+
+	msgs := make([]port.Message, len(events))
+	for i, event := range events {
+		msgs[i] = port.Message{
+			Exchange:   event.Exchange,
+			RoutingKey: event.RoutingKey,
+			MessageID:  fmt.Sprintf("outbox-%d", event.ID),
+			Body:       []byte(event.Payload),
+		}
+	}
+
+	results, _ := s.rmqClient.PublishBatch(ctx, msgs)
+
+	sent := make([]int64, 0, len(events))
+	var publishErr error
+
+	for i, event := range events {
+		if results[i] == nil {
+			sent = append(sent, event.ID)
+			continue
+		}
+
+		if publishErr == nil {
+			publishErr = fmt.Errorf("publish outbox event %d (%s): %w", event.ID, event.RoutingKey, results[i])
+		}
+
+		backoff := s.backoff(event.Attempts + 1)
+		if err := s.repo.MarkFailed(event.ID, results[i].Error(), backoff); err != nil {
+			return 0, fmt.Errorf("mark outbox event %d failed: %w", event.ID, err)
+		}
+
+		logger.LogWarn("Outbox publish failed",
+			zap.Int64("event_id", event.ID),
+			zap.String("routing_key", event.RoutingKey),
+			zap.Int("attempts", event.Attempts+1),
+			zap.Duration("backoff", backoff),
+			zap.Error(results[i]),
+		)
+	}
+
+	// gagal di sini = event tetap IN_FLIGHT dan dipublish ulang setelah klaim kedaluwarsa
+	if err := s.repo.MarkSent(sent); err != nil {
+		return 0, fmt.Errorf("mark outbox events sent: %w", err)
+	}
+
+	return len(sent), publishErr
+}
+
+// claim - ambil event jatuh tempo dengan SKIP LOCKED, tandai IN_FLIGHT lalu commit
+func (s *outboxService) claim() ([]models.OutboxEvent, error) {
+	dbTx, err := s.repo.BeginTx()
+	if err != nil {
+		return nil, fmt.Errorf("begin outbox transaction: %w", err)
+	}
+	defer dbTx.Rollback()
+
+	events, err := s.repo.GetDueForUpdateWithTx(dbTx, s.cfg.BatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("get due outbox events: %w", err)
+	}
+
+	if len(events) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]int64, len(events))
+	for i, event := range events {
+		ids[i] = event.ID
+	}
+
+	if err := s.repo.ClaimWithTx(dbTx, ids, s.cfg.ClaimTimeout); err != nil {
+		return nil, fmt.Errorf("claim outbox events: %w", err)
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit outbox claim: %w", err)
+	}
+
+	return events, nil
+}
+
+func (s *outboxService) backoff(attempts int) time.Duration {
+	if attempts > 16 {
+		return s.cfg.MaxBackoff
+	}
+
+	delay := time.Duration(1<<attempts) * time.Second
+	if delay > s.cfg.MaxBackoff {
+		return s.cfg.MaxBackoff
+	}
+
+	return delay
+}
+
+// Prune implements [OutboxService].
+func (s *outboxService) Prune(ctx context.Context) (int64, error) {
+	var total int64
+
+	for ctx.Err() == nil {
+		deleted, err := s.repo.DeleteSentBefore(s.cfg.SentRetention, 1000)
+		if err != nil {
+			return total, err
+		}
+
+		total += deleted
+		if deleted < 1000 {
+			break
+		}
+	}
+
+	return total, ctx.Err()
+}
+
+// Stats implements [OutboxService].
+func (s *outboxService) Stats() (models.OutboxStats, error) {
+	return s.repo.GetStats(s.cfg.StuckAfter)
+}
+
+// Stuck implements [OutboxService].
+func (s *outboxService) Stuck(limit, offset int) ([]models.OutboxEvent, error) {
+	return s.repo.GetStuck(s.cfg.StuckAfter, limit, offset)
+}
+
+// Retry implements [OutboxService].
+// event dipublish pada putaran relay berikutnya tanpa menunggu backoff
+func (s *outboxService) Retry(id int64) error {
+	ok, err := s.repo.Retry(id)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return entity.ErrOutboxEventNotFound
+	}
+
+	s.Notify()
+	return nil
+}
+
+// Config implements [OutboxService].
+func (s *outboxService) Config() OutboxConfig {
+	return s.cfg
+}
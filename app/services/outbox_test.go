@@ -0,0 +1,259 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+	"github.com/livingdolls/go-blockchain-simulate/app/port"
+	"github.com/livingdolls/go-blockchain-simulate/app/repository"
+	"github.com/livingdolls/go-blockchain-simulate/membroker"
+)
+
+// batchBroker - hanya PublishBatch yang dipakai relay, method lain panic jika terpanggil
+type batchBroker struct {
+	port.MessageBroker
+	publish func(msgs []port.Message) []error
+	calls   int
+}
+
+func (b *batchBroker) PublishBatch(_ context.Context, msgs []port.Message) ([]error, error) {
+	b.calls++
+	return b.publish(msgs), nil
+}
+
+func newOutboxTestService(t *testing.T, broker port.MessageBroker) (OutboxService, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	repo := repository.NewOutboxRepository(sqlx.NewDb(db, "mysql"))
+	return NewOutboxService(repo, broker, OutboxConfig{BatchSize: 10}), mock
+}
+
+func outboxRows(ids ...int64) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{
+		"id", "aggregate_type", "aggregate_id", "exchange_name", "routing_key", "payload", "status",
+		"attempts", "last_error", "next_attempt_at", "created_at", "sent_at",
+	})
+
+	now := time.Now()
+	for _, id := range ids {
+		rows.AddRow(id, OutboxAggregateBlock, id, "ledger", "ledger.batch", `{}`, models.OutboxStatusPending, 0, nil, now, now, nil)
+	}
+
+	return rows
+}
+
+func TestOutboxRelayCommitsClaimBeforePublish(t *testing.T) {
+	var mock sqlmock.Sqlmock
+
+	broker := &batchBroker{}
+	broker.publish = func(msgs []port.Message) []error {
+		// klaim harus sudah di-commit: tidak ada lock / transaction yang terbuka selama publish
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("publish started before claim was committed: %v", err)
+		}
+
+		if len(msgs) != 2 || msgs[0].MessageID != "outbox-1" || msgs[1].MessageID != "outbox-2" {
+			t.Errorf("unexpected batch: %+v", msgs)
+		}
+
+		// percobaan pertama gagal: backoff 2^1 detik dihitung dari NOW() database
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE outbox_events
+		SET status = ?, attempts = attempts + 1, last_error = ?, next_attempt_at = DATE_ADD(NOW(), INTERVAL ? SECOND)
+		WHERE id = ? AND status = ?`)).
+			WithArgs(models.OutboxStatusPending, "nack", int64(2), int64(2), models.OutboxStatusInFlight).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE outbox_events SET status = ?, sent_at = NOW(), last_error = NULL WHERE id IN (?)`)).
+			WithArgs(models.OutboxStatusSent, int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		return []error{nil, errors.New("nack")}
+	}
+
+	service, m := newOutboxTestService(t, broker)
+	mock = m
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE SKIP LOCKED`).
+		WithArgs(models.OutboxStatusPending, models.OutboxStatusInFlight, 10).
+		WillReturnRows(outboxRows(1, 2))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE outbox_events SET status = ?, next_attempt_at = DATE_ADD(NOW(), INTERVAL ? SECOND) WHERE id IN (?, ?)`)).
+		WithArgs(models.OutboxStatusInFlight, int64(60), int64(1), int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	sent, err := service.Relay(context.Background())
+	if sent != 1 {
+		t.Fatalf("sent = %d, want 1", sent)
+	}
+	if err == nil {
+		t.Fatal("expected publish error for nacked event")
+	}
+
+	if broker.calls != 1 {
+		t.Fatalf("PublishBatch calls = %d, want 1", broker.calls)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOutboxRelayNothingDue(t *testing.T) {
+	broker := &batchBroker{publish: func([]port.Message) []error { return nil }}
+	service, mock := newOutboxTestService(t, broker)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE SKIP LOCKED`).WillReturnRows(outboxRows())
+	mock.ExpectRollback()
+
+	sent, err := service.Relay(context.Background())
+	if sent != 0 || err != nil {
+		t.Fatalf("Relay = %d, %v", sent, err)
+	}
+
+	if broker.calls != 0 {
+		t.Fatalf("PublishBatch called %d times with nothing due", broker.calls)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOutboxRelayClaimFailureDoesNotPublish(t *testing.T) {
+	broker := &batchBroker{publish: func([]port.Message) []error { return nil }}
+	service, mock := newOutboxTestService(t, broker)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE SKIP LOCKED`).WillReturnRows(outboxRows(7))
+	mock.ExpectExec(`UPDATE outbox_events SET status`).WillReturnError(errors.New("lock wait timeout"))
+	mock.ExpectRollback()
+
+	if _, err := service.Relay(context.Background()); err == nil {
+		t.Fatal("expected claim error")
+	}
+
+	if broker.calls != 0 {
+		t.Fatal("events published without a committed claim")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOutboxRelayDeliversThroughMemoryBroker(t *testing.T) {
+	broker := membroker.NewMemoryBroker()
+	defer broker.Close()
+
+	if err := broker.DeclareExchange(models.ExchangeDef{Name: "ledger", Kind: "topic", Durable: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := broker.DeclareQueue(models.QueueDef{Name: "ledger.audit", Durable: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := broker.Bind(models.BindDef{Queue: "ledger.audit", Exchange: "ledger", RoutingKey: "ledger.batch"}); err != nil {
+		t.Fatal(err)
+	}
+
+	service, mock := newOutboxTestService(t, broker)
+
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{
+		"id", "aggregate_type", "aggregate_id", "exchange_name", "routing_key", "payload", "status",
+		"attempts", "last_error", "next_attempt_at", "created_at", "sent_at",
+	}).
+		AddRow(1, OutboxAggregateBlock, 1, "ledger", "ledger.batch", `{"block_id":1}`, models.OutboxStatusPending, 0, nil, now, now, nil).
+		AddRow(2, OutboxAggregateBlock, 2, "ledger", "ledger.unbound", `{"block_id":2}`, models.OutboxStatusPending, 0, nil, now, now, nil)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE SKIP LOCKED`).
+		WithArgs(models.OutboxStatusPending, models.OutboxStatusInFlight, 10).
+		WillReturnRows(rows)
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE outbox_events SET status = ?, next_attempt_at = DATE_ADD(NOW(), INTERVAL ? SECOND) WHERE id IN (?, ?)`)).
+		WithArgs(models.OutboxStatusInFlight, int64(60), int64(1), int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+	// event tanpa queue tujuan ditolak broker (mandatory) dan dijadwalkan ulang
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE outbox_events
+		SET status = ?, attempts = attempts + 1, last_error = ?, next_attempt_at = DATE_ADD(NOW(), INTERVAL ? SECOND)
+		WHERE id = ? AND status = ?`)).
+		WithArgs(models.OutboxStatusPending, sqlmock.AnyArg(), int64(2), int64(2), models.OutboxStatusInFlight).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE outbox_events SET status = ?, sent_at = NOW(), last_error = NULL WHERE id IN (?)`)).
+		WithArgs(models.OutboxStatusSent, int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	sent, err := service.Relay(context.Background())
+	if sent != 1 || err == nil {
+		t.Fatalf("Relay = %d, %v; want 1 sent and an unroutable error", sent, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	received := make(chan port.Delivery, 2)
+	if err := broker.ConsumeWithRetry(ctx, "ledger.audit", 1, func(d port.Delivery) error {
+		received <- d
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case d := <-received:
+		if d.MessageID != "outbox-1" || string(d.Body) != `{"block_id":1}` || d.RoutingKey != "ledger.batch" {
+			t.Fatalf("unexpected delivery %+v", d)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("relayed event was not delivered")
+	}
+
+	select {
+	case d := <-received:
+		t.Fatalf("unexpected extra delivery %+v", d)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestOutboxCutoffsUseDatabaseClock(t *testing.T) {
+	service, mock := newOutboxTestService(t, nil)
+
+	// batas stuck / retention dikirim sebagai durasi, dibandingkan dengan NOW() database
+	mock.ExpectQuery(regexp.QuoteMeta(`created_at < DATE_SUB(NOW(), INTERVAL ? SECOND)`)).
+		WithArgs(models.OutboxStatusSent, int64(60), 50, 0).
+		WillReturnRows(outboxRows(3))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM outbox_events WHERE status = ? AND sent_at < DATE_SUB(NOW(), INTERVAL ? SECOND) LIMIT ?`)).
+		WithArgs(models.OutboxStatusSent, int64(86400), 1000).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	stuck, err := service.Stuck(50, 0)
+	if err != nil || len(stuck) != 1 || stuck[0].ID != 3 {
+		t.Fatalf("Stuck = %+v, %v", stuck, err)
+	}
+
+	deleted, err := service.Prune(context.Background())
+	if err != nil || deleted != 2 {
+		t.Fatalf("Prune = %d, %v", deleted, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,463 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/livingdolls/go-blockchain-simulate/app/dto"
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+	"github.com/livingdolls/go-blockchain-simulate/app/publisher"
+	"github.com/livingdolls/go-blockchain-simulate/app/repository"
+	"github.com/livingdolls/go-blockchain-simulate/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	ReplayStatusRunning   = "RUNNING"
+	ReplayStatusCompleted = "COMPLETED"
+	ReplayStatusStopped   = "STOPPED"
+	ReplayStatusFailed    = "FAILED"
+)
+
+const (
+	ReplaySourceTicks = "ticks"
+	ReplaySourceCSV   = "csv"
+)
+
+const (
+	maxRunningReplays = 3
+	MaxReplayTicks    = 500000
+	// jeda antar tick dibatasi agar gap panjang di data (mis. server mati) tidak membuat replay diam
+	maxReplayStepDelay = time.Minute
+)
+
+// ReplayOptions - Speed 0 = secepatnya, 1 = real time, 10 = 10x
+type ReplayOptions struct {
+	Speed      float64
+	BotIDs     []int64
+	Seed       int64
+	InitialUSD float64
+	InitialYTE float64
+}
+
+// ReplaySession - Pair adalah pair terisolasi (REPLAY<id>-<source pair>),
+// candle / ticker / websocket replay hanya dikirim dengan pair ini
+type ReplaySession struct {
+	ID         int64                   `json:"id"`
+	Pair       string                  `json:"pair"`
+	SourcePair string                  `json:"source_pair"`
+	Source     string                  `json:"source"`
+	Speed      float64                 `json:"speed"`
+	Status     string                  `json:"status"`
+	TotalTicks int                     `json:"total_ticks"`
+	Processed  int                     `json:"processed"`
+	LastPrice  float64                 `json:"last_price"`
+	FromTime   int64                   `json:"from_time"`
+	ToTime     int64                   `json:"to_time"`
+	StartedAt  int64                   `json:"started_at"`
+	FinishedAt int64                   `json:"finished_at,omitempty"`
+	Error      string                  `json:"error,omitempty"`
+	Bots       []dto.BotBacktestResult `json:"bots,omitempty"`
+}
+
+// ReplayBotSimulator - paper trading bot terhadap harga replay (diimplementasi bots.Manager)
+type ReplayBotSimulator interface {
+	NewSimulation(botIDs []int64, seed int64, initialUSD, initialYTE float64) (ReplaySimulation, error)
+}
+
+type ReplaySimulation interface {
+	OnTick(tick models.MarketTick)
+	Results() []dto.BotBacktestResult
+}
+
+type ReplayService interface {
+	// ReplayRecorded - replay market_ticks pair dalam rentang [from, to)
+	ReplayRecorded(pair string, from, to int64, opts ReplayOptions) (ReplaySession, error)
+	// ReplayTicks - replay tick dari sumber lain (mis. CSV), diurutkan berdasarkan created_at
+	ReplayTicks(source, pair string, ticks []models.MarketTick, opts ReplayOptions) (ReplaySession, error)
+	Get(id int64) (ReplaySession, error)
+	List() []ReplaySession
+	Stop(id int64) (ReplaySession, error)
+	// Delete - hapus session yang sudah selesai beserta candle replay-nya
+	Delete(id int64) error
+	Shutdown()
+}
+
+type replayRun struct {
+	mu       sync.Mutex
+	session  ReplaySession
+	stopChan chan struct{}
+	doneChan chan struct{}
+	stopOnce sync.Once
+}
+
+type replayService struct {
+	candleRepo   repository.CandlesRepository
+	market       MarketEngineService
+	candles      CandleService
+	marketStream MarketStreamService
+	publisherWS  *publisher.PublisherWS
+	bots         ReplayBotSimulator
+
+	mu     sync.Mutex
+	nextID int64
+	runs   map[int64]*replayRun
+}
+
+func NewReplayService(
+	candleRepo repository.CandlesRepository,
+	market MarketEngineService,
+	candles CandleService,
+	marketStream MarketStreamService,
+	publisherWS *publisher.PublisherWS,
+	bots ReplayBotSimulator,
+) ReplayService {
+	return &replayService{
+		candleRepo:   candleRepo,
+		market:       market,
+		candles:      candles,
+		marketStream: marketStream,
+		publisherWS:  publisherWS,
+		bots:         bots,
+		runs:         make(map[int64]*replayRun),
+	}
+}
+
+// ReplayRecorded implements [ReplayService].
+func (r *replayService) ReplayRecorded(pair string, from, to int64, opts ReplayOptions) (ReplaySession, error) {
+	pair = NormalizePair(pair)
+
+	if to == 0 {
+		to = time.Now().Unix()
+	}
+
+	if from < 0 || to <= from {
+		return ReplaySession{}, fmt.Errorf("%w: from must be before to", entity.ErrInvalidReplay)
+	}
+
+	ticks, err := r.candleRepo.GetTicksRange(pair, from, to)
+	if err != nil {
+		return ReplaySession{}, err
+	}
+
+	return r.ReplayTicks(ReplaySourceTicks, pair, ticks, opts)
+}
+
+// ReplayTicks implements [ReplayService].
+func (r *replayService) ReplayTicks(source, pair string, ticks []models.MarketTick, opts ReplayOptions) (ReplaySession, error) {
+	pair = NormalizePair(pair)
+
+	if len(ticks) == 0 {
+		return ReplaySession{}, fmt.Errorf("%w: no ticks to replay", entity.ErrInvalidReplay)
+	}
+
+	if len(ticks) > MaxReplayTicks {
+		return ReplaySession{}, fmt.Errorf("%w: too many ticks (%d), max %d", entity.ErrInvalidReplay, len(ticks), MaxReplayTicks)
+	}
+
+	if opts.Speed < 0 {
+		return ReplaySession{}, fmt.Errorf("%w: speed must not be negative", entity.ErrInvalidReplay)
+	}
+
+	sort.SliceStable(ticks, func(i, j int) bool { return ticks[i].CreatedAt < ticks[j].CreatedAt })
+
+	var sim ReplaySimulation
+	if len(opts.BotIDs) > 0 {
+		if r.bots == nil {
+			return ReplaySession{}, fmt.Errorf("%w: bot simulation is not available", entity.ErrInvalidReplay)
+		}
+
+		var err error
+		if sim, err = r.bots.NewSimulation(opts.BotIDs, opts.Seed, opts.InitialUSD, opts.InitialYTE); err != nil {
+			return ReplaySession{}, err
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	running := 0
+	for _, run := range r.runs {
+		if run.snapshot().Status == ReplayStatusRunning {
+			running++
+		}
+	}
+
+	if running >= maxRunningReplays {
+		return ReplaySession{}, entity.ErrTooManyReplays
+	}
+
+	r.nextID++
+	id := r.nextID
+
+	replayPair := fmt.Sprintf("REPLAY%d-%s", id, pair)
+	if len(replayPair) > 32 {
+		return ReplaySession{}, fmt.Errorf("%w: pair name too long", entity.ErrInvalidReplay)
+	}
+
+	// sisa candle dari proses sebelumnya dengan id yang sama
+	if _, err := r.candles.DeleteCandlesByPair(replayPair); err != nil {
+		return ReplaySession{}, err
+	}
+
+	run := &replayRun{
+		session: ReplaySession{
+			ID:         id,
+			Pair:       replayPair,
+			SourcePair: pair,
+			Source:     source,
+			Speed:      opts.Speed,
+			Status:     ReplayStatusRunning,
+			TotalTicks: len(ticks),
+			FromTime:   ticks[0].CreatedAt,
+			ToTime:     ticks[len(ticks)-1].CreatedAt,
+			StartedAt:  time.Now().Unix(),
+		},
+		stopChan: make(chan struct{}),
+		doneChan: make(chan struct{}),
+	}
+
+	r.runs[id] = run
+	go r.run(run, ticks, sim, opts.Speed)
+
+	logger.LogInfo("Market replay started",
+		zap.Int64("session_id", id),
+		zap.String("pair", replayPair),
+		zap.Int("ticks", len(ticks)),
+		zap.Float64("speed", opts.Speed),
+	)
+
+	return run.snapshot(), nil
+}
+
+// run - harga dihitung ulang lewat MarketEngineService dari volume tercatat,
+// harga awal = harga tick pertama. tidak menyentuh market_engine / market_ticks live
+func (r *replayService) run(run *replayRun, ticks []models.MarketTick, sim ReplaySimulation, speed float64) {
+	defer close(run.doneChan)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		select {
+		case <-run.stopChan:
+			cancel()
+		case <-run.doneChan:
+		}
+	}()
+
+	pair := run.snapshot().Pair
+	state := models.MarketEngine{Pair: pair, Price: ticks[0].Price}
+
+	for i, recorded := range ticks {
+		if i > 0 && speed > 0 {
+			wait := time.Duration(float64(recorded.CreatedAt-ticks[i-1].CreatedAt) / speed * float64(time.Second))
+			if wait > maxReplayStepDelay {
+				wait = maxReplayStepDelay
+			}
+
+			if wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-timer.C:
+				case <-ctx.Done():
+					timer.Stop()
+				}
+			}
+		}
+
+		if ctx.Err() != nil {
+			run.finish(ReplayStatusStopped, nil)
+			return
+		}
+
+		prevPrice := state.Price
+		if i > 0 {
+			state = r.market.NextState(state, recorded.BuyVolume, recorded.SellVolume)
+		}
+
+		tick := models.MarketTick{
+			Pair:       pair,
+			BlockID:    int64(i + 1),
+			Price:      state.Price,
+			BuyVolume:  recorded.BuyVolume,
+			SellVolume: recorded.SellVolume,
+			TxCount:    recorded.TxCount,
+			CreatedAt:  recorded.CreatedAt,
+		}
+
+		if err := r.candles.ApplyTick(ctx, tick); err != nil {
+			if ctx.Err() != nil {
+				run.finish(ReplayStatusStopped, nil)
+				return
+			}
+
+			logger.LogError(fmt.Sprintf("Market replay %d failed", run.snapshot().ID), err)
+			run.finish(ReplayStatusFailed, err)
+			return
+		}
+
+		r.publish(ctx, run.snapshot().ID, tick, prevPrice, state.Liquidity)
+
+		var bots []dto.BotBacktestResult
+		if sim != nil {
+			sim.OnTick(tick)
+			bots = sim.Results()
+		}
+
+		run.mu.Lock()
+		run.session.Processed = i + 1
+		run.session.LastPrice = state.Price
+		if bots != nil {
+			run.session.Bots = bots
+		}
+		run.mu.Unlock()
+	}
+
+	run.finish(ReplayStatusCompleted, nil)
+	logger.LogInfo("Market replay completed", zap.Int64("session_id", run.snapshot().ID))
+}
+
+func (r *replayService) publish(ctx context.Context, sessionID int64, tick models.MarketTick, prevPrice, liquidity float64) {
+	update := dto.PriceUpdate{
+		Pair:        tick.Pair,
+		BlockID:     tick.BlockID,
+		BlockNumber: int(tick.BlockID),
+		Price:       tick.Price,
+		PriceChange: tick.Price - prevPrice,
+		Liquidity:   liquidity,
+		BuyVolume:   tick.BuyVolume,
+		SellVolume:  tick.SellVolume,
+		TxCount:     tick.TxCount,
+		Timestamp:   tick.CreatedAt,
+	}
+
+	if prevPrice > 0 {
+		update.PriceChangePercent = update.PriceChange / prevPrice * 100
+	}
+
+	// event terpisah dari market.update agar client live tidak menganggapnya harga asli
+	if r.publisherWS != nil {
+		r.publisherWS.Publish(entity.EventMarketReplay, dto.ReplayUpdate{SessionID: sessionID, PriceUpdate: update})
+	}
+
+	if r.marketStream != nil {
+		if err := r.marketStream.PublishTicker(ctx, update); err != nil {
+			logger.LogWarn("Failed to publish replay ticker", zap.Error(err))
+		}
+	}
+}
+
+// Get implements [ReplayService].
+func (r *replayService) Get(id int64) (ReplaySession, error) {
+	r.mu.Lock()
+	run, ok := r.runs[id]
+	r.mu.Unlock()
+
+	if !ok {
+		return ReplaySession{}, entity.ErrReplayNotFound
+	}
+
+	return run.snapshot(), nil
+}
+
+// List implements [ReplayService].
+func (r *replayService) List() []ReplaySession {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sessions := make([]ReplaySession, 0, len(r.runs))
+	for _, run := range r.runs {
+		sessions = append(sessions, run.snapshot())
+	}
+
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].ID > sessions[j].ID })
+
+	return sessions
+}
+
+// Stop implements [ReplayService].
+func (r *replayService) Stop(id int64) (ReplaySession, error) {
+	r.mu.Lock()
+	run, ok := r.runs[id]
+	r.mu.Unlock()
+
+	if !ok {
+		return ReplaySession{}, entity.ErrReplayNotFound
+	}
+
+	run.stop()
+	<-run.doneChan
+
+	return run.snapshot(), nil
+}
+
+// Delete implements [ReplayService].
+func (r *replayService) Delete(id int64) error {
+	r.mu.Lock()
+	run, ok := r.runs[id]
+	r.mu.Unlock()
+
+	if !ok {
+		return entity.ErrReplayNotFound
+	}
+
+	session := run.snapshot()
+	if session.Status == ReplayStatusRunning {
+		return entity.ErrReplayRunning
+	}
+
+	if _, err := r.candles.DeleteCandlesByPair(session.Pair); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	delete(r.runs, id)
+	r.mu.Unlock()
+
+	return nil
+}
+
+// Shutdown implements [ReplayService].
+func (r *replayService) Shutdown() {
+	r.mu.Lock()
+	runs := make([]*replayRun, 0, len(r.runs))
+	for _, run := range r.runs {
+		runs = append(runs, run)
+	}
+	r.mu.Unlock()
+
+	for _, run := range runs {
+		run.stop()
+		<-run.doneChan
+	}
+}
+
+func (run *replayRun) snapshot() ReplaySession {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+
+	session := run.session
+	session.Bots = append([]dto.BotBacktestResult(nil), run.session.Bots...)
+
+	return session
+}
+
+func (run *replayRun) stop() {
+	run.stopOnce.Do(func() { close(run.stopChan) })
+}
+
+func (run *replayRun) finish(status string, err error) {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+
+	run.session.Status = status
+	run.session.FinishedAt = time.Now().Unix()
+	if err != nil {
+		run.session.Error = err.Error()
+	}
+}
@@ -0,0 +1,394 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/livingdolls/go-blockchain-simulate/app/dto"
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+	"github.com/livingdolls/go-blockchain-simulate/app/repository"
+	"github.com/livingdolls/go-blockchain-simulate/logger"
+	"github.com/livingdolls/go-blockchain-simulate/utils"
+	"go.uber.org/zap"
+)
+
+// tick diarsipkan per hari agar satu transaksi tidak terlalu besar
+const retentionTickChunk int64 = 24 * 60 * 60
+
+// RetentionConfig - retention 0 / interval tidak ada di map = simpan selamanya
+type RetentionConfig struct {
+	Enabled         bool
+	RunEvery        time.Duration
+	CandleRetention map[string]time.Duration
+	TickRetention   time.Duration
+	// TickArchiveInterval - ukuran bucket arsip tick (interval candle, mis. 1h)
+	TickArchiveInterval string
+}
+
+type RetentionService interface {
+	// Run - hapus candle lama per interval, arsipkan lalu hapus market_ticks lama
+	Run(ctx context.Context) (models.RetentionReport, error)
+	LastReport() (models.RetentionReport, bool)
+	Config() RetentionConfig
+	UpdateConfig(cfg RetentionConfig) (RetentionConfig, error)
+	StorageStats() ([]models.TableStorage, error)
+	GetTickArchives(pair, interval string, from, to int64) ([]models.TickArchive, error)
+}
+
+type retentionService struct {
+	repo       repository.RetentionRepository
+	candleRepo repository.CandlesRepository
+
+	mu         sync.Mutex
+	cfg        RetentionConfig
+	lastReport *models.RetentionReport
+	running    sync.Mutex
+}
+
+func NewRetentionService(repo repository.RetentionRepository, candleRepo repository.CandlesRepository, cfg RetentionConfig) RetentionService {
+	normalized, err := normalizeRetentionConfig(cfg)
+	if err != nil {
+		logger.LogWarn("Invalid retention config, retention disabled", zap.Error(err))
+		normalized = RetentionConfig{RunEvery: time.Hour, CandleRetention: map[string]time.Duration{}, TickArchiveInterval: "1h"}
+	}
+
+	return &retentionService{
+		repo:       repo,
+		candleRepo: candleRepo,
+		cfg:        normalized,
+	}
+}
+
+// Run implements [RetentionService].
+func (r *retentionService) Run(ctx context.Context) (models.RetentionReport, error) {
+	if !r.running.TryLock() {
+		return models.RetentionReport{}, fmt.Errorf("%w: retention job already running", entity.ErrConflict)
+	}
+	defer r.running.Unlock()
+
+	cfg := r.Config()
+	now := time.Now()
+
+	report := models.RetentionReport{
+		StartedAt:      now.Unix(),
+		CandlesDeleted: make(map[string]int64),
+	}
+
+	err := r.deleteCandles(ctx, cfg, now, &report)
+	if err == nil {
+		err = r.archiveTicks(ctx, cfg, now, &report)
+	}
+
+	report.FinishedAt = time.Now().Unix()
+	if err != nil {
+		report.Error = err.Error()
+	}
+
+	r.mu.Lock()
+	r.lastReport = &report
+	r.mu.Unlock()
+
+	logger.LogInfo("Retention job finished",
+		zap.Any("candles_deleted", report.CandlesDeleted),
+		zap.Int64("ticks_archived", report.TicksArchived),
+		zap.Int64("archives_written", report.ArchivesWritten),
+		zap.Error(err),
+	)
+
+	return report, err
+}
+
+func (r *retentionService) deleteCandles(ctx context.Context, cfg RetentionConfig, now time.Time, report *models.RetentionReport) (err error) {
+	pairs, err := r.repo.GetCandlePairs()
+	if err != nil {
+		return err
+	}
+
+	for _, interval := range utils.CandleIntervals {
+		retention := cfg.CandleRetention[interval]
+		if retention <= 0 {
+			continue
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		cutoff := now.Add(-retention).Unix()
+
+		tx, err := r.candleRepo.CandleBeginTx()
+		if err != nil {
+			return err
+		}
+
+		var deleted int64
+		for _, pair := range pairs {
+			n, err := r.candleRepo.DeleteOldCandlesWithTx(tx, pair, interval, cutoff)
+			if err != nil {
+				_ = tx.Rollback()
+				return err
+			}
+			deleted += n
+		}
+
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+
+		report.CandlesDeleted[interval] = deleted
+	}
+
+	return nil
+}
+
+// archiveTicks - tick sebelum cutoff diringkas per bucket TickArchiveInterval lalu dihapus dalam satu transaksi.
+// cutoff dibulatkan ke awal bucket agar satu bucket tidak terarsip sebagian
+func (r *retentionService) archiveTicks(ctx context.Context, cfg RetentionConfig, now time.Time, report *models.RetentionReport) error {
+	if cfg.TickRetention <= 0 {
+		return nil
+	}
+
+	cutoff := utils.FloorTime(now.Add(-cfg.TickRetention).Unix(), cfg.TickArchiveInterval)
+
+	pairs, err := r.repo.GetTickPairs()
+	if err != nil {
+		return err
+	}
+
+	for _, pair := range pairs {
+		oldest, ok, err := r.repo.GetOldestTickTime(pair)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			continue
+		}
+
+		cursor := utils.FloorTime(oldest, cfg.TickArchiveInterval)
+		for cursor < cutoff {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			end := utils.FloorTime(cursor+retentionTickChunk, cfg.TickArchiveInterval)
+			if end <= cursor {
+				end = utils.NextIntervalStart(cursor, cfg.TickArchiveInterval)
+			}
+			if end > cutoff {
+				end = cutoff
+			}
+
+			if err := r.archiveTickRange(pair, cfg.TickArchiveInterval, cursor, end, report); err != nil {
+				return fmt.Errorf("archive ticks %s [%d, %d): %w", pair, cursor, end, err)
+			}
+
+			cursor = end
+		}
+	}
+
+	return nil
+}
+
+func (r *retentionService) archiveTickRange(pair, interval string, start, end int64, report *models.RetentionReport) (err error) {
+	ticks, err := r.candleRepo.GetTicksRange(pair, start, end)
+	if err != nil {
+		return err
+	}
+
+	if len(ticks) == 0 {
+		return nil
+	}
+
+	tx, err := r.repo.BeginTx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	archives := buildTickArchives(pair, interval, ticks)
+	for _, archive := range archives {
+		if err = r.repo.UpsertTickArchiveWithTx(tx, archive); err != nil {
+			return err
+		}
+	}
+
+	deleted, err := r.repo.DeleteTicksRangeWithTx(tx, pair, start, end)
+	if err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	report.TicksArchived += deleted
+	report.ArchivesWritten += int64(len(archives))
+
+	return nil
+}
+
+// buildTickArchives - ticks sudah urut berdasarkan waktu
+func buildTickArchives(pair, interval string, ticks []models.MarketTick) []models.TickArchive {
+	var archives []models.TickArchive
+
+	for _, tick := range ticks {
+		start := utils.FloorTime(tick.CreatedAt, interval)
+
+		if n := len(archives); n > 0 && archives[n-1].BucketStart == start {
+			last := &archives[n-1]
+			if tick.Price > last.HighPrice {
+				last.HighPrice = tick.Price
+			}
+			if tick.Price < last.LowPrice {
+				last.LowPrice = tick.Price
+			}
+			if tick.BlockID > last.LastBlockID {
+				last.LastBlockID = tick.BlockID
+			}
+			last.ClosePrice = tick.Price
+			last.BuyVolume += tick.BuyVolume
+			last.SellVolume += tick.SellVolume
+			last.TxCount += tick.TxCount
+			last.TickCount++
+			continue
+		}
+
+		archives = append(archives, models.TickArchive{
+			Pair:         pair,
+			IntervalType: interval,
+			BucketStart:  start,
+			OpenPrice:    tick.Price,
+			HighPrice:    tick.Price,
+			LowPrice:     tick.Price,
+			ClosePrice:   tick.Price,
+			BuyVolume:    tick.BuyVolume,
+			SellVolume:   tick.SellVolume,
+			TxCount:      tick.TxCount,
+			TickCount:    1,
+			FirstBlockID: tick.BlockID,
+			LastBlockID:  tick.BlockID,
+		})
+	}
+
+	return archives
+}
+
+// LastReport implements [RetentionService].
+func (r *retentionService) LastReport() (models.RetentionReport, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.lastReport == nil {
+		return models.RetentionReport{}, false
+	}
+
+	return *r.lastReport, true
+}
+
+// Config implements [RetentionService].
+func (r *retentionService) Config() RetentionConfig {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cfg := r.cfg
+	cfg.CandleRetention = make(map[string]time.Duration, len(r.cfg.CandleRetention))
+	for interval, retention := range r.cfg.CandleRetention {
+		cfg.CandleRetention[interval] = retention
+	}
+
+	return cfg
+}
+
+// UpdateConfig implements [RetentionService].
+func (r *retentionService) UpdateConfig(cfg RetentionConfig) (RetentionConfig, error) {
+	normalized, err := normalizeRetentionConfig(cfg)
+	if err != nil {
+		return RetentionConfig{}, err
+	}
+
+	r.mu.Lock()
+	r.cfg = normalized
+	r.mu.Unlock()
+
+	logger.LogInfo("Retention config updated", zap.Bool("enabled", normalized.Enabled), zap.Duration("tick_retention", normalized.TickRetention))
+
+	return r.Config(), nil
+}
+
+// StorageStats implements [RetentionService].
+func (r *retentionService) StorageStats() ([]models.TableStorage, error) {
+	return r.repo.GetTableStorage()
+}
+
+// GetTickArchives implements [RetentionService].
+func (r *retentionService) GetTickArchives(pair, interval string, from, to int64) ([]models.TickArchive, error) {
+	if interval == "" {
+		interval = r.Config().TickArchiveInterval
+	}
+
+	if !dto.IsValidInterval(interval) {
+		return nil, fmt.Errorf("%w: invalid interval %q", entity.ErrInvalidInput, interval)
+	}
+
+	if to == 0 {
+		to = time.Now().Unix()
+	}
+
+	if to <= from {
+		return nil, fmt.Errorf("%w: from must be before to", entity.ErrInvalidInput)
+	}
+
+	return r.repo.GetTickArchives(NormalizePair(pair), interval, from, to)
+}
+
+func normalizeRetentionConfig(cfg RetentionConfig) (RetentionConfig, error) {
+	if cfg.RunEvery <= 0 {
+		cfg.RunEvery = time.Hour
+	}
+
+	if cfg.RunEvery < time.Minute {
+		return RetentionConfig{}, fmt.Errorf("%w: run_every must be at least one minute", entity.ErrInvalidInput)
+	}
+
+	if cfg.TickArchiveInterval == "" {
+		cfg.TickArchiveInterval = "1h"
+	}
+
+	if !dto.IsValidInterval(cfg.TickArchiveInterval) {
+		return RetentionConfig{}, fmt.Errorf("%w: invalid tick_archive_interval %q", entity.ErrInvalidInput, cfg.TickArchiveInterval)
+	}
+
+	if cfg.TickRetention < 0 {
+		return RetentionConfig{}, fmt.Errorf("%w: tick retention must not be negative", entity.ErrInvalidInput)
+	}
+
+	retention := make(map[string]time.Duration, len(cfg.CandleRetention))
+	for interval, d := range cfg.CandleRetention {
+		if !dto.IsValidInterval(interval) {
+			return RetentionConfig{}, fmt.Errorf("%w: invalid interval %q", entity.ErrInvalidInput, interval)
+		}
+
+		if d < 0 {
+			return RetentionConfig{}, fmt.Errorf("%w: retention for %s must not be negative", entity.ErrInvalidInput, interval)
+		}
+
+		// candle harus hidup lebih lama dari window rollup interval di atasnya
+		if d > 0 && d < 48*time.Hour {
+			return RetentionConfig{}, fmt.Errorf("%w: retention for %s must be at least 2 days", entity.ErrInvalidInput, interval)
+		}
+
+		retention[interval] = d
+	}
+	cfg.CandleRetention = retention
+
+	return cfg, nil
+}
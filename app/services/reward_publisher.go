@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/livingdolls/go-blockchain-simulate/app/dto"
+	"github.com/livingdolls/go-blockchain-simulate/app/port"
 	"github.com/livingdolls/go-blockchain-simulate/logger"
 	"github.com/livingdolls/go-blockchain-simulate/rabbitmq"
 )
@@ -16,10 +17,10 @@ type RewardPublisher interface {
 }
 
 type rewardPublisher struct {
-	client *rabbitmq.Client
+	client port.MessageBroker
 }
 
-func NewRewardPublisher(client *rabbitmq.Client) RewardPublisher {
+func NewRewardPublisher(client port.MessageBroker) RewardPublisher {
 	return &rewardPublisher{client: client}
 }
 
@@ -30,7 +31,7 @@ func (rp *rewardPublisher) PublishRewardCalculation(ctx context.Context, event d
 		return fmt.Errorf("failed to marshal reward calculation event: %v", err)
 	}
 
-	err = rp.client.Publish(
+	err = rp.client.PublishWithConfirm(
 		ctx,
 		rabbitmq.RewardExchange,
 		rabbitmq.RewardCalculationKey,
@@ -53,7 +54,7 @@ func (rp *rewardPublisher) PublishRewardDistribution(ctx context.Context, event
 		return fmt.Errorf("failed to marshal reward distribution event: %v", err)
 	}
 
-	err = rp.client.Publish(
+	err = rp.client.PublishWithConfirm(
 		ctx,
 		rabbitmq.RewardExchange,
 		rabbitmq.RewardDistributionKey,
@@ -0,0 +1,504 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/livingdolls/go-blockchain-simulate/app/dto"
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+	"github.com/livingdolls/go-blockchain-simulate/app/repository"
+	"github.com/livingdolls/go-blockchain-simulate/utils"
+)
+
+type TokenService interface {
+	CreateToken(ctx context.Context, req dto.CreateTokenRequest) (models.Transaction, error)
+	TransferToken(ctx context.Context, req dto.TokenTransferRequest) (models.Transaction, error)
+	ListTokens(status string, limit, offset int) ([]models.Token, error)
+	GetToken(symbol string) (dto.TokenDetailResponse, error)
+	GetHolders(symbol string, limit, offset int) (dto.TokenHoldersResponse, error)
+	ValidateBlockTokens(txs []models.Transaction) error
+	ApplyBlockTokensWithTx(tx *sqlx.Tx, blockID int64, txs []models.Transaction) ([]repository.LedgerEntry, error)
+}
+
+type tokenService struct {
+	tokens   repository.TokenRepository
+	assets   repository.AssetRepository
+	users    repository.UserRepository
+	wallets  repository.UserWalletRepository
+	txs      repository.TransactionRepository
+	txVerify VerifyTxService
+}
+
+func NewTokenService(
+	tokens repository.TokenRepository,
+	assets repository.AssetRepository,
+	users repository.UserRepository,
+	wallets repository.UserWalletRepository,
+	txs repository.TransactionRepository,
+	txVerify VerifyTxService,
+) TokenService {
+	return &tokenService{
+		tokens:   tokens,
+		assets:   assets,
+		users:    users,
+		wallets:  wallets,
+		txs:      txs,
+		txVerify: txVerify,
+	}
+}
+
+// CreateToken implements [TokenService].
+// symbol direservasi saat transaksi masuk pending, supply dikreditkan ke creator saat block dikonfirmasi
+func (s *tokenService) CreateToken(ctx context.Context, req dto.CreateTokenRequest) (models.Transaction, error) {
+	symbol := strings.ToUpper(strings.TrimSpace(req.Symbol))
+	name := strings.TrimSpace(req.Name)
+
+	if !assetSymbolPattern.MatchString(symbol) {
+		return models.Transaction{}, fmt.Errorf("%w: symbol must be 2-16 alphanumeric characters", entity.ErrInvalidTokenDefinition)
+	}
+
+	if name == "" || len(name) > 100 {
+		return models.Transaction{}, fmt.Errorf("%w: name is required (max 100 characters)", entity.ErrInvalidTokenDefinition)
+	}
+
+	if req.Decimals < 0 || req.Decimals > models.MaxTokenDecimals {
+		return models.Transaction{}, fmt.Errorf("%w: decimals must be between 0 and %d", entity.ErrInvalidTokenDefinition, models.MaxTokenDecimals)
+	}
+
+	if req.Supply <= 0 || !fitsDecimals(req.Supply, req.Decimals) {
+		return models.Transaction{}, fmt.Errorf("%w: supply must be positive with at most %d decimals", entity.ErrInvalidTokenDefinition, req.Decimals)
+	}
+
+	if _, err := s.assets.GetAsset(symbol); err == nil {
+		return models.Transaction{}, fmt.Errorf("%w: symbol %s already registered", entity.ErrConflict, symbol)
+	}
+
+	if _, err := s.tokens.GetBySymbol(symbol); err == nil {
+		return models.Transaction{}, fmt.Errorf("%w: symbol %s already reserved", entity.ErrConflict, symbol)
+	}
+
+	payload := TokenSigningPayload{
+		Symbol:   symbol,
+		Name:     name,
+		Decimals: req.Decimals,
+		Supply:   req.Supply,
+	}
+
+	if err := s.txVerify.VerifyCreateTokenSignature(ctx, req.Address, payload, req.Nonce, req.Signature); err != nil {
+		return models.Transaction{}, fmt.Errorf("%w: %w", entity.ErrSignatureVerificationFailed, err)
+	}
+
+	fee := utils.FormatFee(utils.MinimumFee)
+	if err := s.ensureFeeAvailable(req.Address, fee); err != nil {
+		return models.Transaction{}, err
+	}
+
+	tx := models.Transaction{
+		FromAddress: req.Address,
+		ToAddress:   req.Address,
+		Amount:      req.Supply,
+		Fee:         fee,
+		Type:        models.TxTypeCreateToken,
+		TokenSymbol: symbol,
+		Signature:   req.Signature,
+		Status:      "PENDING",
+	}
+
+	dbTx, err := s.tokens.BeginTx()
+	if err != nil {
+		return models.Transaction{}, fmt.Errorf("begin tx: %w", err)
+	}
+	defer dbTx.Rollback()
+
+	txID, err := s.txs.CreateWithTx(dbTx, tx)
+	if err != nil {
+		return models.Transaction{}, fmt.Errorf("failed to create tx: %w", err)
+	}
+	tx.ID = txID
+
+	if err := s.tokens.CreateWithTx(dbTx, models.Token{
+		Symbol:         symbol,
+		Name:           name,
+		Decimals:       req.Decimals,
+		TotalSupply:    req.Supply,
+		CreatorAddress: req.Address,
+		CreateTxID:     txID,
+	}); err != nil {
+		return models.Transaction{}, fmt.Errorf("failed to reserve token: %w", err)
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return models.Transaction{}, fmt.Errorf("commit tx: %w", err)
+	}
+
+	return tx, nil
+}
+
+// TransferToken implements [TokenService].
+func (s *tokenService) TransferToken(ctx context.Context, req dto.TokenTransferRequest) (models.Transaction, error) {
+	symbol := strings.ToUpper(strings.TrimSpace(req.Symbol))
+
+	if req.Amount <= 0 {
+		return models.Transaction{}, entity.ErrAmountMustBePositive
+	}
+
+	if strings.EqualFold(req.FromAddress, req.ToAddress) {
+		return models.Transaction{}, fmt.Errorf("%w: cannot send to the same address", entity.ErrInvalidInput)
+	}
+
+	token, err := s.tokens.GetBySymbol(symbol)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+
+	if token.Status != models.TokenStatusActive {
+		return models.Transaction{}, fmt.Errorf("%w: token %s is not active yet", entity.ErrInvalidTokenDefinition, symbol)
+	}
+
+	if !fitsDecimals(req.Amount, token.Decimals) {
+		return models.Transaction{}, fmt.Errorf("%w: amount has more than %d decimals", entity.ErrInvalidTokenDefinition, token.Decimals)
+	}
+
+	if err := s.txVerify.VerifyTokenTransferSignature(ctx, req.FromAddress, req.ToAddress, symbol, req.Amount, req.Nonce, req.Signature); err != nil {
+		return models.Transaction{}, fmt.Errorf("%w: %w", entity.ErrSignatureVerificationFailed, err)
+	}
+
+	if _, err := s.users.GetByAddress(req.ToAddress); err != nil {
+		return models.Transaction{}, fmt.Errorf("receiver not found")
+	}
+
+	balance, err := s.assets.GetBalance(req.FromAddress, symbol)
+	if err != nil {
+		return models.Transaction{}, fmt.Errorf("get token balance: %w", err)
+	}
+
+	available := balance.Balance
+	if pending, err := s.txs.GetPendingTokenAmountByAddress(req.FromAddress, symbol); err == nil {
+		available -= pending
+	}
+
+	if available < req.Amount {
+		return models.Transaction{}, fmt.Errorf("%w: required %.8f, available %.8f", entity.ErrInsufficientTokenBalance, req.Amount, available)
+	}
+
+	fee := utils.FormatFee(utils.MinimumFee)
+	if err := s.ensureFeeAvailable(req.FromAddress, fee); err != nil {
+		return models.Transaction{}, err
+	}
+
+	tx := models.Transaction{
+		FromAddress: req.FromAddress,
+		ToAddress:   req.ToAddress,
+		Amount:      req.Amount,
+		Fee:         fee,
+		Type:        models.TxTypeTokenTransfer,
+		TokenSymbol: symbol,
+		Signature:   req.Signature,
+		Status:      "PENDING",
+	}
+
+	txID, err := s.txs.Create(tx)
+	if err != nil {
+		return models.Transaction{}, fmt.Errorf("failed to create tx: %w", err)
+	}
+	tx.ID = txID
+
+	return tx, nil
+}
+
+// ListTokens implements [TokenService].
+func (s *tokenService) ListTokens(status string, limit, offset int) ([]models.Token, error) {
+	return s.tokens.GetAll(strings.ToUpper(status), limit, offset)
+}
+
+// GetToken implements [TokenService].
+func (s *tokenService) GetToken(symbol string) (dto.TokenDetailResponse, error) {
+	token, err := s.tokens.GetBySymbol(strings.ToUpper(strings.TrimSpace(symbol)))
+	if err != nil {
+		return dto.TokenDetailResponse{}, err
+	}
+
+	holders, err := s.tokens.CountHolders(token.Symbol)
+	if err != nil {
+		return dto.TokenDetailResponse{}, fmt.Errorf("count holders: %w", err)
+	}
+
+	return dto.TokenDetailResponse{Token: token, HolderCount: holders}, nil
+}
+
+// GetHolders implements [TokenService].
+func (s *tokenService) GetHolders(symbol string, limit, offset int) (dto.TokenHoldersResponse, error) {
+	token, err := s.tokens.GetBySymbol(strings.ToUpper(strings.TrimSpace(symbol)))
+	if err != nil {
+		return dto.TokenHoldersResponse{}, err
+	}
+
+	holders, err := s.tokens.GetHolders(token.Symbol, limit, offset)
+	if err != nil {
+		return dto.TokenHoldersResponse{}, fmt.Errorf("get holders: %w", err)
+	}
+
+	total, err := s.tokens.CountHolders(token.Symbol)
+	if err != nil {
+		return dto.TokenHoldersResponse{}, fmt.Errorf("count holders: %w", err)
+	}
+
+	return dto.TokenHoldersResponse{
+		Symbol:  token.Symbol,
+		Holders: holders,
+		Total:   total,
+		Limit:   limit,
+		Offset:  offset,
+	}, nil
+}
+
+// ValidateBlockTokens implements [TokenService].
+// pre-validasi tanpa lock sebelum mining, sama seperti validasi saldo YTE di GenerateBlock
+func (s *tokenService) ValidateBlockTokens(txs []models.Transaction) error {
+	_, _, err := s.simulate(txs,
+		func(address, symbol string) (float64, error) {
+			balance, err := s.assets.GetBalance(address, symbol)
+			return balance.Balance, err
+		},
+		s.tokens.GetBySymbol,
+	)
+
+	return err
+}
+
+// ApplyBlockTokensWithTx implements [TokenService].
+// dijalankan di dalam transaksi block: lock saldo token, aktifkan token baru, update saldo dan kembalikan ledger entries
+func (s *tokenService) ApplyBlockTokensWithTx(tx *sqlx.Tx, blockID int64, txs []models.Transaction) ([]repository.LedgerEntry, error) {
+	moves, balances, err := s.simulate(txs,
+		func(address, symbol string) (float64, error) {
+			balance, err := s.assets.GetBalanceForUpdateWithTx(tx, address, symbol)
+			return balance.Balance, err
+		},
+		func(symbol string) (models.Token, error) {
+			return s.tokens.GetBySymbolForUpdateWithTx(tx, symbol)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(moves) == 0 {
+		return nil, nil
+	}
+
+	var entries []repository.LedgerEntry
+
+	for _, m := range moves {
+		txID := m.tx.ID
+		txIDPtr := &txID
+
+		if m.tx.Type == models.TxTypeCreateToken {
+			// asset harus terdaftar dulu sebelum saldo (foreign key asset_balances)
+			if err := s.assets.CreateAssetWithTx(tx, models.Asset{
+				Symbol:   m.token.Symbol,
+				Name:     m.token.Name,
+				Decimals: m.token.Decimals,
+			}); err != nil {
+				return nil, fmt.Errorf("register token asset %s: %w", m.token.Symbol, err)
+			}
+
+			if err := s.tokens.ActivateWithTx(tx, m.token.Symbol, blockID); err != nil {
+				return nil, fmt.Errorf("activate token %s: %w", m.token.Symbol, err)
+			}
+
+			entries = append(entries, repository.LedgerEntry{
+				BlockID:      blockID,
+				TxID:         txIDPtr,
+				Address:      m.tx.ToAddress,
+				Asset:        m.token.Symbol,
+				Leg:          models.LedgerLegTokenCredit,
+				Amount:       m.tx.Amount,
+				BalanceAfter: m.toAfter,
+			})
+			continue
+		}
+
+		entries = append(entries,
+			repository.LedgerEntry{
+				BlockID:      blockID,
+				TxID:         txIDPtr,
+				Address:      m.tx.FromAddress,
+				Asset:        m.token.Symbol,
+				Leg:          models.LedgerLegTokenDebit,
+				Amount:       -m.tx.Amount,
+				BalanceAfter: m.fromAfter,
+			},
+			repository.LedgerEntry{
+				BlockID:      blockID,
+				TxID:         txIDPtr,
+				Address:      m.tx.ToAddress,
+				Asset:        m.token.Symbol,
+				Leg:          models.LedgerLegTokenCredit,
+				Amount:       m.tx.Amount,
+				BalanceAfter: m.toAfter,
+			},
+		)
+	}
+
+	for key, b := range balances {
+		delta := b.current - b.initial
+		if delta == 0 {
+			continue
+		}
+
+		if err := s.assets.AdjustBalanceWithTx(tx, key.address, key.symbol, delta); err != nil {
+			return nil, fmt.Errorf("adjust token balance %s/%s: %w", key.address, key.symbol, err)
+		}
+	}
+
+	return entries, nil
+}
+
+type tokenBalanceKey struct {
+	address string
+	symbol  string
+}
+
+type tokenBalance struct {
+	initial float64
+	current float64
+}
+
+type tokenMove struct {
+	tx        models.Transaction
+	token     models.Token
+	fromAfter float64
+	toAfter   float64
+}
+
+// simulate menjalankan transaksi token secara berurutan di memory
+func (s *tokenService) simulate(
+	txs []models.Transaction,
+	balanceOf func(address, symbol string) (float64, error),
+	tokenOf func(symbol string) (models.Token, error),
+) ([]tokenMove, map[tokenBalanceKey]*tokenBalance, error) {
+	balances := make(map[tokenBalanceKey]*tokenBalance)
+	tokens := make(map[string]models.Token)
+	var moves []tokenMove
+
+	getBalance := func(address, symbol string) (*tokenBalance, error) {
+		key := tokenBalanceKey{address: address, symbol: symbol}
+		if b, ok := balances[key]; ok {
+			return b, nil
+		}
+
+		amount, err := balanceOf(address, symbol)
+		if err != nil {
+			return nil, fmt.Errorf("get token balance %s/%s: %w", address, symbol, err)
+		}
+
+		b := &tokenBalance{initial: amount, current: amount}
+		balances[key] = b
+		return b, nil
+	}
+
+	getToken := func(symbol string) (models.Token, error) {
+		if t, ok := tokens[symbol]; ok {
+			return t, nil
+		}
+
+		t, err := tokenOf(symbol)
+		if err != nil {
+			return models.Token{}, fmt.Errorf("token %s: %w", symbol, err)
+		}
+
+		tokens[symbol] = t
+		return t, nil
+	}
+
+	for _, t := range txs {
+		if !models.IsTokenTransaction(t.Type) {
+			continue
+		}
+
+		token, err := getToken(t.TokenSymbol)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		switch t.Type {
+		case models.TxTypeCreateToken:
+			if token.Status != models.TokenStatusPending || token.CreateTxID != t.ID {
+				return nil, nil, fmt.Errorf("%w: token %s already created", entity.ErrInvalidTokenDefinition, token.Symbol)
+			}
+
+			creator, err := getBalance(t.ToAddress, token.Symbol)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			creator.current += t.Amount
+
+			// transfer berikutnya di block yang sama boleh memakai token ini
+			token.Status = models.TokenStatusActive
+			tokens[token.Symbol] = token
+
+			moves = append(moves, tokenMove{tx: t, token: token, toAfter: creator.current})
+
+		case models.TxTypeTokenTransfer:
+			if token.Status != models.TokenStatusActive {
+				return nil, nil, fmt.Errorf("%w: token %s is not active", entity.ErrInvalidTokenDefinition, token.Symbol)
+			}
+
+			sender, err := getBalance(t.FromAddress, token.Symbol)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			receiver, err := getBalance(t.ToAddress, token.Symbol)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if sender.current < t.Amount {
+				return nil, nil, fmt.Errorf("%w for address %s: need %.8f %s, have %.8f",
+					entity.ErrInsufficientTokenBalance, t.FromAddress, t.Amount, token.Symbol, sender.current)
+			}
+
+			sender.current -= t.Amount
+			receiver.current += t.Amount
+
+			moves = append(moves, tokenMove{tx: t, token: token, fromAfter: sender.current, toAfter: receiver.current})
+		}
+	}
+
+	return moves, balances, nil
+}
+
+// ensureFeeAvailable memastikan saldo YTE cukup untuk fee setelah dikurangi transaksi pending
+func (s *tokenService) ensureFeeAvailable(address string, fee float64) error {
+	wallet, err := s.wallets.GetByAddress(address)
+	if err != nil {
+		if errors.Is(err, entity.ErrUserWalletNotFound) {
+			return err
+		}
+		return fmt.Errorf("wallet not found for address %s", address)
+	}
+
+	available := wallet.YTEBalance
+	if pending, err := s.txs.GetPendingTransactionsByAddress(address); err == nil {
+		available -= pending
+	}
+
+	if available < fee {
+		return fmt.Errorf("%w: fee %.5f YTE, available %.5f", entity.ErrInsufficientWalletBalance, fee, available)
+	}
+
+	return nil
+}
+
+// fitsDecimals cek amount tidak punya digit lebih dari decimals token
+func fitsDecimals(amount float64, decimals int) bool {
+	scaled := amount * math.Pow10(decimals)
+	return math.Abs(scaled-math.Round(scaled)) < 1e-6
+}
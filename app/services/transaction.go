@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
 	"github.com/livingdolls/go-blockchain-simulate/app/models"
 	"github.com/livingdolls/go-blockchain-simulate/app/repository"
 	"github.com/livingdolls/go-blockchain-simulate/redis"
@@ -19,6 +20,7 @@ type TransactionService interface {
 	SendWithSignature(ctx context.Context, fromAddress, toAddress string, amount float64, nonce, signature string) (models.Transaction, error)
 	Buy(ctx context.Context, address, signature, nonce string, amount float64) (models.Transaction, error)
 	Sell(ctx context.Context, address, signature, nonce string, amount float64) (models.Transaction, error)
+	SubmitPreAuthorized(ctx context.Context, address, signature string, amount float64, txType TransactionType) (models.Transaction, error)
 }
 
 type transactionService struct {
@@ -29,6 +31,7 @@ type transactionService struct {
 	ledgers  repository.LedgerRepository
 	memory   redis.MemoryAdapter
 	txVerify VerifyTxService
+	breaker  CircuitBreakerService
 }
 
 func NewTransactionService(
@@ -39,6 +42,7 @@ func NewTransactionService(
 	ledgers repository.LedgerRepository,
 	memory redis.MemoryAdapter,
 	txVerify VerifyTxService,
+	breaker CircuitBreakerService,
 ) TransactionService {
 	return &transactionService{
 		users:    users,
@@ -48,6 +52,7 @@ func NewTransactionService(
 		ledgers:  ledgers,
 		memory:   memory,
 		txVerify: txVerify,
+		breaker:  breaker,
 	}
 }
 
@@ -69,16 +74,16 @@ func (s *transactionService) GenerateTransactionNonce(ctx context.Context, addre
 func (s *transactionService) SendWithSignature(ctx context.Context, fromAddress, toAddress string, amount float64, nonce, signature string) (models.Transaction, error) {
 	// validate inputs amount
 	if amount <= 0 {
-		return models.Transaction{}, fmt.Errorf("amount must be greater than zero")
+		return models.Transaction{}, entity.ErrAmountMustBePositive
 	}
 
 	if fromAddress == toAddress {
-		return models.Transaction{}, fmt.Errorf("cannot send to the same address")
+		return models.Transaction{}, fmt.Errorf("%w: cannot send to the same address", entity.ErrInvalidInput)
 	}
 
 	// verify signature
 	if err := s.txVerify.VerifyTransactionSignature(ctx, fromAddress, toAddress, amount, nonce, signature); err != nil {
-		return models.Transaction{}, fmt.Errorf("signature verification failed: %w", err)
+		return models.Transaction{}, fmt.Errorf("%w: %w", entity.ErrSignatureVerificationFailed, err)
 	}
 
 	// get sender and receiver
@@ -150,9 +155,44 @@ func (s *transactionService) SendWithSignature(ctx context.Context, fromAddress,
 func (s *transactionService) Buy(ctx context.Context, address, signature, nonce string, amount float64) (models.Transaction, error) {
 	// validate inputs amount
 	if amount <= 0 {
-		return models.Transaction{}, fmt.Errorf("amount must be greater than zero")
+		return models.Transaction{}, entity.ErrAmountMustBePositive
 	}
 
+	// cek halt sebelum verifikasi agar nonce tidak terpakai
+	if err := s.breaker.EnsureTradingOpen(ctx); err != nil {
+		return models.Transaction{}, err
+	}
+
+	// verify signature
+	if err := s.txVerify.VerifyBuySellSignature(ctx, address, amount, nonce, signature, BuyTransaction); err != nil {
+		return models.Transaction{}, fmt.Errorf("%w: %w", entity.ErrSignatureVerificationFailed, err)
+	}
+
+	return s.createBuy(address, signature, amount)
+}
+
+// SubmitPreAuthorized creates a BUY/SELL transaction whose signature was already
+// verified earlier, e.g. when a conditional order was placed
+func (s *transactionService) SubmitPreAuthorized(ctx context.Context, address, signature string, amount float64, txType TransactionType) (models.Transaction, error) {
+	if amount <= 0 {
+		return models.Transaction{}, entity.ErrAmountMustBePositive
+	}
+
+	if err := s.breaker.EnsureTradingOpen(ctx); err != nil {
+		return models.Transaction{}, err
+	}
+
+	switch txType {
+	case BuyTransaction:
+		return s.createBuy(address, signature, amount)
+	case SellTransaction:
+		return s.createSell(address, signature, amount)
+	default:
+		return models.Transaction{}, entity.ErrInvalidTransactionType
+	}
+}
+
+func (s *transactionService) createBuy(address, signature string, amount float64) (models.Transaction, error) {
 	//sistem sebagai penjual adalah address "MINER_ACCOUNT"
 	buyerAddress := address
 	sellerAddress := "MINER_ACCOUNT"
@@ -203,11 +243,6 @@ func (s *transactionService) Buy(ctx context.Context, address, signature, nonce
 		return models.Transaction{}, fmt.Errorf("seller wallet not found for address %s", sellerAddress)
 	}
 
-	// verify signature
-	if err := s.txVerify.VerifyBuySellSignature(ctx, buyerAddress, amount, nonce, signature, BuyTransaction); err != nil {
-		return models.Transaction{}, fmt.Errorf("signature verification failed: %w", err)
-	}
-
 	// create transaction
 	tx := models.Transaction{
 		FromAddress: sellerAddress,
@@ -231,12 +266,26 @@ func (s *transactionService) Buy(ctx context.Context, address, signature, nonce
 	return tx, nil
 }
 
-func (s *transactionService) Sell(ctx context.Context, address, nonce, signature string, amount float64) (models.Transaction, error) {
+func (s *transactionService) Sell(ctx context.Context, address, signature, nonce string, amount float64) (models.Transaction, error) {
 	// validate inputs amount
 	if amount <= 0 {
-		return models.Transaction{}, fmt.Errorf("amount must be greater than zero")
+		return models.Transaction{}, entity.ErrAmountMustBePositive
+	}
+
+	// cek halt sebelum verifikasi agar nonce tidak terpakai
+	if err := s.breaker.EnsureTradingOpen(ctx); err != nil {
+		return models.Transaction{}, err
+	}
+
+	// verify signature
+	if err := s.txVerify.VerifyBuySellSignature(ctx, address, amount, nonce, signature, SellTransaction); err != nil {
+		return models.Transaction{}, fmt.Errorf("%w: %w", entity.ErrSignatureVerificationFailed, err)
 	}
 
+	return s.createSell(address, signature, amount)
+}
+
+func (s *transactionService) createSell(address, signature string, amount float64) (models.Transaction, error) {
 	//sistem sebagai pembeli adalah address "MINER_ACCOUNT"
 	sellerAddress := address
 	buyerAddress := "MINER_ACCOUNT"
@@ -266,11 +315,6 @@ func (s *transactionService) Sell(ctx context.Context, address, nonce, signature
 		return models.Transaction{}, fmt.Errorf("buyer wallet not found for address %s", buyerAddress)
 	}
 
-	// verify signature
-	if err := s.txVerify.VerifyBuySellSignature(ctx, sellerAddress, amount, nonce, signature, SellTransaction); err != nil {
-		return models.Transaction{}, fmt.Errorf("signature verification failed: %w", err)
-	}
-
 	// check if seller has enough balance
 	if sellerWallet.YTEBalance < amount {
 		return models.Transaction{}, fmt.Errorf("insufficient balance: required %.5f, available %.5f", amount, sellerWallet.YTEBalance)
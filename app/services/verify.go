@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
 	"github.com/livingdolls/go-blockchain-simulate/logger"
 	"github.com/livingdolls/go-blockchain-simulate/redis"
 	"github.com/livingdolls/go-blockchain-simulate/utils"
@@ -24,6 +25,26 @@ const (
 type VerifyTxService interface {
 	VerifyTransactionSignature(ctx context.Context, fromAddt, toAddr string, amount float64, nonce, signature string) error
 	VerifyBuySellSignature(ctx context.Context, address string, amount float64, nonce, signature string, txType TransactionType) error
+	VerifyConditionalOrderSignature(ctx context.Context, address string, order ConditionalOrderSigningPayload, nonce, signature string) error
+	VerifyCreateTokenSignature(ctx context.Context, address string, token TokenSigningPayload, nonce, signature string) error
+	VerifyTokenTransferSignature(ctx context.Context, fromAddr, toAddr, symbol string, amount float64, nonce, signature string) error
+}
+
+// TokenSigningPayload berisi definisi token yang ditandatangani creator
+type TokenSigningPayload struct {
+	Symbol   string
+	Name     string
+	Decimals int
+	Supply   float64
+}
+
+// ConditionalOrderSigningPayload berisi field order yang ditandatangani user
+type ConditionalOrderSigningPayload struct {
+	OrderType    string
+	Side         TransactionType
+	Amount       float64
+	TriggerPrice float64
+	TrailPercent float64
 }
 
 type verifyTxService struct {
@@ -52,7 +73,7 @@ func (s *verifyTxService) VerifyTransactionSignature(ctx context.Context, fromAd
 	storedNonce, ok := s.memory.Get(ctx, key)
 
 	if !ok || string(storedNonce) != nonce {
-		return fmt.Errorf("invalid nonce")
+		return entity.ErrInvalidNonce
 	}
 
 	// build cannonical message same on frontend
@@ -163,11 +184,11 @@ func (s *verifyTxService) VerifyBuySellSignature(ctx context.Context, address st
 	storedNonce, ok := s.memory.Get(ctx, key)
 
 	if !ok || string(storedNonce) != nonce {
-		return fmt.Errorf("invalid nonce")
+		return entity.ErrInvalidNonce
 	}
 
 	// build cannonical message same on frontend
-	msg := fmt.Sprintf(" %s %.2f nonce:%s", txType, amount, nonce)
+	msg := BuySellMessage(txType, amount, nonce)
 
 	logger.LogDebug("Message details",
 		zap.String("message", msg),
@@ -258,3 +279,168 @@ func (s *verifyTxService) VerifyBuySellSignature(ctx context.Context, address st
 
 	return nil
 }
+
+func (s *verifyTxService) VerifyConditionalOrderSignature(ctx context.Context, address string, order ConditionalOrderSigningPayload, nonce, signature string) error {
+	addr := strings.ToLower(strings.TrimSpace(address))
+
+	logger.LogDebug("Conditional order verification started",
+		zap.String("address", addr),
+		zap.String("order_type", order.OrderType),
+		zap.String("side", string(order.Side)),
+		zap.Float64("amount", order.Amount),
+		zap.String("nonce", nonce),
+	)
+
+	// verify nonce
+	key := "tx_nonce:" + addr
+	storedNonce, ok := s.memory.Get(ctx, key)
+
+	if !ok || string(storedNonce) != nonce {
+		return entity.ErrInvalidNonce
+	}
+
+	// build cannonical message same on frontend
+	msg := fmt.Sprintf("%s %s %.2f trigger:%.2f trail:%.2f nonce:%s",
+		order.OrderType, order.Side, order.Amount, order.TriggerPrice, order.TrailPercent, nonce)
+
+	recovered, err := recoverSignerAddress(msg, signature)
+	if err != nil {
+		return err
+	}
+
+	if recovered != addr {
+		return fmt.Errorf("address mismatch: recovered=%s expected=%s", recovered, addr)
+	}
+
+	//delete nonce on success
+	s.memory.Del(ctx, key)
+
+	return nil
+}
+
+func (s *verifyTxService) VerifyCreateTokenSignature(ctx context.Context, address string, token TokenSigningPayload, nonce, signature string) error {
+	addr := strings.ToLower(strings.TrimSpace(address))
+
+	logger.LogDebug("Create token verification started",
+		zap.String("address", addr),
+		zap.String("symbol", token.Symbol),
+		zap.Float64("supply", token.Supply),
+		zap.String("nonce", nonce),
+	)
+
+	return s.verifySignedMessage(ctx, addr, CreateTokenMessage(token, nonce), nonce, signature)
+}
+
+func (s *verifyTxService) VerifyTokenTransferSignature(ctx context.Context, fromAddr, toAddr, symbol string, amount float64, nonce, signature string) error {
+	from := strings.ToLower(strings.TrimSpace(fromAddr))
+
+	logger.LogDebug("Token transfer verification started",
+		zap.String("from", from),
+		zap.String("to", toAddr),
+		zap.String("symbol", symbol),
+		zap.Float64("amount", amount),
+		zap.String("nonce", nonce),
+	)
+
+	return s.verifySignedMessage(ctx, from, TokenTransferMessage(toAddr, symbol, amount, nonce), nonce, signature)
+}
+
+// verifySignedMessage cek nonce milik addr lalu pastikan msg ditandatangani addr, nonce dihapus jika sukses
+func (s *verifyTxService) verifySignedMessage(ctx context.Context, addr, msg, nonce, signature string) error {
+	key := "tx_nonce:" + addr
+	storedNonce, ok := s.memory.Get(ctx, key)
+
+	if !ok || string(storedNonce) != nonce {
+		return entity.ErrInvalidNonce
+	}
+
+	recovered, err := recoverSignerAddress(msg, signature)
+	if err != nil {
+		return err
+	}
+
+	if recovered != addr {
+		return fmt.Errorf("address mismatch: recovered=%s expected=%s", recovered, addr)
+	}
+
+	s.memory.Del(ctx, key)
+
+	return nil
+}
+
+// recoverSignerAddress memulihkan address (lowercase) dari signature EIP-191 atas msg
+func recoverSignerAddress(msg, signature string) (string, error) {
+	sigHex := strings.TrimPrefix(strings.TrimSpace(signature), "0x")
+	raw, err := hex.DecodeString(sigHex)
+
+	if err != nil {
+		return "", fmt.Errorf("invalid signature hex: %w", err)
+	}
+
+	if len(raw) != 65 {
+		return "", fmt.Errorf("invalid signature length: %d", len(raw))
+	}
+
+	sPart := new(big.Int).SetBytes(raw[32:64])
+	v := raw[64]
+
+	// normalize v to 27/28
+	origV := v
+	if v == 0 || v == 1 {
+		v += 27
+	} else if v >= 35 {
+		v = byte(((int(v) - 35) % 2) + 27)
+	}
+
+	if v != 27 && v != 28 {
+		return "", fmt.Errorf("invalid v value in signature: %d (original %d)", v, origV)
+	}
+
+	// low check eip-2 cannonical signature
+	halfN := new(big.Int).Rsh(crypto.S256().Params().N, 1)
+	if sPart.Cmp(halfN) == 1 {
+		return "", fmt.Errorf("signature s too high (non-canonical)")
+	}
+
+	hash := utils.PrefixedHash([]byte(msg))
+
+	var pubBytes []byte
+	for _, vTry := range []byte{v, utils.ToggleV(v)} {
+		testSig := make([]byte, 65)
+		copy(testSig[:64], raw[:64])
+		testSig[64] = vTry - 27 // ecrecover expects 0/1
+
+		recovered, err := crypto.Ecrecover(hash, testSig)
+		if err == nil {
+			pubBytes = recovered
+			break
+		}
+	}
+
+	if pubBytes == nil {
+		return "", fmt.Errorf("failed to recover public key from signature")
+	}
+
+	pubKey, err := crypto.UnmarshalPubkey(pubBytes)
+	if err != nil {
+		return "", fmt.Errorf("failed to unmarshal public key: %w", err)
+	}
+
+	return strings.ToLower(crypto.PubkeyToAddress(*pubKey).Hex()), nil
+}
+
+// BuySellMessage membangun message BUY/SELL yang harus ditandatangani (sama dengan frontend)
+func BuySellMessage(txType TransactionType, amount float64, nonce string) string {
+	return fmt.Sprintf(" %s %.2f nonce:%s", txType, amount, nonce)
+}
+
+// CreateTokenMessage membangun message CREATE_TOKEN yang harus ditandatangani (sama dengan frontend)
+func CreateTokenMessage(token TokenSigningPayload, nonce string) string {
+	return fmt.Sprintf("Create token %s %s decimals:%d supply:%.8f nonce:%s",
+		token.Symbol, token.Name, token.Decimals, token.Supply, nonce)
+}
+
+// TokenTransferMessage sama seperti message SEND dengan tambahan symbol token
+func TokenTransferMessage(toAddr, symbol string, amount float64, nonce string) string {
+	return fmt.Sprintf("Send %.8f %s to %s nonce:%s", amount, symbol, strings.ToLower(strings.TrimSpace(toAddr)), nonce)
+}
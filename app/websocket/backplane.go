@@ -0,0 +1,122 @@
+package websocket
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/livingdolls/go-blockchain-simulate/redis"
+)
+
+// BackplaneChannel - channel redis yang dipakai bersama semua instance API
+const BackplaneChannel = "ws:backplane"
+
+// Backplane - fan-out pesan hub ke semua instance. setiap instance menerima setiap pesan tepat sekali
+// dan hanya mengirim ke client yang terhubung ke instance tersebut
+type Backplane interface {
+	Publish(ctx context.Context, payload []byte) error
+	// Subscribe - blocking sampai ctx selesai atau koneksi backplane terputus
+	Subscribe(ctx context.Context, fn func(payload []byte)) error
+	// NextSequence - seq per address, harus sama di semua instance agar resume bisa di instance mana pun
+	NextSequence(ctx context.Context, address string) (uint64, error)
+}
+
+type redisBackplane struct {
+	redis   redis.MemoryAdapter
+	channel string
+}
+
+func NewRedisBackplane(adapter redis.MemoryAdapter, channel string) Backplane {
+	if channel == "" {
+		channel = BackplaneChannel
+	}
+
+	return &redisBackplane{redis: adapter, channel: channel}
+}
+
+// Publish implements [Backplane].
+func (r *redisBackplane) Publish(ctx context.Context, payload []byte) error {
+	return r.redis.Publish(ctx, r.channel, payload)
+}
+
+// Subscribe implements [Backplane].
+func (r *redisBackplane) Subscribe(ctx context.Context, fn func(payload []byte)) error {
+	return r.redis.Subscribe(ctx, r.channel, func(message []byte) error {
+		fn(message)
+		return nil
+	})
+}
+
+// NextSequence implements [Backplane].
+func (r *redisBackplane) NextSequence(ctx context.Context, address string) (uint64, error) {
+	seq, err := r.redis.Incr(ctx, fmt.Sprintf("%s:seq:%s", r.channel, address))
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(seq), nil
+}
+
+// memorySequence - seq per address dalam satu proses
+type memorySequence struct {
+	mu   sync.Mutex
+	seqs map[string]uint64
+}
+
+func newMemorySequence() *memorySequence {
+	return &memorySequence{seqs: make(map[string]uint64)}
+}
+
+func (m *memorySequence) NextSequence(ctx context.Context, address string) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.seqs[address]++
+	return m.seqs[address], nil
+}
+
+// memoryBackplane - backplane dalam satu proses, untuk single instance dan test.
+// beberapa hub yang memakai instance yang sama berperilaku seperti beberapa instance API
+type memoryBackplane struct {
+	*memorySequence
+
+	mu     sync.RWMutex
+	nextID int
+	subs   map[int]func(payload []byte)
+}
+
+func NewMemoryBackplane() Backplane {
+	return &memoryBackplane{
+		memorySequence: newMemorySequence(),
+		subs:           make(map[int]func(payload []byte)),
+	}
+}
+
+// Publish implements [Backplane].
+func (m *memoryBackplane) Publish(ctx context.Context, payload []byte) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, fn := range m.subs {
+		fn(payload)
+	}
+
+	return nil
+}
+
+// Subscribe implements [Backplane].
+func (m *memoryBackplane) Subscribe(ctx context.Context, fn func(payload []byte)) error {
+	m.mu.Lock()
+	id := m.nextID
+	m.nextID++
+	m.subs[id] = fn
+	m.mu.Unlock()
+
+	<-ctx.Done()
+
+	m.mu.Lock()
+	delete(m.subs, id)
+	m.mu.Unlock()
+
+	return nil
+}
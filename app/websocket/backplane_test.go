@@ -0,0 +1,203 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+)
+
+// startHub - jalankan hub seperti di bootstrap, client test dilepas sebelum Close (tanpa koneksi websocket)
+func startHub(t *testing.T, backplane Backplane, cfg HubConfig) *Hub {
+	t.Helper()
+
+	hub := NewHub(backplane, cfg)
+	go hub.Run()
+
+	t.Cleanup(func() {
+		hub.mu.Lock()
+		hub.clients = make(map[*ClientWS]bool)
+		hub.address = make(map[string]map[*ClientWS]bool)
+		hub.subscriptions = make(map[*ClientWS]map[string]*subscription)
+		hub.mu.Unlock()
+
+		hub.Close()
+	})
+
+	return hub
+}
+
+// addClient - daftarkan client lokal (send channel saja) dan subscribe ke topics
+func addClient(t *testing.T, hub *Hub, address string, topics ...string) *ClientWS {
+	t.Helper()
+
+	client := &ClientWS{address: address, hub: hub, send: make(chan []byte, 64), rpcSlots: make(chan struct{}, maxRPCInFlight)}
+
+	hub.mu.Lock()
+	hub.clients[client] = true
+	hub.subscriptions[client] = make(map[string]*subscription)
+	if hub.address[address] == nil {
+		hub.address[address] = make(map[*ClientWS]bool)
+	}
+	hub.address[address][client] = true
+	hub.mu.Unlock()
+
+	for _, topic := range topics {
+		if _, err := hub.Subscribe(client, topic); err != nil {
+			t.Fatalf("subscribe %s: %v", topic, err)
+		}
+	}
+
+	return client
+}
+
+// waitSubscribers - runBackplane subscribe secara async, tunggu sampai semua hub terdaftar
+func waitSubscribers(t *testing.T, backplane Backplane, n int) {
+	t.Helper()
+
+	mem := backplane.(*memoryBackplane)
+	deadline := time.Now().Add(2 * time.Second)
+
+	for {
+		mem.mu.RLock()
+		subs := len(mem.subs)
+		mem.mu.RUnlock()
+
+		if subs == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("backplane subscribers = %d, want %d", subs, n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// receive - tepat n pesan dalam waktu singkat, lalu tidak ada pesan tambahan
+func receive(t *testing.T, client *ClientWS, n int) []Message {
+	t.Helper()
+
+	var messages []Message
+	timeout := time.After(2 * time.Second)
+
+	for len(messages) < n {
+		select {
+		case raw := <-client.send:
+			messages = append(messages, decodeTestMessage(t, raw))
+		case <-timeout:
+			t.Fatalf("client %s received %d messages, want %d", client.address, len(messages), n)
+		}
+	}
+
+	select {
+	case raw := <-client.send:
+		t.Fatalf("client %s received unexpected extra message %s", client.address, raw)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	return messages
+}
+
+func decodeTestMessage(t *testing.T, raw []byte) Message {
+	t.Helper()
+
+	var msg Message
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		t.Fatalf("decode %s: %v", raw, err)
+	}
+	return msg
+}
+
+func TestBackplaneBroadcastReachesEveryHubOnce(t *testing.T) {
+	backplane := NewMemoryBackplane()
+	hubA := startHub(t, backplane, HubConfig{})
+	hubB := startHub(t, backplane, HubConfig{})
+	waitSubscribers(t, backplane, 2)
+
+	onA := addClient(t, hubA, "0xaaa", string(entity.EventMarketUpdate))
+	onB := addClient(t, hubB, "0xbbb", string(entity.EventMarketUpdate))
+	other := addClient(t, hubB, "0xccc", string(entity.EventTypeBlockMined))
+
+	hubA.BroadCast(entity.EventMarketUpdate, map[string]any{"price": 101.5})
+
+	for _, client := range []*ClientWS{onA, onB} {
+		msg := receive(t, client, 1)[0]
+		if msg.Type != entity.EventMarketUpdate || msg.Seq != 0 {
+			t.Fatalf("client %s got %+v", client.address, msg)
+		}
+	}
+
+	receive(t, other, 0)
+}
+
+func TestBackplaneAddressMessageOncePerConnection(t *testing.T) {
+	backplane := NewMemoryBackplane()
+	hubA := startHub(t, backplane, HubConfig{})
+	hubB := startHub(t, backplane, HubConfig{})
+	waitSubscribers(t, backplane, 2)
+
+	const address = "0xabc"
+	topic := "address:" + address + ":transactions"
+
+	// address yang sama terhubung ke dua instance sekaligus (mis. dua tab)
+	onA := addClient(t, hubA, address, topic)
+	onB := addClient(t, hubB, address, topic)
+	stranger := addClient(t, hubB, "0xdef", "address:0xdef:transactions")
+
+	// seq dibagi semua instance: pesan dari A lalu B tetap berurutan
+	hubA.SendToAddress(address, entity.EventTransactionUpdate, map[string]any{"tx": 1})
+	hubB.SendToAddress(address, entity.EventTransactionUpdate, map[string]any{"tx": 2})
+
+	for _, client := range []*ClientWS{onA, onB} {
+		messages := receive(t, client, 2)
+
+		seqs := map[uint64]bool{messages[0].Seq: true, messages[1].Seq: true}
+		if !seqs[1] || !seqs[2] {
+			t.Fatalf("client on hub got seqs %d, %d, want 1 and 2", messages[0].Seq, messages[1].Seq)
+		}
+	}
+
+	receive(t, stranger, 0)
+
+	// kedua instance menyimpan setiap pesan tepat sekali untuk resume
+	for name, hub := range map[string]*Hub{"A": hubA, "B": hubB} {
+		buffer, ok := hub.replays.Get(address)
+		if !ok {
+			t.Fatalf("hub %s has no replay buffer", name)
+		}
+
+		entries, latest, complete := buffer.since(0)
+		if len(entries) != 2 || latest != 2 || !complete {
+			t.Fatalf("hub %s replay = %d entries, latest %d, complete %t", name, len(entries), latest, complete)
+		}
+	}
+}
+
+// failingBackplane - publish selalu gagal, subscribe tetap jalan
+type failingBackplane struct {
+	Backplane
+}
+
+func (failingBackplane) Publish(context.Context, []byte) error {
+	return errors.New("backplane unavailable")
+}
+
+func TestBackplanePublishFailureDeliversLocally(t *testing.T) {
+	backplane := failingBackplane{Backplane: NewMemoryBackplane()}
+	hub := startHub(t, backplane, HubConfig{})
+
+	client := addClient(t, hub, "0xaaa", string(entity.EventMarketUpdate), "address:0xaaa:balance")
+
+	hub.BroadCast(entity.EventMarketUpdate, map[string]any{"price": 1})
+	if msg := receive(t, client, 1)[0]; msg.Type != entity.EventMarketUpdate {
+		t.Fatalf("broadcast fallback = %+v", msg)
+	}
+
+	hub.SendToAddress("0xAAA", entity.EventBalanceUpdate, map[string]any{"balance": 10})
+	if msg := receive(t, client, 1)[0]; msg.Type != entity.EventBalanceUpdate || msg.Seq != 1 {
+		t.Fatalf("address fallback = %+v", msg)
+	}
+}
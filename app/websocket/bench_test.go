@@ -0,0 +1,133 @@
+package websocket
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/livingdolls/go-blockchain-simulate/app/dto"
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+)
+
+// benchmarkMarketUpdate - bentuk sama dengan market.update dari market pricing consumer
+var benchmarkMarketUpdate = dto.PriceUpdate{
+	Pair:               "YTE-USD",
+	BlockID:            123456,
+	BlockNumber:        123456,
+	Price:              101.25,
+	PriceChange:        0.5,
+	PriceChangePercent: 0.496,
+	Liquidity:          250000,
+	BuyVolume:          1250.5,
+	SellVolume:         980.25,
+	TxCount:            42,
+	Timestamp:          1767225600,
+	MinerAddress:       "0x71c7656ec7ab88b098defb751b7401b5f6d8976f",
+}
+
+var benchmarkSubscribers = []int{1000, 5000, 10000}
+
+func TestMain(m *testing.M) {
+	// hub mencatat setiap broadcast lewat package log
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// newBenchmarkHub - hub dengan n client lokal (tanpa jaringan) yang subscribe market.update
+func newBenchmarkHub(b *testing.B, subprotocol string, rpc bool, n int) (*Hub, []*ClientWS) {
+	b.Helper()
+
+	enc, ok := subprotocols[subprotocol]
+	if !ok {
+		b.Fatalf("unsupported subprotocol %q", subprotocol)
+	}
+
+	hub := NewHub(nil, HubConfig{})
+	sub := &subscription{topic: string(entity.EventMarketUpdate), event: entity.EventMarketUpdate}
+
+	clients := make([]*ClientWS, n)
+	for i := range clients {
+		client := &ClientWS{
+			address:  fmt.Sprintf("0x%040x", i),
+			hub:      hub,
+			send:     make(chan []byte, 1),
+			encoding: enc,
+		}
+		client.rpc.Store(rpc)
+
+		hub.clients[client] = true
+		hub.subscriptions[client] = map[string]*subscription{sub.topic: sub}
+		clients[i] = client
+	}
+
+	return hub, clients
+}
+
+// benchmarkBroadcast - biaya filter subscription + encode + antrian ke send channel setiap client.
+// drain dilakukan di luar pengukuran, setara dengan goroutine Write
+func benchmarkBroadcast(b *testing.B, subprotocol string) {
+	payload, err := marshalMessage(entity.EventMarketUpdate, benchmarkMarketUpdate)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, rpc := range []bool{false, true} {
+		for _, n := range benchmarkSubscribers {
+			b.Run(fmt.Sprintf("rpc=%t/subscribers=%d", rpc, n), func(b *testing.B) {
+				hub, clients := newBenchmarkHub(b, subprotocol, rpc, n)
+
+				b.ReportAllocs()
+				b.ResetTimer()
+
+				for range b.N {
+					hub.broadcastMessageToSubscribers(entity.EventMarketUpdate, payload)
+
+					b.StopTimer()
+					for _, client := range clients {
+						<-client.send
+					}
+					b.StartTimer()
+				}
+
+				b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N)/float64(n), "ns/subscriber")
+			})
+		}
+	}
+}
+
+func BenchmarkBroadcastJSON(b *testing.B) {
+	benchmarkBroadcast(b, SubprotocolJSON)
+}
+
+func BenchmarkBroadcastMsgpack(b *testing.B) {
+	benchmarkBroadcast(b, SubprotocolMsgpack)
+}
+
+// BenchmarkBroadcastEncode - marshal JSON + encode ke format client, dibayar sekali per broadcast (bukan per subscriber)
+func BenchmarkBroadcastEncode(b *testing.B) {
+	for _, subprotocol := range []string{SubprotocolJSON, SubprotocolMsgpack} {
+		b.Run(subprotocol, func(b *testing.B) {
+			enc := subprotocols[subprotocol]
+
+			b.ReportAllocs()
+
+			var frameBytes int
+			for range b.N {
+				encoded, err := marshalMessage(entity.EventMarketUpdate, benchmarkMarketUpdate)
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				frame, err := transcode(enc, encoded)
+				if err != nil {
+					b.Fatal(err)
+				}
+				frameBytes = len(frame)
+			}
+
+			b.ReportMetric(float64(frameBytes), "frame-bytes")
+		})
+	}
+}
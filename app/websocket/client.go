@@ -3,6 +3,8 @@ package websocket
 import (
 	"encoding/json"
 	"log"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -10,17 +12,37 @@ import (
 )
 
 const (
-	writeWait      = 10 * time.Second
-	pongWait       = 60 * time.Second
-	pingPeriod     = (pongWait * 9) / 10
-	maxMessageSize = 512
+	writeWait  = 10 * time.Second
+	pongWait   = 60 * time.Second
+	pingPeriod = (pongWait * 9) / 10
+	// maxMessageSize - cukup untuk batch JSON-RPC (maks 20 request sendTransaction)
+	maxMessageSize = 32 * 1024
 )
 
+type pendingAck struct {
+	payload  []byte
+	sentAt   time.Time
+	attempts int
+}
+
 type ClientWS struct {
 	address string
 	conn    *websocket.Conn
 	send    chan []byte
 	hub     *Hub
+	// encoding - hasil negosiasi subprotocol, semua pesan di send sudah dalam format ini
+	encoding encoding
+
+	// mu menjaga pendingAcks dan counter drop
+	mu          sync.Mutex
+	pendingAcks map[uint64]*pendingAck
+	dropStart   time.Time
+	drops       int
+	closing     atomic.Bool
+
+	// rpc true setelah client mengirim request JSON-RPC, lihat handleRPC
+	rpc      atomic.Bool
+	rpcSlots chan struct{}
 }
 
 func (c *ClientWS) Read() {
@@ -37,7 +59,7 @@ func (c *ClientWS) Read() {
 	})
 
 	for {
-		_, message, err := c.conn.ReadMessage()
+		frameType, message, err := c.conn.ReadMessage()
 
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
@@ -46,6 +68,15 @@ func (c *ClientWS) Read() {
 			break
 		}
 
+		message, err = decodeInbound(c.encoding, frameType, message)
+		if err != nil {
+			log.Printf("WebSocket decode inbound error user=%s: %v", c.address, err)
+			if c.rpc.Load() {
+				c.sendRPC(errorResponse(nil, NewRPCError(RPCParseError, "parse error")))
+			}
+			continue
+		}
+
 		// Here you can handle incoming messages from the client if needed
 		log.Printf("Received message from client: %s", message)
 		c.handleMessage(message)
@@ -71,7 +102,7 @@ func (c *ClientWS) Write() {
 			}
 
 			// Write the message
-			if err := c.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+			if err := c.conn.WriteMessage(c.encoding.frameType(), message); err != nil {
 				log.Printf("WebSocket write error: %v", err)
 				return
 			}
@@ -86,6 +117,11 @@ func (c *ClientWS) Write() {
 }
 
 func (c *ClientWS) handleMessage(data []byte) {
+	if c.rpc.Load() || isRPCMessage(data) {
+		c.handleRPC(data)
+		return
+	}
+
 	var msg Message
 
 	if err := json.Unmarshal(data, &msg); err != nil {
@@ -96,34 +132,159 @@ func (c *ClientWS) handleMessage(data []byte) {
 	switch msg.Type {
 	case entity.EventTypeSubscribe:
 		var subReq SubscribeRequest
-		if dataBytes, err := json.Marshal(msg.Data); err == nil {
-			if err := json.Unmarshal(dataBytes, &subReq); err == nil {
-				for _, eventType := range subReq.Events {
-					c.hub.Subscribe(c, eventType)
-				}
+		if err := decodeMessageData(msg.Data, &subReq); err != nil {
+			log.Printf("WebSocket handleMessage subscribe unmarshal error: %v", err)
+			return
+		}
 
-				// send confirmation
-				c.sendResponse(entity.EventTypeSubscribe, SubscribeResponse{
-					Success: true,
-					Events:  subReq.Events,
-				})
-			} else {
-				log.Printf("WebSocket handleMessage subscribe unmarshal error: %v", err)
-			}
-		} else {
-			log.Printf("WebSocket handleMessage subscribe marshal error: %v", err)
+		response, events := c.subscribe(subReq.Events)
+
+		// send confirmation
+		c.sendResponse(entity.EventTypeSubscribe, response)
+
+		// snapshot setelah konfirmasi, delta berikutnya sudah terkirim ke client ini
+		for _, event := range events {
+			c.hub.sendSnapshot(c, event)
 		}
 
 	case entity.EventTypeUnsubscribe:
 		var subReq SubscribeRequest
 		if dataBytes, err := json.Marshal(msg.Data); err == nil {
 			if err := json.Unmarshal(dataBytes, &subReq); err == nil {
-				for _, eventType := range subReq.Events {
-					c.hub.Unsubscribe(c, eventType)
+				for _, topic := range subReq.Events {
+					c.hub.Unsubscribe(c, topic)
 				}
 			}
 		}
+
+	case entity.EventTypeResume:
+		var req ResumeRequest
+		if err := decodeMessageData(msg.Data, &req); err != nil {
+			log.Printf("WebSocket handleMessage resume unmarshal error: %v", err)
+			return
+		}
+
+		c.sendResponse(entity.EventTypeResume, c.hub.resume(c, req.LastSeq))
+
+	case entity.EventTypeAck:
+		var req AckRequest
+		if err := decodeMessageData(msg.Data, &req); err != nil {
+			log.Printf("WebSocket handleMessage ack unmarshal error: %v", err)
+			return
+		}
+
+		if req.Seq > 0 {
+			req.Seqs = append(req.Seqs, req.Seq)
+		}
+		c.ack(req.Seqs)
+	}
+}
+
+// subscribe - topic yang ditolak masuk Rejected, event dikembalikan untuk pengiriman snapshot
+func (c *ClientWS) subscribe(topics []string) (SubscribeResponse, []entity.MessageType) {
+	response := SubscribeResponse{Events: []string{}}
+	var events []entity.MessageType
+
+	for _, topic := range topics {
+		event, err := c.hub.Subscribe(c, topic)
+		if err != nil {
+			response.Rejected = append(response.Rejected, TopicError{Topic: topic, Error: err.Error()})
+			continue
+		}
+
+		response.Events = append(response.Events, topic)
+		events = append(events, event)
+	}
+	response.Success = len(response.Rejected) == 0
+
+	return response, events
+}
+
+func decodeMessageData(data any, v any) error {
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(dataBytes, v)
+}
+
+// trackAck - pesan dikirim ulang oleh hub sampai client mengirim ack
+func (c *ClientWS) trackAck(seq uint64, payload []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.pendingAcks == nil {
+		c.pendingAcks = make(map[uint64]*pendingAck)
+	}
+
+	if _, ok := c.pendingAcks[seq]; ok {
+		return
+	}
+
+	c.pendingAcks[seq] = &pendingAck{payload: payload, sentAt: time.Now(), attempts: 1}
+}
+
+func (c *ClientWS) ack(seqs []uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, seq := range seqs {
+		delete(c.pendingAcks, seq)
+	}
+}
+
+// dueAcks - pesan yang belum di-ack setelah timeout; yang sudah mencapai maxAttempts dibuang
+func (c *ClientWS) dueAcks(now time.Time, timeout time.Duration, maxAttempts int) ([][]byte, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var resend [][]byte
+	expired := 0
+
+	for seq, pending := range c.pendingAcks {
+		if now.Sub(pending.sentAt) < timeout {
+			continue
+		}
+
+		if pending.attempts >= maxAttempts {
+			delete(c.pendingAcks, seq)
+			expired++
+			continue
+		}
+
+		pending.attempts++
+		pending.sentAt = now
+		resend = append(resend, pending.payload)
+	}
+
+	return resend, expired
+}
+
+// recordDrop - jumlah pesan yang gagal masuk send channel dalam window saat ini
+func (c *ClientWS) recordDrop(now time.Time, window time.Duration) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if now.Sub(c.dropStart) > window {
+		c.dropStart = now
+		c.drops = 0
 	}
+
+	c.drops++
+	return c.drops
+}
+
+func (c *ClientWS) stats(now time.Time, window time.Duration) (int, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	drops := c.drops
+	if now.Sub(c.dropStart) > window {
+		drops = 0
+	}
+
+	return drops, len(c.pendingAcks)
 }
 
 func (c *ClientWS) sendResponse(msgType entity.MessageType, data any) {
@@ -132,11 +293,15 @@ func (c *ClientWS) sendResponse(msgType entity.MessageType, data any) {
 		Data: data,
 	}
 
-	if payload, err := json.Marshal(response); err == nil {
-		select {
-		case c.send <- payload:
-		default:
-			log.Println("WebSocket client send channel full, dropping response")
-		}
+	payload, err := json.Marshal(response)
+	if err != nil {
+		return
 	}
+
+	if payload, err = transcode(c.encoding, payload); err != nil {
+		log.Printf("WebSocket encode response error user=%s: %v", c.address, err)
+		return
+	}
+
+	c.hub.sendDirect(c, c.frame(payload))
 }
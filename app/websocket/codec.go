@@ -0,0 +1,103 @@
+package websocket
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+
+	"github.com/gorilla/websocket"
+	"github.com/ugorji/go/codec"
+)
+
+// encoding - format frame per koneksi, dipilih lewat Sec-WebSocket-Protocol saat upgrade
+type encoding uint8
+
+const (
+	encodingJSON encoding = iota
+	encodingMsgpack
+)
+
+const (
+	SubprotocolJSON    = "json"
+	SubprotocolMsgpack = "msgpack"
+)
+
+var subprotocols = map[string]encoding{
+	SubprotocolJSON:    encodingJSON,
+	SubprotocolMsgpack: encodingMsgpack,
+}
+
+var (
+	jsonHandle    = newJSONHandle()
+	msgpackHandle = newMsgpackHandle()
+)
+
+// rpcPushPrefixMsgpack - sama dengan rpcPushPrefix dalam msgpack: fixmap 3 field, value params ditempel per pesan
+var rpcPushPrefixMsgpack = []byte("\x83\xa7jsonrpc\xa32.0\xa6method\xacsubscription\xa6params")
+
+func newJSONHandle() *codec.JsonHandle {
+	h := &codec.JsonHandle{}
+	h.MapType = reflect.TypeOf(map[string]any(nil))
+	return h
+}
+
+// newMsgpackHandle - WriteExt: str8/bin sesuai spec msgpack baru, RawToString: str dari client di-decode sebagai string
+func newMsgpackHandle() *codec.MsgpackHandle {
+	h := &codec.MsgpackHandle{}
+	h.WriteExt = true
+	h.RawToString = true
+	h.MapType = reflect.TypeOf(map[string]any(nil))
+	return h
+}
+
+// negotiateSubprotocol - pilihan pertama client yang didukung, tanpa header = json
+func negotiateSubprotocol(r *http.Request) (string, encoding) {
+	for _, protocol := range websocket.Subprotocols(r) {
+		if enc, ok := subprotocols[protocol]; ok {
+			return protocol, enc
+		}
+	}
+
+	return "", encodingJSON
+}
+
+func (e encoding) frameType() int {
+	if e == encodingMsgpack {
+		return websocket.BinaryMessage
+	}
+
+	return websocket.TextMessage
+}
+
+// transcode - payload JSON hub diubah ke format client. JSON dikembalikan apa adanya
+func transcode(enc encoding, payload []byte) ([]byte, error) {
+	if enc == encodingJSON {
+		return payload, nil
+	}
+
+	var v any
+	if err := codec.NewDecoderBytes(payload, jsonHandle).Decode(&v); err != nil {
+		return nil, err
+	}
+
+	var out []byte
+	if err := codec.NewEncoderBytes(&out, msgpackHandle).Encode(v); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// decodeInbound - frame binary msgpack dari client diubah ke JSON agar handleMessage tetap satu jalur
+func decodeInbound(enc encoding, frameType int, data []byte) ([]byte, error) {
+	if enc == encodingJSON || frameType == websocket.TextMessage {
+		return data, nil
+	}
+
+	var v any
+	if err := codec.NewDecoderBytes(data, msgpackHandle).Decode(&v); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(v)
+}
@@ -16,6 +16,8 @@ var upgrader = websocket.Upgrader{
 	CheckOrigin: func(r *http.Request) bool {
 		return true
 	},
+	// permessage-deflate jika client mendukung
+	EnableCompression: true,
 }
 
 func GinHandler(hub *Hub, jwt security.JWTService) gin.HandlerFunc {
@@ -37,7 +39,15 @@ func GinHandler(hub *Hub, jwt security.JWTService) gin.HandlerFunc {
 
 		log.Printf("WebSocket connection established for user: %s", claims.Address)
 
-		conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+		// subprotocol json (default) atau msgpack
+		protocol, enc := negotiateSubprotocol(c.Request)
+
+		var header http.Header
+		if protocol != "" {
+			header = http.Header{"Sec-Websocket-Protocol": {protocol}}
+		}
+
+		conn, err := upgrader.Upgrade(c.Writer, c.Request, header)
 
 		if err != nil {
 			log.Printf("WebSocket upgrade error: %v", err)
@@ -45,10 +55,12 @@ func GinHandler(hub *Hub, jwt security.JWTService) gin.HandlerFunc {
 		}
 
 		client := &ClientWS{
-			address: strings.ToLower(claims.Address),
-			hub:     hub,
-			conn:    conn,
-			send:    make(chan []byte, 256),
+			address:  strings.ToLower(claims.Address),
+			hub:      hub,
+			conn:     conn,
+			send:     make(chan []byte, 256),
+			encoding: enc,
+			rpcSlots: make(chan struct{}, maxRPCInFlight),
 		}
 
 		hub.register <- client
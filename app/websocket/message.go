@@ -2,16 +2,46 @@ package websocket
 
 import "github.com/livingdolls/go-blockchain-simulate/app/entity"
 
+// Message - Seq hanya ada pada pesan per address, Ack true berarti client harus membalas dengan pesan ack
 type Message struct {
 	Type entity.MessageType `json:"type"`
 	Data any                `json:"data,omitempty"`
+	Seq  uint64             `json:"seq,omitempty"`
+	Ack  bool               `json:"ack,omitempty"`
 }
 
+// SubscribeRequest - events berisi nama event atau topic dengan filter (mis. candles:5m)
 type SubscribeRequest struct {
-	Events []entity.MessageType `json:"events"`
+	Events []string `json:"events"`
 }
 
+// SubscribeResponse - Events berisi topic yang diterima, Success false jika ada topic yang ditolak
 type SubscribeResponse struct {
-	Success bool                 `json:"success"`
-	Events  []entity.MessageType `json:"events"`
+	Success  bool         `json:"success"`
+	Events   []string     `json:"events"`
+	Rejected []TopicError `json:"rejected,omitempty"`
+}
+
+type TopicError struct {
+	Topic string `json:"topic"`
+	Error string `json:"error"`
+}
+
+// ResumeRequest - seq terakhir yang diterima client sebelum reconnect
+type ResumeRequest struct {
+	LastSeq uint64 `json:"last_seq"`
+}
+
+// ResumeResponse - complete false berarti sebagian pesan sudah keluar dari replay buffer,
+// client perlu mengambil ulang state lewat REST
+type ResumeResponse struct {
+	LastSeq   uint64 `json:"last_seq"`
+	LatestSeq uint64 `json:"latest_seq"`
+	Replayed  int    `json:"replayed"`
+	Complete  bool   `json:"complete"`
+}
+
+type AckRequest struct {
+	Seq  uint64   `json:"seq,omitempty"`
+	Seqs []uint64 `json:"seqs,omitempty"`
 }
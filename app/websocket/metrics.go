@@ -0,0 +1,75 @@
+package websocket
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// HubMetrics - counter sejak hub dibuat, gauge dihitung saat Metrics dipanggil
+type HubMetrics struct {
+	Clients          int          `json:"clients"`
+	PendingAcks      int          `json:"pending_acks"`
+	Sent             int64        `json:"sent"`
+	Dropped          int64        `json:"dropped"`
+	BroadcastDropped int64        `json:"broadcast_dropped"`
+	Replayed         int64        `json:"replayed"`
+	AckRetries       int64        `json:"ack_retries"`
+	AckExpired       int64        `json:"ack_expired"`
+	SlowDisconnects  int64        `json:"slow_disconnects"`
+	SlowClients      []SlowClient `json:"slow_clients"`
+}
+
+// SlowClient - client yang kehilangan pesan dalam window SlowClientWindow saat ini
+type SlowClient struct {
+	Address     string `json:"address"`
+	Drops       int    `json:"drops"`
+	QueueLen    int    `json:"queue_len"`
+	PendingAcks int    `json:"pending_acks"`
+}
+
+type hubCounters struct {
+	sent             atomic.Int64
+	dropped          atomic.Int64
+	broadcastDropped atomic.Int64
+	replayed         atomic.Int64
+	ackRetries       atomic.Int64
+	ackExpired       atomic.Int64
+	slowDisconnects  atomic.Int64
+}
+
+// Metrics - dipakai endpoint admin untuk memantau consumer yang lambat
+func (h *Hub) Metrics() HubMetrics {
+	metrics := HubMetrics{
+		Sent:             h.counters.sent.Load(),
+		Dropped:          h.counters.dropped.Load(),
+		BroadcastDropped: h.counters.broadcastDropped.Load(),
+		Replayed:         h.counters.replayed.Load(),
+		AckRetries:       h.counters.ackRetries.Load(),
+		AckExpired:       h.counters.ackExpired.Load(),
+		SlowDisconnects:  h.counters.slowDisconnects.Load(),
+		SlowClients:      []SlowClient{},
+	}
+
+	now := time.Now()
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	metrics.Clients = len(h.clients)
+
+	for client := range h.clients {
+		drops, pending := client.stats(now, h.cfg.SlowClientWindow)
+		metrics.PendingAcks += pending
+
+		if drops > 0 {
+			metrics.SlowClients = append(metrics.SlowClients, SlowClient{
+				Address:     client.address,
+				Drops:       drops,
+				QueueLen:    len(client.send),
+				PendingAcks: pending,
+			})
+		}
+	}
+
+	return metrics
+}
@@ -0,0 +1,71 @@
+package websocket
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+)
+
+type replayEntry struct {
+	seq     uint64
+	msgType entity.MessageType
+	ack     bool
+	payload []byte
+}
+
+// replayBuffer - pesan terakhir satu address, urut berdasarkan seq dan dibatasi size
+type replayBuffer struct {
+	mu      sync.Mutex
+	size    int
+	entries []replayEntry
+}
+
+func newReplayBuffer(size int) *replayBuffer {
+	return &replayBuffer{size: size}
+}
+
+// add - pesan dari backplane bisa datang sedikit tidak urut, disisipkan sesuai seq
+func (b *replayBuffer) add(entry replayEntry) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	i := sort.Search(len(b.entries), func(i int) bool { return b.entries[i].seq >= entry.seq })
+	if i < len(b.entries) && b.entries[i].seq == entry.seq {
+		return
+	}
+
+	b.entries = append(b.entries, replayEntry{})
+	copy(b.entries[i+1:], b.entries[i:])
+	b.entries[i] = entry
+
+	if len(b.entries) > b.size {
+		b.entries = b.entries[len(b.entries)-b.size:]
+	}
+}
+
+// since - pesan dengan seq > lastSeq. complete false jika ada seq yang hilang setelah lastSeq,
+// baik karena sudah keluar dari buffer maupun tidak pernah sampai ke instance ini
+func (b *replayBuffer) since(lastSeq uint64) ([]replayEntry, uint64, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.entries) == 0 {
+		return nil, lastSeq, true
+	}
+
+	latest := b.entries[len(b.entries)-1].seq
+
+	i := sort.Search(len(b.entries), func(i int) bool { return b.entries[i].seq > lastSeq })
+	entries := append([]replayEntry(nil), b.entries[i:]...)
+
+	complete := true
+	for n, entry := range entries {
+		if entry.seq != lastSeq+1+uint64(n) {
+			complete = false
+			break
+		}
+	}
+
+	return entries, latest, complete
+}
@@ -0,0 +1,147 @@
+package websocket
+
+import (
+	"testing"
+	"time"
+
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+)
+
+func replaySeqs(entries []replayEntry) []uint64 {
+	seqs := make([]uint64, len(entries))
+	for i, entry := range entries {
+		seqs[i] = entry.seq
+	}
+	return seqs
+}
+
+func equalSeqs(got, want []uint64) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestReplayBufferSince(t *testing.T) {
+	tests := []struct {
+		name         string
+		size         int
+		added        []uint64
+		lastSeq      uint64
+		want         []uint64
+		wantLatest   uint64
+		wantComplete bool
+	}{
+		{name: "empty buffer", size: 4, lastSeq: 3, wantLatest: 3, wantComplete: true},
+		{name: "contiguous", size: 4, added: []uint64{1, 2, 3}, lastSeq: 1, want: []uint64{2, 3}, wantLatest: 3, wantComplete: true},
+		{name: "up to date", size: 4, added: []uint64{1, 2, 3}, lastSeq: 3, wantLatest: 3, wantComplete: true},
+		// backplane tidak menjamin urutan, duplikat redelivery diabaikan
+		{name: "out of order and duplicate", size: 4, added: []uint64{2, 1, 3, 2}, lastSeq: 0, want: []uint64{1, 2, 3}, wantLatest: 3, wantComplete: true},
+		{name: "evicted", size: 3, added: []uint64{1, 2, 3, 4, 5}, lastSeq: 1, want: []uint64{3, 4, 5}, wantLatest: 5, wantComplete: false},
+		{name: "evicted before last seq", size: 3, added: []uint64{1, 2, 3, 4, 5}, lastSeq: 2, want: []uint64{3, 4, 5}, wantLatest: 5, wantComplete: true},
+		{name: "hole in the middle", size: 8, added: []uint64{1, 2, 4, 5}, lastSeq: 1, want: []uint64{2, 4, 5}, wantLatest: 5, wantComplete: false},
+		{name: "hole before last seq", size: 8, added: []uint64{1, 3, 4}, lastSeq: 3, want: []uint64{4}, wantLatest: 4, wantComplete: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buffer := newReplayBuffer(tt.size)
+			for _, seq := range tt.added {
+				buffer.add(replayEntry{seq: seq, msgType: entity.EventTransactionUpdate})
+			}
+
+			entries, latest, complete := buffer.since(tt.lastSeq)
+			if got := replaySeqs(entries); !equalSeqs(got, tt.want) || latest != tt.wantLatest || complete != tt.wantComplete {
+				t.Fatalf("since(%d) = %v, latest %d, complete %t; want %v, latest %d, complete %t",
+					tt.lastSeq, got, latest, complete, tt.want, tt.wantLatest, tt.wantComplete)
+			}
+		})
+	}
+}
+
+func TestResumeOnAnotherInstance(t *testing.T) {
+	backplane := NewMemoryBackplane()
+	hubA := startHub(t, backplane, HubConfig{})
+	hubB := startHub(t, backplane, HubConfig{})
+	waitSubscribers(t, backplane, 2)
+
+	const address = "0xabc"
+	transactions := "address:" + address + ":transactions"
+
+	// client terputus dari A setelah menerima seq 1, pesan berikutnya dikirim selama offline
+	hubA.SendToAddress(address, entity.EventTransactionUpdate, map[string]any{"tx": 1})
+	hubA.SendToAddressWithAck(address, entity.EventTransactionUpdate, map[string]any{"tx": 2})
+	hubA.SendToAddress(address, entity.EventBalanceUpdate, map[string]any{"balance": 5})
+	hubA.SendToAddress(address, entity.EventTransactionUpdate, map[string]any{"tx": 3})
+
+	// reconnect ke B, hanya subscribe transactions
+	client := addClient(t, hubB, address, transactions)
+
+	// pesan backplane diproses async, tunggu sampai B melihat seq terakhir
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if buffer, ok := hubB.replays.Get(address); ok {
+			if _, latest, _ := buffer.since(0); latest == 4 {
+				break
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("hub B did not buffer all messages")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	response := hubB.resume(client, 1)
+	if response.LatestSeq != 4 || response.Replayed != 2 || !response.Complete {
+		t.Fatalf("resume = %+v, want latest 4, replayed 2, complete", response)
+	}
+
+	messages := receive(t, client, 2)
+	if messages[0].Seq != 2 || messages[1].Seq != 4 || !messages[0].Ack {
+		t.Fatalf("replayed seqs = %d (ack %t), %d; want 2 (ack), 4", messages[0].Seq, messages[0].Ack, messages[1].Seq)
+	}
+
+	// pesan ack yang di-replay ditunggu ack-nya lagi di instance baru
+	client.mu.Lock()
+	_, pending := client.pendingAcks[2]
+	client.mu.Unlock()
+	if !pending {
+		t.Fatal("replayed ack message is not tracked")
+	}
+}
+
+func TestResumeReportsGap(t *testing.T) {
+	hub := startHub(t, nil, HubConfig{ReplayBufferSize: 2})
+
+	const address = "0xabc"
+	client := addClient(t, hub, address, "address:"+address+":transactions")
+
+	// instance baru tanpa buffer: hanya lengkap jika client belum pernah menerima pesan
+	if response := hub.resume(client, 0); !response.Complete {
+		t.Fatalf("resume(0) without buffer = %+v", response)
+	}
+	if response := hub.resume(client, 3); response.Complete || response.LatestSeq != 3 {
+		t.Fatalf("resume(3) without buffer = %+v", response)
+	}
+
+	for i := 1; i <= 4; i++ {
+		hub.SendToAddress(address, entity.EventTransactionUpdate, map[string]any{"tx": i})
+	}
+	receive(t, client, 4)
+
+	// seq 2 sudah keluar dari buffer berukuran 2
+	response := hub.resume(client, 1)
+	if response.Complete || response.LatestSeq != 4 || response.Replayed != 2 {
+		t.Fatalf("resume(1) = %+v, want incomplete with 2 replayed", response)
+	}
+
+	messages := receive(t, client, 2)
+	if messages[0].Seq != 3 || messages[1].Seq != 4 {
+		t.Fatalf("replayed seqs = %d, %d", messages[0].Seq, messages[1].Seq)
+	}
+}
@@ -0,0 +1,317 @@
+package websocket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+)
+
+// kode error JSON-RPC 2.0, -32000 s/d -32099 untuk error aplikasi
+const (
+	RPCParseError     = -32700
+	RPCInvalidRequest = -32600
+	RPCMethodNotFound = -32601
+	RPCInvalidParams  = -32602
+	RPCInternalError  = -32603
+	RPCServerError    = -32000
+)
+
+const (
+	rpcVersion = "2.0"
+	rpcTimeout = 10 * time.Second
+	// maxRPCInFlight - request RPC yang diproses bersamaan per koneksi, Read menunggu jika penuh
+	maxRPCInFlight = 4
+	maxRPCBatch    = 20
+)
+
+// rpcPushPrefix - push ke koneksi mode JSON-RPC dibungkus notification method "subscription"
+var rpcPushPrefix = []byte(`{"jsonrpc":"2.0","method":"subscription","params":`)
+
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	return e.Message
+}
+
+func NewRPCError(code int, message string) *RPCError {
+	return &RPCError{Code: code, Message: message}
+}
+
+// RPCHandler - method yang memakai service (getBalance, sendTransaction, ...), diimplementasikan di luar
+// package websocket. address adalah address JWT koneksi (lowercase)
+type RPCHandler interface {
+	HandleRPC(ctx context.Context, address, method string, params json.RawMessage) (any, error)
+}
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// rpcResponse - ID nil di-marshal sebagai null (request tidak bisa di-parse)
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+// DecodeRPCParams - params kosong dibiarkan (v tetap nilai default), error dipetakan ke invalid params
+func DecodeRPCParams(params json.RawMessage, v any) error {
+	if len(params) == 0 || string(params) == "null" {
+		return nil
+	}
+
+	if err := json.Unmarshal(params, v); err != nil {
+		return &RPCError{Code: RPCInvalidParams, Message: "invalid params", Data: err.Error()}
+	}
+
+	return nil
+}
+
+// isRPCMessage - batch (array) atau object dengan field jsonrpc
+func isRPCMessage(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		return true
+	}
+
+	var probe struct {
+		JSONRPC string `json:"jsonrpc"`
+	}
+	return json.Unmarshal(data, &probe) == nil && probe.JSONRPC != ""
+}
+
+// handleRPC - setelah request JSON-RPC pertama koneksi masuk mode RPC: semua pesan client harus JSON-RPC
+// dan push event dikirim sebagai notification "subscription"
+func (c *ClientWS) handleRPC(data []byte) {
+	c.rpc.Store(true)
+	data = bytes.TrimSpace(data)
+
+	if !json.Valid(data) {
+		c.sendRPC(errorResponse(nil, NewRPCError(RPCParseError, "parse error")))
+		return
+	}
+
+	batch := data[0] == '['
+	raws := []json.RawMessage{data}
+	if batch {
+		if err := json.Unmarshal(data, &raws); err != nil || len(raws) == 0 {
+			c.sendRPC(errorResponse(nil, NewRPCError(RPCInvalidRequest, "invalid request")))
+			return
+		}
+
+		if len(raws) > maxRPCBatch {
+			c.sendRPC(errorResponse(nil, NewRPCError(RPCInvalidRequest, "batch too large")))
+			return
+		}
+	}
+
+	// service bisa lambat (DB, RabbitMQ), Read tidak boleh tertahan kecuali slot penuh
+	c.rpcSlots <- struct{}{}
+
+	go func() {
+		defer func() { <-c.rpcSlots }()
+
+		var snapshots []entity.MessageType
+		responses := make([]rpcResponse, 0, len(raws))
+
+		for _, raw := range raws {
+			if response, ok := c.callRPC(raw, &snapshots); ok {
+				responses = append(responses, response)
+			}
+		}
+
+		// batch berisi notification saja tidak dibalas
+		switch {
+		case len(responses) == 0:
+		case batch:
+			c.sendRPC(responses)
+		default:
+			c.sendRPC(responses[0])
+		}
+
+		// snapshot setelah balasan subscribe, sama seperti protokol lama
+		for _, event := range snapshots {
+			c.hub.sendSnapshot(c, event)
+		}
+	}()
+}
+
+// callRPC - false jika request adalah notification (tanpa id)
+func (c *ClientWS) callRPC(raw json.RawMessage, snapshots *[]entity.MessageType) (rpcResponse, bool) {
+	var req rpcRequest
+	if err := json.Unmarshal(raw, &req); err != nil || req.JSONRPC != rpcVersion || req.Method == "" {
+		return errorResponse(req.ID, NewRPCError(RPCInvalidRequest, "invalid request")), true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), rpcTimeout)
+	defer cancel()
+
+	result, err := c.dispatchRPC(ctx, req, snapshots)
+	if req.ID == nil {
+		return rpcResponse{}, false
+	}
+
+	if err != nil {
+		return errorResponse(req.ID, toRPCError(err)), true
+	}
+
+	payload, err := json.Marshal(result)
+	if err != nil {
+		log.Printf("WebSocket RPC marshal result error method=%s: %v", req.Method, err)
+		return errorResponse(req.ID, NewRPCError(RPCInternalError, "internal error")), true
+	}
+
+	return rpcResponse{JSONRPC: rpcVersion, ID: req.ID, Result: payload}, true
+}
+
+// dispatchRPC - subscribe/unsubscribe/ack/resume ditangani hub, method lain diteruskan ke RPCHandler
+func (c *ClientWS) dispatchRPC(ctx context.Context, req rpcRequest, snapshots *[]entity.MessageType) (any, error) {
+	switch req.Method {
+	case "subscribe":
+		topics, err := decodeTopics(req.Params)
+		if err != nil {
+			return nil, err
+		}
+
+		response, events := c.subscribe(topics)
+		*snapshots = append(*snapshots, events...)
+		return response, nil
+
+	case "unsubscribe":
+		topics, err := decodeTopics(req.Params)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, topic := range topics {
+			c.hub.Unsubscribe(c, topic)
+		}
+		return SubscribeResponse{Success: true, Events: topics}, nil
+
+	case "ack":
+		var params AckRequest
+		if err := DecodeRPCParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+
+		if params.Seq > 0 {
+			params.Seqs = append(params.Seqs, params.Seq)
+		}
+		c.ack(params.Seqs)
+		return true, nil
+
+	case "resume":
+		var params ResumeRequest
+		if err := DecodeRPCParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+
+		return c.hub.resume(c, params.LastSeq), nil
+	}
+
+	handler := c.hub.rpcHandler()
+	if handler == nil {
+		return nil, NewRPCError(RPCMethodNotFound, "method not found")
+	}
+
+	return handler.HandleRPC(ctx, c.address, req.Method, req.Params)
+}
+
+// decodeTopics - params berupa array topic atau {"events": [...]}
+func decodeTopics(params json.RawMessage) ([]string, error) {
+	var topics []string
+	if err := json.Unmarshal(params, &topics); err == nil {
+		return topics, nil
+	}
+
+	var req SubscribeRequest
+	if err := DecodeRPCParams(params, &req); err != nil {
+		return nil, err
+	}
+
+	if len(req.Events) == 0 {
+		return nil, NewRPCError(RPCInvalidParams, "events is required")
+	}
+
+	return req.Events, nil
+}
+
+func toRPCError(err error) *RPCError {
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		return rpcErr
+	}
+
+	return NewRPCError(RPCServerError, err.Error())
+}
+
+func errorResponse(id json.RawMessage, err *RPCError) rpcResponse {
+	return rpcResponse{JSONRPC: rpcVersion, ID: id, Error: err}
+}
+
+// sendRPC - balasan JSON-RPC tidak dibungkus frame push
+func (c *ClientWS) sendRPC(response any) {
+	payload, err := json.Marshal(response)
+	if err != nil {
+		log.Printf("WebSocket RPC marshal response error: %v", err)
+		return
+	}
+
+	if payload, err = transcode(c.encoding, payload); err != nil {
+		log.Printf("WebSocket RPC encode response error: %v", err)
+		return
+	}
+
+	c.hub.sendDirect(c, payload)
+}
+
+// frame - push event untuk koneksi mode JSON-RPC dibungkus notification. payload sudah dalam format client
+func (c *ClientWS) frame(payload []byte) []byte {
+	if !c.rpc.Load() {
+		return payload
+	}
+
+	return wrapRPCPush(c.encoding, payload)
+}
+
+// wrapRPCPush - payload asli tidak diubah karena dipakai bersama oleh client lain
+func wrapRPCPush(enc encoding, payload []byte) []byte {
+	if enc == encodingMsgpack {
+		framed := make([]byte, 0, len(rpcPushPrefixMsgpack)+len(payload))
+		framed = append(framed, rpcPushPrefixMsgpack...)
+		return append(framed, payload...)
+	}
+
+	framed := make([]byte, 0, len(rpcPushPrefix)+len(payload)+1)
+	framed = append(framed, rpcPushPrefix...)
+	framed = append(framed, payload...)
+	return append(framed, '}')
+}
+
+// SetRPCHandler - dipasang setelah service dibuat, sebelumnya hanya method hub yang tersedia
+func (h *Hub) SetRPCHandler(handler RPCHandler) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.rpc = handler
+}
+
+func (h *Hub) rpcHandler() RPCHandler {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.rpc
+}
@@ -0,0 +1,212 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+)
+
+// recordingRPC - RPCHandler test, mencatat method yang diteruskan oleh hub
+type recordingRPC struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *recordingRPC) HandleRPC(ctx context.Context, address, method string, params json.RawMessage) (any, error) {
+	r.mu.Lock()
+	r.calls = append(r.calls, address+" "+method)
+	r.mu.Unlock()
+
+	switch method {
+	case "getBalance":
+		var p struct {
+			Address string `json:"address"`
+		}
+		if err := DecodeRPCParams(params, &p); err != nil {
+			return nil, err
+		}
+		return map[string]any{"address": p.Address, "balance": 10}, nil
+	case "sendTransaction":
+		return nil, &RPCError{Code: -32001, Message: "trading halted"}
+	case "getBlock":
+		return nil, errors.New("block not found")
+	}
+
+	return nil, NewRPCError(RPCMethodNotFound, "method not found")
+}
+
+func (r *recordingRPC) recorded() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.calls...)
+}
+
+type testRPCResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result"`
+	Error   *RPCError       `json:"error"`
+}
+
+// rpcCall - kirim satu frame dari client dan ambil balasannya (frame mentah)
+func rpcCall(t *testing.T, client *ClientWS, frame string) []byte {
+	t.Helper()
+
+	client.handleMessage([]byte(frame))
+
+	select {
+	case raw := <-client.send:
+		return raw
+	case <-time.After(2 * time.Second):
+		t.Fatalf("no response to %s", frame)
+		return nil
+	}
+}
+
+func rpcResult(t *testing.T, client *ClientWS, frame string) testRPCResponse {
+	t.Helper()
+
+	var response testRPCResponse
+	if raw := rpcCall(t, client, frame); json.Unmarshal(raw, &response) != nil {
+		t.Fatalf("response is not a JSON-RPC object: %s", raw)
+	}
+	return response
+}
+
+func assertRPCError(t *testing.T, response testRPCResponse, id string, code int) {
+	t.Helper()
+
+	if response.JSONRPC != rpcVersion || string(response.ID) != id || response.Error == nil || response.Error.Code != code {
+		t.Fatalf("response = %+v (error %+v), want id %s with error code %d", response, response.Error, id, code)
+	}
+}
+
+func TestRPCSubscribeWrapsPushAsNotification(t *testing.T) {
+	hub := startHub(t, nil, HubConfig{})
+	client := addClient(t, hub, "0xaaa")
+
+	response := rpcResult(t, client, `{"jsonrpc":"2.0","id":1,"method":"subscribe","params":["market.update","address:0xbbb:balance"]}`)
+
+	var result SubscribeResponse
+	if err := json.Unmarshal(response.Result, &result); err != nil || string(response.ID) != "1" {
+		t.Fatalf("subscribe response = %+v", response)
+	}
+	if result.Success || len(result.Events) != 1 || len(result.Rejected) != 1 || result.Rejected[0].Topic != "address:0xbbb:balance" {
+		t.Fatalf("subscribe result = %+v, want market.update accepted and foreign address rejected", result)
+	}
+
+	hub.BroadCast(entity.EventMarketUpdate, map[string]any{"price": 2})
+
+	var push struct {
+		JSONRPC string  `json:"jsonrpc"`
+		Method  string  `json:"method"`
+		Params  Message `json:"params"`
+	}
+	raw := <-client.send
+	if err := json.Unmarshal(raw, &push); err != nil || push.Method != "subscription" || push.Params.Type != entity.EventMarketUpdate {
+		t.Fatalf("push = %s, want subscription notification", raw)
+	}
+}
+
+func TestRPCDispatchToHandler(t *testing.T) {
+	hub := startHub(t, nil, HubConfig{})
+	client := addClient(t, hub, "0xaaa")
+
+	// tanpa handler hanya method hub yang tersedia
+	assertRPCError(t, rpcResult(t, client, `{"jsonrpc":"2.0","id":1,"method":"getBalance"}`), "1", RPCMethodNotFound)
+
+	handler := &recordingRPC{}
+	hub.SetRPCHandler(handler)
+
+	response := rpcResult(t, client, `{"jsonrpc":"2.0","id":"a","method":"getBalance","params":{"address":"0xccc"}}`)
+	if string(response.ID) != `"a"` || response.Error != nil || string(response.Result) != `{"address":"0xccc","balance":10}` {
+		t.Fatalf("getBalance response = %s / %+v", response.Result, response.Error)
+	}
+
+	// RPCError diteruskan apa adanya, error biasa menjadi server error
+	assertRPCError(t, rpcResult(t, client, `{"jsonrpc":"2.0","id":2,"method":"sendTransaction"}`), "2", -32001)
+	assertRPCError(t, rpcResult(t, client, `{"jsonrpc":"2.0","id":3,"method":"getBlock"}`), "3", RPCServerError)
+	assertRPCError(t, rpcResult(t, client, `{"jsonrpc":"2.0","id":4,"method":"getBalance","params":[1]}`), "4", RPCInvalidParams)
+
+	// method hub tidak diteruskan ke handler
+	rpcResult(t, client, `{"jsonrpc":"2.0","id":5,"method":"ack","params":{"seq":1}}`)
+
+	calls := handler.recorded()
+	if len(calls) != 4 || calls[0] != "0xaaa getBalance" || calls[1] != "0xaaa sendTransaction" {
+		t.Fatalf("handler calls = %v", calls)
+	}
+}
+
+func TestRPCBatch(t *testing.T) {
+	hub := startHub(t, nil, HubConfig{})
+	hub.SetRPCHandler(&recordingRPC{})
+	client := addClient(t, hub, "0xaaa")
+
+	raw := rpcCall(t, client, `[
+		{"jsonrpc":"2.0","id":1,"method":"getBalance","params":{"address":"0xaaa"}},
+		{"jsonrpc":"2.0","method":"subscribe","params":["market.update"]},
+		{"jsonrpc":"1.0","id":2,"method":"getBalance"},
+		{"jsonrpc":"2.0","id":3,"method":"resume","params":"x"},
+		{"jsonrpc":"2.0","id":4,"method":"nope"}
+	]`)
+
+	var responses []testRPCResponse
+	if err := json.Unmarshal(raw, &responses); err != nil {
+		t.Fatalf("batch response = %s", raw)
+	}
+
+	// notification (tanpa id) dijalankan tapi tidak dibalas
+	if len(responses) != 4 {
+		t.Fatalf("batch responses = %d, want 4: %s", len(responses), raw)
+	}
+	if string(responses[0].ID) != "1" || responses[0].Error != nil {
+		t.Fatalf("first response = %+v", responses[0])
+	}
+	assertRPCError(t, responses[1], "2", RPCInvalidRequest)
+	assertRPCError(t, responses[2], "3", RPCInvalidParams)
+	assertRPCError(t, responses[3], "4", RPCMethodNotFound)
+
+	hub.mu.RLock()
+	_, subscribed := hub.subscriptions[client][string(entity.EventMarketUpdate)]
+	hub.mu.RUnlock()
+	if !subscribed {
+		t.Fatal("notification subscribe was not applied")
+	}
+
+	// batch notification saja tidak dibalas sama sekali
+	client.handleMessage([]byte(`[{"jsonrpc":"2.0","method":"unsubscribe","params":["market.update"]}]`))
+	select {
+	case raw := <-client.send:
+		t.Fatalf("notification batch was answered: %s", raw)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestRPCInvalidFrames(t *testing.T) {
+	hub := startHub(t, nil, HubConfig{})
+	client := addClient(t, hub, "0xaaa")
+
+	// request pertama memindahkan koneksi ke mode RPC
+	if client.rpc.Load() {
+		t.Fatal("client starts in RPC mode")
+	}
+	assertRPCError(t, rpcResult(t, client, `[]`), "null", RPCInvalidRequest)
+	if !client.rpc.Load() {
+		t.Fatal("client did not switch to RPC mode")
+	}
+
+	assertRPCError(t, rpcResult(t, client, `{"jsonrpc":"2.0","id":1,`), "null", RPCParseError)
+	assertRPCError(t, rpcResult(t, client, `{"jsonrpc":"2.0","id":1}`), "1", RPCInvalidRequest)
+
+	batch := make([]json.RawMessage, maxRPCBatch+1)
+	for i := range batch {
+		batch[i] = json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"ack"}`)
+	}
+	frame, _ := json.Marshal(batch)
+	assertRPCError(t, rpcResult(t, client, string(frame)), "null", RPCInvalidRequest)
+}
@@ -0,0 +1,260 @@
+package websocket
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+	"github.com/livingdolls/go-blockchain-simulate/utils"
+)
+
+// addressTopics - topic private address:<addr>:<kind>, hanya untuk address JWT client sendiri
+var addressTopics = map[string]entity.MessageType{
+	"transactions": entity.EventTransactionUpdate,
+	"balance":      entity.EventBalanceUpdate,
+	"orders":       entity.EventConditionalOrder,
+}
+
+// messageFilter - true jika pesan diteruskan ke client
+type messageFilter func(data map[string]any) bool
+
+// subscription - hasil parse topic. contoh topic:
+//
+//	market.update                    semua pesan event (format lama)
+//	market.update:threshold=2%       hanya jika harga bergerak >= 2% dari pesan terakhir yang dikirim ke subscription ini
+//	block.mined:miner=<addr>         block yang ditambang address tertentu
+//	candles:5m / candles:5m:pair=X   candle interval 5m (default pair YTE-USD)
+//	address:<addr>:transactions      transaction.update milik address sendiri
+type subscription struct {
+	topic   string
+	event   entity.MessageType
+	filters []messageFilter
+}
+
+func (s *subscription) match(msg *delivery) bool {
+	if len(s.filters) == 0 {
+		return true
+	}
+
+	data := msg.fields()
+	if data == nil {
+		return false
+	}
+
+	// filter stateful (threshold) selalu terakhir, hanya melihat pesan yang lolos filter lain
+	for _, filter := range s.filters {
+		if !filter(data) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// delivery - data pesan hanya di-decode jika ada subscription dengan filter,
+// frame per format / mode RPC dibuat sekali lalu dipakai semua penerima
+type delivery struct {
+	msgType entity.MessageType
+	payload []byte
+	data    map[string]any
+	decoded bool
+
+	// frames[encoding][rpc]
+	frames    [2][2][]byte
+	encodeErr error
+}
+
+func (d *delivery) fields() map[string]any {
+	if d.decoded {
+		return d.data
+	}
+	d.decoded = true
+
+	var msg struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(d.payload, &msg); err == nil {
+		d.data = msg.Data
+	}
+
+	return d.data
+}
+
+// frame - payload dalam format client, dibungkus notification untuk koneksi mode JSON-RPC
+func (d *delivery) frame(enc encoding, rpc bool) ([]byte, error) {
+	mode := 0
+	if rpc {
+		mode = 1
+	}
+
+	if framed := d.frames[enc][mode]; framed != nil {
+		return framed, nil
+	}
+
+	if d.encodeErr != nil {
+		return nil, d.encodeErr
+	}
+
+	payload := d.frames[enc][0]
+	if payload == nil {
+		if payload, d.encodeErr = transcode(enc, d.payload); d.encodeErr != nil {
+			return nil, d.encodeErr
+		}
+		d.frames[enc][0] = payload
+	}
+
+	if rpc {
+		d.frames[enc][1] = wrapRPCPush(enc, payload)
+	}
+
+	return d.frames[enc][mode], nil
+}
+
+// parseTopic - address client dipakai untuk otorisasi topic private
+func parseTopic(topic, clientAddress string) (*subscription, error) {
+	topic = strings.TrimSpace(topic)
+	parts := strings.Split(topic, ":")
+	if topic == "" || slices.Contains(parts, "") {
+		return nil, fmt.Errorf("%w: %q", entity.ErrInvalidTopic, topic)
+	}
+
+	sub := &subscription{topic: topic, event: entity.MessageType(parts[0])}
+
+	if parts[0] == "address" {
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("%w: expected address:<addr>:<kind>", entity.ErrInvalidTopic)
+		}
+
+		event, ok := addressTopics[parts[2]]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown address topic %q", entity.ErrInvalidTopic, parts[2])
+		}
+
+		if !strings.EqualFold(parts[1], clientAddress) {
+			return nil, fmt.Errorf("%w: %s", entity.ErrTopicForbidden, topic)
+		}
+
+		sub.event = event
+		return sub, nil
+	}
+
+	paramParts := parts[1:]
+	if sub.event == entity.EventCandleUpdate {
+		if len(parts) < 2 || !slices.Contains(utils.CandleIntervals, parts[1]) {
+			return nil, fmt.Errorf("%w: expected candles:<interval>", entity.ErrInvalidTopic)
+		}
+		paramParts = parts[2:]
+	}
+
+	params, err := parseTopicParams(paramParts)
+	if err != nil {
+		return nil, err
+	}
+
+	switch sub.event {
+	case entity.EventCandleUpdate:
+		pair := models.DefaultPair
+		if value, ok := params["pair"]; ok {
+			pair = normalizeTopicPair(value)
+			delete(params, "pair")
+		}
+		sub.filters = append(sub.filters, fieldEquals("interval_type", parts[1]), fieldEquals("pair", pair))
+
+	case entity.EventMarketUpdate:
+		if value, ok := params["pair"]; ok {
+			sub.filters = append(sub.filters, fieldEquals("pair", normalizeTopicPair(value)))
+			delete(params, "pair")
+		}
+
+		if value, ok := params["threshold"]; ok {
+			threshold, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+			if err != nil || threshold <= 0 {
+				return nil, fmt.Errorf("%w: invalid threshold %q", entity.ErrInvalidTopic, value)
+			}
+			sub.filters = append(sub.filters, priceThreshold(threshold))
+			delete(params, "threshold")
+		}
+
+	case entity.EventTypeBlockMined:
+		if value, ok := params["miner"]; ok {
+			sub.filters = append(sub.filters, fieldEqualsFold("miner_address", value))
+			delete(params, "miner")
+		}
+	}
+
+	if unknown := slices.Sorted(maps.Keys(params)); len(unknown) > 0 {
+		return nil, fmt.Errorf("%w: unsupported parameter %q for %s", entity.ErrInvalidTopic, unknown[0], sub.event)
+	}
+
+	return sub, nil
+}
+
+func parseTopicParams(parts []string) (map[string]string, error) {
+	params := make(map[string]string, len(parts))
+
+	for _, part := range parts {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || key == "" || value == "" {
+			return nil, fmt.Errorf("%w: invalid parameter %q", entity.ErrInvalidTopic, part)
+		}
+
+		if _, dup := params[key]; dup {
+			return nil, fmt.Errorf("%w: duplicate parameter %q", entity.ErrInvalidTopic, key)
+		}
+
+		params[key] = value
+	}
+
+	return params, nil
+}
+
+// normalizeTopicPair - sama seperti services.NormalizePair (BTC_USD / btc-usd -> BTC-USD)
+func normalizeTopicPair(pair string) string {
+	return strings.NewReplacer("/", "-", "_", "-").Replace(strings.ToUpper(strings.TrimSpace(pair)))
+}
+
+func fieldEquals(field, value string) messageFilter {
+	return func(data map[string]any) bool {
+		v, _ := data[field].(string)
+		return v == value
+	}
+}
+
+func fieldEqualsFold(field, value string) messageFilter {
+	return func(data map[string]any) bool {
+		v, _ := data[field].(string)
+		return strings.EqualFold(v, value)
+	}
+}
+
+// priceThreshold - stateful per subscription, pesan pertama selalu dikirim
+func priceThreshold(percent float64) messageFilter {
+	var (
+		mu   sync.Mutex
+		last float64
+	)
+
+	return func(data map[string]any) bool {
+		price, ok := data["price"].(float64)
+		if !ok || price <= 0 {
+			return false
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if last > 0 && math.Abs(price-last)/last*100 < percent {
+			return false
+		}
+
+		last = price
+		return true
+	}
+}
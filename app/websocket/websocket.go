@@ -1,44 +1,140 @@
 package websocket
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"log"
+	"strings"
 	"sync"
+	"time"
 
+	lru "github.com/hashicorp/golang-lru/v2"
 	"github.com/livingdolls/go-blockchain-simulate/app/entity"
 )
 
+// HubConfig - nilai 0 diganti default, kecuali SlowClientMaxDrops (0 = client lambat tidak diputus)
+type HubConfig struct {
+	// ReplayBufferSize - jumlah pesan per address yang disimpan untuk resume
+	ReplayBufferSize int
+	// ReplayAddresses - jumlah address yang buffer-nya disimpan (LRU)
+	ReplayAddresses int
+	AckTimeout      time.Duration
+	AckMaxAttempts  int
+	// SlowClientMaxDrops - putuskan client yang kehilangan pesan sebanyak ini dalam SlowClientWindow
+	SlowClientMaxDrops int
+	SlowClientWindow   time.Duration
+	// MaxSubscriptions - jumlah topic per koneksi
+	MaxSubscriptions int
+}
+
+func normalizeHubConfig(cfg HubConfig) HubConfig {
+	if cfg.ReplayBufferSize <= 0 {
+		cfg.ReplayBufferSize = 256
+	}
+	if cfg.ReplayAddresses <= 0 {
+		cfg.ReplayAddresses = 10000
+	}
+	if cfg.AckTimeout <= 0 {
+		cfg.AckTimeout = 10 * time.Second
+	}
+	if cfg.AckMaxAttempts <= 0 {
+		cfg.AckMaxAttempts = 3
+	}
+	if cfg.SlowClientWindow <= 0 {
+		cfg.SlowClientWindow = time.Minute
+	}
+	if cfg.MaxSubscriptions <= 0 {
+		cfg.MaxSubscriptions = 32
+	}
+	return cfg
+}
+
+// sequencer - sumber seq per address, backplane atau memory jika single instance
+type sequencer interface {
+	NextSequence(ctx context.Context, address string) (uint64, error)
+}
+
+// SnapshotFunc - dipanggil saat client subscribe ke event yang punya state (snapshot-then-delta).
+// send harus dipanggil di bawah lock yang sama dengan lock pembuat delta agar urutan seq terjaga
+type SnapshotFunc func(send func(data any))
+
+// outbound - pesan yang sudah di-marshal, siap dikirim ke client lokal
+type outbound struct {
+	msgType entity.MessageType
+	payload []byte
+}
+
+// backplaneMessage - address kosong berarti broadcast ke semua subscriber
+type backplaneMessage struct {
+	Address string             `json:"address,omitempty"`
+	Type    entity.MessageType `json:"type"`
+	Seq     uint64             `json:"seq,omitempty"`
+	Ack     bool               `json:"ack,omitempty"`
+	Payload json.RawMessage    `json:"payload"`
+}
+
 type Hub struct {
 	clients       map[*ClientWS]bool
 	address       map[string]map[*ClientWS]bool
-	subscriptions map[*ClientWS]map[entity.MessageType]bool
+	subscriptions map[*ClientWS]map[string]*subscription
 	register      chan *ClientWS
 	unregister    chan *ClientWS
-	broadcast     chan *Message
+	broadcast     chan outbound
+	snapshots     map[entity.MessageType]SnapshotFunc
+	rpc           RPCHandler
+
+	// backplane nil = single instance, pesan langsung dikirim ke client lokal
+	backplane Backplane
+	sequence  sequencer
+	replays   *lru.Cache[string, *replayBuffer]
+	cfg       HubConfig
+	counters  hubCounters
 
 	stopChan chan struct{}
 	mu       sync.RWMutex
 }
 
-func NewHub() *Hub {
+func NewHub(backplane Backplane, cfg HubConfig) *Hub {
+	cfg = normalizeHubConfig(cfg)
+
+	var sequence sequencer = newMemorySequence()
+	if backplane != nil {
+		sequence = backplane
+	}
+
+	// error hanya jika size <= 0, sudah dinormalisasi
+	replays, _ := lru.New[string, *replayBuffer](cfg.ReplayAddresses)
+
 	return &Hub{
 		clients:       make(map[*ClientWS]bool),
 		address:       make(map[string]map[*ClientWS]bool),
-		subscriptions: make(map[*ClientWS]map[entity.MessageType]bool),
+		subscriptions: make(map[*ClientWS]map[string]*subscription),
 		register:      make(chan *ClientWS),
 		unregister:    make(chan *ClientWS),
-		broadcast:     make(chan *Message, 256),
+		broadcast:     make(chan outbound, 256),
+		snapshots:     make(map[entity.MessageType]SnapshotFunc),
+		backplane:     backplane,
+		sequence:      sequence,
+		replays:       replays,
+		cfg:           cfg,
 		stopChan:      make(chan struct{}),
 	}
 }
 
 func (h *Hub) Run() {
+	if h.backplane != nil {
+		go h.runBackplane()
+	}
+
+	go h.runAckRetry()
+
 	for {
 		select {
 		case c := <-h.register:
 			h.mu.Lock()
 			h.clients[c] = true
-			h.subscriptions[c] = make(map[entity.MessageType]bool)
+			h.subscriptions[c] = make(map[string]*subscription)
 
 			// Register client by address
 			if h.address[c.address] == nil {
@@ -69,7 +165,7 @@ func (h *Hub) Run() {
 			h.mu.Unlock()
 			log.Printf("Client unregistered user=%s total=%d", c.address, len(h.clients))
 		case msg := <-h.broadcast:
-			h.broadcastMessageToSubscribers(msg)
+			h.broadcastMessageToSubscribers(msg.msgType, msg.payload)
 		case <-h.stopChan:
 			h.closeAllConnections()
 			return
@@ -77,101 +173,403 @@ func (h *Hub) Run() {
 	}
 }
 
-func (h *Hub) broadcastMessageToSubscribers(message *Message) {
-	log.Printf("Broadcasting message to subscribers: %v", message.Type)
-	payload, err := json.Marshal(message)
-	if err != nil {
-		log.Printf("==========================================")
-		log.Printf("WebSocket broadcast marshal error: %v", err)
+// runBackplane - terima pesan dari semua instance (termasuk instance ini) lalu kirim ke client lokal
+func (h *Hub) runBackplane() {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-h.stopChan
+		cancel()
+	}()
+
+	for {
+		err := h.backplane.Subscribe(ctx, h.handleBackplaneMessage)
+		if ctx.Err() != nil {
+			return
+		}
+
+		log.Printf("WebSocket backplane subscription ended: %v, retrying", err)
+
+		select {
+		case <-time.After(2 * time.Second):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (h *Hub) handleBackplaneMessage(data []byte) {
+	var msg backplaneMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		log.Printf("WebSocket backplane unmarshal error: %v", err)
+		return
+	}
+
+	if msg.Address != "" {
+		h.deliverToAddress(msg)
 		return
 	}
 
+	h.enqueueLocal(msg.Type, msg.Payload)
+}
+
+// publishBackplane - false jika backplane tidak dipakai / gagal, pesan harus dikirim lokal
+func (h *Hub) publishBackplane(msg backplaneMessage) bool {
+	if h.backplane == nil {
+		return false
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		log.Printf("WebSocket backplane marshal error: %v", err)
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := h.backplane.Publish(ctx, data); err != nil {
+		// fallback: minimal client di instance ini tetap menerima pesan
+		log.Printf("WebSocket backplane publish error, delivering locally: %v", err)
+		return false
+	}
+
+	return true
+}
+
+func (h *Hub) enqueueLocal(msgType entity.MessageType, payload []byte) {
+	select {
+	case h.broadcast <- outbound{msgType: msgType, payload: payload}:
+	case <-h.stopChan:
+		log.Println("Hub is closing, broadcast message dropped")
+	default:
+		h.counters.broadcastDropped.Add(1)
+		log.Println("WebSocket broadcast channel full, dropping message")
+	}
+}
+
+func (h *Hub) broadcastMessageToSubscribers(msgType entity.MessageType, payload []byte) {
+	log.Printf("Broadcasting message to subscribers: %v", msgType)
+
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
 	log.Printf("COUNT CLIENT %v", len(h.clients))
 
+	msg := &delivery{msgType: msgType, payload: payload}
 	for client := range h.clients {
-		log.Printf("SEND TO CLIENT: %v", len(h.clients))
-		if subscribed, ok := h.subscriptions[client][message.Type]; ok && subscribed {
+		if h.matches(client, msg) {
+			h.trySend(client, msg)
+		}
+	}
+}
+
+// matches - harus dipanggil dengan h.mu dipegang
+func (h *Hub) matches(client *ClientWS, msg *delivery) bool {
+	for _, sub := range h.subscriptions[client] {
+		if sub.event == msg.msgType && sub.match(msg) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// trySend - push event dalam format client, dibungkus frame JSON-RPC jika koneksi memakai mode RPC.
+// harus dipanggil dengan h.mu dipegang
+func (h *Hub) trySend(client *ClientWS, msg *delivery) bool {
+	payload, err := msg.frame(client.encoding, client.rpc.Load())
+	if err != nil {
+		log.Printf("WebSocket encode error user=%s type=%s: %v", client.address, msg.msgType, err)
+		return false
+	}
+
+	return h.enqueue(client, payload)
+}
+
+// sendDirect - balasan untuk satu client, aman dipanggil dari goroutine mana pun
+// (send channel tidak ditutup selama h.mu dipegang dan client masih terdaftar)
+func (h *Hub) sendDirect(client *ClientWS, payload []byte) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if !h.clients[client] {
+		return false
+	}
+
+	return h.enqueue(client, payload)
+}
+
+// enqueue - harus dipanggil dengan h.mu dipegang.
+// client yang terus kehilangan pesan diputus jika SlowClientMaxDrops diset
+func (h *Hub) enqueue(client *ClientWS, payload []byte) bool {
+	select {
+	case client.send <- payload:
+		h.counters.sent.Add(1)
+		return true
+	default:
+	}
+
+	h.counters.dropped.Add(1)
+	drops := client.recordDrop(time.Now(), h.cfg.SlowClientWindow)
+	log.Printf("WebSocket client send channel full, dropping message user=%s drops=%d", client.address, drops)
+
+	if h.cfg.SlowClientMaxDrops > 0 && drops >= h.cfg.SlowClientMaxDrops && client.closing.CompareAndSwap(false, true) {
+		h.counters.slowDisconnects.Add(1)
+		log.Printf("Disconnecting slow WebSocket client user=%s", client.address)
+
+		// dipanggil dari loop Run / di bawah h.mu, unregister harus async
+		go func() {
 			select {
-			case client.send <- payload:
-				log.Println("Message sent to client")
-			default:
-				log.Println("WebSocket client send channel full, dropping message")
+			case h.unregister <- client:
+			case <-h.stopChan:
 			}
+		}()
+	}
+
+	return false
+}
+
+// runAckRetry - kirim ulang pesan yang belum di-ack, dibuang setelah AckMaxAttempts
+func (h *Hub) runAckRetry() {
+	interval := h.cfg.AckTimeout / 2
+	if interval > time.Second {
+		interval = time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			h.retryAcks(now)
+		case <-h.stopChan:
+			return
+		}
+	}
+}
+
+func (h *Hub) retryAcks(now time.Time) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for client := range h.clients {
+		resend, expired := client.dueAcks(now, h.cfg.AckTimeout, h.cfg.AckMaxAttempts)
+
+		if expired > 0 {
+			h.counters.ackExpired.Add(int64(expired))
+			log.Printf("WebSocket ack expired user=%s count=%d", client.address, expired)
+		}
+
+		for _, payload := range resend {
+			h.counters.ackRetries.Add(1)
+			h.trySend(client, &delivery{payload: payload})
+		}
+	}
+}
+
+// resume - kirim ulang pesan address client dengan seq > lastSeq (hanya event yang sudah di-subscribe)
+func (h *Hub) resume(client *ClientWS, lastSeq uint64) ResumeResponse {
+	response := ResumeResponse{LastSeq: lastSeq, LatestSeq: lastSeq, Complete: true}
+
+	buffer, ok := h.replays.Get(client.address)
+	if !ok {
+		// tidak ada buffer: instance belum melihat pesan address ini atau sudah terbuang dari LRU
+		response.Complete = lastSeq == 0
+		return response
+	}
+
+	entries, latest, complete := buffer.since(lastSeq)
+	response.LatestSeq = latest
+	response.Complete = complete
+
+	h.mu.RLock()
+	for _, entry := range entries {
+		msg := &delivery{msgType: entry.msgType, payload: entry.payload}
+		if !h.matches(client, msg) {
+			continue
+		}
+
+		if h.trySend(client, msg) {
+			response.Replayed++
+			h.counters.replayed.Add(1)
+		}
+
+		if entry.ack {
+			client.trackAck(entry.seq, entry.payload)
 		}
 	}
+	h.mu.RUnlock()
+
+	return response
 }
 
-func (h *Hub) Subscribe(client *ClientWS, evenType entity.MessageType) {
+// Subscribe - topic berupa nama event (format lama) atau topic dengan filter, lihat parseTopic.
+// mengembalikan event dari topic untuk pengiriman snapshot
+func (h *Hub) Subscribe(client *ClientWS, topic string) (entity.MessageType, error) {
+	sub, err := parseTopic(topic, client.address)
+	if err != nil {
+		return "", err
+	}
+
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
-	if subs, ok := h.subscriptions[client]; ok {
-		subs[evenType] = true
+	subs, ok := h.subscriptions[client]
+	if !ok {
+		return "", entity.ErrUnauthorized
+	}
+
+	if _, exists := subs[sub.topic]; !exists && len(subs) >= h.cfg.MaxSubscriptions {
+		return "", fmt.Errorf("%w: limit is %d per connection", entity.ErrTooManySubscriptions, h.cfg.MaxSubscriptions)
 	}
 
-	log.Printf("Client subscribed to %v", evenType)
+	subs[sub.topic] = sub
+
+	log.Printf("Client subscribed to %v", sub.topic)
+	return sub.event, nil
 }
 
-func (h *Hub) Unsubscribe(client *ClientWS, eventType entity.MessageType) {
+// RegisterSnapshot - snapshot dikirim ke client setiap kali subscribe ke msgType
+func (h *Hub) RegisterSnapshot(msgType entity.MessageType, fn SnapshotFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.snapshots[msgType] = fn
+}
+
+func (h *Hub) sendSnapshot(client *ClientWS, msgType entity.MessageType) {
+	h.mu.RLock()
+	fn, ok := h.snapshots[msgType]
+	h.mu.RUnlock()
+
+	if !ok {
+		return
+	}
+
+	fn(func(data any) {
+		client.sendResponse(msgType, data)
+	})
+}
+
+func (h *Hub) Unsubscribe(client *ClientWS, topic string) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
 	if subs, ok := h.subscriptions[client]; ok {
-		delete(subs, eventType)
+		delete(subs, strings.TrimSpace(topic))
 	}
 
-	log.Printf("Client unsubscribed from %v", eventType)
+	log.Printf("Client unsubscribed from %v", topic)
 }
 
+// BroadCast - lewat backplane agar client di semua instance menerima pesan
 func (h *Hub) BroadCast(msgType entity.MessageType, data any) {
-	message := &Message{
-		Type: msgType,
-		Data: data,
+	payload, err := marshalMessage(msgType, data)
+	if err != nil {
+		log.Printf("WebSocket broadcast marshal error: %v", err)
+		return
 	}
 
-	select {
-	case h.broadcast <- message:
-	case <-h.stopChan:
-		log.Println("Hub is closing, broadcast message dropped")
-	default:
-		log.Println("WebSocket broadcast channel full, dropping message")
+	if h.publishBackplane(backplaneMessage{Type: msgType, Payload: payload}) {
+		return
+	}
+
+	h.enqueueLocal(msgType, payload)
+}
+
+// BroadCastLocal - hanya client di instance ini, untuk state yang dihitung per instance
+// (mis. market feed dengan seq per instance)
+func (h *Hub) BroadCastLocal(msgType entity.MessageType, data any) {
+	payload, err := marshalMessage(msgType, data)
+	if err != nil {
+		log.Printf("WebSocket broadcast marshal error: %v", err)
+		return
 	}
+
+	h.enqueueLocal(msgType, payload)
 }
 
 func (h *Hub) SendToAddress(address string, msgType entity.MessageType, data any) {
-	message := &Message{
+	h.sendToAddress(address, msgType, data, false)
+}
+
+// SendToAddressWithAck - untuk notifikasi penting, dikirim ulang sampai client mengirim ack
+func (h *Hub) SendToAddressWithAck(address string, msgType entity.MessageType, data any) {
+	h.sendToAddress(address, msgType, data, true)
+}
+
+func (h *Hub) sendToAddress(address string, msgType entity.MessageType, data any, ack bool) {
+	// client didaftarkan dengan address lowercase
+	address = strings.ToLower(address)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// tanpa seq pesan tetap dikirim, hanya tidak bisa di-resume / di-ack
+	seq, err := h.sequence.NextSequence(ctx, address)
+	if err != nil {
+		log.Printf("WebSocket sequence error for %s: %v", address, err)
+		ack = false
+	}
+
+	payload, err := json.Marshal(&Message{
 		Type: msgType,
 		Data: data,
+		Seq:  seq,
+		Ack:  ack,
+	})
+	if err != nil {
+		log.Printf("Websocket send to users marshal error %v", err)
+		return
 	}
 
-	payload, err := json.Marshal(message)
+	msg := backplaneMessage{Address: address, Type: msgType, Seq: seq, Ack: ack, Payload: payload}
+	if h.publishBackplane(msg) {
+		return
+	}
 
-	if err != nil {
-		log.Printf("Websocket send to users marshal error %v", err)
+	h.deliverToAddress(msg)
+}
+
+// deliverToAddress - setiap instance menyimpan pesan ke replay buffer agar resume bisa di instance mana pun
+func (h *Hub) deliverToAddress(msg backplaneMessage) {
+	if msg.Seq > 0 {
+		buffer, ok := h.replays.Get(msg.Address)
+		if !ok {
+			buffer = newReplayBuffer(h.cfg.ReplayBufferSize)
+			if previous, found, _ := h.replays.PeekOrAdd(msg.Address, buffer); found {
+				buffer = previous
+			}
+		}
+
+		buffer.add(replayEntry{seq: msg.Seq, msgType: msg.Type, ack: msg.Ack, payload: msg.Payload})
 	}
 
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
-	if clients, ok := h.address[address]; ok {
+	target := &delivery{msgType: msg.Type, payload: msg.Payload}
+	if clients, ok := h.address[msg.Address]; ok {
 		for client := range clients {
-			if subscribed, ok := h.subscriptions[client][message.Type]; ok && subscribed {
-				select {
-				case client.send <- payload:
-					log.Printf("Message sent to client at address %s", address)
-				case <-h.stopChan:
-					log.Println("Hub is closing, message dropped")
-				default:
-					log.Println("WebSocket client send channel full, dropping message")
+			if h.matches(client, target) {
+				h.trySend(client, target)
+
+				if msg.Ack {
+					client.trackAck(msg.Seq, msg.Payload)
 				}
 			}
 		}
 	}
 }
 
+func marshalMessage(msgType entity.MessageType, data any) ([]byte, error) {
+	return json.Marshal(&Message{
+		Type: msgType,
+		Data: data,
+	})
+}
+
 func (h *Hub) closeAllConnections() {
 	h.mu.Lock()
 	defer h.mu.Unlock()
@@ -186,7 +584,7 @@ func (h *Hub) closeAllConnections() {
 	// clear maps
 	h.clients = make(map[*ClientWS]bool)
 	h.address = make(map[string]map[*ClientWS]bool)
-	h.subscriptions = make(map[*ClientWS]map[entity.MessageType]bool)
+	h.subscriptions = make(map[*ClientWS]map[string]*subscription)
 
 	log.Println("All client connections closed.")
 }
@@ -0,0 +1,109 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/livingdolls/go-blockchain-simulate/app/dto"
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+	"github.com/livingdolls/go-blockchain-simulate/app/port"
+	"github.com/livingdolls/go-blockchain-simulate/app/services"
+	"github.com/livingdolls/go-blockchain-simulate/logger"
+	"github.com/livingdolls/go-blockchain-simulate/rabbitmq"
+	"go.uber.org/zap"
+)
+
+// CandleAggregationConsumer - update candle secara incremental dari event market.pricing
+// Queue market.candles mendapat salinan event yang sama dengan market.pricing
+// Worker count sebaiknya 1 agar urutan block terjaga (close price = block terakhir)
+type CandleAggregationConsumer struct {
+	client        port.MessageBroker
+	candleService services.CandleService
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	workerCount       int
+	processingTimeout time.Duration
+
+	running atomic.Bool
+}
+
+func NewCandleAggregationConsumer(
+	client port.MessageBroker,
+	candleService services.CandleService,
+	workerCount int,
+) *CandleAggregationConsumer {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &CandleAggregationConsumer{
+		client:            client,
+		candleService:     candleService,
+		ctx:               ctx,
+		cancel:            cancel,
+		workerCount:       workerCount,
+		processingTimeout: 30 * time.Second,
+	}
+}
+
+func (c *CandleAggregationConsumer) Start() error {
+	if !c.running.CompareAndSwap(false, true) {
+		logger.LogWarn("Candle aggregation consumer is already running")
+		return nil
+	}
+
+	logger.LogInfo("Starting candle aggregation consumer")
+
+	return c.client.ConsumeWithRetry(
+		c.ctx,
+		rabbitmq.CandleAggregationQueue,
+		c.workerCount,
+		c.handleMessage,
+	)
+}
+
+func (c *CandleAggregationConsumer) Stop() {
+	if !c.running.CompareAndSwap(true, false) {
+		return
+	}
+
+	logger.LogInfo("Stopping candle aggregation consumer")
+	c.cancel()
+	logger.LogInfo("Candle aggregation consumer stopped")
+}
+
+func (c *CandleAggregationConsumer) handleMessage(msg port.Delivery) error {
+	var event dto.MarketPricingEvent
+
+	if err := json.Unmarshal(msg.Body, &event); err != nil {
+		return port.Permanent(fmt.Errorf("unmarshal market pricing event: %w", err))
+	}
+
+	if event.Timestamp == 0 {
+		event.Timestamp = time.Now().Unix()
+	}
+
+	ctx, cancel := context.WithTimeout(c.ctx, c.processingTimeout)
+	defer cancel()
+
+	tick := models.MarketTick{
+		Pair:       event.Pair,
+		BlockID:    event.BlockID,
+		Price:      event.Price,
+		BuyVolume:  event.BuyVolume,
+		SellVolume: event.SellVolume,
+		TxCount:    event.TxCount,
+		CreatedAt:  event.Timestamp,
+	}
+
+	// idempotent: block yang sudah diterapkan diabaikan di repository
+	if err := c.candleService.ApplyTick(ctx, tick); err != nil {
+		logger.LogError("Failed to apply tick to candles", err, zap.Int64("block_id", event.BlockID))
+		return err
+	}
+
+	return nil
+}
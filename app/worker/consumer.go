@@ -0,0 +1,16 @@
+package worker
+
+import "context"
+
+// stopContext - context untuk ConsumeWithRetry pada consumer yang dihentikan lewat stopChan,
+// consumer RabbitMQ ikut berhenti saat stopChan ditutup
+func stopContext(stop <-chan struct{}) context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		<-stop
+		cancel()
+	}()
+
+	return ctx
+}
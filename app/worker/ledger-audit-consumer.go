@@ -3,18 +3,19 @@ package worker
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"sync"
 	"time"
 
 	"github.com/livingdolls/go-blockchain-simulate/app/dto"
+	"github.com/livingdolls/go-blockchain-simulate/app/port"
 	"github.com/livingdolls/go-blockchain-simulate/logger"
 	"github.com/livingdolls/go-blockchain-simulate/rabbitmq"
-	"github.com/rabbitmq/amqp091-go"
 	"go.uber.org/zap"
 )
 
 type LedgerAuditConsumer struct {
-	client            *rabbitmq.Client
+	client            port.MessageBroker
 	mu                sync.Mutex
 	isRunning         bool
 	stopChan          chan struct{}
@@ -24,7 +25,7 @@ type LedgerAuditConsumer struct {
 	auditTrailMu      sync.RWMutex
 }
 
-func NewLedgerAuditConsumer(rmqClient *rabbitmq.Client, workerCount int) *LedgerAuditConsumer {
+func NewLedgerAuditConsumer(rmqClient port.MessageBroker, workerCount int) *LedgerAuditConsumer {
 	return &LedgerAuditConsumer{
 		client:            rmqClient,
 		stopChan:          make(chan struct{}),
@@ -46,25 +47,19 @@ func (l *LedgerAuditConsumer) Start() error {
 
 	logger.LogInfo("Starting ledger audit consumer")
 
-	return l.client.Consume(
+	return l.client.ConsumeWithRetry(
+		stopContext(l.stopChan),
 		rabbitmq.LedgerAuditQueue,
 		l.workerCount,
 		l.handleMessage,
 	)
 }
 
-func (l *LedgerAuditConsumer) handleMessage(msg amqp091.Delivery) {
-	defer func() {
-		if err := msg.Ack(false); err != nil {
-			logger.LogError("Failed to ack message", err)
-		}
-	}()
-
+func (l *LedgerAuditConsumer) handleMessage(msg port.Delivery) error {
 	var batch dto.LedgerBatchEvent
 
 	if err := json.Unmarshal(msg.Body, &batch); err != nil {
-		logger.LogError("Failed to unmarshal ledger batch", err)
-		return
+		return port.Permanent(fmt.Errorf("unmarshal ledger batch: %w", err))
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), l.processingTimeout)
@@ -76,6 +71,7 @@ func (l *LedgerAuditConsumer) handleMessage(msg amqp091.Delivery) {
 		zap.Int("block_number", batch.BlockNumber),
 		zap.Int("entries", batch.TotalEntries),
 	)
+	return nil
 }
 
 func (l *LedgerAuditConsumer) processAuditTrail(ctx context.Context, batch dto.LedgerBatchEvent) {
@@ -0,0 +1,127 @@
+package worker
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/livingdolls/go-blockchain-simulate/logger"
+	"go.uber.org/zap"
+
+	"github.com/livingdolls/go-blockchain-simulate/app/dto"
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+	"github.com/livingdolls/go-blockchain-simulate/app/port"
+	"github.com/livingdolls/go-blockchain-simulate/app/publisher"
+	"github.com/livingdolls/go-blockchain-simulate/app/repository"
+	"github.com/livingdolls/go-blockchain-simulate/rabbitmq"
+)
+
+// LedgerProjectionConsumer - ledger sudah ditulis di transaction block, consumer ini hanya memproyeksikan
+// batch yang sudah commit ke luar: balance.update ke setiap address yang saldonya berubah.
+// idempotent (mengirim saldo terkini), redelivery aman
+type LedgerProjectionConsumer struct {
+	client      port.MessageBroker
+	userRepo    repository.UserRepository
+	publisherWS *publisher.PublisherWS
+	mu          sync.Mutex
+	isRunning   bool
+	stopChan    chan struct{}
+	workerCount int
+}
+
+func NewLedgerProjectionConsumer(rmqClient port.MessageBroker, userRepo repository.UserRepository, publisherWS *publisher.PublisherWS, workerCount int) *LedgerProjectionConsumer {
+	return &LedgerProjectionConsumer{
+		client:      rmqClient,
+		userRepo:    userRepo,
+		publisherWS: publisherWS,
+		stopChan:    make(chan struct{}),
+		workerCount: workerCount,
+	}
+}
+
+func (l *LedgerProjectionConsumer) Start() error {
+	l.mu.Lock()
+	if l.isRunning {
+		l.mu.Unlock()
+		return nil
+	}
+
+	l.isRunning = true
+	l.mu.Unlock()
+
+	logger.LogInfo("Starting ledger projection consumer")
+
+	// queue ledger.persistence dipakai ulang agar binding yang sudah ada di broker tetap berlaku
+	return l.client.ConsumeWithRetry(
+		stopContext(l.stopChan),
+		rabbitmq.LedgerPresistenceQueue,
+		l.workerCount,
+		l.handleMessage,
+	)
+}
+
+func (l *LedgerProjectionConsumer) handleMessage(msg port.Delivery) error {
+	var batch dto.LedgerBatchEvent
+
+	if err := json.Unmarshal(msg.Body, &batch); err != nil {
+		return port.Permanent(fmt.Errorf("unmarshal ledger batch event: %w", err))
+	}
+
+	if l.publisherWS == nil {
+		return nil
+	}
+
+	projected := 0
+	for _, address := range affectedAddresses(batch) {
+		user, err := l.userRepo.GetByAddressWithBalance(address)
+		if err != nil {
+			// MINER_ACCOUNT dan address sistem lain tidak punya user
+			logger.LogDebug("Skip balance projection", zap.String("address", address), zap.Error(err))
+			continue
+		}
+
+		l.publisherWS.PublishToAddress(strings.ToLower(address), entity.EventBalanceUpdate, dto.DTOUserWithBalance{
+			Name:       user.Name,
+			Address:    user.Address,
+			YTEBalance: user.YTEBalance,
+			USDBalance: user.USDBalance,
+		})
+		projected++
+	}
+
+	logger.LogInfo(fmt.Sprintf("Projected ledger batch for block %d to %d addresses", batch.BlockNumber, projected))
+	return nil
+}
+
+// affectedAddresses - unik, urutan kemunculan di batch
+func affectedAddresses(batch dto.LedgerBatchEvent) []string {
+	seen := make(map[string]bool, len(batch.Entries))
+	addresses := make([]string, 0, len(batch.Entries))
+
+	for _, entry := range batch.Entries {
+		if seen[entry.Address] {
+			continue
+		}
+		seen[entry.Address] = true
+		addresses = append(addresses, entry.Address)
+	}
+
+	return addresses
+}
+
+func (l *LedgerProjectionConsumer) Stop() {
+	l.mu.Lock()
+
+	if !l.isRunning {
+		l.mu.Unlock()
+		return
+	}
+
+	l.isRunning = false
+	l.mu.Unlock()
+
+	logger.LogInfo("Stopping ledger projection")
+	close(l.stopChan)
+	logger.LogInfo("Stopped ledger projection consumer")
+}
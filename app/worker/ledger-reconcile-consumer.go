@@ -12,9 +12,9 @@ import (
 
 	"github.com/livingdolls/go-blockchain-simulate/app/dto"
 	"github.com/livingdolls/go-blockchain-simulate/app/models"
+	"github.com/livingdolls/go-blockchain-simulate/app/port"
 	"github.com/livingdolls/go-blockchain-simulate/app/repository"
 	"github.com/livingdolls/go-blockchain-simulate/rabbitmq"
-	"github.com/rabbitmq/amqp091-go"
 )
 
 type RecoilConfig struct {
@@ -25,7 +25,7 @@ type RecoilConfig struct {
 }
 
 type LedgerReconcileConsumer struct {
-	client          *rabbitmq.Client
+	client          port.MessageBroker
 	walletRepo      repository.UserWalletRepository
 	ledgerRepo      repository.LedgerRepository
 	discrepancyRepo repository.DiscrepancyRepository
@@ -39,7 +39,7 @@ type LedgerReconcileConsumer struct {
 	discrepanciesMu sync.RWMutex
 }
 
-func NewLedgerReconcileConsumer(rmqClient *rabbitmq.Client, walletRepo repository.UserWalletRepository, ledgerRepo repository.LedgerRepository, discrepancyRepo repository.DiscrepancyRepository, cfg RecoilConfig) *LedgerReconcileConsumer {
+func NewLedgerReconcileConsumer(rmqClient port.MessageBroker, walletRepo repository.UserWalletRepository, ledgerRepo repository.LedgerRepository, discrepancyRepo repository.DiscrepancyRepository, cfg RecoilConfig) *LedgerReconcileConsumer {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &LedgerReconcileConsumer{
 		client:          rmqClient,
@@ -71,24 +71,18 @@ func (l *LedgerReconcileConsumer) Start() error {
 		go l.reconcileWorker(i)
 	}
 
-	return l.client.Consume(
+	return l.client.ConsumeWithRetry(
+		l.stopCtx,
 		rabbitmq.LedgerReconcileQueue,
 		l.cfg.WorkerCount,
 		l.handleMessage,
 	)
 }
 
-func (l *LedgerReconcileConsumer) handleMessage(msg amqp091.Delivery) {
-	defer func() {
-		if err := msg.Ack(false); err != nil {
-			logger.LogError("Failed to ack message", err)
-		}
-	}()
-
+func (l *LedgerReconcileConsumer) handleMessage(msg port.Delivery) error {
 	var batch dto.LedgerBatchEvent
 	if err := json.Unmarshal(msg.Body, &batch); err != nil {
-		logger.LogError("Failed to unmarshal ledger batch", err)
-		return
+		return port.Permanent(fmt.Errorf("unmarshal ledger batch: %w", err))
 	}
 
 	// reconcile setiap 50 blocks
@@ -106,6 +100,7 @@ func (l *LedgerReconcileConsumer) handleMessage(msg amqp091.Delivery) {
 		batch.BlockNumber,
 		len(batch.Entries),
 	))
+	return nil
 }
 
 // worker untuk reconcile balances
@@ -130,7 +125,11 @@ func (l *LedgerReconcileConsumer) reconcileBalances(ctx context.Context, batch d
 	// get unique addresses from batch entries
 	addressMap := make(map[string]bool)
 
+	// hanya entry YTE yang dibandingkan dengan user_wallets
 	for _, entry := range batch.Entries {
+		if !isNativeLedgerAsset(entry.Asset) {
+			continue
+		}
 		addressMap[entry.Address] = true
 	}
 
@@ -170,6 +169,14 @@ func (l *LedgerReconcileConsumer) reconcileBalances(ctx context.Context, batch d
 	ledgerMap := make(map[string]float64)
 	lastEntryIDMap := make(map[string]int64)
 
+	nativeEntries := ledgerEntries[:0]
+	for _, entry := range ledgerEntries {
+		if isNativeLedgerAsset(entry.Asset) {
+			nativeEntries = append(nativeEntries, entry)
+		}
+	}
+	ledgerEntries = nativeEntries
+
 	for _, entry := range ledgerEntries {
 		ledgerMap[entry.Address] = entry.BalanceAfter
 
@@ -298,6 +305,7 @@ func (l *LedgerReconcileConsumer) batchEntriesToLedgerEntries(entries []dto.Ledg
 			BlockID:      entry.BlockID,
 			TxID:         entry.TxID,
 			Address:      entry.Address,
+			Asset:        entry.Asset,
 			Amount:       entry.Amount,
 			BalanceAfter: entry.BalanceAfter,
 		})
@@ -306,6 +314,10 @@ func (l *LedgerReconcileConsumer) batchEntriesToLedgerEntries(entries []dto.Ledg
 	return ledgerEntries
 }
 
+func isNativeLedgerAsset(asset string) bool {
+	return asset == "" || asset == models.NativeAsset
+}
+
 func (l *LedgerReconcileConsumer) flagBlockForManualReview(blockID int64, blockNumber int, reason string) {
 	logger.LogInfo(fmt.Sprintf("Block #%d (ID: %d) flagged for manual review: %s", blockNumber, blockID, reason))
 
@@ -0,0 +1,362 @@
+package worker
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/livingdolls/go-blockchain-simulate/app/dto"
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+	"github.com/livingdolls/go-blockchain-simulate/app/publisher"
+	"github.com/livingdolls/go-blockchain-simulate/app/repository"
+	"github.com/livingdolls/go-blockchain-simulate/app/services"
+	"github.com/livingdolls/go-blockchain-simulate/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	feedTickerWindow   = 24 * time.Hour
+	feedRecentTrades   = 50
+	feedDepthInterval  = time.Second
+	feedResubscribeGap = 5 * time.Second
+)
+
+// MarketFeedWorker - stream ticker 24h, recent trades dan depth pending BUY/SELL ke WebSocket.
+// setiap stream punya seq sendiri: snapshot membawa seq saat ini, delta berikutnya seq+1,
+// client yang melihat seq loncat harus subscribe ulang untuk mendapat snapshot baru.
+// setiap instance API menghitung feed sendiri, sehingga pesan hanya dikirim ke client lokal
+type MarketFeedWorker struct {
+	marketRepo   repository.MarketRepository
+	txRepo       repository.TransactionRepository
+	marketStream services.MarketStreamService
+	publisherWS  *publisher.PublisherWS
+	pair         string
+
+	// mu menjaga state dan seq, broadcast delta & kirim snapshot dilakukan di bawah mu
+	mu         sync.Mutex
+	tickerSeq  uint64
+	ticker     dto.Ticker24h
+	tradesSeq  uint64
+	trades     []dto.TradeEvent
+	depthSeq   uint64
+	depth      map[services.TransactionType]map[int]dto.DepthLevel
+	depthTotal dto.DepthUpdate
+	// depthPoll - refresh depth dari ticker dan poller tidak boleh saling menimpa
+	depthPoll sync.Mutex
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewMarketFeedWorker(
+	marketRepo repository.MarketRepository,
+	txRepo repository.TransactionRepository,
+	marketStream services.MarketStreamService,
+	publisherWS *publisher.PublisherWS,
+) *MarketFeedWorker {
+	return &MarketFeedWorker{
+		marketRepo:   marketRepo,
+		txRepo:       txRepo,
+		marketStream: marketStream,
+		publisherWS:  publisherWS,
+		pair:         models.DefaultPair,
+		depth: map[services.TransactionType]map[int]dto.DepthLevel{
+			services.BuyTransaction:  {},
+			services.SellTransaction: {},
+		},
+	}
+}
+
+// Start - muat state awal, daftarkan snapshot ke hub lalu dengarkan ticker/trade (redis) dan poll depth
+func (w *MarketFeedWorker) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+
+	w.refreshTicker(time.Now().Unix())
+	w.loadRecentTrades()
+	w.refreshDepth()
+
+	w.publisherWS.RegisterSnapshot(entity.EventTicker24h, w.tickerSnapshot)
+	w.publisherWS.RegisterSnapshot(entity.EventRecentTrades, w.tradesSnapshot)
+	w.publisherWS.RegisterSnapshot(entity.EventMarketDepth, w.depthSnapshot)
+
+	w.wg.Add(3)
+
+	go w.subscribe(ctx, "ticker", func(ctx context.Context) error {
+		return w.marketStream.SubscribeTicker(ctx, w.pair, func(update dto.PriceUpdate) error {
+			w.refreshTicker(update.Timestamp)
+			// block baru mengosongkan pending pool, depth langsung diperbarui
+			w.refreshDepth()
+			return nil
+		})
+	})
+
+	go w.subscribe(ctx, "trades", func(ctx context.Context) error {
+		return w.marketStream.SubscribeTrades(ctx, w.pair, func(trade dto.TradeEvent) error {
+			w.appendTrade(trade)
+			return nil
+		})
+	})
+
+	go func() {
+		defer w.wg.Done()
+
+		ticker := time.NewTicker(feedDepthInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				w.refreshDepth()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	logger.LogInfo("Market feed worker started", zap.String("pair", w.pair))
+}
+
+// subscribe - redis subscribe blocking, diulang jika koneksi terputus
+func (w *MarketFeedWorker) subscribe(ctx context.Context, name string, run func(ctx context.Context) error) {
+	defer w.wg.Done()
+
+	for {
+		err := run(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		logger.LogWarn("Market feed subscription ended, retrying", zap.String("stream", name), zap.Error(err))
+
+		select {
+		case <-time.After(feedResubscribeGap):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (w *MarketFeedWorker) refreshTicker(now int64) {
+	stats, err := w.marketRepo.GetTickerStats(w.pair, now-int64(feedTickerWindow/time.Second))
+	if err != nil {
+		logger.LogError("Failed to load 24h ticker stats", err)
+		return
+	}
+
+	ticker := dto.Ticker24h{
+		Pair:       w.pair,
+		Price:      stats.LastPrice,
+		Open:       stats.OpenPrice,
+		High:       stats.HighPrice,
+		Low:        stats.LowPrice,
+		BuyVolume:  stats.BuyVolume,
+		SellVolume: stats.SellVolume,
+		Volume:     stats.BuyVolume + stats.SellVolume,
+		TxCount:    stats.TxCount,
+		Timestamp:  now,
+	}
+
+	if stats.OpenPrice > 0 {
+		ticker.PriceChange = stats.LastPrice - stats.OpenPrice
+		ticker.PriceChangePercent = ticker.PriceChange / stats.OpenPrice * 100
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.tickerSeq++
+	ticker.Seq = w.tickerSeq
+	ticker.Kind = dto.FeedDelta
+	w.ticker = ticker
+
+	w.publisherWS.PublishLocal(entity.EventTicker24h, ticker)
+}
+
+func (w *MarketFeedWorker) tickerSnapshot(send func(data any)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	snapshot := w.ticker
+	snapshot.Kind = dto.FeedSnapshot
+	send(snapshot)
+}
+
+func (w *MarketFeedWorker) loadRecentTrades() {
+	trades, err := w.marketRepo.GetRecentTrades(feedRecentTrades)
+	if err != nil {
+		logger.LogError("Failed to load recent trades", err)
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.trades = w.trades[:0]
+	for _, trade := range trades {
+		w.trades = append(w.trades, dto.TradeEvent{
+			Pair:        w.pair,
+			TxID:        trade.TxID,
+			BlockNumber: trade.BlockNumber,
+			Side:        trade.Side,
+			Address:     trade.Address,
+			Amount:      trade.Amount,
+			Price:       trade.Price,
+			Timestamp:   trade.Timestamp,
+		})
+	}
+}
+
+func (w *MarketFeedWorker) appendTrade(trade dto.TradeEvent) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.trades = append(w.trades, trade)
+	if len(w.trades) > feedRecentTrades {
+		w.trades = w.trades[len(w.trades)-feedRecentTrades:]
+	}
+
+	w.tradesSeq++
+	w.publisherWS.PublishLocal(entity.EventRecentTrades, dto.RecentTradesUpdate{
+		Seq:    w.tradesSeq,
+		Kind:   dto.FeedDelta,
+		Pair:   w.pair,
+		Trades: []dto.TradeEvent{trade},
+	})
+}
+
+func (w *MarketFeedWorker) tradesSnapshot(send func(data any)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	send(dto.RecentTradesUpdate{
+		Seq:    w.tradesSeq,
+		Kind:   dto.FeedSnapshot,
+		Pair:   w.pair,
+		Trades: append([]dto.TradeEvent(nil), w.trades...),
+	})
+}
+
+// refreshDepth - delta hanya dikirim jika ada level yang berubah
+func (w *MarketFeedWorker) refreshDepth() {
+	w.depthPoll.Lock()
+	defer w.depthPoll.Unlock()
+
+	levels, err := w.txRepo.GetPendingDepth()
+	if err != nil {
+		logger.LogError("Failed to load pending depth", err)
+		return
+	}
+
+	next := map[services.TransactionType]map[int]dto.DepthLevel{
+		services.BuyTransaction:  {},
+		services.SellTransaction: {},
+	}
+
+	for _, level := range levels {
+		side := services.TransactionType(strings.ToUpper(level.Side))
+		if _, ok := next[side]; !ok {
+			continue
+		}
+
+		next[side][level.Tier] = dto.DepthLevel{
+			Tier:      level.Tier,
+			MinAmount: math.Pow10(level.Tier),
+			Orders:    level.Orders,
+			Amount:    level.Amount,
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	delta := dto.DepthUpdate{
+		Pair:  w.pair,
+		Buys:  diffDepthLevels(w.depth[services.BuyTransaction], next[services.BuyTransaction]),
+		Sells: diffDepthLevels(w.depth[services.SellTransaction], next[services.SellTransaction]),
+	}
+
+	if len(delta.Buys) == 0 && len(delta.Sells) == 0 {
+		return
+	}
+
+	w.depth = next
+	w.depthSeq++
+
+	w.depthTotal = dto.DepthUpdate{Pair: w.pair, Timestamp: time.Now().Unix()}
+	for _, level := range next[services.BuyTransaction] {
+		w.depthTotal.BuyAmount += level.Amount
+		w.depthTotal.BuyOrders += level.Orders
+	}
+	for _, level := range next[services.SellTransaction] {
+		w.depthTotal.SellAmount += level.Amount
+		w.depthTotal.SellOrders += level.Orders
+	}
+
+	delta.Seq = w.depthSeq
+	delta.Kind = dto.FeedDelta
+	delta.BuyAmount = w.depthTotal.BuyAmount
+	delta.SellAmount = w.depthTotal.SellAmount
+	delta.BuyOrders = w.depthTotal.BuyOrders
+	delta.SellOrders = w.depthTotal.SellOrders
+	delta.Timestamp = w.depthTotal.Timestamp
+
+	w.publisherWS.PublishLocal(entity.EventMarketDepth, delta)
+}
+
+func (w *MarketFeedWorker) depthSnapshot(send func(data any)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	snapshot := w.depthTotal
+	snapshot.Seq = w.depthSeq
+	snapshot.Kind = dto.FeedSnapshot
+	snapshot.Pair = w.pair
+	snapshot.Buys = sortedDepthLevels(w.depth[services.BuyTransaction])
+	snapshot.Sells = sortedDepthLevels(w.depth[services.SellTransaction])
+
+	send(snapshot)
+}
+
+// diffDepthLevels - level yang hilang dikirim dengan orders & amount 0
+func diffDepthLevels(prev, next map[int]dto.DepthLevel) []dto.DepthLevel {
+	changed := map[int]dto.DepthLevel{}
+
+	for tier, level := range next {
+		if old, ok := prev[tier]; !ok || old != level {
+			changed[tier] = level
+		}
+	}
+
+	for tier, level := range prev {
+		if _, ok := next[tier]; !ok {
+			changed[tier] = dto.DepthLevel{Tier: tier, MinAmount: level.MinAmount}
+		}
+	}
+
+	return sortedDepthLevels(changed)
+}
+
+func sortedDepthLevels(levels map[int]dto.DepthLevel) []dto.DepthLevel {
+	sorted := make([]dto.DepthLevel, 0, len(levels))
+	for _, level := range levels {
+		sorted = append(sorted, level)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Tier < sorted[j].Tier })
+
+	return sorted
+}
+
+func (w *MarketFeedWorker) Stop() {
+	logger.LogInfo("MarketFeedWorker: Stopping worker")
+	if w.cancel != nil {
+		w.cancel()
+	}
+	w.wg.Wait()
+	logger.LogInfo("MarketFeedWorker: Worker stopped")
+}
@@ -3,16 +3,19 @@ package worker
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"sync"
 	"time"
 
 	"github.com/livingdolls/go-blockchain-simulate/app/dto"
 	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+	"github.com/livingdolls/go-blockchain-simulate/app/port"
 	"github.com/livingdolls/go-blockchain-simulate/app/publisher"
 	"github.com/livingdolls/go-blockchain-simulate/app/repository"
+	"github.com/livingdolls/go-blockchain-simulate/app/services"
 	"github.com/livingdolls/go-blockchain-simulate/logger"
 	"github.com/livingdolls/go-blockchain-simulate/rabbitmq"
-	"github.com/rabbitmq/amqp091-go"
 	"go.uber.org/zap"
 )
 
@@ -20,32 +23,47 @@ import (
 // Mendengarkan queue market.pricing dan memproses pricing updates
 // Multiple consumer instances bisa berjalan untuk scalability
 type MarketPricingConsumer struct {
-	client            *rabbitmq.Client
+	client            port.MessageBroker
 	marketRepo        repository.MarketRepository
 	publisherWS       *publisher.PublisherWS
+	condOrders        services.ConditionalOrderService
+	breaker           services.CircuitBreakerService
+	marketStream      services.MarketStreamService
 	mu                sync.Mutex
 	isRunning         bool
 	stopChan          chan struct{}
 	workerCount       int
 	processingTimeout time.Duration
-	priceCache        map[int64]float64 // Cache harga terakhir per block ID
+	priceCache        map[priceCacheKey]float64 // Cache harga per (pair, block)
 	priceCacheMu      sync.RWMutex
 }
 
+// priceCacheKey - harga di-cache per pair supaya block yang sama di pair lain tidak saling menimpa
+type priceCacheKey struct {
+	pair  string
+	block int64
+}
+
 func NewMarketPricingConsumer(
-	client *rabbitmq.Client,
+	client port.MessageBroker,
 	marketRepo repository.MarketRepository,
 	publisherWS *publisher.PublisherWS,
+	condOrders services.ConditionalOrderService,
+	breaker services.CircuitBreakerService,
+	marketStream services.MarketStreamService,
 	workerCount int,
 ) *MarketPricingConsumer {
 	return &MarketPricingConsumer{
 		client:            client,
 		marketRepo:        marketRepo,
 		publisherWS:       publisherWS,
+		condOrders:        condOrders,
+		breaker:           breaker,
+		marketStream:      marketStream,
 		stopChan:          make(chan struct{}),
 		workerCount:       workerCount,
 		processingTimeout: 30 * time.Second,
-		priceCache:        make(map[int64]float64),
+		priceCache:        make(map[priceCacheKey]float64),
 	}
 }
 
@@ -70,7 +88,8 @@ func (m *MarketPricingConsumer) Start() error {
 		logger.LogWarn("Failed to load initial price cache", zap.Error(err))
 	}
 
-	return m.client.Consume(
+	return m.client.ConsumeWithRetry(
+		stopContext(m.stopChan),
 		rabbitmq.MarketPricingQueue,
 		m.workerCount,
 		m.handleMessage,
@@ -82,20 +101,20 @@ func (m *MarketPricingConsumer) Start() error {
 // - Parse pesan
 // - Update price cache untuk tracking perubahab
 // - Kirim update ke WebSocket clients
+// - Evaluasi circuit breaker (halt BUY/SELL jika perubahan harga melewati threshold)
+// - Evaluasi conditional orders (stop-loss, take-profit, trailing stop)
 // - store ke database untuk historical data
-// - ack message jika sukses
-func (m *MarketPricingConsumer) handleMessage(msg amqp091.Delivery) {
-	defer func() {
-		if err := msg.Ack(false); err != nil {
-			logger.LogError("Failed to ack message", err)
-		}
-	}()
-
+// kegagalan setelah update dikirim ke client hanya dicatat: retry akan mengirim ulang market.update
+func (m *MarketPricingConsumer) handleMessage(msg port.Delivery) error {
 	var event dto.MarketPricingEvent
 
 	if err := json.Unmarshal(msg.Body, &event); err != nil {
-		logger.LogError("Failed to unmarshal message", err)
-		return
+		return port.Permanent(fmt.Errorf("unmarshal market pricing event: %w", err))
+	}
+
+	// event lama belum membawa pair
+	if event.Pair == "" {
+		event.Pair = models.DefaultPair
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), m.processingTimeout)
@@ -103,8 +122,8 @@ func (m *MarketPricingConsumer) handleMessage(msg amqp091.Delivery) {
 
 	// update price cache dan hitung perubahan harga
 	m.priceCacheMu.Lock()
-	previousPrice := m.priceCache[int64(event.BlockNumber)-1]
-	m.priceCache[int64(event.BlockNumber)] = event.Price
+	previousPrice := m.priceCache[priceCacheKey{pair: event.Pair, block: int64(event.BlockNumber) - 1}]
+	m.priceCache[priceCacheKey{pair: event.Pair, block: int64(event.BlockNumber)}] = event.Price
 	priceChage := event.Price - previousPrice
 	priceChangePercent := 0.0
 
@@ -114,23 +133,47 @@ func (m *MarketPricingConsumer) handleMessage(msg amqp091.Delivery) {
 
 	m.priceCacheMu.Unlock()
 
+	priceUpdate := dto.PriceUpdate{
+		Pair:               event.Pair,
+		BlockID:            event.BlockID,
+		BlockNumber:        event.BlockNumber,
+		Price:              event.Price,
+		PriceChange:        priceChage,
+		PriceChangePercent: priceChangePercent,
+		Liquidity:          event.Liquidity,
+		BuyVolume:          event.BuyVolume,
+		SellVolume:         event.SellVolume,
+		TxCount:            event.TxCount,
+		Timestamp:          event.Timestamp,
+		MinerAddress:       event.MinerAddress,
+	}
+
 	// kirim update ke WebSocket clients
 	if m.publisherWS != nil {
-		priceUpdate := dto.PriceUpdate{
-			BlockID:            event.BlockID,
-			BlockNumber:        event.BlockNumber,
-			Price:              event.Price,
-			PriceChange:        priceChage,
-			PriceChangePercent: priceChangePercent,
-			Liquidity:          event.Liquidity,
-			BuyVolume:          event.BuyVolume,
-			SellVolume:         event.SellVolume,
-			TxCount:            event.TxCount,
-			Timestamp:          event.Timestamp,
-			MinerAddress:       event.MinerAddress,
+		m.publisherWS.Publish(entity.EventMarketUpdate, priceUpdate)
+	}
+
+	// ticker untuk SSE
+	if m.marketStream != nil {
+		if err := m.marketStream.PublishTicker(ctx, priceUpdate); err != nil {
+			logger.LogWarn("Failed to publish ticker", zap.Error(err))
 		}
+	}
 
-		m.publisherWS.Publish(entity.EventMarketUpdate, priceUpdate)
+	// circuit breaker hanya untuk pair native (YTE-USD), BUY/SELL hanya ada di pair ini
+	halted := false
+	if m.breaker != nil && event.Pair == models.DefaultPair {
+		status, err := m.breaker.Evaluate(ctx, event.Pair, int64(event.BlockNumber), priceChangePercent)
+		if err != nil {
+			logger.LogError("Failed to evaluate circuit breaker", err)
+		}
+		halted = status.Halted
+	}
+
+	// evaluasi conditional orders terhadap harga block terbaru
+	// conditional order hanya untuk pair native (YTE-USD), ditunda selama trading di-halt
+	if m.condOrders != nil && event.Pair == models.DefaultPair && !halted {
+		m.condOrders.EvaluatePrice(ctx, event.Price, int64(event.BlockNumber))
 	}
 
 	// simpan historical data ke database
@@ -139,6 +182,8 @@ func (m *MarketPricingConsumer) handleMessage(msg amqp091.Delivery) {
 			logger.LogError("Failed to store market snapshot", err)
 		}
 	}()
+
+	return nil
 }
 
 // monitorMarketMetrics - Monitor dan simpan metrik market ke database
@@ -161,7 +206,7 @@ func (m *MarketPricingConsumer) monitorMarketMetrics(ctx context.Context, event
 
 	// calculate price metrics untuk monitoring
 	m.priceCacheMu.RLock()
-	previousPirce := m.priceCache[int64(event.BlockNumber)-1]
+	previousPirce := m.priceCache[priceCacheKey{pair: event.Pair, block: int64(event.BlockNumber) - 1}]
 	m.priceCacheMu.RUnlock()
 
 	priceChange := event.Price - previousPirce
@@ -253,6 +298,7 @@ func (m *MarketPricingConsumer) GetPriceHistory(limit, offset int) ([]dto.PriceU
 		}
 
 		updates = append(updates, dto.PriceUpdate{
+			Pair:               tick.Pair,
 			BlockID:            tick.BlockID,
 			BlockNumber:        int(tick.BlockID),
 			Price:              tick.Price,
@@ -279,7 +325,7 @@ func (m *MarketPricingConsumer) LoadInitialPriceCache(limit int) error {
 	defer m.priceCacheMu.Unlock()
 
 	for _, tick := range ticks {
-		m.priceCache[int64(tick.BlockID)] = tick.Price
+		m.priceCache[priceCacheKey{pair: tick.Pair, block: tick.BlockID}] = tick.Price
 	}
 
 	logger.LogInfo("Loaded initial price cache",
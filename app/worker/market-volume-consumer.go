@@ -3,16 +3,16 @@ package worker
 import (
 	"context"
 	"encoding/json"
-	"errors"
+	"fmt"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/livingdolls/go-blockchain-simulate/app/dto"
+	"github.com/livingdolls/go-blockchain-simulate/app/port"
 	"github.com/livingdolls/go-blockchain-simulate/app/repository"
 	"github.com/livingdolls/go-blockchain-simulate/logger"
 	"github.com/livingdolls/go-blockchain-simulate/rabbitmq"
-	"github.com/rabbitmq/amqp091-go"
 	"go.uber.org/zap"
 )
 
@@ -23,7 +23,7 @@ import (
 // - Backup/archive purposes
 // - Multiple independent instances bisa berjalan
 type MarketVolumeConsumer struct {
-	client     *rabbitmq.Client
+	client     port.MessageBroker
 	marketRepo repository.MarketRepository
 
 	ctx    context.Context
@@ -55,7 +55,7 @@ type VolumeStats struct {
 }
 
 func NewMarketVolumeConsumer(
-	client *rabbitmq.Client,
+	client port.MessageBroker,
 	marketRepo repository.MarketRepository,
 	workerCount int,
 ) *MarketVolumeConsumer {
@@ -85,7 +85,7 @@ func (m *MarketVolumeConsumer) Start() error {
 	go m.cleanupLoop()
 
 	// start consuming messages
-	return m.client.ConsumeWithContext(
+	return m.client.ConsumeWithRetry(
 		m.ctx,
 		rabbitmq.MarketVolumeQueue,
 		m.workerCount,
@@ -108,27 +108,17 @@ func (m *MarketVolumeConsumer) Stop() {
 }
 
 // handle incoming messages
-func (m *MarketVolumeConsumer) handleMessage(msg amqp091.Delivery) {
-	defer func() {
-		if r := recover(); r != nil {
-			logger.LogError("Panic in market volume consumer", errors.New("panic occurred"), zap.Any("recovered", r))
-			msg.Nack(false, true)
-		}
-	}()
-
+func (m *MarketVolumeConsumer) handleMessage(msg port.Delivery) error {
 	var update dto.MarketVolumeUpdate
 
 	if err := json.Unmarshal(msg.Body, &update); err != nil {
-		logger.LogError("Failed to unmarshal market volume update", err)
-		msg.Nack(false, false)
-		return
+		return port.Permanent(fmt.Errorf("unmarshal market volume update: %w", err))
 	}
 
 	// idempotency check
 	if m.isProcessed(update.BlockID) {
 		logger.LogInfo("Market volume update already processed for block", zap.Int64("blockID", update.BlockID))
-		msg.Ack(false)
-		return
+		return nil
 	}
 
 	ctx, cancel := context.WithTimeout(m.ctx, 30*time.Second)
@@ -139,8 +129,7 @@ func (m *MarketVolumeConsumer) handleMessage(msg amqp091.Delivery) {
 
 	if err != nil {
 		logger.LogError("Failed to process market volume update", err, zap.Int64("blockID", update.BlockID))
-		msg.Nack(false, true)
-		return
+		return err
 	}
 
 	// mark as processed after successful processing
@@ -149,9 +138,8 @@ func (m *MarketVolumeConsumer) handleMessage(msg amqp091.Delivery) {
 	// update stats
 	m.updateStats(update)
 
-	msg.Ack(false)
-
 	logger.LogInfo("Successfully processed market volume update", zap.Int64("blockID", update.BlockID), zap.Float64("buyVolume", update.BuyVolume), zap.Float64("sellVolume", update.SellVolume))
+	return nil
 }
 
 // process volume
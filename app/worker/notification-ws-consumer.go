@@ -9,10 +9,10 @@ import (
 
 	"github.com/livingdolls/go-blockchain-simulate/app/dto"
 	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+	"github.com/livingdolls/go-blockchain-simulate/app/port"
 	"github.com/livingdolls/go-blockchain-simulate/app/publisher"
 	"github.com/livingdolls/go-blockchain-simulate/logger"
 	"github.com/livingdolls/go-blockchain-simulate/rabbitmq"
-	"github.com/rabbitmq/amqp091-go"
 	"go.uber.org/zap"
 )
 
@@ -24,7 +24,7 @@ type RetryConfig struct {
 }
 
 type NotificationWSConsumer struct {
-	client            *rabbitmq.Client
+	client            port.MessageBroker
 	publisherWS       *publisher.PublisherWS
 	mu                sync.Mutex
 	isRunning         bool
@@ -61,7 +61,7 @@ type MessageMetadata struct {
 }
 
 func NewNotificationWebSocketConsumer(
-	client *rabbitmq.Client,
+	client port.MessageBroker,
 	publisherWS *publisher.PublisherWS,
 	workerCount int,
 ) *NotificationWSConsumer {
@@ -100,7 +100,8 @@ func (n *NotificationWSConsumer) Start() error {
 
 	logger.LogInfo("Starting notification Websocket consumer", zap.Int("worker_count", n.workerCount))
 
-	return n.client.Consume(
+	return n.client.ConsumeWithRetry(
+		stopContext(n.stopChan),
 		rabbitmq.NotificationRealTimeQueue,
 		n.workerCount,
 		n.handleMessage,
@@ -108,35 +109,26 @@ func (n *NotificationWSConsumer) Start() error {
 
 }
 
-func (n *NotificationWSConsumer) handleMessage(msg amqp091.Delivery) {
+// handleMessage - pesan kosong/rusak/tidak valid langsung diparkir, pengiriman ke WebSocket di-retry sendiri
+func (n *NotificationWSConsumer) handleMessage(msg port.Delivery) error {
 	startTime := time.Now()
 
-	// defer ACK handling
-	defer func() {
-		if err := msg.Ack(false); err != nil {
-			logger.LogError("[NOTIFICATION_WS_CONSUMER] Failed to acknowledge notification WebSocket message: %v\n", err)
-		}
-	}()
-
 	logger.LogDebug("[NOTIFICATION_WS_CONSUMER] Received notification WebSocket message", zap.ByteString("body", msg.Body))
 
 	// validate
 	if len(msg.Body) == 0 {
-		logger.LogWarn("[NOTIFICATION_WS_CONSUMER] Empty notification WebSocket message body")
-		return
+		return port.Permanent(fmt.Errorf("empty notification message body"))
 	}
 
 	var notification dto.NotificationEvent
 
 	if err := json.Unmarshal(msg.Body, &notification); err != nil {
-		logger.LogError("[NOTIFICATION_WS_CONSUMER] Failed to unmarshal notification WebSocket message: %v\n", err)
-		return
+		return port.Permanent(fmt.Errorf("unmarshal notification message: %w", err))
 	}
 
 	// validate notification
 	if err := n.validateNotification(&notification); err != nil {
-		logger.LogError("[NOTIFICATION_WS_CONSUMER] Invalid notification WebSocket message: %v\n", err)
-		return
+		return port.Permanent(fmt.Errorf("invalid notification message: %w", err))
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), n.processingTimeout)
@@ -152,6 +144,7 @@ func (n *NotificationWSConsumer) handleMessage(msg amqp091.Delivery) {
 	// record latency
 	latency := time.Since(startTime).Milliseconds()
 	n.recordDeliveryMetrics(notification.RecipientAddress, latency)
+	return nil
 }
 
 func (n *NotificationWSConsumer) validateNotification(notification *dto.NotificationEvent) error {
@@ -276,12 +269,14 @@ func (n *NotificationWSConsumer) deliverNotification(ctx context.Context, notifi
 	// send via WebSocket publisher
 	deliveryChan := make(chan error, 1)
 
+	// notifikasi high priority (transaksi terkonfirmasi, reward) dikirim ulang hub sampai client ack
 	go func() {
-		n.publisherWS.PublishToAddress(
-			notification.RecipientAddress,
-			entity.MessageType("notification."+string(notification.Type)),
-			wsMsg,
-		)
+		msgType := entity.MessageType("notification." + string(notification.Type))
+		if requiresAck(notification) {
+			n.publisherWS.PublishToAddressWithAck(notification.RecipientAddress, msgType, wsMsg)
+		} else {
+			n.publisherWS.PublishToAddress(notification.RecipientAddress, msgType, wsMsg)
+		}
 		deliveryChan <- nil
 	}()
 
@@ -416,3 +411,12 @@ func (n *NotificationWSConsumer) IsRunning() bool {
 
 	return n.isRunning
 }
+
+func requiresAck(notification dto.NotificationEvent) bool {
+	switch notification.Type {
+	case dto.TypeTransactionConfirmed, dto.TypeRewardEarned:
+		return true
+	}
+
+	return notification.Priority == dto.PriorityHigh
+}
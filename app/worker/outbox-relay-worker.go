@@ -0,0 +1,95 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/livingdolls/go-blockchain-simulate/app/services"
+	"github.com/livingdolls/go-blockchain-simulate/logger"
+	"go.uber.org/zap"
+)
+
+// OutboxRelayWorker - publish event outbox ke RabbitMQ. berjalan saat di-Notify (block baru commit)
+// atau setiap pollInterval, sehingga event yang tertinggal karena crash/RabbitMQ mati tetap terkirim
+type OutboxRelayWorker struct {
+	service       services.OutboxService
+	pollInterval  time.Duration
+	pruneInterval time.Duration
+	stopChan      chan struct{}
+	doneChan      chan struct{}
+	cancel        context.CancelFunc
+}
+
+func NewOutboxRelayWorker(service services.OutboxService) *OutboxRelayWorker {
+	return &OutboxRelayWorker{
+		service:       service,
+		pollInterval:  time.Second,
+		pruneInterval: time.Hour,
+		stopChan:      make(chan struct{}),
+		doneChan:      make(chan struct{}),
+	}
+}
+
+func (w *OutboxRelayWorker) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+
+	go func() {
+		defer close(w.doneChan)
+
+		ticker := time.NewTicker(w.pollInterval)
+		defer ticker.Stop()
+
+		pruneTicker := time.NewTicker(w.pruneInterval)
+		defer pruneTicker.Stop()
+
+		for {
+			select {
+			case <-w.service.Wake():
+				w.relay(ctx)
+			case <-ticker.C:
+				w.relay(ctx)
+			case <-pruneTicker.C:
+				deleted, err := w.service.Prune(ctx)
+				if err != nil && !errors.Is(err, context.Canceled) {
+					logger.LogError("Outbox prune error", err)
+				} else if deleted > 0 {
+					logger.LogInfo("Outbox pruned sent events", zap.Int64("deleted", deleted))
+				}
+			case <-w.stopChan:
+				logger.LogInfo("OutboxRelayWorker: Stopping relay")
+				return
+			}
+		}
+	}()
+}
+
+// relay - ulangi selama batch penuh agar backlog habis tanpa menunggu tick berikutnya
+func (w *OutboxRelayWorker) relay(ctx context.Context) {
+	batchSize := w.service.Config().BatchSize
+
+	for ctx.Err() == nil {
+		sent, err := w.service.Relay(ctx)
+		if err != nil {
+			if !errors.Is(err, context.Canceled) {
+				logger.LogError("Outbox relay error", err)
+			}
+			return
+		}
+
+		if sent < batchSize {
+			return
+		}
+	}
+}
+
+func (w *OutboxRelayWorker) Stop() {
+	logger.LogInfo("OutboxRelayWorker: Stopping worker")
+	if w.cancel != nil {
+		w.cancel()
+	}
+	close(w.stopChan)
+	<-w.doneChan
+	logger.LogInfo("OutboxRelayWorker: Worker stopped")
+}
@@ -0,0 +1,72 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+	"github.com/livingdolls/go-blockchain-simulate/app/services"
+	"github.com/livingdolls/go-blockchain-simulate/logger"
+)
+
+// RetentionWorker - jalankan retention job sesuai RunEvery di config (dicek setiap checkInterval,
+// sehingga perubahan config lewat admin langsung berlaku)
+type RetentionWorker struct {
+	service       services.RetentionService
+	checkInterval time.Duration
+	stopChan      chan struct{}
+	doneChan      chan struct{}
+	cancel        context.CancelFunc
+}
+
+func NewRetentionWorker(service services.RetentionService) *RetentionWorker {
+	return &RetentionWorker{
+		service:       service,
+		checkInterval: time.Minute,
+		stopChan:      make(chan struct{}),
+		doneChan:      make(chan struct{}),
+	}
+}
+
+func (w *RetentionWorker) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+
+	go func() {
+		defer close(w.doneChan)
+
+		ticker := time.NewTicker(w.checkInterval)
+		defer ticker.Stop()
+
+		var lastRun time.Time
+
+		for {
+			select {
+			case <-ticker.C:
+				cfg := w.service.Config()
+				if !cfg.Enabled || time.Since(lastRun) < cfg.RunEvery {
+					continue
+				}
+
+				lastRun = time.Now()
+				if _, err := w.service.Run(ctx); err != nil && !errors.Is(err, entity.ErrConflict) && !errors.Is(err, context.Canceled) {
+					logger.LogError("Retention job error", err)
+				}
+			case <-w.stopChan:
+				logger.LogInfo("RetentionWorker: Stopping ticker")
+				return
+			}
+		}
+	}()
+}
+
+func (w *RetentionWorker) Stop() {
+	logger.LogInfo("RetentionWorker: Stopping worker")
+	if w.cancel != nil {
+		w.cancel()
+	}
+	close(w.stopChan)
+	<-w.doneChan
+	logger.LogInfo("RetentionWorker: Worker stopped")
+}
@@ -10,20 +10,18 @@ import (
 	"github.com/livingdolls/go-blockchain-simulate/logger"
 
 	"github.com/livingdolls/go-blockchain-simulate/app/dto"
+	"github.com/livingdolls/go-blockchain-simulate/app/port"
 	"github.com/livingdolls/go-blockchain-simulate/app/services"
 	"github.com/livingdolls/go-blockchain-simulate/rabbitmq"
-	"github.com/rabbitmq/amqp091-go"
 )
 
 type RewardEngineConfig struct {
 	ConsumerWorkers   int
-	CalcWorkers       int
 	ProcessingTimeout time.Duration
-	QueueSize         int
 }
 
 type RewardCalculationConsumer struct {
-	client          *rabbitmq.Client
+	client          port.MessageBroker
 	rewardPublisher services.RewardPublisher
 	cfg             RewardEngineConfig
 	mu              sync.Mutex
@@ -31,14 +29,9 @@ type RewardCalculationConsumer struct {
 	stopCtx         context.Context
 	stopCancel      context.CancelFunc
 
-	calcQueue   chan dto.RewardCalculationEvent
 	processedMu sync.RWMutex
 	processed   map[int64]ProcessedBlock
 
-	// retry mechanism for pending blocks
-	pendingBlocksMu sync.RWMutex
-	pendingBlocks   map[int64]dto.RewardCalculationEvent
-
 	// metrics
 	metricsMu           sync.RWMutex
 	processedCount      int64
@@ -52,7 +45,7 @@ type ProcessedBlock struct {
 	ProcessedAt int64
 }
 
-func NewRewardCalculationConsumer(rmqClient *rabbitmq.Client, rewardPublisher services.RewardPublisher, cfg RewardEngineConfig) *RewardCalculationConsumer {
+func NewRewardCalculationConsumer(rmqClient port.MessageBroker, rewardPublisher services.RewardPublisher, cfg RewardEngineConfig) *RewardCalculationConsumer {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &RewardCalculationConsumer{
 		client:          rmqClient,
@@ -60,9 +53,7 @@ func NewRewardCalculationConsumer(rmqClient *rabbitmq.Client, rewardPublisher se
 		cfg:             cfg,
 		stopCtx:         ctx,
 		stopCancel:      cancel,
-		calcQueue:       make(chan dto.RewardCalculationEvent, cfg.QueueSize),
 		processed:       make(map[int64]ProcessedBlock),
-		pendingBlocks:   make(map[int64]dto.RewardCalculationEvent),
 	}
 }
 
@@ -78,125 +69,43 @@ func (r *RewardCalculationConsumer) Start() error {
 
 	logger.LogInfo("Starting reward calculation consumer")
 
-	for i := 0; i < r.cfg.CalcWorkers; i++ {
-		go r.calcWorker(i)
-	}
-
-	// start retry worker for handle pending blocks
-	go r.retryPendingBlocksWorker()
-
 	// start cleanup worker for processed blocks
 	go r.cleanUpWorker()
 
-	return r.client.Consume(
+	return r.client.ConsumeWithRetry(
+		r.stopCtx,
 		rabbitmq.RewardCalculationQueue,
 		r.cfg.ConsumerWorkers,
 		r.handleMessage,
 	)
 }
 
-func (r *RewardCalculationConsumer) handleMessage(msg amqp091.Delivery) {
-	defer func() {
-		if err := msg.Ack(false); err != nil {
-			logger.LogError("Failed to ack message", err)
-		}
-	}()
-
+// handleMessage - diproses langsung (bukan antrian in-memory) agar kegagalan publish di-retry broker
+// dan diparkir di dead letter queue, bukan hilang saat proses restart
+func (r *RewardCalculationConsumer) handleMessage(msg port.Delivery) error {
 	var event dto.RewardCalculationEvent
 	if err := json.Unmarshal(msg.Body, &event); err != nil {
-		logger.LogError("Failed to unmarshal message", err)
-		return
+		return port.Permanent(fmt.Errorf("unmarshal reward calculation event: %w", err))
 	}
 
 	if r.isProcessed(event.BlockID) {
 		logger.LogInfo(fmt.Sprintf("Block %d already processed, skipping", event.BlockNumber))
-		return
+		return nil
 	}
 
-	select {
-	case r.calcQueue <- event:
-		logger.LogInfo(fmt.Sprintf("Enqueued reward calculation for block %d", event.BlockNumber))
-	default:
-		// durbality retry
-		r.pendingBlocksMu.Lock()
-		r.pendingBlocks[event.BlockID] = event
-		r.pendingBlocksMu.Unlock()
-
-		logger.LogInfo(fmt.Sprintf("Calculation queue full, storing block %d for retry", event.BlockNumber))
+	if msg.Attempts > 0 {
+		r.recordRetry()
 	}
-}
 
-func (r *RewardCalculationConsumer) calcWorker(workerID int) {
-	logger.LogInfo(fmt.Sprintf("Calculation worker %d started", workerID))
-
-	for {
-		select {
-		case <-r.stopCtx.Done():
-			logger.LogInfo(fmt.Sprintf("Calculation worker %d stopping", workerID))
-			return
-
-		case event, ok := <-r.calcQueue:
-			if !ok {
-				logger.LogInfo(fmt.Sprintf("Calculation worker %d stopping - queue closed", workerID))
-				return
-			}
-
-			ctx, cancel := context.WithTimeout(r.stopCtx, r.cfg.ProcessingTimeout)
-			r.process(ctx, event)
-			cancel()
-		}
-	}
-}
-
-func (r *RewardCalculationConsumer) retryPendingBlocksWorker() {
-	ticker := time.NewTicker(5 * time.Second)
-	defer ticker.Stop()
-
-	logger.LogInfo("Starting retry pending blocks worker")
-
-	for {
-		select {
-		case <-r.stopCtx.Done():
-			logger.LogInfo("Retry pending blocks worker stopping")
-			return
-		case <-ticker.C:
-			r.retryPendingBlocks()
-		}
-	}
-}
-
-func (r *RewardCalculationConsumer) retryPendingBlocks() {
-	r.pendingBlocksMu.Lock()
-	pendingList := make([]dto.RewardCalculationEvent, 0)
-	pendingIDs := make([]int64, 0)
-
-	for blockID, event := range r.pendingBlocks {
-		pendingList = append(pendingList, event)
-		pendingIDs = append(pendingIDs, blockID)
-	}
+	ctx, cancel := context.WithTimeout(r.stopCtx, r.cfg.ProcessingTimeout)
+	defer cancel()
 
-	for i, event := range pendingList {
-		select {
-		case <-r.stopCtx.Done():
-			return
-		case r.calcQueue <- event:
-			// retry successful, remove from pending
-			r.pendingBlocksMu.Lock()
-			delete(r.pendingBlocks, pendingIDs[i])
-			r.pendingBlocksMu.Unlock()
-			r.recordRetry()
-			logger.LogInfo(fmt.Sprintf("Retry success for block %d", event.BlockNumber))
-		default:
-			// queue full, will retry later
-			logger.LogInfo(fmt.Sprintf("Calculation queue full, will retry block %d later", event.BlockNumber))
-		}
+	if err := r.process(ctx, event); err != nil {
+		r.recordFailure()
+		return err
 	}
-}
 
-func (r *RewardCalculationConsumer) addPending(event dto.RewardCalculationEvent) {
-	r.pendingBlocksMu.Lock()
-	r.pendingBlocks[event.BlockID] = event
-	r.pendingBlocksMu.Unlock()
+	return nil
 }
 
 func (r *RewardCalculationConsumer) cleanUpWorker() {
@@ -231,14 +140,9 @@ func (r *RewardCalculationConsumer) cleanupOldEntries() {
 	}
 }
 
-func (r *RewardCalculationConsumer) process(ctx context.Context, event dto.RewardCalculationEvent) {
-	select {
-	case <-ctx.Done():
-		logger.LogInfo(fmt.Sprintf("Context cancelled for block %d", event.BlockNumber))
-		r.recordFailure()
-		r.addPending(event)
-		return
-	default:
+func (r *RewardCalculationConsumer) process(ctx context.Context, event dto.RewardCalculationEvent) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("reward calculation for block %d: %w", event.BlockNumber, err)
 	}
 
 	// calculate bonus reward
@@ -253,27 +157,13 @@ func (r *RewardCalculationConsumer) process(ctx context.Context, event dto.Rewar
 	breakdown.TotalReward = breakdown.BlockReward + breakdown.TransactionFees + breakdown.BonusReward
 	breakdown.EstimatedUSDValue = breakdown.TotalReward * event.MarketPrice
 
-	//validate calculations
+	// nilai negatif tidak akan berubah dengan retry
 	if breakdown.TotalReward < 0 {
-		logger.LogInfo(fmt.Sprintf(
-			"ERROR: Negative total reward for block #%d: %.8f",
-			event.BlockNumber,
-			breakdown.TotalReward,
-		))
-		r.recordFailure()
-		r.addPending(event)
-		return
+		return port.Permanent(fmt.Errorf("negative total reward for block #%d: %.8f", event.BlockNumber, breakdown.TotalReward))
 	}
 
 	if breakdown.EstimatedUSDValue < 0 {
-		logger.LogInfo(fmt.Sprintf(
-			"ERROR: Negative USD value for block #%d: %.2f",
-			event.BlockNumber,
-			breakdown.EstimatedUSDValue,
-		))
-		r.recordFailure()
-		r.addPending(event)
-		return
+		return port.Permanent(fmt.Errorf("negative USD value for block #%d: %.2f", event.BlockNumber, breakdown.EstimatedUSDValue))
 	}
 
 	distributionEvent := dto.RewardDistributionEvent{
@@ -291,16 +181,14 @@ func (r *RewardCalculationConsumer) process(ctx context.Context, event dto.Rewar
 
 	// Publish reward distribution event
 	if err := r.rewardPublisher.PublishRewardDistribution(publishCtx, distributionEvent); err != nil {
-		logger.LogError("Failed to publish reward distribution", err)
-		r.recordFailure()
-		r.addPending(event)
-		return
+		return fmt.Errorf("publish reward distribution for block %d: %w", event.BlockNumber, err)
 	}
 
 	r.markProcessed(event.BlockID)
 	r.recordSuccess(breakdown.TotalReward, breakdown.EstimatedUSDValue)
 
 	logger.LogInfo(fmt.Sprintf("Completed reward calculation for block %d", event.BlockNumber))
+	return nil
 }
 
 // bonus reward calculation rules
@@ -375,10 +263,6 @@ func (r *RewardCalculationConsumer) GetMetrics() map[string]interface{} {
 	totalUSDValueIssued := r.totalUSDValueIssued
 	r.metricsMu.RUnlock()
 
-	r.pendingBlocksMu.RLock()
-	pendingCount := len(r.pendingBlocks)
-	r.pendingBlocksMu.RUnlock()
-
 	avgRewardPerBlock := 0.0
 	if processedCount > 0 {
 		avgRewardPerBlock = totalRewardsIssued / float64(processedCount)
@@ -388,7 +272,6 @@ func (r *RewardCalculationConsumer) GetMetrics() map[string]interface{} {
 		"processed_count":        processedCount,
 		"failed_count":           failedCount,
 		"retried_count":          retriedCount,
-		"pending_blocks_count":   pendingCount,
 		"total_rewards_issued":   totalRewardsIssued,
 		"total_usd_value_issued": totalUSDValueIssued,
 		"avg_reward_per_block":   avgRewardPerBlock,
@@ -405,12 +288,9 @@ func (r *RewardCalculationConsumer) Stop() {
 
 	logger.LogInfo("Stopping reward calculation consumer")
 
-	// Cancel context terlebih dahulu (triggers all workers to stop)
+	// Cancel context (consumer dan cleanup worker berhenti)
 	r.stopCancel()
 
-	// Close channel setelah context cancelled
-	close(r.calcQueue)
-
 	r.isRunning = false
 
 	logger.LogInfo("Reward calculation consumer stopped")
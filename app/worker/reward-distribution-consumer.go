@@ -11,9 +11,9 @@ import (
 
 	"github.com/livingdolls/go-blockchain-simulate/app/dto"
 	"github.com/livingdolls/go-blockchain-simulate/app/models"
+	"github.com/livingdolls/go-blockchain-simulate/app/port"
 	"github.com/livingdolls/go-blockchain-simulate/app/repository"
 	"github.com/livingdolls/go-blockchain-simulate/rabbitmq"
-	"github.com/rabbitmq/amqp091-go"
 )
 
 type RewardStats struct {
@@ -33,15 +33,13 @@ type ProcessedReward struct {
 
 type RewardDistConfig struct {
 	ConsumerWorkers   int
-	DistWorkers       int
 	ProcessingTimeout time.Duration
-	QueueSize         int
 	CleanupInterval   time.Duration // TTL cleanup interval
 	TTLDuration       time.Duration // TTL for processed rewards (default 24h)
 }
 
 type RewardDistributionConsumer struct {
-	client     *rabbitmq.Client
+	client     port.MessageBroker
 	walletRepo repository.UserWalletRepository
 	cfg        RewardDistConfig
 	mu         sync.Mutex
@@ -49,19 +47,15 @@ type RewardDistributionConsumer struct {
 	stopCtx    context.Context
 	stopCancel context.CancelFunc
 
-	distQueue chan dto.RewardDistributionEvent
-	statsMu   sync.RWMutex
-	stats     map[string]RewardStats
+	statsMu sync.RWMutex
+	stats   map[string]RewardStats
 
 	// Idempotency tracking
-	processedMu   sync.RWMutex
-	processed     map[string]ProcessedReward // Key: blockNumber_minerAddress
-	retryQueue    chan dto.RewardDistributionEvent
-	retryTickerC  <-chan time.Time
-	cleanupTicker <-chan time.Time
+	processedMu sync.RWMutex
+	processed   map[string]ProcessedReward // Key: blockNumber_minerAddress
 }
 
-func NewRewardDistributionConsumer(client *rabbitmq.Client, walletRepo repository.UserWalletRepository, cfg RewardDistConfig) *RewardDistributionConsumer {
+func NewRewardDistributionConsumer(client port.MessageBroker, walletRepo repository.UserWalletRepository, cfg RewardDistConfig) *RewardDistributionConsumer {
 	if cfg.CleanupInterval == 0 {
 		cfg.CleanupInterval = 5 * time.Minute
 	}
@@ -76,8 +70,6 @@ func NewRewardDistributionConsumer(client *rabbitmq.Client, walletRepo repositor
 		cfg:        cfg,
 		stopCtx:    ctx,
 		stopCancel: cancel,
-		distQueue:  make(chan dto.RewardDistributionEvent, cfg.QueueSize),
-		retryQueue: make(chan dto.RewardDistributionEvent, cfg.QueueSize/2),
 		stats:      make(map[string]RewardStats),
 		processed:  make(map[string]ProcessedReward),
 	}
@@ -96,104 +88,56 @@ func (rdc *RewardDistributionConsumer) Start() error {
 
 	logger.LogInfo("Starting reward distribution consumer")
 
-	// Start worker pool before consuming messages
-	for i := 0; i < rdc.cfg.DistWorkers; i++ {
-		go rdc.distWorker(i)
-	}
-
 	// Start cleanup worker
 	go rdc.cleanupWorker()
 
-	// Start retry worker
-	go rdc.retryPendingRewardsWorker()
-
-	return rdc.client.Consume(
+	return rdc.client.ConsumeWithRetry(
+		rdc.stopCtx,
 		rabbitmq.RewardDistributionQueue,
 		rdc.cfg.ConsumerWorkers,
 		rdc.handleMessage,
 	)
 }
 
-func (rdc *RewardDistributionConsumer) handleMessage(msg amqp091.Delivery) {
+// handleMessage - diproses langsung, kegagalan database di-retry broker lalu diparkir di dead letter
+// queue (sebelumnya retry in-memory yang membuang reward saat antrian penuh)
+func (rdc *RewardDistributionConsumer) handleMessage(msg port.Delivery) error {
 	var event dto.RewardDistributionEvent
 
 	if err := json.Unmarshal(msg.Body, &event); err != nil {
-		logger.LogError("Failed to unmarshal message", err)
-		if err := msg.Nack(false, false); err != nil {
-			logger.LogError("Failed to nack message", err)
-		}
-		return
+		return port.Permanent(fmt.Errorf("unmarshal reward distribution event: %w", err))
 	}
 
-	// Proper queue full handling with nack for retry
-	select {
-	case rdc.distQueue <- event:
-		logger.LogInfo(fmt.Sprintf("Enqueued reward distribution event for block %d, miner %s", event.BlockNumber, event.MinerAddress))
-		if err := msg.Ack(false); err != nil {
-			logger.LogError("Failed to ack message", err)
-		}
-	default:
-		logger.LogInfo(fmt.Sprintf("Distribution queue full for block %d, nacking for retry", event.BlockNumber))
-		// Nack without requeue to send to dead letter queue, will be retried by retry worker
-		if err := msg.Nack(false, true); err != nil {
-			logger.LogError("Failed to nack message", err)
-		}
-	}
-}
+	ctx, cancel := context.WithTimeout(rdc.stopCtx, rdc.cfg.ProcessingTimeout)
+	defer cancel()
 
-// distWorker processes rewards from the distribution queue
-func (rdc *RewardDistributionConsumer) distWorker(id int) {
-	logger.LogInfo(fmt.Sprintf("Distribution worker %d started", id))
-
-	for {
-		select {
-		case <-rdc.stopCtx.Done():
-			logger.LogInfo(fmt.Sprintf("Distribution worker %d stopping", id))
-			return
-		case event, ok := <-rdc.distQueue:
-			if !ok {
-				logger.LogInfo(fmt.Sprintf("Distribution worker %d stopping - queue closed", id))
-				return
-			}
-			// ✅ FIX #3: Use stopCtx instead of Background
-			ctx, cancel := context.WithTimeout(rdc.stopCtx, rdc.cfg.ProcessingTimeout)
-			rdc.process(ctx, event)
-			cancel()
-		}
-	}
+	return rdc.process(ctx, event)
 }
 
 // process handles the actual reward distribution with transactional persistence
-func (rdc *RewardDistributionConsumer) process(ctx context.Context, event dto.RewardDistributionEvent) {
+func (rdc *RewardDistributionConsumer) process(ctx context.Context, event dto.RewardDistributionEvent) error {
 	// Check context timeout before processing
-	select {
-	case <-ctx.Done():
-		logger.LogInfo(fmt.Sprintf("Context cancelled for block %d", event.BlockNumber))
-		return
-	default:
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("reward distribution for block %d: %w", event.BlockNumber, err)
 	}
 
 	// Check idempotency - prevent duplicate distribution
 	if rdc.isProcessed(event.BlockNumber, event.MinerAddress) {
 		logger.LogInfo(fmt.Sprintf("Reward already distributed for block %d, miner %s (skipping)", event.BlockNumber, event.MinerAddress))
-		return
+		return nil
 	}
 
 	// Implement transactional wallet update with persistence
 	tx, err := rdc.walletRepo.BeginTx()
 	if err != nil {
-		logger.LogError("Failed to begin transaction, retrying", err)
-		rdc.enqueueForRetry(event)
-		return
+		return fmt.Errorf("begin transaction: %w", err)
 	}
 
 	// Lock wallet for update to prevent race conditions
 	wallets, err := rdc.walletRepo.GetMultipleByAddressWithTx(tx, []string{event.MinerAddress})
 	if err != nil {
 		tx.Rollback()
-		logger.LogError("Failed to get miner wallet, retrying", err)
-		rdc.enqueueForRetry(event)
-		return
+		return fmt.Errorf("get miner wallet: %w", err)
 	}
 
 	var minerWallet *models.UserWallet
@@ -204,9 +148,7 @@ func (rdc *RewardDistributionConsumer) process(ctx context.Context, event dto.Re
 		// Create wallet if not exists
 		if err := rdc.walletRepo.UpsertEmptyIfNotExistsWithTx(tx, event.MinerAddress); err != nil {
 			tx.Rollback()
-			logger.LogError("Failed to create wallet for miner", err)
-			rdc.enqueueForRetry(event)
-			return
+			return fmt.Errorf("create wallet for miner: %w", err)
 		}
 		minerWallet = &models.UserWallet{
 			UserAddress: event.MinerAddress,
@@ -220,9 +162,7 @@ func (rdc *RewardDistributionConsumer) process(ctx context.Context, event dto.Re
 	// Update wallet balance in database
 	if err := rdc.walletRepo.UpdateWalletWithTx(tx, event.MinerAddress, newBalance); err != nil {
 		tx.Rollback()
-		logger.LogError("Failed to update wallet balance, retrying", err)
-		rdc.enqueueForRetry(event)
-		return
+		return fmt.Errorf("update wallet balance: %w", err)
 	}
 
 	// Record wallet history for audit
@@ -238,16 +178,12 @@ func (rdc *RewardDistributionConsumer) process(ctx context.Context, event dto.Re
 
 	if err := rdc.walletRepo.InsertHistoryWithTx(tx, history); err != nil {
 		tx.Rollback()
-		logger.LogError("Failed to record wallet history, retrying", err)
-		rdc.enqueueForRetry(event)
-		return
+		return fmt.Errorf("record wallet history: %w", err)
 	}
 
 	// Commit transaction
 	if err := tx.Commit(); err != nil {
-		logger.LogError("Failed to commit transaction, retrying", err)
-		rdc.enqueueForRetry(event)
-		return
+		return fmt.Errorf("commit transaction: %w", err)
 	}
 
 	// Mark as processed (idempotency)
@@ -258,63 +194,7 @@ func (rdc *RewardDistributionConsumer) process(ctx context.Context, event dto.Re
 
 	// Log result
 	rdc.logResult(event, prev, newBalance)
-}
-
-// enqueueForRetry adds failed events back to retry queue
-func (rdc *RewardDistributionConsumer) enqueueForRetry(event dto.RewardDistributionEvent) {
-	select {
-	case rdc.retryQueue <- event:
-		logger.LogInfo(fmt.Sprintf("Queued for retry: block %d, miner %s", event.BlockNumber, event.MinerAddress))
-	default:
-		logger.LogInfo(fmt.Sprintf("Retry queue full, dropping reward for block %d (will be lost)", event.BlockNumber))
-	}
-}
-
-// retryPendingRewardsWorker periodically retries failed reward distributions
-func (rdc *RewardDistributionConsumer) retryPendingRewardsWorker() {
-	ticker := time.NewTicker(5 * time.Second)
-	defer ticker.Stop()
-
-	retryAttempts := make(map[string]int)
-	maxRetries := 5
-
-	logger.LogInfo("Retry worker started")
-
-	for {
-		select {
-		case <-rdc.stopCtx.Done():
-			logger.LogInfo("Retry worker stopping")
-			return
-		case <-ticker.C:
-			retryBatch := []dto.RewardDistributionEvent{}
-			// Drain retry queue without blocking
-			for len(retryBatch) < 100 {
-				select {
-				case event := <-rdc.retryQueue:
-					retryBatch = append(retryBatch, event)
-				default:
-					break
-				}
-			}
-
-			for _, event := range retryBatch {
-				key := fmt.Sprintf("%d_%s", event.BlockNumber, event.MinerAddress)
-				attempts := retryAttempts[key]
-
-				if attempts >= maxRetries {
-					logger.LogInfo(fmt.Sprintf("Max retries exceeded for block %d, miner %s, dropping", event.BlockNumber, event.MinerAddress))
-					delete(retryAttempts, key)
-					continue
-				}
-
-				ctx, cancel := context.WithTimeout(rdc.stopCtx, rdc.cfg.ProcessingTimeout)
-				rdc.process(ctx, event)
-				cancel()
-
-				retryAttempts[key]++
-			}
-		}
-	}
+	return nil
 }
 
 // cleanupWorker periodically cleans up old processed reward entries to prevent memory leaks
@@ -451,8 +331,6 @@ func (rdc *RewardDistributionConsumer) Stop() {
 
 	logger.LogInfo("Stopping consumer")
 	rdc.stopCancel()
-	close(rdc.distQueue)
-	close(rdc.retryQueue)
 	rdc.isRunning = false
 	logger.LogInfo("Consumer stopped")
 }
@@ -3,6 +3,7 @@ package worker
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
@@ -10,9 +11,11 @@ import (
 
 	"github.com/livingdolls/go-blockchain-simulate/logger"
 
+	"github.com/livingdolls/go-blockchain-simulate/app/dto"
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+	"github.com/livingdolls/go-blockchain-simulate/app/port"
 	"github.com/livingdolls/go-blockchain-simulate/app/services"
 	"github.com/livingdolls/go-blockchain-simulate/rabbitmq"
-	"github.com/rabbitmq/amqp091-go"
 )
 
 type TransactionMessage struct {
@@ -20,13 +23,17 @@ type TransactionMessage struct {
 	Address   string  `json:"address"`
 	ToAddress string  `json:"to_address"`
 	Amount    float64 `json:"amount"`
+	Token     string  `json:"token,omitempty"`
+	TokenName string  `json:"token_name,omitempty"`
+	Decimals  int     `json:"decimals,omitempty"`
 	Nonce     string  `json:"nonce"`
 	Signature string  `json:"signature"`
 }
 
 type TransactionConsumer struct {
-	client            *rabbitmq.Client
+	client            port.MessageBroker
 	txService         services.TransactionService
+	tokenService      services.TokenService
 	mu                sync.Mutex
 	isRunning         bool
 	stopChan          chan struct{}
@@ -35,13 +42,15 @@ type TransactionConsumer struct {
 }
 
 func NewTransactionConsumer(
-	client *rabbitmq.Client,
+	client port.MessageBroker,
 	txService services.TransactionService,
+	tokenService services.TokenService,
 	workerCount int,
 ) *TransactionConsumer {
 	return &TransactionConsumer{
 		client:            client,
 		txService:         txService,
+		tokenService:      tokenService,
 		stopChan:          make(chan struct{}),
 		workerCount:       workerCount,
 		processingTimeout: 30 * time.Second,
@@ -63,7 +72,9 @@ func (tc *TransactionConsumer) Start(ctx context.Context) error {
 
 	logger.LogInfo(fmt.Sprintf("Starting with %d workers", tc.workerCount))
 
-	handler := func(delivery amqp091.Delivery) {
+	// error dikembalikan ke ConsumeWithRetry: retry dengan delay, diparkir di dead letter queue
+	// setelah MaxDeliveryAttempts (sebelumnya requeue tanpa batas)
+	handler := func(delivery port.Delivery) error {
 		ctx, cancel := context.WithTimeout(context.Background(), tc.processingTimeout)
 
 		defer cancel()
@@ -72,11 +83,7 @@ func (tc *TransactionConsumer) Start(ctx context.Context) error {
 		var msg TransactionMessage
 
 		if err := json.Unmarshal(delivery.Body, &msg); err != nil {
-			logger.LogError("Failed to parse message", err)
-
-			// negative acknowledge, without requeue (discard message)
-			delivery.Nack(false, false)
-			return
+			return port.Permanent(fmt.Errorf("parse transaction message: %w", err))
 		}
 
 		logger.LogInfo(fmt.Sprintf("Receiver transaction: type=%s, From=%s, Amount=%.8f",
@@ -111,26 +118,47 @@ func (tc *TransactionConsumer) Start(ctx context.Context) error {
 				msg.Nonce,
 				msg.Amount,
 			)
+		case "CREATE_TOKEN":
+			_, err = tc.tokenService.CreateToken(ctx, dto.CreateTokenRequest{
+				Address:   msg.Address,
+				Symbol:    msg.Token,
+				Name:      msg.TokenName,
+				Decimals:  msg.Decimals,
+				Supply:    msg.Amount,
+				Nonce:     msg.Nonce,
+				Signature: msg.Signature,
+			})
+		case "TOKEN_TRANSFER":
+			_, err = tc.tokenService.TransferToken(ctx, dto.TokenTransferRequest{
+				FromAddress: msg.Address,
+				ToAddress:   msg.ToAddress,
+				Symbol:      msg.Token,
+				Amount:      msg.Amount,
+				Nonce:       msg.Nonce,
+				Signature:   msg.Signature,
+			})
 		default:
-			err = fmt.Errorf("unknown transaction type: %s", msg.Type)
+			err = port.Permanent(fmt.Errorf("%w: %s", entity.ErrInvalidTransactionType, msg.Type))
 		}
 
 		if err != nil {
 			logger.LogError(fmt.Sprintf("Error processing %s transaction", msg.Type), err)
-			// negative acknowledge, with requeue
-			delivery.Nack(false, true)
-			return
+
+			// trading di-halt: order ditolak seperti saat submit langsung, tidak di-retry
+			if errors.Is(err, entity.ErrTradingHalted) {
+				return nil
+			}
+
+			return permanentTransactionError(err)
 		}
 
 		// successfully processed
 		logger.LogInfo(fmt.Sprintf("Successfully processed %s transaction from %s", msg.Type, msg.Address))
-
-		// Positive acknowledge
-		delivery.Ack(false)
+		return nil
 	}
 
 	// start consuming with multiple workers
-	if err := tc.client.Consume(rabbitmq.TransactionPendingQueue, tc.workerCount, handler); err != nil {
+	if err := tc.client.ConsumeWithRetry(stopContext(tc.stopChan), rabbitmq.TransactionPendingQueue, tc.workerCount, handler); err != nil {
 		tc.isRunning = false
 		return fmt.Errorf("[TRANSACTION_CONSUMER] failed to start consuming: %w", err)
 	}
@@ -139,6 +167,22 @@ func (tc *TransactionConsumer) Start(ctx context.Context) error {
 	return nil
 }
 
+// permanentTransactionError - signature, nonce dan validasi tidak akan berubah saat retry
+// (nonce sudah terpakai atau memang salah), langsung diparkir di dead letter queue
+func permanentTransactionError(err error) error {
+	switch {
+	case errors.Is(err, entity.ErrSignatureVerificationFailed),
+		errors.Is(err, entity.ErrInvalidNonce),
+		errors.Is(err, entity.ErrAmountMustBePositive),
+		errors.Is(err, entity.ErrInvalidInput),
+		errors.Is(err, entity.ErrInvalidTokenDefinition),
+		errors.Is(err, entity.ErrInvalidTransactionType):
+		return port.Permanent(err)
+	default:
+		return err
+	}
+}
+
 func (tc *TransactionConsumer) Stop() {
 	tc.mu.Lock()
 	defer tc.mu.Unlock()
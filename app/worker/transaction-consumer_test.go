@@ -0,0 +1,37 @@
+package worker
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/livingdolls/go-blockchain-simulate/app/entity"
+	"github.com/livingdolls/go-blockchain-simulate/app/port"
+)
+
+func TestPermanentTransactionError(t *testing.T) {
+	cases := []struct {
+		name      string
+		err       error
+		permanent bool
+	}{
+		{"invalid nonce", fmt.Errorf("%w: %w", entity.ErrSignatureVerificationFailed, entity.ErrInvalidNonce), true},
+		{"bad signature", fmt.Errorf("%w: address mismatch", entity.ErrSignatureVerificationFailed), true},
+		{"non-positive amount", entity.ErrAmountMustBePositive, true},
+		{"same address", fmt.Errorf("%w: cannot send to the same address", entity.ErrInvalidInput), true},
+		{"invalid token", fmt.Errorf("%w: symbol", entity.ErrInvalidTokenDefinition), true},
+		{"database", fmt.Errorf("insert transaction: %w", errors.New("connection refused")), false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := permanentTransactionError(tc.err)
+			if got := port.IsPermanent(err); got != tc.permanent {
+				t.Fatalf("IsPermanent = %v, want %v", got, tc.permanent)
+			}
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("classified error no longer wraps %v", tc.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+// candles-backfill membangun ulang candle dari market_ticks untuk rentang waktu tertentu.
+//
+//	go run ./cmd/candles-backfill -pair YTE-USD -interval all -from 2026-01-01T00:00:00Z -to 2026-01-02T00:00:00Z
+//
+// -from / -to menerima RFC3339 atau unix seconds, -to default sekarang.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/livingdolls/go-blockchain-simulate/app/dto"
+	"github.com/livingdolls/go-blockchain-simulate/app/repository"
+	"github.com/livingdolls/go-blockchain-simulate/app/services"
+	"github.com/livingdolls/go-blockchain-simulate/database"
+	"github.com/livingdolls/go-blockchain-simulate/logger"
+	"github.com/livingdolls/go-blockchain-simulate/utils"
+)
+
+func main() {
+	pair := flag.String("pair", "", "trading pair (default YTE-USD)")
+	interval := flag.String("interval", "all", "candle interval (1m, 5m, 15m, 30m, 1h, 4h, 1d) or all")
+	fromStr := flag.String("from", "", "start time, RFC3339 or unix seconds (required)")
+	toStr := flag.String("to", "", "end time (exclusive), RFC3339 or unix seconds (default now)")
+	fill := flag.Bool("fill", true, "fill intervals without ticks with flat candles (previous close, zero volume)")
+	flag.Parse()
+
+	from, err := utils.ParseTime(*fromStr)
+	if err != nil {
+		exit(fmt.Errorf("invalid -from: %w", err))
+	}
+
+	to := time.Now().Unix()
+	if *toStr != "" {
+		if to, err = utils.ParseTime(*toStr); err != nil {
+			exit(fmt.Errorf("invalid -to: %w", err))
+		}
+	}
+
+	intervals := utils.CandleIntervals
+	if *interval != "all" {
+		if !dto.IsValidInterval(*interval) {
+			exit(fmt.Errorf("invalid -interval: %s", *interval))
+		}
+		intervals = []string{*interval}
+	}
+
+	if err := logger.Init(logger.DevelopmentConfig("candles-backfill", "1.0.0")); err != nil {
+		exit(err)
+	}
+	defer logger.Shutdown(5 * time.Second)
+
+	db, err := database.NewDBConn()
+	if err != nil {
+		exit(err)
+	}
+	defer db.Close()
+
+	// tanpa stream: candle hasil backfill tidak dipublish ke SSE
+	candleService := services.NewCandleService(repository.NewCandleRepository(db.GetDB()), nil, nil, services.CandleConfig{FillGaps: *fill})
+	normalizedPair := services.NormalizePair(*pair)
+
+	ctx := context.Background()
+	for _, iv := range intervals {
+		count, err := candleService.RebuildCandles(ctx, normalizedPair, iv, from, to)
+		if err != nil {
+			exit(fmt.Errorf("rebuild %s: %w", iv, err))
+		}
+
+		fmt.Printf("%s %s: %d candles rebuilt\n", normalizedPair, iv, count)
+	}
+}
+
+func exit(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
@@ -0,0 +1,159 @@
+// ledger-rebuild me-replay semua block, ledger dan history dari genesis lalu membandingkan hasilnya
+// dengan user_wallets, user_balances, asset_balances dan market_engine YTE-USD. pair lain hanya dicantumkan
+// sebagai tidak diperiksa.
+//
+//	go run ./cmd/ledger-rebuild                 # dry run, laporan selisih
+//	go run ./cmd/ledger-rebuild -format json    # laporan lengkap dalam JSON
+//	go run ./cmd/ledger-rebuild -apply          # tulis ulang baris yang berbeda
+//
+// exit code 0 = konsisten (atau sudah ditulis ulang), 1 = error, 2 = ada selisih (dry run).
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+	"github.com/livingdolls/go-blockchain-simulate/app/repository"
+	"github.com/livingdolls/go-blockchain-simulate/app/services"
+	"github.com/livingdolls/go-blockchain-simulate/database"
+	"github.com/livingdolls/go-blockchain-simulate/logger"
+)
+
+func main() {
+	apply := flag.Bool("apply", false, "rewrite user_wallets, user_balances and market_engine rows that differ from the replay")
+	tolerance := flag.Float64("tolerance", services.DefaultRebuildTolerance, "difference still treated as equal (USD is compared to the cent)")
+	format := flag.String("format", "text", "report format: text or json")
+	flag.Parse()
+
+	if *format != "text" && *format != "json" {
+		exit(fmt.Errorf("invalid -format: %s", *format))
+	}
+
+	if err := logger.Init(logger.DevelopmentConfig("ledger-rebuild", "1.0.0")); err != nil {
+		exit(err)
+	}
+	defer logger.Shutdown(5 * time.Second)
+
+	db, err := database.NewDBConn()
+	if err != nil {
+		exit(err)
+	}
+	defer db.Close()
+
+	marketRepo := repository.NewMarketRepository(db.GetDB())
+	rebuildService := services.NewLedgerRebuildService(
+		repository.NewLedgerRebuildRepository(db.GetDB()),
+		marketRepo,
+		services.NewMarketEngineService(marketRepo),
+	)
+
+	report, err := rebuildService.Rebuild(context.Background(), services.LedgerRebuildOptions{
+		Apply:     *apply,
+		Tolerance: *tolerance,
+	})
+	if err != nil {
+		exit(fmt.Errorf("rebuild: %w", err))
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			exit(err)
+		}
+	} else {
+		printReport(os.Stdout, report)
+	}
+
+	if !report.Consistent && !report.Applied {
+		os.Exit(2)
+	}
+}
+
+func printReport(out io.Writer, r models.LedgerRebuildReport) {
+	fmt.Fprintf(out, "replayed %d blocks, %d transactions, %d ledger entries, %d token ledger entries, %d mining rewards, %d deposits\n",
+		r.Blocks, r.Transactions, r.LedgerEntries, r.TokenLedgerEntries, r.MiningRewards, r.Deposits)
+	fmt.Fprintf(out, "checked %d wallets, %d USD balances, %d asset balances, %d market ticks (tolerance %g)\n\n",
+		r.WalletsChecked, r.BalancesChecked, r.AssetBalancesChecked, r.TicksChecked, r.Tolerance)
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+
+	fmt.Fprintf(out, "user_wallets: %d differences\n", len(r.WalletDiffs))
+	if len(r.WalletDiffs) > 0 {
+		fmt.Fprintln(w, "address\texpected\tactual\tdifference\t")
+		for _, d := range r.WalletDiffs {
+			fmt.Fprintf(w, "%s\t%.8f\t%.8f\t%+.8f\t%s\n", d.Address, d.Expected, d.Actual, d.Difference, missingNote(d.Missing))
+		}
+		w.Flush()
+	}
+
+	fmt.Fprintf(out, "\nuser_balances: %d differences\n", len(r.USDBalanceDiffs))
+	if len(r.USDBalanceDiffs) > 0 {
+		fmt.Fprintln(w, "address\texpected\tactual\tdifference\tdeposited\twithdrawn\ttraded\t")
+		for _, d := range r.USDBalanceDiffs {
+			fmt.Fprintf(w, "%s\t%.2f\t%.2f\t%+.2f\t%.2f/%.2f\t%.2f/%.2f\t%.2f/%.2f\t%s\n",
+				d.Address, d.Expected.USDBalance, d.Actual.USDBalance, d.Difference,
+				d.Expected.TotalDeposited, d.Actual.TotalDeposited,
+				d.Expected.TotalWithdrawn, d.Actual.TotalWithdrawn,
+				d.Expected.TotalTraded, d.Actual.TotalTraded,
+				missingNote(d.Missing))
+		}
+		w.Flush()
+	}
+
+	fmt.Fprintf(out, "\nasset_balances: %d differences\n", len(r.AssetBalanceDiffs))
+	if len(r.AssetBalanceDiffs) > 0 {
+		fmt.Fprintln(w, "address\tasset\texpected\tactual\tdifference\t")
+		for _, d := range r.AssetBalanceDiffs {
+			fmt.Fprintf(w, "%s\t%s\t%.8f\t%.8f\t%+.8f\t%s\n", d.Address, d.Asset, d.Expected, d.Actual, d.Difference, missingNote(d.Missing))
+		}
+		w.Flush()
+	}
+
+	m := r.Market
+	fmt.Fprintf(out, "\nmarket_engine %s: match=%t\n", m.Expected.Pair, m.Match)
+	fmt.Fprintf(out, "  expected price %.8f liquidity %.8f last_block %d\n", m.Expected.Price, m.Expected.Liquidity, m.Expected.LastBlock)
+	fmt.Fprintf(out, "  actual   price %.8f liquidity %.8f last_block %d\n", m.Actual.Price, m.Actual.Liquidity, m.Actual.LastBlock)
+
+	fmt.Fprintf(out, "\nmarket_ticks: %d mismatches\n", r.TickMismatches)
+	if r.FirstTickDiff != nil {
+		fmt.Fprintf(out, "  first at block #%d: recorded %.8f, replayed %.8f\n", r.FirstTickDiff.BlockNumber, r.FirstTickDiff.Recorded, r.FirstTickDiff.Replayed)
+	}
+
+	if len(r.UncheckedPairs) > 0 {
+		fmt.Fprintf(out, "\nnot checked (no replayable history): market_engine / market_ticks of %s\n", strings.Join(r.UncheckedPairs, ", "))
+	}
+	if len(r.UncheckedWallets) > 0 {
+		fmt.Fprintf(out, "not checked (not maintained by blocks): yte_balance of %s\n", strings.Join(r.UncheckedWallets, ", "))
+	}
+
+	switch {
+	case r.Applied:
+		fmt.Fprintf(out, "\napplied: %d wallets, %d USD balances, %d asset balances rewritten, market_engine rewritten=%t\n",
+			r.WalletsRewritten, r.BalancesRewritten, r.AssetBalancesRewritten, r.MarketRewritten)
+	case r.Consistent:
+		fmt.Fprintln(out, "\nmaterialized state is consistent with the ledger")
+	default:
+		fmt.Fprintln(out, "\nrun with -apply to rewrite the differing rows")
+	}
+}
+
+func missingNote(missing bool) string {
+	if missing {
+		return "missing row"
+	}
+	return ""
+}
+
+func exit(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
@@ -0,0 +1,128 @@
+// market-export menulis data market historis ke file, default format columnar (.ycol).
+//
+//	go run ./cmd/market-export candles -pair YTE-USD -interval 1m -from 2026-01-01T00:00:00Z -out candles.ycol
+//	go run ./cmd/market-export ticks -from 2026-01-01T00:00:00Z -format csv -out ticks.csv
+//	go run ./cmd/market-export trades -from 1767225600 -format ndjson
+//
+// subcommand: candles | ticks | trades. -from / -to menerima RFC3339 atau unix seconds, -to default sekarang.
+// Tanpa -out file ditulis ke <dataset>_<pair>_<from>_<to>.<ext>, -out - untuk stdout.
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/livingdolls/go-blockchain-simulate/app/export"
+	"github.com/livingdolls/go-blockchain-simulate/app/repository"
+	"github.com/livingdolls/go-blockchain-simulate/app/services"
+	"github.com/livingdolls/go-blockchain-simulate/database"
+	"github.com/livingdolls/go-blockchain-simulate/logger"
+	"github.com/livingdolls/go-blockchain-simulate/utils"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	dataset := os.Args[1]
+	switch dataset {
+	case services.ExportDatasetCandles, services.ExportDatasetTicks, services.ExportDatasetTrades:
+	default:
+		usage()
+	}
+
+	fs := flag.NewFlagSet(dataset, flag.ExitOnError)
+	pair := fs.String("pair", "", "trading pair (default YTE-USD)")
+	interval := fs.String("interval", "1m", "candle interval, only for candles")
+	fromStr := fs.String("from", "", "start time, RFC3339 or unix seconds (required)")
+	toStr := fs.String("to", "", "end time (exclusive), RFC3339 or unix seconds (default now)")
+	format := fs.String("format", export.FormatColumnar, "output format: columnar, csv or ndjson")
+	out := fs.String("out", "", "output file, - for stdout")
+	fs.Parse(os.Args[2:])
+
+	from, err := utils.ParseTime(*fromStr)
+	if err != nil {
+		exit(fmt.Errorf("invalid -from: %w", err))
+	}
+
+	var to int64
+	if *toStr != "" {
+		if to, err = utils.ParseTime(*toStr); err != nil {
+			exit(fmt.Errorf("invalid -to: %w", err))
+		}
+	}
+
+	if err := logger.Init(logger.DevelopmentConfig("market-export", "1.0.0")); err != nil {
+		exit(err)
+	}
+	defer logger.Shutdown(5 * time.Second)
+
+	db, err := database.NewDBConn()
+	if err != nil {
+		exit(err)
+	}
+	defer db.Close()
+
+	exportService := services.NewExportService(repository.NewExportRepository(db.GetDB()))
+
+	query, err := exportService.Validate(*format, services.ExportQuery{
+		Dataset:  dataset,
+		Pair:     *pair,
+		Interval: *interval,
+		From:     from,
+		To:       to,
+	})
+	if err != nil {
+		exit(err)
+	}
+
+	path := *out
+	if path == "" {
+		path = fmt.Sprintf("%s_%s_%d_%d.%s", dataset, query.Pair, query.From, query.To, export.FileExtension(*format))
+	}
+
+	var w io.Writer = os.Stdout
+	if path != "-" {
+		file, err := os.Create(path)
+		if err != nil {
+			exit(err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	buffered := bufio.NewWriterSize(w, 1<<20)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	rows, err := exportService.Export(ctx, buffered, *format, query)
+	if err != nil {
+		exit(fmt.Errorf("export %s: %w", dataset, err))
+	}
+
+	if err := buffered.Flush(); err != nil {
+		exit(err)
+	}
+
+	if path != "-" {
+		fmt.Fprintf(os.Stderr, "%s %s: %d rows written to %s\n", dataset, query.Pair, rows, path)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: market-export <candles|ticks|trades> -from <time> [-to <time>] [-pair YTE-USD] [-interval 1m] [-format columnar|csv|ndjson] [-out file]")
+	os.Exit(2)
+}
+
+func exit(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
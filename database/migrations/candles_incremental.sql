@@ -0,0 +1,4 @@
+-- candle diupdate incremental dari event market.pricing
+-- last_block_id mencegah event yang sama (redelivery) dihitung dua kali
+ALTER TABLE candles
+ADD COLUMN last_block_id BIGINT NOT NULL DEFAULT 0 AFTER volume;
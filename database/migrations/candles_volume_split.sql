@@ -0,0 +1,5 @@
+-- buy/sell volume dan jumlah transaksi per candle
+ALTER TABLE candles
+ADD COLUMN buy_volume DECIMAL(20, 8) NOT NULL DEFAULT 0 AFTER volume,
+ADD COLUMN sell_volume DECIMAL(20, 8) NOT NULL DEFAULT 0 AFTER buy_volume,
+ADD COLUMN trade_count INT NOT NULL DEFAULT 0 AFTER sell_volume;
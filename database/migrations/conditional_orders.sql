@@ -0,0 +1,22 @@
+CREATE TABLE conditional_orders (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_address VARCHAR(255) NOT NULL,
+    order_type ENUM('STOP_LOSS', 'TAKE_PROFIT', 'TRAILING_STOP') NOT NULL,
+    side ENUM('BUY', 'SELL') NOT NULL,
+    amount DECIMAL(20, 8) NOT NULL,
+    trigger_price DECIMAL(20, 8) NOT NULL DEFAULT 0,
+    trail_percent DECIMAL(10, 4) NOT NULL DEFAULT 0,
+    reference_price DECIMAL(20, 8) NOT NULL DEFAULT 0,
+    signature TEXT NOT NULL,
+    status ENUM('ACTIVE', 'TRIGGERED', 'EXECUTED', 'FAILED', 'CANCELLED') NOT NULL DEFAULT 'ACTIVE',
+    triggered_price DECIMAL(20, 8) NULL,
+    triggered_block BIGINT NULL,
+    tx_id BIGINT NULL,
+    failure_reason VARCHAR(255) NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    INDEX idx_conditional_status (status),
+    INDEX idx_conditional_user (user_address, status),
+    FOREIGN KEY (tx_id) REFERENCES transactions(id)
+);
@@ -0,0 +1,42 @@
+-- ledger ditulis di transaction block, leg membedakan entry dalam satu transaksi:
+-- debit pengirim, credit penerima, fee miner, debit/credit token.
+-- jalankan setelah tokens.sql (kolom asset)
+ALTER TABLE ledger ADD COLUMN leg VARCHAR(16) NOT NULL DEFAULT '' AFTER asset;
+
+-- duplikat persis dari redelivery consumer persistence lama, simpan yang pertama.
+-- <=> agar entry dengan tx_id NULL juga dianggap sama
+DELETE newer FROM ledger newer
+JOIN ledger older
+    ON older.block_id = newer.block_id
+    AND older.tx_id <=> newer.tx_id
+    AND older.address = newer.address
+    AND older.asset = newer.asset
+    AND older.change_amount = newer.change_amount
+    AND older.balance_after = newer.balance_after
+    AND older.id < newer.id;
+
+-- entry lama: leg diturunkan dari asset, arah dan address
+UPDATE ledger SET leg = CASE
+    WHEN asset <> 'YTE' AND change_amount < 0 THEN 'token_debit'
+    WHEN asset <> 'YTE' THEN 'token_credit'
+    WHEN change_amount < 0 THEN 'debit'
+    WHEN address = 'MINER_ACCOUNT' THEN 'fee'
+    ELSE 'credit'
+END
+WHERE leg = '';
+
+-- transaksi ke MINER_ACCOUNT: entry credit ditulis sebelum entry fee
+UPDATE ledger credit
+JOIN ledger fee
+    ON fee.block_id = credit.block_id
+    AND fee.tx_id <=> credit.tx_id
+    AND fee.address = credit.address
+    AND fee.leg = 'fee'
+    AND fee.id > credit.id
+SET credit.leg = 'credit'
+WHERE credit.leg = 'fee';
+
+-- unique key mengabaikan baris yang salah satu kolomnya NULL, tx_id NULL dipetakan ke 0 (id transaksi mulai dari 1)
+ALTER TABLE ledger
+    ADD COLUMN tx_key BIGINT AS (COALESCE(tx_id, 0)) STORED AFTER tx_id,
+    ADD UNIQUE KEY uq_ledger_entry (block_id, tx_key, address, leg);
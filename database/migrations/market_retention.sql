@@ -0,0 +1,22 @@
+-- Arsip market_ticks yang sudah melewati masa retensi (OHLC per bucket)
+CREATE TABLE market_tick_archives (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    pair VARCHAR(32) NOT NULL,
+    interval_type ENUM('1m', '5m', '15m', '30m', '1h', '4h', '1d') NOT NULL,
+    bucket_start BIGINT NOT NULL,
+
+    open_price DECIMAL(20, 8) NOT NULL,
+    high_price DECIMAL(20, 8) NOT NULL,
+    low_price DECIMAL(20, 8) NOT NULL,
+    close_price DECIMAL(20, 8) NOT NULL,
+    buy_volume DECIMAL(20, 8) NOT NULL DEFAULT 0,
+    sell_volume DECIMAL(20, 8) NOT NULL DEFAULT 0,
+    tx_count INT NOT NULL DEFAULT 0,
+    tick_count INT NOT NULL DEFAULT 0,
+    first_block_id BIGINT NOT NULL,
+    last_block_id BIGINT NOT NULL,
+    archived_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE KEY uniq_tick_archive (pair, interval_type, bucket_start)
+);
+
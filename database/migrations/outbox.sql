@@ -0,0 +1,20 @@
+-- Event RabbitMQ yang ditulis dalam SQL transaction yang sama dengan block (transactional outbox),
+-- diklaim relay (IN_FLIGHT sampai next_attempt_at) lalu dipublish di luar transaction dan ditandai SENT
+CREATE TABLE outbox_events (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    aggregate_type VARCHAR(32) NOT NULL,
+    aggregate_id BIGINT NOT NULL,
+    exchange_name VARCHAR(64) NOT NULL,
+    routing_key VARCHAR(128) NOT NULL,
+    payload LONGTEXT NOT NULL,
+    status ENUM('PENDING', 'IN_FLIGHT', 'SENT') NOT NULL DEFAULT 'PENDING',
+    attempts INT NOT NULL DEFAULT 0,
+    last_error VARCHAR(512) NULL,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    sent_at TIMESTAMP NULL,
+
+    INDEX idx_outbox_due (status, next_attempt_at, id),
+    INDEX idx_outbox_aggregate (aggregate_type, aggregate_id),
+    INDEX idx_outbox_sent_at (sent_at)
+);
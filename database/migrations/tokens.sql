@@ -0,0 +1,30 @@
+-- Token fungible buatan user (CREATE_TOKEN / TOKEN_TRANSFER)
+ALTER TABLE transactions
+MODIFY COLUMN type ENUM('TRANSFER', 'BUY', 'SELL', 'CREATE_TOKEN', 'TOKEN_TRANSFER') NOT NULL DEFAULT 'TRANSFER';
+
+ALTER TABLE transactions
+ADD COLUMN token_symbol VARCHAR(16) NOT NULL DEFAULT '' AFTER type,
+ADD INDEX idx_transactions_token (token_symbol, status);
+
+-- definisi token, symbol sudah direservasi sejak transaksi CREATE_TOKEN masuk pending
+-- saat block dikonfirmasi token menjadi ACTIVE dan didaftarkan ke tabel assets
+CREATE TABLE tokens (
+    symbol VARCHAR(16) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    decimals INT NOT NULL DEFAULT 8,
+    total_supply DECIMAL(30, 8) NOT NULL,
+    creator_address VARCHAR(255) NOT NULL,
+    create_tx_id BIGINT NOT NULL,
+    block_id BIGINT NULL,
+    status ENUM('PENDING', 'ACTIVE', 'FAILED') NOT NULL DEFAULT 'PENDING',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    INDEX idx_tokens_creator (creator_address),
+    INDEX idx_tokens_status (status),
+    FOREIGN KEY (create_tx_id) REFERENCES transactions(id)
+);
+
+-- ledger mencatat asset per entry, entry lama adalah YTE
+ALTER TABLE ledger
+ADD COLUMN asset VARCHAR(16) NOT NULL DEFAULT 'YTE' AFTER address,
+ADD INDEX idx_ledger_address_asset (address, asset);
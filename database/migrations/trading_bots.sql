@@ -0,0 +1,23 @@
+-- Trading bots untuk simulasi likuiditas (market maker, momentum, dll)
+-- Bot adalah user biasa (terdaftar di tabel users) yang punya private key sendiri
+-- private_key disimpan terenkripsi AES-256-GCM ("v1:" + base64), key dari env BOT_KEY_ENCRYPTION_KEY
+CREATE TABLE trading_bots (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    address VARCHAR(255) NOT NULL UNIQUE,
+    private_key VARCHAR(128) NOT NULL,
+    strategy ENUM('RANDOM_WALK', 'MOMENTUM', 'MEAN_REVERSION', 'MARKET_MAKER') NOT NULL,
+    status ENUM('RUNNING', 'STOPPED') NOT NULL DEFAULT 'STOPPED',
+    interval_seconds INT NOT NULL DEFAULT 5,
+    min_amount DECIMAL(20, 8) NOT NULL DEFAULT 1,
+    max_amount DECIMAL(20, 8) NOT NULL DEFAULT 10,
+    lookback INT NOT NULL DEFAULT 20,
+    threshold_percent DECIMAL(10, 4) NOT NULL DEFAULT 0.5,
+    spread_percent DECIMAL(10, 4) NOT NULL DEFAULT 1,
+    target_inventory DECIMAL(20, 8) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    INDEX idx_bot_status (status),
+    FOREIGN KEY (address) REFERENCES users(address)
+);
@@ -0,0 +1,18 @@
+-- Riwayat trading halt (circuit breaker otomatis / manual oleh admin)
+CREATE TABLE trading_halts (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    pair VARCHAR(32) NOT NULL DEFAULT 'YTE-USD',
+    source ENUM('AUTO', 'MANUAL') NOT NULL,
+    reason VARCHAR(255) NOT NULL,
+    price_change_percent DECIMAL(10, 4) NULL,
+    threshold_percent DECIMAL(10, 4) NULL,
+    block_number BIGINT NULL,
+    halted_by INT NULL,
+    halted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    resume_at TIMESTAMP NULL,
+    resumed_at TIMESTAMP NULL,
+    resumed_by INT NULL,
+
+    INDEX idx_trading_halts_halted_at (halted_at),
+    INDEX idx_trading_halts_open (resumed_at)
+);
@@ -0,0 +1,63 @@
+-- Asset registry
+CREATE TABLE assets (
+    symbol VARCHAR(16) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    decimals INT NOT NULL DEFAULT 8,
+    is_native BOOLEAN NOT NULL DEFAULT FALSE,
+    status ENUM('ACTIVE', 'DELISTED') NOT NULL DEFAULT 'ACTIVE',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO assets (symbol, name, decimals, is_native)
+VALUES ('YTE', 'Yute Token', 8, TRUE), ('USD', 'US Dollar', 2, TRUE)
+ON DUPLICATE KEY UPDATE symbol = symbol;
+
+-- Trading pairs (BASE-QUOTE)
+CREATE TABLE trading_pairs (
+    symbol VARCHAR(32) PRIMARY KEY,
+    base_asset VARCHAR(16) NOT NULL,
+    quote_asset VARCHAR(16) NOT NULL,
+    status ENUM('ACTIVE', 'DELISTED') NOT NULL DEFAULT 'ACTIVE',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE KEY uniq_pair_assets (base_asset, quote_asset),
+    FOREIGN KEY (base_asset) REFERENCES assets(symbol),
+    FOREIGN KEY (quote_asset) REFERENCES assets(symbol)
+);
+
+INSERT INTO trading_pairs (symbol, base_asset, quote_asset)
+VALUES ('YTE-USD', 'YTE', 'USD')
+ON DUPLICATE KEY UPDATE symbol = symbol;
+
+-- Saldo per asset untuk asset non-native
+-- YTE tetap di user_wallets dan USD tetap di user_balances
+CREATE TABLE asset_balances (
+    user_address VARCHAR(255) NOT NULL,
+    asset_symbol VARCHAR(16) NOT NULL,
+    balance DECIMAL(30, 8) NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (user_address, asset_symbol),
+    INDEX idx_asset_balance_symbol (asset_symbol),
+    FOREIGN KEY (asset_symbol) REFERENCES assets(symbol)
+);
+
+-- market_engine: satu row per pair (sebelumnya hanya id = 1)
+ALTER TABLE market_engine DROP CHECK market_engine_chk_1;
+ALTER TABLE market_engine MODIFY COLUMN id INT NOT NULL AUTO_INCREMENT;
+ALTER TABLE market_engine
+ADD COLUMN pair VARCHAR(32) NOT NULL DEFAULT 'YTE-USD' AFTER id,
+ADD UNIQUE KEY uniq_market_pair (pair);
+
+-- market_ticks per pair
+ALTER TABLE market_ticks
+ADD COLUMN pair VARCHAR(32) NOT NULL DEFAULT 'YTE-USD' AFTER id,
+ADD INDEX idx_tick_pair_block (pair, block_id),
+ADD INDEX idx_tick_pair_created (pair, created_at);
+
+-- candles per pair
+ALTER TABLE candles
+ADD COLUMN pair VARCHAR(32) NOT NULL DEFAULT 'YTE-USD' AFTER id,
+DROP INDEX uniq_candle,
+ADD UNIQUE KEY uniq_candle (pair, interval_type, start_time),
+ADD INDEX idx_candle_pair_interval_time (pair, interval_type, start_time);
@@ -0,0 +1,678 @@
+package membroker
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+	"github.com/livingdolls/go-blockchain-simulate/app/port"
+	"github.com/livingdolls/go-blockchain-simulate/logger"
+	"github.com/livingdolls/go-blockchain-simulate/rabbitmq"
+	"github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+)
+
+// MemoryBroker - broker dalam proses dengan semantik RabbitMQ yang dipakai aplikasi: exchange direct/fanout/topic,
+// default exchange, mandatory + confirm, ack/nack/requeue, x-message-ttl dan dead-letter exchange (argumen atau policy) sehingga
+// retry dan parking dari rabbitmq.DeadLetterTopology berjalan sama. pesan hilang saat proses berhenti, untuk test dan demo lokal
+type MemoryBroker struct {
+	mu        sync.RWMutex
+	exchanges map[string]string
+	queues    map[string]*memoryQueue
+	binds     []models.BindDef
+	policies  map[string]memoryPolicy
+
+	tags      atomic.Uint64
+	close     chan struct{}
+	closeOnce sync.Once
+}
+
+type memoryPolicy struct {
+	def     models.PolicyDef
+	pattern *regexp.Regexp
+}
+
+type memoryMessage struct {
+	exchange    string
+	routingKey  string
+	publishing  amqp091.Publishing
+	redelivered bool
+}
+
+type memoryQueue struct {
+	broker *MemoryBroker
+	name   string
+	ttl    time.Duration
+	dlx    *string
+	dlxKey string
+	// dlxFromArgs - argumen queue menang atas policy, sama dengan RabbitMQ
+	dlxFromArgs bool
+
+	mu      sync.Mutex
+	ready   []*memoryMessage
+	unacked map[uint64]*memoryMessage
+	// signal - buffer 1, dikirim ulang oleh pop selama masih ada pesan agar worker lain ikut bangun
+	signal chan struct{}
+}
+
+var _ port.MessageBroker = (*MemoryBroker)(nil)
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{
+		exchanges: make(map[string]string),
+		queues:    make(map[string]*memoryQueue),
+		policies:  make(map[string]memoryPolicy),
+		close:     make(chan struct{}),
+	}
+}
+
+func (b *MemoryBroker) DeclareExchange(e models.ExchangeDef) error {
+	switch e.Kind {
+	case "direct", "fanout", "topic":
+	default:
+		return fmt.Errorf("[MEMORY_BROKER] unsupported exchange kind %q for %s", e.Kind, e.Name)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if kind, ok := b.exchanges[e.Name]; ok && kind != e.Kind {
+		return fmt.Errorf("[MEMORY_BROKER] exchange %s already declared as %s", e.Name, kind)
+	}
+
+	b.exchanges[e.Name] = e.Kind
+	return nil
+}
+
+func (b *MemoryBroker) DeclareQueue(q models.QueueDef) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.queues[q.Name]; ok {
+		return nil
+	}
+
+	queue := &memoryQueue{
+		broker:  b,
+		name:    q.Name,
+		unacked: make(map[uint64]*memoryMessage),
+		signal:  make(chan struct{}, 1),
+	}
+
+	if ttl := intArg(q.Args["x-message-ttl"]); ttl > 0 {
+		queue.ttl = time.Duration(ttl) * time.Millisecond
+	}
+
+	if dlx, ok := q.Args["x-dead-letter-exchange"].(string); ok {
+		queue.dlx = &dlx
+		queue.dlxKey = stringArg(q.Args["x-dead-letter-routing-key"])
+		queue.dlxFromArgs = true
+	} else {
+		b.applyPoliciesLocked(queue)
+	}
+
+	b.queues[q.Name] = queue
+	return nil
+}
+
+// ApplyPolicy - berlaku untuk queue yang sudah ada dan yang dideklarasikan setelahnya
+func (b *MemoryBroker) ApplyPolicy(p models.PolicyDef) error {
+	if p.ApplyTo != "" && p.ApplyTo != "queues" && p.ApplyTo != "all" {
+		return nil
+	}
+
+	pattern, err := regexp.Compile(p.Pattern)
+	if err != nil {
+		return fmt.Errorf("[MEMORY_BROKER] invalid policy pattern %q: %w", p.Pattern, err)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.policies[p.Name] = memoryPolicy{def: p, pattern: pattern}
+	for _, queue := range b.queues {
+		if !queue.dlxFromArgs {
+			b.applyPoliciesLocked(queue)
+		}
+	}
+
+	return nil
+}
+
+// applyPoliciesLocked - hanya policy dengan prioritas tertinggi yang cocok yang berlaku, harus dipanggil dengan b.mu dipegang
+func (b *MemoryBroker) applyPoliciesLocked(queue *memoryQueue) {
+	var best *models.PolicyDef
+	for _, p := range b.policies {
+		if p.pattern.MatchString(queue.name) && (best == nil || p.def.Priority > best.Priority) {
+			def := p.def
+			best = &def
+		}
+	}
+
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+
+	queue.dlx = nil
+	queue.dlxKey = ""
+	if best == nil {
+		return
+	}
+
+	if dlx, ok := best.Definition["dead-letter-exchange"].(string); ok {
+		queue.dlx = &dlx
+		queue.dlxKey = stringArg(best.Definition["dead-letter-routing-key"])
+	}
+}
+
+func (b *MemoryBroker) Bind(bind models.BindDef) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.exchanges[bind.Exchange]; !ok {
+		return fmt.Errorf("[MEMORY_BROKER] exchange %s not found", bind.Exchange)
+	}
+
+	if _, ok := b.queues[bind.Queue]; !ok {
+		return fmt.Errorf("[MEMORY_BROKER] queue %s not found", bind.Queue)
+	}
+
+	for _, existing := range b.binds {
+		if existing == bind {
+			return nil
+		}
+	}
+
+	b.binds = append(b.binds, bind)
+	return nil
+}
+
+// Publish - tanpa mandatory, pesan yang tidak ter-route dibuang seperti di RabbitMQ
+func (b *MemoryBroker) Publish(ctx context.Context, exchange, routingKey string, body []byte) error {
+	_, err := b.route(exchange, routingKey, amqp091.Publishing{
+		ContentType: "application/json",
+		Body:        body,
+		Timestamp:   time.Now(),
+	})
+	return err
+}
+
+func (b *MemoryBroker) PublishWithConfirm(ctx context.Context, exchange, routingKey string, body []byte) error {
+	_, err := b.PublishBatch(ctx, []port.Message{{Exchange: exchange, RoutingKey: routingKey, Body: body}})
+	return err
+}
+
+// PublishBatch - setiap pesan di-route langsung, error kedua = kegagalan pertama
+func (b *MemoryBroker) PublishBatch(ctx context.Context, msgs []port.Message) ([]error, error) {
+	now := time.Now()
+	results := make([]error, len(msgs))
+
+	var first error
+	for i, m := range msgs {
+		id := m.MessageID
+		if id == "" {
+			id = uuid.NewString()
+		}
+
+		results[i] = b.publishWithConfirm(ctx, m.Exchange, m.RoutingKey, amqp091.Publishing{
+			ContentType: "application/json",
+			MessageId:   id,
+			Body:        m.Body,
+			Timestamp:   now,
+		})
+		if results[i] != nil && first == nil {
+			first = results[i]
+		}
+	}
+
+	return results, first
+}
+
+func (b *MemoryBroker) publishWithConfirm(ctx context.Context, exchange, routingKey string, msg amqp091.Publishing) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	routed, err := b.route(exchange, routingKey, msg)
+	if err != nil {
+		return err
+	}
+
+	if routed == 0 {
+		return fmt.Errorf("%w: %s/%s", rabbitmq.ErrUnroutable, exchange, routingKey)
+	}
+
+	return nil
+}
+
+// route - salin pesan ke setiap queue yang cocok, jumlah queue dikembalikan untuk semantik mandatory
+func (b *MemoryBroker) route(exchange, routingKey string, msg amqp091.Publishing) (int, error) {
+	b.mu.RLock()
+
+	var targets []*memoryQueue
+	if exchange == "" {
+		if q, ok := b.queues[routingKey]; ok {
+			targets = append(targets, q)
+		}
+	} else {
+		kind, ok := b.exchanges[exchange]
+		if !ok {
+			b.mu.RUnlock()
+			return 0, fmt.Errorf("[MEMORY_BROKER] exchange %s not found", exchange)
+		}
+
+		seen := make(map[string]bool)
+		for _, bind := range b.binds {
+			if bind.Exchange != exchange || seen[bind.Queue] || !bindingMatches(kind, bind.RoutingKey, routingKey) {
+				continue
+			}
+
+			seen[bind.Queue] = true
+			targets = append(targets, b.queues[bind.Queue])
+		}
+	}
+
+	b.mu.RUnlock()
+
+	for _, q := range targets {
+		q.push(&memoryMessage{
+			exchange:   exchange,
+			routingKey: routingKey,
+			publishing: clonePublishing(msg),
+		})
+	}
+
+	return len(targets), nil
+}
+
+func bindingMatches(kind, pattern, routingKey string) bool {
+	switch kind {
+	case "fanout":
+		return true
+	case "topic":
+		return topicMatches(strings.Split(pattern, "."), strings.Split(routingKey, "."))
+	default:
+		return pattern == routingKey
+	}
+}
+
+// topicMatches - * tepat satu kata, # nol atau lebih kata
+func topicMatches(pattern, words []string) bool {
+	if len(pattern) == 0 {
+		return len(words) == 0
+	}
+
+	if pattern[0] == "#" {
+		for i := 0; i <= len(words); i++ {
+			if topicMatches(pattern[1:], words[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(words) == 0 || (pattern[0] != "*" && pattern[0] != words[0]) {
+		return false
+	}
+
+	return topicMatches(pattern[1:], words[1:])
+}
+
+// clonePublishing - setiap queue mendapat header sendiri, dead-lettering menambah x-death per salinan
+func clonePublishing(msg amqp091.Publishing) amqp091.Publishing {
+	headers := make(amqp091.Table, len(msg.Headers))
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+
+	msg.Headers = headers
+	return msg
+}
+
+func (b *MemoryBroker) queue(name string) (*memoryQueue, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	q, ok := b.queues[name]
+	if !ok {
+		return nil, fmt.Errorf("[MEMORY_BROKER] queue %s not found", name)
+	}
+
+	return q, nil
+}
+
+func (b *MemoryBroker) ConsumeWithContext(ctx context.Context, queueName string, workerCount int, handler rabbitmq.HandlerFunc) error {
+	q, err := b.queue(queueName)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < workerCount; i++ {
+		go q.consume(ctx, fmt.Sprintf("consumer-%s-%d", queueName, i), handler)
+	}
+
+	return nil
+}
+
+// ConsumeWithRetry - retry dan parking memakai header dan queue yang sama dengan rabbitmq.Client
+func (b *MemoryBroker) ConsumeWithRetry(ctx context.Context, queueName string, workerCount int, handler port.DeliveryHandler) error {
+	return b.ConsumeWithContext(ctx, queueName, workerCount, rabbitmq.RetryHandler(b.publishWithConfirm, queueName, handler))
+}
+
+func (b *MemoryBroker) DeadLetterStats(queues []string) ([]models.DeadLetterQueueStats, error) {
+	stats := make([]models.DeadLetterQueueStats, 0, len(queues))
+
+	for _, queue := range queues {
+		q, err := b.queue(rabbitmq.DeadLetterQueueName(queue))
+		if err != nil {
+			return nil, fmt.Errorf("inspect %s: %w", rabbitmq.DeadLetterQueueName(queue), err)
+		}
+
+		q.mu.Lock()
+		messages := len(q.ready)
+		q.mu.Unlock()
+
+		stats = append(stats, models.DeadLetterQueueStats{
+			Queue:           queue,
+			DeadLetterQueue: q.name,
+			Messages:        messages,
+		})
+	}
+
+	return stats, nil
+}
+
+func (b *MemoryBroker) PeekDeadLetters(queue string, limit int) ([]models.DeadLetter, error) {
+	q, err := b.queue(rabbitmq.DeadLetterQueueName(queue))
+	if err != nil {
+		return nil, err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	letters := make([]models.DeadLetter, 0, min(limit, len(q.ready)))
+	for _, m := range q.ready {
+		if len(letters) == limit {
+			break
+		}
+		letters = append(letters, rabbitmq.ToDeadLetter(queue, q.delivery(m, 0, "")))
+	}
+
+	return letters, nil
+}
+
+func (b *MemoryBroker) ReplayDeadLetters(ctx context.Context, queue string, limit int) (int, error) {
+	q, err := b.queue(rabbitmq.DeadLetterQueueName(queue))
+	if err != nil {
+		return 0, err
+	}
+
+	replayed := 0
+	for replayed < limit {
+		m, tag, ok := q.pop()
+		if !ok {
+			break
+		}
+
+		if err := b.publishWithConfirm(ctx, "", queue, rabbitmq.ReplayPublishing(q.delivery(m, tag, ""))); err != nil {
+			q.Nack(tag, false, true)
+			return replayed, err
+		}
+
+		q.Ack(tag, false)
+		replayed++
+	}
+
+	return replayed, nil
+}
+
+func (b *MemoryBroker) PurgeDeadLetters(queue string) (int, error) {
+	q, err := b.queue(rabbitmq.DeadLetterQueueName(queue))
+	if err != nil {
+		return 0, err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	purged := len(q.ready)
+	q.ready = nil
+	return purged, nil
+}
+
+// Close - hentikan semua consumer, pesan yang tersisa dibuang
+func (b *MemoryBroker) Close() {
+	b.closeOnce.Do(func() {
+		close(b.close)
+	})
+}
+
+func (q *memoryQueue) push(m *memoryMessage) {
+	q.mu.Lock()
+	q.ready = append(q.ready, m)
+	q.mu.Unlock()
+
+	if q.ttl > 0 {
+		time.AfterFunc(q.ttl, func() { q.expire(m) })
+	}
+
+	q.wake()
+}
+
+func (q *memoryQueue) wake() {
+	select {
+	case q.signal <- struct{}{}:
+	default:
+	}
+}
+
+func (q *memoryQueue) pop() (*memoryMessage, uint64, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.ready) == 0 {
+		return nil, 0, false
+	}
+
+	m := q.ready[0]
+	q.ready[0] = nil
+	q.ready = q.ready[1:]
+
+	tag := q.broker.tags.Add(1)
+	q.unacked[tag] = m
+
+	if len(q.ready) > 0 {
+		q.wake()
+	}
+
+	return m, tag, true
+}
+
+// expire - TTL hanya berlaku untuk pesan yang masih menunggu, bukan yang sedang diproses
+func (q *memoryQueue) expire(m *memoryMessage) {
+	q.mu.Lock()
+	found := false
+	for i, ready := range q.ready {
+		if ready == m {
+			q.ready = append(q.ready[:i], q.ready[i+1:]...)
+			found = true
+			break
+		}
+	}
+	q.mu.Unlock()
+
+	if found {
+		q.deadLetter(m, "expired")
+	}
+}
+
+// deadLetter - sama dengan broker: x-death ditambahkan lalu pesan dikirim ke x-dead-letter-exchange,
+// tanpa dead-letter exchange pesan dibuang
+func (q *memoryQueue) deadLetter(m *memoryMessage, reason string) {
+	// dlx bisa diganti ApplyPolicy
+	q.mu.Lock()
+	dlx, routingKey := q.dlx, q.dlxKey
+	q.mu.Unlock()
+
+	if dlx == nil {
+		return
+	}
+
+	if routingKey == "" {
+		routingKey = m.routingKey
+	}
+
+	publishing := clonePublishing(m.publishing)
+	publishing.Headers["x-death"] = []interface{}{amqp091.Table{
+		"queue":        q.name,
+		"reason":       reason,
+		"exchange":     m.exchange,
+		"routing-keys": []interface{}{m.routingKey},
+		"time":         time.Now(),
+		"count":        int64(1),
+	}}
+
+	if _, err := q.broker.route(*dlx, routingKey, publishing); err != nil {
+		logger.LogError("Failed to dead-letter message", err, zap.String("queue", q.name), zap.String("reason", reason))
+	}
+}
+
+func (q *memoryQueue) consume(ctx context.Context, tag string, handler rabbitmq.HandlerFunc) {
+	for ctx.Err() == nil {
+		m, deliveryTag, ok := q.pop()
+		if !ok {
+			select {
+			case <-q.signal:
+			case <-ctx.Done():
+			case <-q.broker.close:
+				return
+			}
+			continue
+		}
+
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					logger.LogError("Panic in consumer handler", fmt.Errorf("panic: %v", r), zap.String("consumerTag", tag))
+					q.Nack(deliveryTag, false, false)
+				}
+			}()
+
+			handler(q.delivery(m, deliveryTag, tag))
+		}()
+	}
+
+	logger.LogInfo("Stopped consuming from queue", zap.String("queue", q.name), zap.String("consumerTag", tag))
+}
+
+func (q *memoryQueue) delivery(m *memoryMessage, deliveryTag uint64, consumerTag string) amqp091.Delivery {
+	return amqp091.Delivery{
+		Acknowledger:  q,
+		Headers:       m.publishing.Headers,
+		ContentType:   m.publishing.ContentType,
+		DeliveryMode:  m.publishing.DeliveryMode,
+		CorrelationId: m.publishing.CorrelationId,
+		MessageId:     m.publishing.MessageId,
+		Timestamp:     m.publishing.Timestamp,
+		Type:          m.publishing.Type,
+		ConsumerTag:   consumerTag,
+		DeliveryTag:   deliveryTag,
+		Redelivered:   m.redelivered,
+		Exchange:      m.exchange,
+		RoutingKey:    m.routingKey,
+		Body:          m.publishing.Body,
+	}
+}
+
+// settled - ambil pesan unacked untuk tag (atau semua <= tag jika multiple) sesuai urutan tag
+func (q *memoryQueue) settled(tag uint64, multiple bool) ([]*memoryMessage, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !multiple {
+		m, ok := q.unacked[tag]
+		if !ok {
+			return nil, fmt.Errorf("[MEMORY_BROKER] unknown delivery tag %d on %s", tag, q.name)
+		}
+		delete(q.unacked, tag)
+		return []*memoryMessage{m}, nil
+	}
+
+	tags := make([]uint64, 0, len(q.unacked))
+	for t := range q.unacked {
+		if t <= tag {
+			tags = append(tags, t)
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i] < tags[j] })
+
+	msgs := make([]*memoryMessage, len(tags))
+	for i, t := range tags {
+		msgs[i] = q.unacked[t]
+		delete(q.unacked, t)
+	}
+
+	return msgs, nil
+}
+
+// Ack implements [amqp091.Acknowledger].
+func (q *memoryQueue) Ack(tag uint64, multiple bool) error {
+	_, err := q.settled(tag, multiple)
+	return err
+}
+
+// Nack implements [amqp091.Acknowledger].
+func (q *memoryQueue) Nack(tag uint64, multiple bool, requeue bool) error {
+	msgs, err := q.settled(tag, multiple)
+	if err != nil {
+		return err
+	}
+
+	if !requeue {
+		for _, m := range msgs {
+			q.deadLetter(m, "rejected")
+		}
+		return nil
+	}
+
+	q.mu.Lock()
+	for _, m := range msgs {
+		m.redelivered = true
+	}
+	q.ready = append(msgs, q.ready...)
+	q.mu.Unlock()
+
+	q.wake()
+	return nil
+}
+
+// Reject implements [amqp091.Acknowledger].
+func (q *memoryQueue) Reject(tag uint64, requeue bool) error {
+	return q.Nack(tag, false, requeue)
+}
+
+// intArg - argumen queue numerik (x-message-ttl) bisa berupa tipe integer apa pun
+func intArg(v interface{}) int {
+	switch n := v.(type) {
+	case int:
+		return n
+	case int32:
+		return int(n)
+	case int64:
+		return int(n)
+	default:
+		return 0
+	}
+}
+
+func stringArg(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
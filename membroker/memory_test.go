@@ -0,0 +1,225 @@
+package membroker
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+	"github.com/livingdolls/go-blockchain-simulate/app/port"
+	"github.com/livingdolls/go-blockchain-simulate/logger"
+	"github.com/livingdolls/go-blockchain-simulate/rabbitmq"
+	"github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	logger.L = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+// newTopologyBroker - broker dengan topology dead letter yang sama seperti SetupRabbitMQTopology
+func newTopologyBroker(t *testing.T, queue string) *MemoryBroker {
+	t.Helper()
+
+	b := NewMemoryBroker()
+	t.Cleanup(b.Close)
+
+	queues, binds, policies := rabbitmq.DeadLetterTopology([]models.QueueDef{{Name: queue, Durable: true}})
+
+	if len(queues[0].Args) != 0 {
+		t.Fatalf("work queue must be declared without arguments, got %v", queues[0].Args)
+	}
+
+	if err := b.DeclareExchange(models.ExchangeDef{Name: rabbitmq.DeadLetterExchange, Kind: "direct", Durable: true}); err != nil {
+		t.Fatal(err)
+	}
+	for _, q := range queues {
+		if err := b.DeclareQueue(q); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, bind := range binds {
+		if err := b.Bind(bind); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, p := range policies {
+		if err := b.ApplyPolicy(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return b
+}
+
+// waitDeadLetters - poll parking queue sampai berisi want pesan
+func waitDeadLetters(t *testing.T, b *MemoryBroker, queue string, want int) []models.DeadLetter {
+	t.Helper()
+
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		letters, err := b.PeekDeadLetters(queue, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(letters) == want {
+			return letters
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("dead letters = %d, want %d", len(letters), want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestMemoryBrokerDeadLetterPolicy(t *testing.T) {
+	const queue = "work"
+	b := newTopologyBroker(t, queue)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := b.ConsumeWithContext(ctx, queue, 1, func(msg amqp091.Delivery) {
+		msg.Nack(false, false)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := b.PublishWithConfirm(ctx, "", queue, []byte(`{"id":1}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	letters := waitDeadLetters(t, b, queue, 1)
+	if letters[0].Body != `{"id":1}` || letters[0].Reason != "rejected" {
+		t.Fatalf("unexpected dead letter %+v", letters[0])
+	}
+}
+
+func TestMemoryBrokerConsumeWithRetry(t *testing.T) {
+	const queue = "work"
+	b := newTopologyBroker(t, queue)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		attempts []int
+	)
+	done := make(chan struct{})
+
+	if err := b.ConsumeWithRetry(ctx, queue, 1, func(d port.Delivery) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		attempts = append(attempts, d.Attempts)
+		if d.Attempts == 0 {
+			return errors.New("temporary failure")
+		}
+
+		close(done)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := b.PublishWithConfirm(ctx, "", queue, []byte(`{"id":1}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(rabbitmq.RetryDelay(1) + 2*time.Second):
+		t.Fatal("message was not retried")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(attempts) != 2 || attempts[0] != 0 || attempts[1] != 1 {
+		t.Fatalf("attempts = %v, want [0 1]", attempts)
+	}
+
+	if letters := waitDeadLetters(t, b, queue, 0); len(letters) != 0 {
+		t.Fatalf("retried message must not be parked, got %+v", letters)
+	}
+}
+
+func TestMemoryBrokerPermanentErrorParksAndReplays(t *testing.T) {
+	const queue = "work"
+	b := newTopologyBroker(t, queue)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mu      sync.Mutex
+		fixed   bool
+		calls   int
+		handled = make(chan struct{}, 1)
+	)
+
+	if err := b.ConsumeWithRetry(ctx, queue, 1, func(d port.Delivery) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		calls++
+		if !fixed {
+			return port.Permanent(errors.New("invalid payload"))
+		}
+
+		handled <- struct{}{}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := b.PublishBatch(ctx, []port.Message{{RoutingKey: queue, MessageID: "msg-1", Body: []byte(`{"id":1}`)}}); err != nil {
+		t.Fatal(err)
+	}
+
+	letters := waitDeadLetters(t, b, queue, 1)
+	if letters[0].Reason != "permanent_error" || letters[0].Attempts != 1 || letters[0].MessageID != "msg-1" {
+		t.Fatalf("unexpected dead letter %+v", letters[0])
+	}
+
+	mu.Lock()
+	if calls != 1 {
+		t.Fatalf("handler calls = %d, want 1 (no retry for permanent errors)", calls)
+	}
+	fixed = true
+	mu.Unlock()
+
+	replayed, err := b.ReplayDeadLetters(ctx, queue, 10)
+	if err != nil || replayed != 1 {
+		t.Fatalf("ReplayDeadLetters = %d, %v", replayed, err)
+	}
+
+	select {
+	case <-handled:
+	case <-time.After(2 * time.Second):
+		t.Fatal("replayed message was not consumed")
+	}
+
+	waitDeadLetters(t, b, queue, 0)
+}
+
+func TestMemoryBrokerPublishBatchUnroutable(t *testing.T) {
+	const queue = "work"
+	b := newTopologyBroker(t, queue)
+
+	results, err := b.PublishBatch(context.Background(), []port.Message{
+		{RoutingKey: queue, Body: []byte(`{"id":1}`)},
+		{RoutingKey: "missing", Body: []byte(`{"id":2}`)},
+	})
+
+	if !errors.Is(err, rabbitmq.ErrUnroutable) {
+		t.Fatalf("batch error = %v, want ErrUnroutable", err)
+	}
+	if results[0] != nil || !errors.Is(results[1], rabbitmq.ErrUnroutable) {
+		t.Fatalf("results = %v", results)
+	}
+}
@@ -23,12 +23,21 @@ type RabbitMQConn struct {
 	binds     []models.BindDef
 
 	pool *ChannelPool
+
+	consumersMu sync.Mutex
+	consumers   map[*consumer]struct{}
+	prefetch    int
+
+	// openChannel - pengganti NewChannel untuk channel consumer, nil di produksi
+	openChannel func() (consumerChannel, error)
 }
 
 func NewRabbitMQConn(url string) (*RabbitMQConn, error) {
 	c := &RabbitMQConn{
-		url:   url,
-		close: make(chan struct{}),
+		url:       url,
+		close:     make(chan struct{}),
+		consumers: make(map[*consumer]struct{}),
+		prefetch:  DefaultPrefetchCount,
 	}
 
 	if err := c.connect(); err != nil {
@@ -101,6 +110,8 @@ func (c *RabbitMQConn) reconnect() {
 			c.pool.Rebuild()
 		}
 
+		c.resubscribeConsumers()
+
 		log.Println("[RABBITMQ] Reconnected to RabbitMQ successfully")
 		return
 	}
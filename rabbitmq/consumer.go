@@ -3,117 +3,233 @@ package rabbitmq
 import (
 	"context"
 	"fmt"
-	"log"
+	"time"
 
 	"github.com/livingdolls/go-blockchain-simulate/logger"
 	"github.com/rabbitmq/amqp091-go"
 	"go.uber.org/zap"
 )
 
+// DefaultPrefetchCount - pesan yang boleh belum di-ack per worker consumer
+const DefaultPrefetchCount = 10
+
 type HandlerFunc func(amqp091.Delivery)
 
+// consumerChannel - bagian *amqp091.Channel yang dipakai worker consumer
+type consumerChannel interface {
+	Qos(prefetchCount, prefetchSize int, global bool) error
+	Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp091.Table) (<-chan amqp091.Delivery, error)
+	Cancel(consumer string, noWait bool) error
+	Close() error
+}
+
+// consumer - satu worker yang terdaftar. channel-nya dibuat ulang (QoS + basic.consume) setelah
+// reconnect atau jika channel ditutup broker, sampai ctx selesai
+type consumer struct {
+	queue       string
+	tag         string
+	prefetch    int
+	handler     HandlerFunc
+	resubscribe chan struct{}
+}
+
 // Deprecated: use ConsumeWithContext instead
 func (c *RabbitMQConn) Consume(queue string, workers int, handler func(amqp091.Delivery)) error {
-	for i := 0; i < workers; i++ {
-		ch, err := c.pool.Get()
+	return c.ConsumeWithContext(context.Background(), queue, workers, handler)
+}
+
+func (c *RabbitMQConn) ConsumeWithContext(ctx context.Context, queueName string, workerCount int, handler HandlerFunc) error {
+	for i := 0; i < workerCount; i++ {
+		cons := &consumer{
+			queue:       queueName,
+			tag:         fmt.Sprintf("consumer-%s-%d", queueName, i),
+			prefetch:    c.Prefetch(),
+			handler:     handler,
+			resubscribe: make(chan struct{}, 1),
+		}
 
+		// subscribe pertama sinkron agar queue yang tidak ada tetap dilaporkan ke pemanggil
+		ch, msgs, err := c.subscribe(cons)
 		if err != nil {
 			return err
 		}
 
-		msgs, err := ch.Consume(
-			queue,
-			"",
-			false,
-			false,
-			false,
-			false,
-			nil,
-		)
+		c.register(cons)
+		go c.runConsumer(ctx, cons, ch, msgs)
+	}
 
-		if err != nil {
-			return err
+	return nil
+}
+
+// SetPrefetch - berlaku untuk consumer yang didaftarkan setelahnya. 0 = tanpa batas
+func (c *RabbitMQConn) SetPrefetch(count int) {
+	c.consumersMu.Lock()
+	defer c.consumersMu.Unlock()
+	c.prefetch = count
+}
+
+func (c *RabbitMQConn) Prefetch() int {
+	c.consumersMu.Lock()
+	defer c.consumersMu.Unlock()
+	return c.prefetch
+}
+
+func (c *RabbitMQConn) register(cons *consumer) {
+	c.consumersMu.Lock()
+	defer c.consumersMu.Unlock()
+	c.consumers[cons] = struct{}{}
+}
+
+func (c *RabbitMQConn) unregister(cons *consumer) {
+	c.consumersMu.Lock()
+	defer c.consumersMu.Unlock()
+	delete(c.consumers, cons)
+}
+
+// resubscribeConsumers - dipanggil reconnect setelah topology dipulihkan
+func (c *RabbitMQConn) resubscribeConsumers() {
+	c.consumersMu.Lock()
+	defer c.consumersMu.Unlock()
+
+	for cons := range c.consumers {
+		select {
+		case cons.resubscribe <- struct{}{}:
+		default:
 		}
+	}
 
-		go func(ch *amqp091.Channel) {
-			defer c.pool.Put(ch)
-			for msg := range msgs {
-				handler(msg)
-			}
+	logger.LogInfo("RabbitMQ consumers scheduled for resubscription", zap.Int("consumers", len(c.consumers)))
+}
 
-			log.Printf("[RABBITMQ] Stopped consuming from queue: %s", queue)
-		}(ch)
+// openConsumerChannel - channel baru dari koneksi aktif, atau dari openChannel jika diisi (test)
+func (c *RabbitMQConn) openConsumerChannel() (consumerChannel, error) {
+	if c.openChannel != nil {
+		return c.openChannel()
 	}
 
-	return nil
+	ch, err := c.NewChannel()
+	if err != nil {
+		return nil, err
+	}
+
+	return ch, nil
 }
 
-func (c *RabbitMQConn) ConsumeWithContext(ctx context.Context, queueName string, workerCount int, handler HandlerFunc) error {
-	for i := 0; i < workerCount; i++ {
-		ch, err := c.pool.Get()
+// subscribe - channel khusus per worker (bukan dari pool) karena QoS melekat pada channel
+func (c *RabbitMQConn) subscribe(cons *consumer) (consumerChannel, <-chan amqp091.Delivery, error) {
+	ch, err := c.openConsumerChannel()
+	if err != nil {
+		return nil, nil, err
+	}
 
-		if err != nil {
-			return err
+	if cons.prefetch > 0 {
+		if err := ch.Qos(cons.prefetch, 0, false); err != nil {
+			ch.Close()
+			return nil, nil, fmt.Errorf("[RABBITMQ] set qos for %s: %w", cons.queue, err)
 		}
+	}
 
-		consumerTag := fmt.Sprintf("consumer-%s-%d", queueName, i)
+	msgs, err := ch.Consume(
+		cons.queue,
+		cons.tag,
+		false,
+		false,
+		false,
+		false,
+		nil,
+	)
+
+	if err != nil {
+		ch.Close()
+		return nil, nil, err
+	}
 
-		msgs, err := ch.Consume(
-			queueName,
-			consumerTag,
-			false,
-			false,
-			false,
-			false,
-			nil,
-		)
+	return ch, msgs, nil
+}
 
-		if err != nil {
-			c.pool.Put(ch)
-			return err
+func (c *RabbitMQConn) runConsumer(ctx context.Context, cons *consumer, ch consumerChannel, msgs <-chan amqp091.Delivery) {
+	defer c.unregister(cons)
+
+	for {
+		if c.deliver(ctx, cons, msgs) {
+			if err := ch.Cancel(cons.tag, false); err != nil {
+				logger.LogError("Failed to cancel consumer", err, zap.String("consumerTag", cons.tag))
+			}
+			ch.Close()
+
+			logger.LogInfo("Stopped consuming from queue", zap.String("queue", cons.queue), zap.String("consumerTag", cons.tag))
+			return
 		}
 
-		go func(
-			ch *amqp091.Channel,
-			tag string,
-			msgs <-chan amqp091.Delivery,
-		) {
-			defer func() {
-				err := ch.Cancel(tag, false)
-				if err != nil {
-					logger.LogError("Failed to cancel consumer", err, zap.String("consumerTag", tag))
-				}
+		ch.Close()
+		logger.LogWarn("Message channel closed, waiting to resubscribe", zap.String("queue", cons.queue), zap.String("consumerTag", cons.tag))
 
-				c.pool.Put(ch)
+		ch, msgs = c.waitResubscribe(ctx, cons)
+		if ch == nil {
+			logger.LogInfo("Stopped consuming from queue", zap.String("queue", cons.queue), zap.String("consumerTag", cons.tag))
+			return
+		}
 
-				logger.LogInfo("Stopped consuming from queue", zap.String("queue", queueName), zap.String("consumerTag", tag))
-			}()
+		logger.LogInfo("Consumer resubscribed", zap.String("queue", cons.queue), zap.String("consumerTag", cons.tag))
+	}
+}
+
+// deliver - true jika berhenti karena ctx, false jika delivery channel ditutup (koneksi/channel mati)
+func (c *RabbitMQConn) deliver(ctx context.Context, cons *consumer, msgs <-chan amqp091.Delivery) bool {
+	for {
+		select {
+		case <-ctx.Done():
+			logger.LogInfo("Context cancelled, stopping consumer", zap.String("consumerTag", cons.tag))
+			return true
+		case msg, ok := <-msgs:
+			if !ok {
+				return false
+			}
 
-			for {
-				select {
-				case <-ctx.Done():
-					logger.LogInfo("Context cancelled, stopping consumer", zap.String("consumerTag", tag))
-					return
-				case msg, ok := <-msgs:
-					if !ok {
-						logger.LogInfo("Message channel closed, stopping consumer", zap.String("consumerTag", tag))
-						return
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						// tanpa requeue: pesan yang selalu panic tidak boleh berputar selamanya,
+						// broker memindahkannya ke dead letter queue
+						logger.LogError("Panic in consumer handler", fmt.Errorf("panic: %v", r), zap.String("consumerTag", cons.tag))
+						msg.Nack(false, false)
 					}
+				}()
 
-					func() {
-						defer func() {
-							if r := recover(); r != nil {
-								logger.LogError("Panic in consumer handler", fmt.Errorf("panic: %v", r), zap.String("consumerTag", tag))
-								msg.Nack(false, true)
-							}
-						}()
-
-						handler(msg)
-					}()
-				}
-			}
-		}(ch, consumerTag, msgs)
+				cons.handler(msg)
+			}()
+		}
 	}
+}
 
-	return nil
+// waitResubscribe - coba lagi saat reconnect selesai atau dengan backoff (channel bisa ditutup broker
+// tanpa koneksi putus). nil jika ctx selesai atau koneksi ditutup
+func (c *RabbitMQConn) waitResubscribe(ctx context.Context, cons *consumer) (consumerChannel, <-chan amqp091.Delivery) {
+	backoff := time.Second
+
+	for {
+		timer := time.NewTimer(backoff)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, nil
+		case <-c.close:
+			timer.Stop()
+			return nil, nil
+		case <-cons.resubscribe:
+			timer.Stop()
+		case <-timer.C:
+		}
+
+		ch, msgs, err := c.subscribe(cons)
+		if err == nil {
+			return ch, msgs
+		}
+
+		logger.LogWarn("Consumer resubscribe failed", zap.String("queue", cons.queue), zap.String("consumerTag", cons.tag), zap.Error(err))
+		if backoff < 30*time.Second {
+			backoff *= 2
+		}
+	}
 }
@@ -0,0 +1,368 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+// faultyBroker - pengganti broker untuk consumer: satu queue, prefetch dihormati per channel,
+// kill() meniru koneksi putus (delivery channel ditutup, pesan unacked kembali ke queue)
+type faultyBroker struct {
+	mu       sync.Mutex
+	up       bool
+	pending  [][]byte
+	channels []*faultyChannel
+	opened   []*faultyChannel
+	nextTag  uint64
+}
+
+type faultyChannel struct {
+	broker     *faultyBroker
+	qos        int
+	calls      []string
+	deliveries chan amqp091.Delivery
+	unacked    map[uint64][]byte
+	consuming  bool
+	closed     bool
+}
+
+func newFaultyBroker() *faultyBroker {
+	return &faultyBroker{up: true}
+}
+
+func (b *faultyBroker) open() (consumerChannel, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.up {
+		return nil, errors.New("connection is not established")
+	}
+
+	ch := &faultyChannel{broker: b, unacked: make(map[uint64][]byte)}
+	b.channels = append(b.channels, ch)
+	b.opened = append(b.opened, ch)
+	return ch, nil
+}
+
+func (b *faultyBroker) publish(body string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.pending = append(b.pending, []byte(body))
+	b.dispatchLocked()
+}
+
+// dispatchLocked - bagikan pesan ke channel yang consume selama unacked < qos (0 = tanpa batas)
+func (b *faultyBroker) dispatchLocked() {
+	for len(b.pending) > 0 {
+		delivered := false
+
+		for _, ch := range b.channels {
+			if len(b.pending) == 0 {
+				break
+			}
+			if !ch.consuming || ch.closed || (ch.qos > 0 && len(ch.unacked) >= ch.qos) {
+				continue
+			}
+
+			b.nextTag++
+			body := b.pending[0]
+			b.pending = b.pending[1:]
+			ch.unacked[b.nextTag] = body
+			ch.deliveries <- amqp091.Delivery{Acknowledger: ch, DeliveryTag: b.nextTag, Body: body}
+			delivered = true
+		}
+
+		if !delivered {
+			return
+		}
+	}
+}
+
+// kill - koneksi putus: semua channel mati dan pesan yang belum di-ack dikembalikan ke queue
+func (b *faultyBroker) kill() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.up = false
+	for _, ch := range b.channels {
+		for _, body := range ch.unacked {
+			b.pending = append(b.pending, body)
+		}
+		ch.unacked = nil
+		ch.closeLocked()
+	}
+	b.channels = nil
+}
+
+func (b *faultyBroker) restore() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.up = true
+}
+
+func (b *faultyBroker) consumingChannels() []*faultyChannel {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var out []*faultyChannel
+	for _, ch := range b.channels {
+		if ch.consuming && !ch.closed {
+			out = append(out, ch)
+		}
+	}
+	return out
+}
+
+func (ch *faultyChannel) closeLocked() {
+	if ch.closed {
+		return
+	}
+	ch.closed = true
+	if ch.deliveries != nil {
+		close(ch.deliveries)
+	}
+}
+
+func (ch *faultyChannel) Qos(prefetchCount, prefetchSize int, global bool) error {
+	ch.broker.mu.Lock()
+	defer ch.broker.mu.Unlock()
+
+	if ch.closed {
+		return amqp091.ErrClosed
+	}
+	ch.qos = prefetchCount
+	ch.calls = append(ch.calls, fmt.Sprintf("qos:%d", prefetchCount))
+	return nil
+}
+
+func (ch *faultyChannel) Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp091.Table) (<-chan amqp091.Delivery, error) {
+	ch.broker.mu.Lock()
+	defer ch.broker.mu.Unlock()
+
+	if ch.closed {
+		return nil, amqp091.ErrClosed
+	}
+	ch.calls = append(ch.calls, "consume:"+queue)
+	ch.deliveries = make(chan amqp091.Delivery, 128)
+	ch.consuming = true
+	ch.broker.dispatchLocked()
+	return ch.deliveries, nil
+}
+
+func (ch *faultyChannel) Cancel(consumer string, noWait bool) error {
+	ch.broker.mu.Lock()
+	defer ch.broker.mu.Unlock()
+
+	ch.consuming = false
+	return nil
+}
+
+func (ch *faultyChannel) Close() error {
+	ch.broker.mu.Lock()
+	defer ch.broker.mu.Unlock()
+
+	for _, body := range ch.unacked {
+		ch.broker.pending = append(ch.broker.pending, body)
+	}
+	ch.unacked = nil
+	ch.closeLocked()
+	ch.broker.dispatchLocked()
+	return nil
+}
+
+func (ch *faultyChannel) Ack(tag uint64, multiple bool) error {
+	ch.broker.mu.Lock()
+	defer ch.broker.mu.Unlock()
+
+	if ch.closed {
+		return amqp091.ErrClosed
+	}
+	delete(ch.unacked, tag)
+	ch.broker.dispatchLocked()
+	return nil
+}
+
+func (ch *faultyChannel) Nack(tag uint64, multiple, requeue bool) error {
+	return ch.Reject(tag, requeue)
+}
+
+func (ch *faultyChannel) Reject(tag uint64, requeue bool) error {
+	ch.broker.mu.Lock()
+	defer ch.broker.mu.Unlock()
+
+	if ch.closed {
+		return amqp091.ErrClosed
+	}
+	if body, ok := ch.unacked[tag]; ok && requeue {
+		ch.broker.pending = append(ch.broker.pending, body)
+	}
+	delete(ch.unacked, tag)
+	ch.broker.dispatchLocked()
+	return nil
+}
+
+func newFaultyConn(broker *faultyBroker, prefetch int) *RabbitMQConn {
+	return &RabbitMQConn{
+		close:       make(chan struct{}),
+		consumers:   make(map[*consumer]struct{}),
+		prefetch:    prefetch,
+		openChannel: broker.open,
+	}
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestConsumerResumesWithQoSAfterConnectionLoss(t *testing.T) {
+	const (
+		queue    = "transaction.pending"
+		workers  = 2
+		prefetch = 3
+	)
+
+	broker := newFaultyBroker()
+	conn := newFaultyConn(broker, prefetch)
+	defer close(conn.close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mu        sync.Mutex
+		processed = make(map[string]int)
+	)
+	inHandler := make(chan struct{})
+	release := make(chan struct{})
+
+	handler := func(msg amqp091.Delivery) {
+		// "stuck" menahan worker sampai koneksi diputus, ack-nya gagal dan broker mengirim ulang
+		if string(msg.Body) == "stuck" {
+			mu.Lock()
+			first := processed["stuck"] == 0
+			processed["stuck"]++
+			mu.Unlock()
+
+			if first {
+				close(inHandler)
+				<-release
+			}
+			msg.Ack(false)
+			return
+		}
+
+		mu.Lock()
+		processed[string(msg.Body)]++
+		mu.Unlock()
+		msg.Ack(false)
+	}
+
+	if err := conn.ConsumeWithContext(ctx, queue, workers, handler); err != nil {
+		t.Fatalf("ConsumeWithContext: %v", err)
+	}
+
+	seen := func(bodies ...string) func() bool {
+		return func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, body := range bodies {
+				if processed[body] == 0 {
+					return false
+				}
+			}
+			return true
+		}
+	}
+
+	broker.publish("before-1")
+	broker.publish("before-2")
+	waitFor(t, "messages before connection loss", seen("before-1", "before-2"))
+
+	broker.publish("stuck")
+	<-inHandler
+
+	broker.kill()
+	close(release)
+
+	// selama broker mati pesan tetap antri, worker menunggu resubscribe
+	broker.publish("during-outage")
+
+	broker.restore()
+	conn.resubscribeConsumers()
+
+	waitFor(t, "consumers to resubscribe", func() bool {
+		return len(broker.consumingChannels()) == workers
+	})
+
+	broker.publish("after-1")
+	broker.publish("after-2")
+	waitFor(t, "messages after reconnect", seen("during-outage", "after-1", "after-2"))
+	waitFor(t, "redelivery of the unacked message", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return processed["stuck"] >= 2
+	})
+
+	for i, ch := range broker.consumingChannels() {
+		broker.mu.Lock()
+		calls := append([]string(nil), ch.calls...)
+		broker.mu.Unlock()
+
+		want := []string{fmt.Sprintf("qos:%d", prefetch), "consume:" + queue}
+		if len(calls) != len(want) || calls[0] != want[0] || calls[1] != want[1] {
+			t.Fatalf("channel %d calls = %v, want %v (QoS before consume)", i, calls, want)
+		}
+	}
+
+	conn.consumersMu.Lock()
+	registered := len(conn.consumers)
+	conn.consumersMu.Unlock()
+	if registered != workers {
+		t.Fatalf("registered consumers = %d, want %d", registered, workers)
+	}
+}
+
+func TestConsumerStopsWhileBrokerIsDown(t *testing.T) {
+	broker := newFaultyBroker()
+	conn := newFaultyConn(broker, DefaultPrefetchCount)
+	defer close(conn.close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	if err := conn.ConsumeWithContext(ctx, "ledger.persistence", 1, func(msg amqp091.Delivery) {
+		msg.Ack(false)
+	}); err != nil {
+		t.Fatalf("ConsumeWithContext: %v", err)
+	}
+
+	broker.kill()
+	cancel()
+
+	waitFor(t, "consumer to unregister", func() bool {
+		conn.consumersMu.Lock()
+		defer conn.consumersMu.Unlock()
+		return len(conn.consumers) == 0
+	})
+
+	broker.mu.Lock()
+	opened := len(broker.opened)
+	broker.mu.Unlock()
+	if opened != 1 {
+		t.Fatalf("channels opened = %d, want 1 (no resubscribe after stop)", opened)
+	}
+}
@@ -0,0 +1,423 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/livingdolls/go-blockchain-simulate/app/models"
+	"github.com/livingdolls/go-blockchain-simulate/app/port"
+	"github.com/livingdolls/go-blockchain-simulate/logger"
+	"github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+)
+
+const (
+	// DeadLetterExchange - direct exchange, routing key = nama queue asal, tujuan <queue>.dead
+	DeadLetterExchange = "dead-letter"
+
+	// MaxDeliveryAttempts - percobaan (termasuk yang pertama) sebelum pesan diparkir
+	MaxDeliveryAttempts = 5
+	// RetryBaseDelay - jeda retry ke-n = RetryBaseDelay * 2^(n-1): 1s, 2s, 4s, 8s
+	RetryBaseDelay = time.Second
+
+	RetryCountHeader         = "x-retry-count"
+	LastErrorHeader          = "x-last-error"
+	ParkReasonHeader         = "x-park-reason"
+	FailedAtHeader           = "x-failed-at"
+	OriginalExchangeHeader   = "x-original-exchange"
+	OriginalRoutingKeyHeader = "x-original-routing-key"
+
+	// DeadLetterPolicyPriority - policy lain yang cocok dengan queue kerja harus punya prioritas lebih rendah
+	// (hanya satu policy yang berlaku per queue) atau ikut memuat dead-letter-exchange
+	DeadLetterPolicyPriority = 10
+
+	maxErrorHeaderLen = 512
+)
+
+// PublishFunc - publish dengan confirm, dipakai settle agar logika retry sama untuk RabbitMQ dan membroker
+type PublishFunc func(ctx context.Context, exchange, routingKey string, msg amqp091.Publishing) error
+
+func RetryQueueName(queue string, attempt int) string {
+	return fmt.Sprintf("%s.retry.%d", queue, attempt)
+}
+
+func DeadLetterQueueName(queue string) string {
+	return queue + ".dead"
+}
+
+func RetryDelay(attempt int) time.Duration {
+	return RetryBaseDelay << (attempt - 1)
+}
+
+// DeadLetterTopology - setiap queue kerja diberi dead-letter exchange, delay queue per percobaan
+// (<queue>.retry.N, TTL lalu kembali ke queue asal lewat default exchange) dan parking queue <queue>.dead.
+// delay queue per percobaan agar pesan dengan TTL pendek tidak tertahan di belakang TTL panjang.
+// dead-letter queue kerja dipasang lewat policy, bukan argumen x-dead-letter-*: queue durable yang sudah ada
+// tanpa argumen tersebut akan ditolak broker (PRECONDITION_FAILED) jika dideklarasikan ulang dengan argumen berbeda
+func DeadLetterTopology(queues []models.QueueDef) ([]models.QueueDef, []models.BindDef, []models.PolicyDef) {
+	all := make([]models.QueueDef, 0, len(queues)*(MaxDeliveryAttempts+1))
+	binds := make([]models.BindDef, 0, len(queues))
+	policies := make([]models.PolicyDef, 0, len(queues))
+
+	for _, q := range queues {
+		all = append(all, q)
+		policies = append(policies, DeadLetterPolicy(q.Name))
+
+		for attempt := 1; attempt < MaxDeliveryAttempts; attempt++ {
+			all = append(all, models.QueueDef{
+				Name:    RetryQueueName(q.Name, attempt),
+				Durable: q.Durable,
+				Args: map[string]interface{}{
+					"x-message-ttl":             RetryDelay(attempt).Milliseconds(),
+					"x-dead-letter-exchange":    "",
+					"x-dead-letter-routing-key": q.Name,
+				},
+			})
+		}
+
+		all = append(all, models.QueueDef{Name: DeadLetterQueueName(q.Name), Durable: q.Durable})
+		binds = append(binds, models.BindDef{
+			Queue:      DeadLetterQueueName(q.Name),
+			Exchange:   DeadLetterExchange,
+			RoutingKey: q.Name,
+		})
+	}
+
+	return all, binds, policies
+}
+
+// DeadLetterPolicy - policy dead-letter untuk satu queue kerja, routing key = nama queue asal
+func DeadLetterPolicy(queue string) models.PolicyDef {
+	return models.PolicyDef{
+		Name:     "dlx." + queue,
+		Pattern:  "^" + regexp.QuoteMeta(queue) + "$",
+		ApplyTo:  "queues",
+		Priority: DeadLetterPolicyPriority,
+		Definition: map[string]interface{}{
+			"dead-letter-exchange":    DeadLetterExchange,
+			"dead-letter-routing-key": queue,
+		},
+	}
+}
+
+// ConsumeWithRetry - ack/retry/park diurus di sini. panic dihitung sebagai percobaan gagal
+func (c *RabbitMQConn) ConsumeWithRetry(ctx context.Context, queueName string, workerCount int, handler port.DeliveryHandler) error {
+	return c.ConsumeWithContext(ctx, queueName, workerCount, RetryHandler(c.publishWithConfirm, queueName, handler))
+}
+
+// RetryHandler - bungkus handler port menjadi HandlerFunc yang ack, menjadwalkan retry ke <queue>.retry.N
+// atau memarkir pesan di <queue>.dead. publish harus publish dengan confirm ke broker yang sama
+func RetryHandler(publish PublishFunc, queue string, handler port.DeliveryHandler) HandlerFunc {
+	return func(msg amqp091.Delivery) {
+		settle(publish, queue, msg, callRetryHandler(handler, msg))
+	}
+}
+
+func callRetryHandler(handler port.DeliveryHandler, msg amqp091.Delivery) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return handler(toDelivery(msg))
+}
+
+// toDelivery - salinan header agar handler tidak mengubah pesan yang akan di-retry
+func toDelivery(msg amqp091.Delivery) port.Delivery {
+	headers := make(map[string]interface{}, len(msg.Headers))
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+
+	return port.Delivery{
+		Exchange:   msg.Exchange,
+		RoutingKey: msg.RoutingKey,
+		MessageID:  msg.MessageId,
+		Headers:    headers,
+		Body:       msg.Body,
+		Attempts:   RetryCount(msg),
+	}
+}
+
+func settle(publish PublishFunc, queue string, msg amqp091.Delivery, handlerErr error) {
+	if handlerErr == nil {
+		if err := msg.Ack(false); err != nil {
+			logger.LogError("Failed to ack message", err, zap.String("queue", queue))
+		}
+		return
+	}
+
+	attempts := RetryCount(msg) + 1
+	permanent := port.IsPermanent(handlerErr)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var (
+		exchange, key string
+		reason        string
+	)
+
+	if !permanent && attempts < MaxDeliveryAttempts {
+		exchange, key = "", RetryQueueName(queue, attempts)
+		logger.LogWarn("Message processing failed, scheduling retry",
+			zap.String("queue", queue),
+			zap.Int("attempt", attempts),
+			zap.Duration("delay", RetryDelay(attempts)),
+			zap.Error(handlerErr),
+		)
+	} else {
+		exchange, key = DeadLetterExchange, queue
+		reason = "max_attempts"
+		if permanent {
+			reason = "permanent_error"
+		}
+		logger.LogError("Message parked in dead letter queue", handlerErr,
+			zap.String("queue", queue),
+			zap.Int("attempts", attempts),
+			zap.String("reason", reason),
+		)
+	}
+
+	// ack pesan asal hanya setelah salinannya dikonfirmasi broker, pesan tidak hilang di antara keduanya
+	if err := publish(ctx, exchange, key, failedPublishing(msg, attempts, handlerErr, reason)); err != nil {
+		// broker tetap memindahkan pesan ke <queue>.dead lewat policy dead-letter queue kerja
+		logger.LogError("Failed to republish failed message, dead-lettering", err, zap.String("queue", queue))
+		if err := msg.Nack(false, false); err != nil {
+			logger.LogError("Failed to nack message", err, zap.String("queue", queue))
+		}
+		return
+	}
+
+	if err := msg.Ack(false); err != nil {
+		logger.LogError("Failed to ack message", err, zap.String("queue", queue))
+	}
+}
+
+// failedPublishing - salinan pesan dengan header percobaan. exchange/routing key asal disimpan sekali,
+// setelah retry pesan datang dari default exchange
+func failedPublishing(msg amqp091.Delivery, attempts int, handlerErr error, reason string) amqp091.Publishing {
+	publishing := copyPublishing(msg)
+
+	if _, ok := publishing.Headers[OriginalExchangeHeader]; !ok {
+		publishing.Headers[OriginalExchangeHeader] = msg.Exchange
+		publishing.Headers[OriginalRoutingKeyHeader] = msg.RoutingKey
+	}
+
+	errMsg := handlerErr.Error()
+	if len(errMsg) > maxErrorHeaderLen {
+		errMsg = errMsg[:maxErrorHeaderLen]
+	}
+
+	publishing.Headers[RetryCountHeader] = int32(attempts)
+	publishing.Headers[LastErrorHeader] = errMsg
+	publishing.Headers[FailedAtHeader] = time.Now()
+	if reason != "" {
+		publishing.Headers[ParkReasonHeader] = reason
+	}
+
+	return publishing
+}
+
+// ReplayPublishing - hitungan percobaan dan jejak dead letter dihapus, pesan mulai dari awal
+func ReplayPublishing(msg amqp091.Delivery) amqp091.Publishing {
+	publishing := copyPublishing(msg)
+	for _, header := range []string{RetryCountHeader, LastErrorHeader, ParkReasonHeader, FailedAtHeader, "x-death"} {
+		delete(publishing.Headers, header)
+	}
+
+	return publishing
+}
+
+func copyPublishing(msg amqp091.Delivery) amqp091.Publishing {
+	headers := amqp091.Table{}
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+
+	return amqp091.Publishing{
+		Headers:       headers,
+		ContentType:   msg.ContentType,
+		DeliveryMode:  msg.DeliveryMode,
+		CorrelationId: msg.CorrelationId,
+		MessageId:     msg.MessageId,
+		Timestamp:     msg.Timestamp,
+		Type:          msg.Type,
+		Body:          msg.Body,
+	}
+}
+
+// RetryCount - jumlah percobaan yang sudah gagal
+func RetryCount(msg amqp091.Delivery) int {
+	return headerInt(msg.Headers[RetryCountHeader])
+}
+
+func headerInt(v interface{}) int {
+	switch n := v.(type) {
+	case int:
+		return n
+	case int8:
+		return int(n)
+	case int16:
+		return int(n)
+	case int32:
+		return int(n)
+	case int64:
+		return int(n)
+	case uint8:
+		return int(n)
+	case uint16:
+		return int(n)
+	case uint32:
+		return int(n)
+	default:
+		return 0
+	}
+}
+
+func headerString(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
+
+// DeadLetterStats - passive declare, channel baru per queue karena queue yang tidak ada menutup channel
+func (c *RabbitMQConn) DeadLetterStats(queues []string) ([]models.DeadLetterQueueStats, error) {
+	stats := make([]models.DeadLetterQueueStats, 0, len(queues))
+
+	for _, queue := range queues {
+		ch, err := c.NewChannel()
+		if err != nil {
+			return nil, err
+		}
+
+		q, err := ch.QueueDeclarePassive(DeadLetterQueueName(queue), true, false, false, false, nil)
+		ch.Close()
+		if err != nil {
+			return nil, fmt.Errorf("inspect %s: %w", DeadLetterQueueName(queue), err)
+		}
+
+		stats = append(stats, models.DeadLetterQueueStats{
+			Queue:           queue,
+			DeadLetterQueue: q.Name,
+			Messages:        q.Messages,
+		})
+	}
+
+	return stats, nil
+}
+
+// PeekDeadLetters - ambil tanpa ack lalu kembalikan semuanya ke queue, urutan tetap
+func (c *RabbitMQConn) PeekDeadLetters(queue string, limit int) ([]models.DeadLetter, error) {
+	ch, err := c.NewChannel()
+	if err != nil {
+		return nil, err
+	}
+	defer ch.Close()
+
+	letters := make([]models.DeadLetter, 0, limit)
+	var lastTag uint64
+
+	for len(letters) < limit {
+		msg, ok, err := ch.Get(DeadLetterQueueName(queue), false)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+
+		lastTag = msg.DeliveryTag
+		letters = append(letters, ToDeadLetter(queue, msg))
+	}
+
+	if lastTag > 0 {
+		if err := ch.Nack(lastTag, true, true); err != nil {
+			return nil, err
+		}
+	}
+
+	return letters, nil
+}
+
+// ReplayDeadLetters - publish ulang ke queue asal lewat default exchange (hanya consumer queue itu yang
+// menerima, bukan semua queue yang bind ke routing key asal) dengan hitungan percobaan direset
+func (c *RabbitMQConn) ReplayDeadLetters(ctx context.Context, queue string, limit int) (int, error) {
+	ch, err := c.NewChannel()
+	if err != nil {
+		return 0, err
+	}
+	defer ch.Close()
+
+	replayed := 0
+	for replayed < limit {
+		msg, ok, err := ch.Get(DeadLetterQueueName(queue), false)
+		if err != nil {
+			return replayed, err
+		}
+		if !ok {
+			break
+		}
+
+		if err := c.publishWithConfirm(ctx, "", queue, ReplayPublishing(msg)); err != nil {
+			msg.Nack(false, true)
+			return replayed, err
+		}
+
+		if err := msg.Ack(false); err != nil {
+			return replayed, err
+		}
+		replayed++
+	}
+
+	return replayed, nil
+}
+
+func (c *RabbitMQConn) PurgeDeadLetters(queue string) (int, error) {
+	ch, err := c.NewChannel()
+	if err != nil {
+		return 0, err
+	}
+	defer ch.Close()
+
+	return ch.QueuePurge(DeadLetterQueueName(queue), false)
+}
+
+// ToDeadLetter - pesan yang di-nack langsung (bukan lewat settle) hanya punya header x-death dari broker
+func ToDeadLetter(queue string, msg amqp091.Delivery) models.DeadLetter {
+	letter := models.DeadLetter{
+		Queue:      queue,
+		MessageID:  msg.MessageId,
+		Exchange:   headerString(msg.Headers[OriginalExchangeHeader]),
+		RoutingKey: headerString(msg.Headers[OriginalRoutingKeyHeader]),
+		Attempts:   RetryCount(msg),
+		LastError:  headerString(msg.Headers[LastErrorHeader]),
+		Reason:     headerString(msg.Headers[ParkReasonHeader]),
+		Body:       string(msg.Body),
+	}
+
+	if t, ok := msg.Headers[FailedAtHeader].(time.Time); ok {
+		letter.ParkedAt = t
+	}
+
+	if deaths, ok := msg.Headers["x-death"].([]interface{}); ok && len(deaths) > 0 {
+		if death, ok := deaths[0].(amqp091.Table); ok {
+			if letter.Reason == "" {
+				letter.Reason = headerString(death["reason"])
+			}
+			if letter.Exchange == "" {
+				letter.Exchange = headerString(death["exchange"])
+			}
+			if keys, ok := death["routing-keys"].([]interface{}); ok && len(keys) > 0 && letter.RoutingKey == "" {
+				letter.RoutingKey = headerString(keys[0])
+			}
+			if t, ok := death["time"].(time.Time); ok && letter.ParkedAt.IsZero() {
+				letter.ParkedAt = t
+			}
+		}
+	}
+
+	return letter
+}
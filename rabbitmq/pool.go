@@ -6,9 +6,19 @@ import (
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
+// returnBuffer - basic.return dikirim dari goroutine pembaca koneksi, buffer agar tidak menahannya
+// saat publisher belum sempat membaca
+const returnBuffer = 16
+
+// pooledChannel - channel dalam confirm mode beserta listener basic.return miliknya
+type pooledChannel struct {
+	*amqp.Channel
+	returns chan amqp.Return
+}
+
 type ChannelPool struct {
 	conn   *RabbitMQConn
-	pool   chan *amqp.Channel
+	pool   chan *pooledChannel
 	size   int
 	mu     sync.RWMutex
 	closed bool
@@ -18,11 +28,11 @@ func NewChannelPool(conn *RabbitMQConn, size int) (*ChannelPool, error) {
 	p := &ChannelPool{
 		conn: conn,
 		size: size,
-		pool: make(chan *amqp.Channel, size),
+		pool: make(chan *pooledChannel, size),
 	}
 
 	for i := 0; i < size; i++ {
-		ch, err := conn.NewChannel()
+		ch, err := p.newChannel()
 
 		if err != nil {
 			return nil, err
@@ -36,7 +46,26 @@ func NewChannelPool(conn *RabbitMQConn, size int) (*ChannelPool, error) {
 	return p, nil
 }
 
-func (p *ChannelPool) Get() (*amqp.Channel, error) {
+// newChannel - semua channel pool dalam confirm mode agar PublishWithConfirm/PublishBatch bisa
+// memakai channel mana pun
+func (p *ChannelPool) newChannel() (*pooledChannel, error) {
+	ch, err := p.conn.NewChannel()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ch.Confirm(false); err != nil {
+		ch.Close()
+		return nil, err
+	}
+
+	return &pooledChannel{
+		Channel: ch,
+		returns: ch.NotifyReturn(make(chan amqp.Return, returnBuffer)),
+	}, nil
+}
+
+func (p *ChannelPool) Get() (*pooledChannel, error) {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
@@ -47,16 +76,16 @@ func (p *ChannelPool) Get() (*amqp.Channel, error) {
 	select {
 	case ch := <-p.pool:
 		if ch.IsClosed() {
-			return p.conn.NewChannel()
+			return p.newChannel()
 		}
 
 		return ch, nil
 	default:
-		return p.conn.NewChannel()
+		return p.newChannel()
 	}
 }
 
-func (p *ChannelPool) Put(ch *amqp.Channel) {
+func (p *ChannelPool) Put(ch *pooledChannel) {
 	if ch == nil || ch.IsClosed() {
 		return
 	}
@@ -83,10 +112,10 @@ func (p *ChannelPool) Rebuild() error {
 
 	oldPool := p.pool
 
-	p.pool = make(chan *amqp.Channel, p.size)
+	p.pool = make(chan *pooledChannel, p.size)
 
 	for i := 0; i < p.size; i++ {
-		ch, err := p.conn.NewChannel()
+		ch, err := p.newChannel()
 
 		if err != nil {
 			continue
@@ -2,12 +2,41 @@ package rabbitmq
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/livingdolls/go-blockchain-simulate/app/port"
 	"github.com/rabbitmq/amqp091-go"
 )
 
+// DefaultConfirmTimeout - batas menunggu ack/nack broker jika ctx tidak punya deadline lebih pendek
+const DefaultConfirmTimeout = 5 * time.Second
+
+var (
+	ErrPublishNacked = errors.New("[RABBITMQ] message nacked by broker")
+	ErrUnroutable    = errors.New("[RABBITMQ] message unroutable")
+)
+
+// outgoing - pesan siap publish, Publishing lengkap agar header retry/dead letter ikut terkirim
+type outgoing struct {
+	exchange   string
+	routingKey string
+	msg        amqp091.Publishing
+}
+
+// Publish - fire and forget, tidak tahu apakah pesan sampai ke queue. gunakan PublishWithConfirm
+// untuk event yang tidak boleh hilang
 func (c *RabbitMQConn) Publish(ctx context.Context, exchange, routingKey string, body []byte) error {
+	return c.publish(ctx, exchange, routingKey, amqp091.Publishing{
+		ContentType: "application/json",
+		Body:        body,
+		Timestamp:   time.Now(),
+	})
+}
+
+func (c *RabbitMQConn) publish(ctx context.Context, exchange, routingKey string, msg amqp091.Publishing) error {
 	ch, err := c.pool.Get()
 
 	if err != nil {
@@ -22,10 +51,171 @@ func (c *RabbitMQConn) Publish(ctx context.Context, exchange, routingKey string,
 		routingKey,
 		false,
 		false,
-		amqp091.Publishing{
-			ContentType: "application/json",
-			Body:        body,
-			Timestamp:   time.Now(),
-		},
+		msg,
 	)
 }
+
+// PublishWithConfirm - publish mandatory lalu tunggu ack broker. gagal dengan ErrPublishNacked,
+// ErrUnroutable (tidak ada queue yang bind ke routing key) atau context.DeadlineExceeded
+func (c *RabbitMQConn) PublishWithConfirm(ctx context.Context, exchange, routingKey string, body []byte) error {
+	_, err := c.PublishBatch(ctx, []port.Message{{Exchange: exchange, RoutingKey: routingKey, Body: body}})
+	return err
+}
+
+// publishWithConfirm - seperti PublishWithConfirm untuk Publishing yang sudah lengkap (header retry, dll)
+func (c *RabbitMQConn) publishWithConfirm(ctx context.Context, exchange, routingKey string, msg amqp091.Publishing) error {
+	return c.publishConfirmed(ctx, []outgoing{{exchange: exchange, routingKey: routingKey, msg: msg}})[0]
+}
+
+// PublishBatch - MessageID kosong diisi uuid, dipakai untuk mencocokkan basic.return.
+// publish semua pesan di satu channel lalu tunggu confirm sekaligus, jauh lebih cepat
+// daripada PublishWithConfirm per pesan. results[i] nil = pesan ke-i di-ack dan sudah di-route,
+// error kedua = kegagalan pertama (nil jika semua berhasil)
+func (c *RabbitMQConn) PublishBatch(ctx context.Context, msgs []port.Message) ([]error, error) {
+	results := c.publishConfirmed(ctx, newBatch(msgs))
+	return results, firstError(results)
+}
+
+func newBatch(msgs []port.Message) []outgoing {
+	now := time.Now()
+	batch := make([]outgoing, len(msgs))
+
+	for i, m := range msgs {
+		id := m.MessageID
+		if id == "" {
+			id = uuid.NewString()
+		}
+
+		batch[i] = outgoing{
+			exchange:   m.Exchange,
+			routingKey: m.RoutingKey,
+			msg: amqp091.Publishing{
+				ContentType: "application/json",
+				MessageId:   id,
+				Body:        m.Body,
+				Timestamp:   now,
+			},
+		}
+	}
+
+	return batch
+}
+
+func firstError(results []error) error {
+	for _, err := range results {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// publishConfirmed - inti PublishBatch. broker mengirim basic.return sebelum basic.ack untuk pesan yang
+// sama, jadi setelah semua confirm diterima return yang relevan sudah ada di listener channel.
+// channel yang timeout atau gagal ditutup, bukan dikembalikan ke pool, agar return/confirm yang
+// terlambat tidak terbaca oleh publisher berikutnya
+func (c *RabbitMQConn) publishConfirmed(ctx context.Context, batch []outgoing) []error {
+	results := make([]error, len(batch))
+	if len(batch) == 0 {
+		return results
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultConfirmTimeout)
+	defer cancel()
+
+	ch, err := c.pool.Get()
+	if err != nil {
+		fillErrors(results, 0, err)
+		return results
+	}
+
+	healthy := false
+	defer func() {
+		if healthy {
+			c.pool.Put(ch)
+		} else {
+			ch.Close()
+		}
+	}()
+
+	confirms := make([]*amqp091.DeferredConfirmation, 0, len(batch))
+	index := make(map[string]int, len(batch))
+
+	for i, out := range batch {
+		index[out.msg.MessageId] = i
+
+		confirm, err := ch.PublishWithDeferredConfirmWithContext(ctx, out.exchange, out.routingKey, true, false, out.msg)
+		if err != nil {
+			fillErrors(results, i, fmt.Errorf("publish to %s/%s: %w", out.exchange, out.routingKey, err))
+			break
+		}
+
+		confirms = append(confirms, confirm)
+	}
+
+	returned := make(map[int]amqp091.Return)
+	record := func(ret amqp091.Return) {
+		if i, ok := index[ret.MessageId]; ok {
+			returned[i] = ret
+		}
+	}
+
+	returns := ch.returns
+	for i, confirm := range confirms {
+	wait:
+		for {
+			select {
+			case ret, ok := <-returns:
+				if !ok {
+					returns = nil
+					continue
+				}
+				record(ret)
+			case <-confirm.Done():
+				break wait
+			case <-ctx.Done():
+				fillErrors(results, i, fmt.Errorf("wait for publisher confirm: %w", ctx.Err()))
+				return results
+			}
+		}
+
+		if !confirm.Acked() {
+			if ch.IsClosed() {
+				results[i] = fmt.Errorf("wait for publisher confirm: %w", amqp091.ErrClosed)
+			} else {
+				results[i] = fmt.Errorf("%w: %s/%s", ErrPublishNacked, batch[i].exchange, batch[i].routingKey)
+			}
+		}
+	}
+
+drain:
+	for returns != nil {
+		select {
+		case ret, ok := <-returns:
+			if !ok {
+				break drain
+			}
+			record(ret)
+		default:
+			break drain
+		}
+	}
+
+	for i, ret := range returned {
+		if results[i] == nil {
+			results[i] = fmt.Errorf("%w: %s/%s (%d %s)", ErrUnroutable, ret.Exchange, ret.RoutingKey, ret.ReplyCode, ret.ReplyText)
+		}
+	}
+
+	healthy = !ch.IsClosed() && len(confirms) == len(batch)
+	return results
+}
+
+func fillErrors(results []error, from int, err error) {
+	for i := from; i < len(results); i++ {
+		if results[i] == nil {
+			results[i] = err
+		}
+	}
+}
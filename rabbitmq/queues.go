@@ -16,6 +16,7 @@ const (
 	BlockMinedQueue           = "block.mined"
 	LedgerEntriesQueue        = "ledger.entries"
 	MarketPricingQueue        = "market.pricing"
+	CandleAggregationQueue    = "market.candles"
 	MarketVolumeQueue         = "market.volume.updates"
 	RewardCalculationQueue    = "reward.calculation"
 	RewardDistributionQueue   = "reward.distribution"
@@ -4,6 +4,7 @@ import (
 	"log"
 
 	"github.com/livingdolls/go-blockchain-simulate/app/models"
+	amqp "github.com/rabbitmq/amqp091-go"
 )
 
 func (c *RabbitMQConn) DeclareQueue(q models.QueueDef) error {
@@ -14,7 +15,7 @@ func (c *RabbitMQConn) DeclareQueue(q models.QueueDef) error {
 
 	defer ch.Close()
 
-	_, err = ch.QueueDeclare(q.Name, q.Durable, q.AutoDelete, false, false, nil)
+	_, err = ch.QueueDeclare(q.Name, q.Durable, q.AutoDelete, false, false, amqp.Table(q.Args))
 
 	if err == nil {
 		log.Printf("[RABBITMQ] Declared queue: %s", q.Name)
@@ -67,7 +68,7 @@ func (c *RabbitMQConn) restoreTopology() {
 	}
 
 	for _, q := range c.queues {
-		ch.QueueDeclare(q.Name, q.Durable, q.AutoDelete, false, false, nil)
+		ch.QueueDeclare(q.Name, q.Durable, q.AutoDelete, false, false, amqp.Table(q.Args))
 	}
 
 	for _, b := range c.binds {
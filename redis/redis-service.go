@@ -12,11 +12,15 @@ import (
 
 type MemoryAdapter interface {
 	Get(ctx context.Context, key string) ([]byte, bool)
+	// GetFresh - baca langsung dari redis tanpa lru cache, untuk state yang diubah instance lain
+	GetFresh(ctx context.Context, key string) ([]byte, bool)
 	Set(ctx context.Context, key string, value []byte, ttl time.Duration)
 	Del(ctx context.Context, key string)
 	InvalidatePattern(ctx context.Context, pattern string) error
 	Publish(ctx context.Context, channel string, message []byte) error
 	Subscribe(ctx context.Context, channel string, callback func([]byte) error) error
+	// Incr - counter atomik di redis (tidak lewat lru cache)
+	Incr(ctx context.Context, key string) (int64, error)
 }
 
 type cacheItem struct {
@@ -89,6 +93,21 @@ func (m *memoryAdapter) Get(ctx context.Context, key string) ([]byte, bool) {
 	return nil, false
 }
 
+// GetFresh implements port.MemoryAdapter.
+// tanpa redis hanya ada lru cache proses ini, jadi dibaca dari sana
+func (m *memoryAdapter) GetFresh(ctx context.Context, key string) ([]byte, bool) {
+	if m.redis == nil {
+		return m.Get(ctx, key)
+	}
+
+	b, err := m.redis.Get(ctx, key).Bytes()
+	if err != nil {
+		return nil, false
+	}
+
+	return b, true
+}
+
 // InvalidatePattern implements port.MemoryAdapter.
 func (m *memoryAdapter) InvalidatePattern(ctx context.Context, pattern string) error {
 	if m.redis == nil {
@@ -123,6 +142,11 @@ func (m *memoryAdapter) Set(ctx context.Context, key string, value []byte, ttl t
 	}
 }
 
+// Incr implements port.MemoryAdapter.
+func (m *memoryAdapter) Incr(ctx context.Context, key string) (int64, error) {
+	return m.redis.Incr(ctx, key).Result()
+}
+
 func (r *memoryAdapter) Publish(ctx context.Context, channel string, message []byte) error {
 	result := r.redis.Publish(ctx, channel, message)
 	return result.Err()
@@ -0,0 +1,90 @@
+package security
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// sealedKeyPrefix - penanda versi format ciphertext, nilai tanpa prefix dianggap plaintext lama
+const sealedKeyPrefix = "v1:"
+
+// KeySealer mengenkripsi private key sebelum disimpan ke database.
+// address dipakai sebagai associated data sehingga ciphertext tidak bisa dipindah ke bot lain
+type KeySealer interface {
+	Seal(plaintext, address string) (string, error)
+	Open(sealed, address string) (string, error)
+}
+
+type aesKeySealer struct {
+	aead cipher.AEAD
+}
+
+// NewKeySealer membuat sealer AES-256-GCM dari key hex 32 byte (64 karakter)
+func NewKeySealer(hexKey string) (KeySealer, error) {
+	key, err := hex.DecodeString(strings.TrimSpace(hexKey))
+	if err != nil {
+		return nil, fmt.Errorf("decode encryption key: %v", err)
+	}
+
+	if len(key) != 32 {
+		return nil, fmt.Errorf("encryption key must be 32 bytes, got %d", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &aesKeySealer{aead: aead}, nil
+}
+
+// IsSealed true jika nilai sudah dalam format terenkripsi
+func IsSealed(value string) bool {
+	return strings.HasPrefix(value, sealedKeyPrefix)
+}
+
+// Seal implements [KeySealer].
+func (s *aesKeySealer) Seal(plaintext, address string) (string, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("generate nonce: %v", err)
+	}
+
+	out := s.aead.Seal(nonce, nonce, []byte(plaintext), []byte(address))
+	return sealedKeyPrefix + base64.RawStdEncoding.EncodeToString(out), nil
+}
+
+// Open implements [KeySealer].
+func (s *aesKeySealer) Open(sealed, address string) (string, error) {
+	if !IsSealed(sealed) {
+		return "", errors.New("value is not sealed")
+	}
+
+	raw, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(sealed, sealedKeyPrefix))
+	if err != nil {
+		return "", fmt.Errorf("decode sealed value: %v", err)
+	}
+
+	nonceSize := s.aead.NonceSize()
+	if len(raw) < nonceSize {
+		return "", errors.New("sealed value too short")
+	}
+
+	plaintext, err := s.aead.Open(nil, raw[:nonceSize], raw[nonceSize:], []byte(address))
+	if err != nil {
+		return "", fmt.Errorf("decrypt sealed value: %v", err)
+	}
+
+	return string(plaintext), nil
+}
@@ -0,0 +1,48 @@
+package security
+
+import (
+	"strings"
+	"testing"
+)
+
+const testSealKey = "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+
+func TestKeySealerRoundTrip(t *testing.T) {
+	sealer, err := NewKeySealer(testSealKey)
+	if err != nil {
+		t.Fatalf("NewKeySealer: %v", err)
+	}
+
+	plain := strings.Repeat("ab", 32)
+	sealed, err := sealer.Seal(plain, "0xbot")
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	if !IsSealed(sealed) || strings.Contains(sealed, plain) {
+		t.Fatalf("sealed value leaks plaintext: %s", sealed)
+	}
+
+	// harus muat di kolom trading_bots.private_key VARCHAR(128)
+	if len(sealed) > 128 {
+		t.Fatalf("sealed length %d exceeds column size", len(sealed))
+	}
+
+	got, err := sealer.Open(sealed, "0xbot")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if got != plain {
+		t.Fatalf("Open = %q, want %q", got, plain)
+	}
+
+	if _, err := sealer.Open(sealed, "0xother"); err == nil {
+		t.Fatal("Open with a different address should fail")
+	}
+}
+
+func TestNewKeySealerRejectsShortKey(t *testing.T) {
+	if _, err := NewKeySealer("abcd"); err == nil {
+		t.Fatal("expected error for short key")
+	}
+}
@@ -52,3 +52,19 @@ func IntervalDuration(interval string) int64 {
 		return int64(time.Minute.Seconds())
 	}
 }
+
+// CandleIntervals - semua interval candle, urut dari timeframe terkecil
+var CandleIntervals = []string{"1m", "5m", "15m", "30m", "1h", "4h", "1d"}
+
+// NextIntervalStart - awal bucket berikutnya setelah start
+func NextIntervalStart(start int64, interval string) int64 {
+	duration := IntervalDuration(interval)
+
+	next := FloorTime(start+duration, interval)
+	if next <= start {
+		// pergeseran DST: hari lebih pendek/panjang dari 24 jam
+		next = FloorTime(start+duration+duration/2, interval)
+	}
+
+	return next
+}
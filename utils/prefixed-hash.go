@@ -1,8 +1,10 @@
 package utils
 
 import (
+	"crypto/ecdsa"
 	"fmt"
 
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
 )
 
@@ -17,3 +19,15 @@ func ToggleV(v byte) byte {
 	}
 	return 27
 }
+
+// SignPrefixedMessage sign message dengan format personal_sign (EIP-191), v = 27/28
+func SignPrefixedMessage(privateKey *ecdsa.PrivateKey, msg string) (string, error) {
+	sig, err := crypto.Sign(PrefixedHash([]byte(msg)), privateKey)
+	if err != nil {
+		return "", err
+	}
+
+	sig[64] += 27
+
+	return hexutil.Encode(sig), nil
+}
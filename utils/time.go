@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ParseTime - unix seconds atau RFC3339
+func ParseTime(value string) (int64, error) {
+	if value == "" {
+		return 0, fmt.Errorf("value is required")
+	}
+
+	if unix, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return unix, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return 0, err
+	}
+
+	return t.Unix(), nil
+}
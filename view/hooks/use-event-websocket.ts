@@ -5,11 +5,19 @@ export type TEventType =
   | "block.mined"
   | "subscribe"
   | "transaction.update"
-  | "balance.update";
+  | "balance.update"
+  | "market.ticker"
+  | "market.trades"
+  | "market.depth"
+  | "candles";
 
 interface IWebSocketMessage {
   type: TEventType;
   data: any;
+  // seq per address, dipakai untuk resume setelah reconnect
+  seq?: number;
+  // server mengirim ulang pesan ini sampai menerima ack
+  ack?: boolean;
 }
 
 interface IEventHandlers {
@@ -22,6 +30,7 @@ export const useEventWebSocket = (url: string) => {
   const subscribedEventsRef = useRef<TEventType[]>([]);
   const reconnectTimeoutRef = useRef<NodeJS.Timeout | null>(null);
   const shouldReconnectRef = useRef(true);
+  const lastSeqRef = useRef(0);
 
   const [connected, setConnected] = useState(false);
   const [error, setError] = useState<string | null>(null);
@@ -50,11 +59,34 @@ export const useEventWebSocket = (url: string) => {
             },
           })
         );
+
+        // minta pesan yang terlewat selama terputus
+        if (lastSeqRef.current > 0) {
+          ws.send(
+            JSON.stringify({
+              type: "resume",
+              data: { last_seq: lastSeqRef.current },
+            })
+          );
+        }
       }
     };
 
     ws.onmessage = (event) => {
       const msg: IWebSocketMessage = JSON.parse(event.data);
+
+      if (msg.ack && msg.seq) {
+        ws.send(JSON.stringify({ type: "ack", data: { seq: msg.seq } }));
+      }
+
+      if (msg.seq) {
+        // kiriman ulang (retry ack / resume) yang sudah diproses
+        if (msg.seq <= lastSeqRef.current) {
+          return;
+        }
+        lastSeqRef.current = msg.seq;
+      }
+
       handlersRef.current[msg.type]?.(msg.data);
     };
 
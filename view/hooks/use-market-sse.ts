@@ -46,7 +46,8 @@ export const UseMarketSSE = () => {
         setIsConnected(true);
       };
 
-      es.onmessage = (event) => {
+      // candle dikirim sebagai named event "candle"
+      es.addEventListener("candle", (event: MessageEvent) => {
         try {
           const newCandle: TOHLCV = JSON.parse(event.data);
           console.log("Received SSE candle data:", newCandle);
@@ -75,7 +76,7 @@ export const UseMarketSSE = () => {
         } catch (error) {
           console.error("Error parsing SSE message:", error);
         }
-      };
+      });
 
       es.onerror = (err) => {
         console.error("SSE error:", err);
@@ -3,6 +3,10 @@ export const WSEvents = {
   MARKET_UPDATE: "market.update",
   TRANSACTION_UPDATE: "transaction.update",
   BALANCE_UPDATE: "balance.update",
+  MARKET_TICKER: "market.ticker",
+  MARKET_TRADES: "market.trades",
+  MARKET_DEPTH: "market.depth",
+  CANDLES: "candles",
 } as const;
 
 export type WSEventType = (typeof WSEvents)[keyof typeof WSEvents];
@@ -7,6 +7,9 @@ export type TOHLCV = {
   low_price: number;
   close_price: number;
   volume: number;
+  buy_volume: number;
+  sell_volume: number;
+  trade_count: number;
 };
 
 export const TInterval = ["1m", "5m", "15m", "30m", "1h", "4h", "1d"] as const;
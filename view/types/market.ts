@@ -32,3 +32,61 @@ export type TMarketUpdateEvent = {
   type: "market.update";
   data: TMarketData;
 };
+
+// market feed: snapshot saat subscribe, delta setelahnya (seq naik 1 per delta)
+export type TFeedKind = "snapshot" | "delta";
+
+export type TTicker24h = {
+  seq: number;
+  kind: TFeedKind;
+  pair: string;
+  price: number;
+  open: number;
+  high: number;
+  low: number;
+  price_change: number;
+  price_change_percent: number;
+  buy_volume: number;
+  sell_volume: number;
+  volume: number;
+  tx_count: number;
+  timestamp: number;
+};
+
+export type TTradeEvent = {
+  pair: string;
+  tx_id: number;
+  block_number: number;
+  side: "BUY" | "SELL";
+  address: string;
+  amount: number;
+  price: number;
+  timestamp: number;
+};
+
+export type TRecentTradesUpdate = {
+  seq: number;
+  kind: TFeedKind;
+  pair: string;
+  trades: TTradeEvent[];
+};
+
+export type TDepthLevel = {
+  tier: number;
+  min_amount: number;
+  orders: number;
+  amount: number;
+};
+
+export type TDepthUpdate = {
+  seq: number;
+  kind: TFeedKind;
+  pair: string;
+  buys: TDepthLevel[];
+  sells: TDepthLevel[];
+  buy_amount: number;
+  sell_amount: number;
+  buy_orders: number;
+  sell_orders: number;
+  timestamp: number;
+};